@@ -9,6 +9,7 @@ import (
 	"sipflow/internal/binding"
 	"sipflow/internal/engine"
 	"sipflow/internal/scenario"
+	"sipflow/internal/scheduler"
 )
 
 // App struct
@@ -18,7 +19,9 @@ type App struct {
 	engineBinding   *binding.EngineBinding
 	scenarioBinding *binding.ScenarioBinding
 	mediaBinding    *binding.MediaBinding
+	scheduleBinding *binding.ScheduleBinding
 	scenarioRepo    *scenario.Repository
+	scheduler       *scheduler.Scheduler
 }
 
 // NewApp creates a new App application struct
@@ -45,12 +48,17 @@ func NewApp() *App {
 	// Create Engine
 	eng := engine.NewEngine(repo)
 
+	// Scheduled runs execute in the background while the app is open
+	sched := scheduler.New(repo, eng)
+
 	return &App{
 		engine:          eng,
 		engineBinding:   binding.NewEngineBinding(eng),
 		scenarioBinding: binding.NewScenarioBinding(repo),
 		mediaBinding:    binding.NewMediaBinding(),
+		scheduleBinding: binding.NewScheduleBinding(repo, sched),
 		scenarioRepo:    repo,
+		scheduler:       sched,
 	}
 }
 
@@ -62,10 +70,17 @@ func (a *App) startup(ctx context.Context) {
 	a.engineBinding.SetContext(ctx)
 	a.scenarioBinding.SetContext(ctx)
 	a.mediaBinding.SetContext(ctx)
+	a.scheduleBinding.SetContext(ctx)
+	a.scheduler.Start(ctx)
 }
 
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
+	// Stop the scheduler first so in-flight scheduled runs are stopped and recorded
+	if a.scheduler != nil {
+		a.scheduler.Stop()
+	}
+
 	// Stop running scenario if any
 	if a.engine != nil && a.engine.IsRunning() {
 		a.engine.StopScenario()
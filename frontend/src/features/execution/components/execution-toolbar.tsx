@@ -3,6 +3,7 @@ import { Play, Square, ToggleLeft } from 'lucide-react';
 import { toast } from 'sonner';
 import {
   useExecutionActions,
+  useExecutionRunId,
   useExecutionStatus,
 } from '../store/execution-store';
 import { useScenarioCurrentScenarioId } from '@/features/scenario/store/scenario-store';
@@ -18,9 +19,10 @@ const statusStyles: Record<string, string> = {
 
 export function ExecutionToolbar() {
   const status = useExecutionStatus();
-  const { reset, startListening, stopListening } = useExecutionActions();
+  const runId = useExecutionRunId();
+  const { beginRun, trackRun, cancelRun, startListening, stopListening } = useExecutionActions();
   const currentScenarioId = useScenarioCurrentScenarioId();
-  const { startScenario, stopScenario } = useEngineApi();
+  const { startScenario, stopRun } = useEngineApi();
 
   useEffect(() => {
     startListening();
@@ -36,9 +38,10 @@ export function ExecutionToolbar() {
     }
 
     try {
-      reset();
-      await startScenario(currentScenarioId);
+      beginRun();
+      trackRun(await startScenario(currentScenarioId));
     } catch (error) {
+      cancelRun();
       toast.error('Failed to start scenario', {
         description: String(error),
       });
@@ -46,8 +49,11 @@ export function ExecutionToolbar() {
   };
 
   const handleStop = async () => {
+    if (!runId) {
+      return;
+    }
     try {
-      await stopScenario();
+      await stopRun(runId);
     } catch (error) {
       toast.error('Failed to stop scenario', {
         description: String(error),
@@ -77,7 +83,7 @@ export function ExecutionToolbar() {
       {/* Stop button */}
       <button
         onClick={handleStop}
-        disabled={status !== 'running'}
+        disabled={status !== 'running' || !runId}
         className="flex items-center gap-1 px-3 py-1 text-sm bg-red-600 text-white rounded hover:bg-red-700 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
         title="Stop scenario"
       >
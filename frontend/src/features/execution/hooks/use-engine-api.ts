@@ -1,6 +1,6 @@
 import {
   StartScenario,
-  StopScenario,
+  StopRun,
   IsRunning,
 } from '../../../../wailsjs/go/binding/EngineBinding';
 
@@ -8,20 +8,20 @@ import {
  * Hook providing typed wrappers around Wails EngineBinding calls
  */
 export function useEngineApi() {
-  const startScenario = async (scenarioId: string): Promise<void> => {
+  const startScenario = async (scenarioId: string): Promise<string> => {
     try {
-      await StartScenario(scenarioId);
+      return await StartScenario(scenarioId);
     } catch (error) {
       console.error('Failed to start scenario:', error);
       throw error;
     }
   };
 
-  const stopScenario = async (): Promise<void> => {
+  const stopRun = async (runId: string): Promise<void> => {
     try {
-      await StopScenario();
+      await StopRun(runId);
     } catch (error) {
-      console.error('Failed to stop scenario:', error);
+      console.error('Failed to stop run:', error);
       throw error;
     }
   };
@@ -37,7 +37,7 @@ export function useEngineApi() {
 
   return {
     startScenario,
-    stopScenario,
+    stopRun,
     isRunning,
   };
 }
@@ -16,6 +16,8 @@ import type {
 import { EXECUTION_EVENTS } from '../types/execution';
 
 interface ExecutionStoreState {
+  runId: string | null;
+  awaitingRun: boolean;
   status: ScenarioExecutionStatus;
   nodeStates: Record<string, NodeExecutionState>;
   actionLogs: ActionLog[];
@@ -25,6 +27,9 @@ interface ExecutionStoreState {
 }
 
 interface ExecutionStoreActions {
+  beginRun: () => void;
+  trackRun: (runId: string) => void;
+  cancelRun: () => void;
   startListening: () => void;
   stopListening: () => void;
   updateNodeState: (event: NodeStateEvent) => void;
@@ -42,8 +47,13 @@ type ExecutionStore = ExecutionStoreState & {
 
 const MAX_ACTION_LOGS = 500;
 
+// Events that arrive between StartScenario and its returned runId are held until the run is known.
+let pendingRunEvents: Array<{ runId: string; apply: () => void }> = [];
+
 export const useExecutionStore = createStore<ExecutionStore>(
   (set, get) => ({
+    runId: null,
+    awaitingRun: false,
     status: 'idle',
     nodeStates: {},
     actionLogs: [],
@@ -51,39 +61,81 @@ export const useExecutionStore = createStore<ExecutionStore>(
     edgeAnimations: [],
     scenarioError: null,
     actions: {
+      beginRun: () => {
+        pendingRunEvents = [];
+        get().actions.reset();
+        set((state) => {
+          state.awaitingRun = true;
+        });
+      },
+      trackRun: (runId) => {
+        const pending = pendingRunEvents;
+        pendingRunEvents = [];
+        set((state) => {
+          state.runId = runId;
+          state.awaitingRun = false;
+        });
+        pending.filter((event) => event.runId === runId).forEach((event) => event.apply());
+      },
+      cancelRun: () => {
+        pendingRunEvents = [];
+        set((state) => {
+          state.awaitingRun = false;
+        });
+      },
       startListening: () => {
+        // Only events of the tracked run reach the store; other runs share the same event names.
+        const forTrackedRun = (runId: string | undefined, apply: () => void) => {
+          const { runId: trackedRunId, awaitingRun } = get();
+          if (!runId) {
+            apply();
+          } else if (awaitingRun) {
+            pendingRunEvents.push({ runId, apply });
+          } else if (runId === trackedRunId) {
+            apply();
+          }
+        };
+
         EventsOn(EXECUTION_EVENTS.NODE_STATE, (event: NodeStateEvent) => {
-          get().actions.updateNodeState(event);
+          forTrackedRun(event.runId, () => get().actions.updateNodeState(event));
         });
 
         EventsOn(EXECUTION_EVENTS.ACTION_LOG, (event: ActionLogEvent) => {
-          get().actions.addActionLog(event);
+          forTrackedRun(event.runId, () => get().actions.addActionLog(event));
         });
 
-        EventsOn(EXECUTION_EVENTS.STARTED, (_event: ScenarioStartedEvent) => {
-          set((state) => {
-            state.status = 'running';
-            state.scenarioError = null;
-          });
+        EventsOn(EXECUTION_EVENTS.STARTED, (event: ScenarioStartedEvent) => {
+          forTrackedRun(event.runId, () =>
+            set((state) => {
+              state.status = 'running';
+              state.scenarioError = null;
+            })
+          );
         });
 
-        EventsOn(EXECUTION_EVENTS.COMPLETED, (_event: ScenarioCompletedEvent) => {
-          set((state) => {
-            state.status = 'completed';
-          });
+        EventsOn(EXECUTION_EVENTS.COMPLETED, (event: ScenarioCompletedEvent) => {
+          forTrackedRun(event.runId, () =>
+            set((state) => {
+              state.status = 'completed';
+            })
+          );
         });
 
         EventsOn(EXECUTION_EVENTS.FAILED, (event: ScenarioFailedEvent) => {
-          set((state) => {
-            state.status = 'failed';
-            state.scenarioError = event.error;
-          });
+          forTrackedRun(event.runId, () =>
+            set((state) => {
+              state.status = 'failed';
+              state.scenarioError = event.error;
+            })
+          );
         });
 
-        EventsOn(EXECUTION_EVENTS.STOPPED, (_event: ScenarioStoppedEvent) => {
-          set((state) => {
-            state.status = 'stopped';
-          });
+        EventsOn(EXECUTION_EVENTS.STOPPED, (event: ScenarioStoppedEvent) => {
+          forTrackedRun(event.runId, () =>
+            set((state) => {
+              state.status = 'stopped';
+            })
+          );
         });
       },
       stopListening: () => {
@@ -159,6 +211,8 @@ export const useExecutionStore = createStore<ExecutionStore>(
       },
       reset: () => {
         set((state) => {
+          state.runId = null;
+          state.awaitingRun = false;
           state.status = 'idle';
           state.nodeStates = {};
           state.actionLogs = [];
@@ -176,6 +230,9 @@ export const useExecutionStore = createStore<ExecutionStore>(
   }
 );
 
+export const useExecutionRunId = () =>
+  useExecutionStore((state) => state.runId);
+
 export const useExecutionStatus = () =>
   useExecutionStore((state) => state.status);
 
@@ -1,5 +1,5 @@
 // 노드 실행 상태
-export type NodeExecutionStatus = 'pending' | 'running' | 'completed' | 'failed';
+export type NodeExecutionStatus = 'pending' | 'running' | 'retrying' | 'completed' | 'failed' | 'skipped';
 
 // 시나리오 실행 상태
 export type ScenarioExecutionStatus = 'idle' | 'running' | 'completed' | 'failed' | 'stopped';
@@ -36,13 +36,19 @@ export interface EdgeAnimationMessage {
 
 // Go -> Frontend 이벤트 페이로드 타입
 export interface NodeStateEvent {
+  runId: string;
   nodeId: string;
   previousState: NodeExecutionStatus;
   newState: NodeExecutionStatus;
   timestamp: number;
+  attempt?: number;     // retrying 상태에서만 — 실패한 시도 번호
+  maxAttempts?: number;
+  error?: string;
+  retryInMs?: number;
 }
 
 export interface ActionLogEvent {
+  runId?: string;           // 엔진 전역 로그에는 없음
   timestamp: number;
   nodeId: string;
   instanceId: string;
@@ -53,20 +59,24 @@ export interface ActionLogEvent {
 }
 
 export interface ScenarioStartedEvent {
+  runId: string;
   scenarioId: string;
   timestamp: number;
 }
 
 export interface ScenarioCompletedEvent {
+  runId: string;
   timestamp: number;
 }
 
 export interface ScenarioFailedEvent {
+  runId: string;
   timestamp: number;
   error: string;
 }
 
 export interface ScenarioStoppedEvent {
+  runId: string;
   timestamp: number;
 }
 
@@ -1,4 +1,4 @@
-import { Redo2, Trash2, Undo2 } from 'lucide-react';
+import { Redo2, Settings2, Trash2, Undo2 } from 'lucide-react';
 import { Button } from '@/components/ui/button';
 
 interface CanvasToolbarProps {
@@ -8,6 +8,7 @@ interface CanvasToolbarProps {
   onUndo: () => void;
   onRedo: () => void;
   onDelete: () => void;
+  onOpenSettings: () => void;
 }
 
 export function CanvasToolbar({
@@ -17,6 +18,7 @@ export function CanvasToolbar({
   onUndo,
   onRedo,
   onDelete,
+  onOpenSettings,
 }: CanvasToolbarProps) {
   return (
     <div className="flex items-center gap-1.5 rounded-lg border border-border bg-background/95 p-1.5 shadow-sm backdrop-blur">
@@ -56,6 +58,17 @@ export function CanvasToolbar({
       >
         <Trash2 />
       </Button>
+      <Button
+        type="button"
+        variant="ghost"
+        size="icon"
+        className="h-8 w-8 rounded-md"
+        onClick={onOpenSettings}
+        title="Scenario settings"
+        aria-label="Scenario settings"
+      >
+        <Settings2 />
+      </Button>
     </div>
   );
 }
@@ -1,4 +1,4 @@
-import { useEffect, useRef } from 'react';
+import { useEffect, useRef, useState } from 'react';
 import { useTheme } from 'next-themes';
 import { toast } from 'sonner';
 import { v4 as uuidv4 } from 'uuid';
@@ -39,9 +39,10 @@ import { wouldCreateCycle } from '../lib/validation';
 import { edgeTypes } from '../edges/branch-edge';
 import { nodeTypes } from './nodes';
 import { CanvasToolbar } from './canvas-toolbar';
+import { ScenarioSettingsModal } from './scenario-settings-modal';
 import { HelperLines } from './helper-lines';
 import { ConnectionLine } from './connection-line';
-import { INSTANCE_COLORS, DEFAULT_CODECS } from '../types/scenario';
+import { INSTANCE_COLORS } from '../types/scenario';
 import type { EdgeAnimationMessage } from '@/features/execution/types/execution';
 import { formatEventLabel } from '../lib/event-label';
 
@@ -79,6 +80,7 @@ export function Canvas() {
   const actionLogs = useExecutionActionLogs();
   const { addEdgeAnimation } = useExecutionActions();
   const lastLogCountRef = useRef(0);
+  const [isSettingsOpen, setIsSettingsOpen] = useState(false);
 
   const onDrop = (event: React.DragEvent) => {
     event.preventDefault();
@@ -106,7 +108,7 @@ export function Canvas() {
         dn: '',
         register: true,
         color: INSTANCE_COLORS[instanceCount % INSTANCE_COLORS.length],
-        codecs: [...DEFAULT_CODECS],
+        // codecs are left unset so the project's codec setting applies until the user reorders them
       };
     } else if (dragType.startsWith('command-')) {
       // Parse command name from type string (e.g., 'command-MakeCall' -> 'MakeCall')
@@ -283,6 +285,7 @@ export function Canvas() {
             onUndo={undo}
             onRedo={redo}
             onDelete={handleDeleteSelected}
+            onOpenSettings={() => setIsSettingsOpen(true)}
           />
         </Panel>
 
@@ -291,6 +294,7 @@ export function Canvas() {
         <Controls />
         <MiniMap />
       </ReactFlow>
+      <ScenarioSettingsModal isOpen={isSettingsOpen} onExit={() => setIsSettingsOpen(false)} />
     </div>
   );
 }
@@ -14,8 +14,33 @@ import {
   Pause,
   Play,
   ArrowRightLeft,
+  Link,
+  Unlink,
+  Users,
   ChevronDown,
   ChevronRight,
+  UserCheck,
+  Forward,
+  Replace,
+  SquareParking,
+  SquareParkingOff,
+  Hand,
+  PhoneForwarded,
+  Eye,
+  EyeOff,
+  BellDot,
+  MessageSquare,
+  Activity,
+  MessageSquareText,
+  TimerOff,
+  RefreshCw,
+  SlidersHorizontal,
+  RotateCcw,
+  Sliders,
+  Flag,
+  Hourglass,
+  Fence,
+  Workflow,
 } from 'lucide-react';
 import { Separator } from '@/components/ui/separator';
 import { formatEventLabel } from '../lib/event-label';
@@ -151,6 +176,96 @@ export function NodePalette() {
           icon={ArrowRightLeft}
           colorClass={COMMAND_ITEM_CLASS}
         />
+        <PaletteItem
+          type="command-Bridge"
+          label="Bridge"
+          icon={Link}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Unbridge"
+          label="Unbridge"
+          icon={Unlink}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Conference"
+          label="Conference"
+          icon={Users}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Park"
+          label="Park"
+          icon={SquareParking}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Unpark"
+          label="Unpark"
+          icon={SquareParkingOff}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Pickup"
+          label="Pickup"
+          icon={Hand}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-SetForwarding"
+          label="SetForwarding"
+          icon={PhoneForwarded}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Subscribe"
+          label="Subscribe"
+          icon={Eye}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Unsubscribe"
+          label="Unsubscribe"
+          icon={EyeOff}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-SendMessage"
+          label="SendMessage"
+          icon={MessageSquare}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-SendOptions"
+          label="SendOptions"
+          icon={Activity}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-ReInvite"
+          label="ReInvite"
+          icon={RefreshCw}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Update"
+          label="Update"
+          icon={SlidersHorizontal}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-Signal"
+          label="Signal"
+          icon={Flag}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="command-CallScenario"
+          label="CallScenario"
+          icon={Workflow}
+          colorClass={COMMAND_ITEM_CLASS}
+        />
       </Section>
 
       <Separator />
@@ -198,12 +313,72 @@ export function NodePalette() {
           icon={ArrowRightLeft}
           colorClass={EVENT_ITEM_CLASS}
         />
+        <PaletteItem
+          type="event-REFER_RECEIVED"
+          label={formatEventLabel('REFER_RECEIVED')}
+          icon={Forward}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-REPLACED"
+          label={formatEventLabel('REPLACED')}
+          icon={Replace}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-SESSION_REFRESH_FAILED"
+          label={formatEventLabel('SESSION_REFRESH_FAILED')}
+          icon={TimerOff}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-REINVITE_RECEIVED"
+          label={formatEventLabel('REINVITE_RECEIVED')}
+          icon={RotateCcw}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-UPDATE_RECEIVED"
+          label={formatEventLabel('UPDATE_RECEIVED')}
+          icon={Sliders}
+          colorClass={EVENT_ITEM_CLASS}
+        />
         <PaletteItem
           type="event-DTMFReceived"
           label={formatEventLabel('DTMFReceived')}
           icon={Ear}
           colorClass={EVENT_ITEM_CLASS}
         />
+        <PaletteItem
+          type="event-WaitSignal"
+          label={formatEventLabel('WaitSignal')}
+          icon={Hourglass}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-Barrier"
+          label={formatEventLabel('Barrier')}
+          icon={Fence}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-REGISTER_RECEIVED"
+          label={formatEventLabel('REGISTER_RECEIVED')}
+          icon={UserCheck}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-NOTIFY_RECEIVED"
+          label={formatEventLabel('NOTIFY_RECEIVED')}
+          icon={BellDot}
+          colorClass={EVENT_ITEM_CLASS}
+        />
+        <PaletteItem
+          type="event-MESSAGE_RECEIVED"
+          label={formatEventLabel('MESSAGE_RECEIVED')}
+          icon={MessageSquareText}
+          colorClass={EVENT_ITEM_CLASS}
+        />
       </Section>
     </div>
   );
@@ -1,5 +1,29 @@
 import type { NodeProps } from '@xyflow/react';
-import { Phone, PhoneIncoming, PhoneOff, Volume2, Hash, Pause, Play, ArrowRightLeft } from 'lucide-react';
+import {
+  Phone,
+  PhoneIncoming,
+  PhoneOff,
+  Volume2,
+  Hash,
+  Pause,
+  Play,
+  ArrowRightLeft,
+  Link,
+  Unlink,
+  Users,
+  SquareParking,
+  SquareParkingOff,
+  Hand,
+  PhoneForwarded,
+  Eye,
+  EyeOff,
+  MessageSquare,
+  Activity,
+  RefreshCw,
+  SlidersHorizontal,
+  Flag,
+  Workflow,
+} from 'lucide-react';
 import type { CommandNode as CommandNodeType } from '../../types/scenario';
 import {
   useFlowEditorNodes,
@@ -18,11 +42,27 @@ const COMMAND_ICONS = {
   Retrieve: Play,
   BlindTransfer: ArrowRightLeft,
   MuteTransfer: ArrowRightLeft,
+  Bridge: Link,
+  Unbridge: Unlink,
+  Conference: Users,
+  Park: SquareParking,
+  Unpark: SquareParkingOff,
+  Pickup: Hand,
+  SetForwarding: PhoneForwarded,
+  Subscribe: Eye,
+  Unsubscribe: EyeOff,
+  SendMessage: MessageSquare,
+  SendOptions: Activity,
+  ReInvite: RefreshCw,
+  Update: SlidersHorizontal,
+  Signal: Flag,
+  CallScenario: Workflow,
 } as const;
 
 function getExecutionState(status?: string): 'running' | 'completed' | 'failed' | null {
   switch (status) {
     case 'running':
+    case 'retrying':
       return 'running';
     case 'completed':
       return 'completed';
@@ -36,7 +76,10 @@ function getExecutionState(status?: string): 'running' | 'completed' | 'failed'
 function getCommandSummary(data: CommandNodeType['data']): string | null {
   switch (data.command) {
     case 'MakeCall':
+    case 'SendOptions':
       return data.targetUri ?? null;
+    case 'SendMessage':
+      return data.targetUri ? `${data.targetUri}: ${data.body ?? ''}` : null;
     case 'PlayAudio':
       return data.filePath?.split(/[\\/]/).pop() ?? null;
     case 'SendDTMF':
@@ -47,6 +90,38 @@ function getCommandSummary(data: CommandNodeType['data']): string | null {
         : null;
     case 'MuteTransfer':
       return 'Consult transfer';
+    case 'Bridge':
+      return data.peerCallId ? `↔ ${data.peerCallId}` : null;
+    case 'Conference':
+      return `${data.conferenceAction === 'leave' ? 'Leave' : 'Join'} ${
+        data.callIds?.length ? data.callIds.join(', ') : data.callId ?? 'default'
+      }`;
+    case 'Park':
+      return data.parkSlot ? `Slot ${data.parkSlot}` : null;
+    case 'Unpark':
+      return data.parkSlot ? `Slot ${data.parkSlot}` : 'Last parked';
+    case 'Pickup':
+      return data.pickupTarget
+        ? `${data.pickupTarget}${data.pickupMode === 'replaces' ? ' (Replaces)' : ''}`
+        : null;
+    case 'SetForwarding':
+      return data.forwardEnabled === false
+        ? `${data.forwardType ?? 'always'} off`
+        : data.forwardTarget
+        ? `${data.forwardType ?? 'always'} → ${data.forwardTarget}`
+        : null;
+    case 'Subscribe':
+    case 'Unsubscribe':
+      return data.subscribeTarget ? `${data.eventPackage ?? 'dialog'} ${data.subscribeTarget}` : null;
+    case 'ReInvite':
+    case 'Update':
+      return data.withoutSdp
+        ? 'Without SDP'
+        : [data.direction, data.codecs?.join(','), data.mediaAddress].filter(Boolean).join(' ') || null;
+    case 'Signal':
+      return data.signalName ? `Raise ${data.signalName}` : null;
+    case 'CallScenario':
+      return data.fragmentName ? `Fragment ${data.fragmentName}` : data.scenarioId ? 'Saved scenario' : null;
     default:
       return null;
   }
@@ -74,6 +149,7 @@ export function CommandNode({ data, id, selected }: NodeProps<CommandNodeType>)
     data.timeout ? { label: 'Timeout', value: `${data.timeout}ms` } : null,
     data.primaryCallId ? { label: 'Primary', value: data.primaryCallId } : null,
     data.consultCallId ? { label: 'Consult', value: data.consultCallId } : null,
+    data.peerCallId ? { label: 'Peer', value: data.peerCallId } : null,
   ].filter(Boolean) as Array<{ label: string; value: string }>;
 
   return (
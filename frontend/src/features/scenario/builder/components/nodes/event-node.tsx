@@ -8,6 +8,16 @@ import {
   Play,
   ArrowRightLeft,
   Ear,
+  UserCheck,
+  Forward,
+  Replace,
+  TimerOff,
+  BellDot,
+  MessageSquareText,
+  RotateCcw,
+  Sliders,
+  Hourglass,
+  Fence,
 } from 'lucide-react';
 import type { EventNode as EventNodeType } from '../../types/scenario';
 import {
@@ -26,12 +36,23 @@ const EVENT_ICONS = {
   HELD: Pause,
   RETRIEVED: Play,
   TRANSFERRED: ArrowRightLeft,
+  REFER_RECEIVED: Forward,
+  REPLACED: Replace,
+  SESSION_REFRESH_FAILED: TimerOff,
+  REINVITE_RECEIVED: RotateCcw,
+  UPDATE_RECEIVED: Sliders,
   DTMFReceived: Ear,
+  WaitSignal: Hourglass,
+  Barrier: Fence,
+  REGISTER_RECEIVED: UserCheck,
+  NOTIFY_RECEIVED: BellDot,
+  MESSAGE_RECEIVED: MessageSquareText,
 } as const;
 
 function getExecutionState(status?: string): 'running' | 'completed' | 'failed' | null {
   switch (status) {
     case 'running':
+    case 'retrying':
       return 'running';
     case 'completed':
       return 'completed';
@@ -50,6 +71,23 @@ function getEventSummary(data: EventNodeType['data']): string | null {
       return data.expectedDigit ? `Expect ${data.expectedDigit}` : 'Expect any digit';
     case 'INCOMING':
       return data.number ? `Wait for ${data.number}` : 'Wait for inbound call';
+    case 'MESSAGE_RECEIVED':
+      return data.messageFrom || data.messageMatch
+        ? [data.messageFrom && `from ${data.messageFrom}`, data.messageMatch && `/${data.messageMatch}/`].filter(Boolean).join(' ')
+        : null;
+    case 'REINVITE_RECEIVED':
+    case 'UPDATE_RECEIVED':
+      return [data.direction, data.codec].filter(Boolean).join(' ') || null;
+    case 'WaitSignal':
+      return data.signalName ? `Wait for ${data.signalName}` : null;
+    case 'Barrier':
+      return data.barrierName
+        ? `${data.barrierName}${data.parties ? ` (${data.parties} chains)` : ''}`
+        : null;
+    case 'NOTIFY_RECEIVED':
+      return data.subscribeTarget
+        ? `${data.eventPackage ?? 'dialog'} ${data.subscribeTarget}${data.expectedState ? ` = ${data.expectedState}` : ''}`
+        : null;
     default:
       return null;
   }
@@ -4,7 +4,6 @@ import { Badge } from '@/components/ui/badge';
 import { useExecutionStatus } from '@/features/execution/store/execution-store';
 import { usePbxInstances, type PbxInstanceSettings } from '@/features/settings/store/app-settings-store';
 import type { SipInstanceNode } from '../../types/scenario';
-import { DEFAULT_CODECS } from '../../types/scenario';
 import { useFlowEditorValidationErrors } from '../../store/flow-editor-context';
 import { NodeShell } from './node-shell';
 
@@ -27,7 +26,7 @@ export function SipInstanceNode({ data, id, selected }: NodeProps<SipInstanceNod
   const status = useExecutionStatus();
   const isActive = status === 'running';
   const displayName = data.dn || data.label || 'SIP Instance';
-  const codecs = data.codecs && data.codecs.length > 0 ? data.codecs : DEFAULT_CODECS;
+  const codecsLabel = data.codecs && data.codecs.length > 0 ? data.codecs.join(', ') : 'Project default';
   const selectedPbxInstanceId = data.pbxInstanceId || data.serverId;
   const selectedSipInstance = selectedPbxInstanceId
     ? sipInstances.find((instance) => instance.id === selectedPbxInstanceId)
@@ -66,8 +65,8 @@ export function SipInstanceNode({ data, id, selected }: NodeProps<SipInstanceNod
         </div>
         <div className="flex items-center justify-between gap-3 text-xs">
           <span className="text-muted-foreground">Codecs</span>
-          <span className="max-w-[148px] truncate font-medium text-card-foreground" title={codecs.join(', ')}>
-            {codecs.join(', ')}
+          <span className="max-w-[148px] truncate font-medium text-card-foreground" title={codecsLabel}>
+            {codecsLabel}
           </span>
         </div>
       </div>
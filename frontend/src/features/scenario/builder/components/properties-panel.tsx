@@ -44,6 +44,7 @@ export function PropertiesPanel() {
 
       {selectedNode.type === 'command' && (
         <CommandProperties
+          key={selectedNode.id}
           node={selectedNode as CommandNode}
           onUpdate={handleUpdate}
         />
@@ -1,4 +1,4 @@
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
@@ -7,9 +7,40 @@ import { Separator } from '@/components/ui/separator';
 import { Button } from '@/components/ui/button';
 import { toast } from 'sonner';
 import { useFlowEditorNodes } from '../../store/flow-editor-context';
-import type { CommandNode } from '../../types/scenario';
+import {
+  CONFERENCE_ACTIONS,
+  EVENT_PACKAGES,
+  FORWARD_TYPES,
+  MEDIA_CODECS,
+  MEDIA_DIRECTIONS,
+  PICKUP_MODES,
+  type CommandNode,
+} from '../../types/scenario';
 import { getInstanceDisplayName } from '../../lib/instance-key';
+import { RetryProperties } from './retry-properties';
 import { SelectWAVFile } from '../../../../../../wailsjs/go/binding/MediaBinding';
+import { ListFragments, ListScenarios } from '../../../../../../wailsjs/go/binding/ScenarioBinding';
+import { useScenarioCurrentScenarioId } from '@/features/scenario/store/scenario-store';
+
+// Formats a name -> value map as "name=value, ..." for single-line editing
+function formatAssignments(values?: Record<string, unknown>): string {
+  return Object.entries(values ?? {})
+    .map(([name, value]) => `${name}=${String(value)}`)
+    .join(', ');
+}
+
+// Parses "name=value, ..." back into a map; numeric and boolean values keep their type
+function parseAssignments(text: string): Record<string, string | number | boolean> | undefined {
+  const values: Record<string, string | number | boolean> = {};
+  text.split(',').forEach((entry) => {
+    const [name, ...rest] = entry.split('=');
+    if (!name?.trim()) return;
+    const value = rest.join('=').trim();
+    values[name.trim()] =
+      /^-?\d+(\.\d+)?$/.test(value) ? Number(value) : value === 'true' || value === 'false' ? value === 'true' : value;
+  });
+  return Object.keys(values).length > 0 ? values : undefined;
+}
 
 interface CommandPropertiesProps {
   node: CommandNode;
@@ -20,6 +51,31 @@ export function CommandProperties({ node, onUpdate }: CommandPropertiesProps) {
   const { data } = node;
   const nodes = useFlowEditorNodes();
   const [isSelecting, setIsSelecting] = useState(false);
+  const [callIdsText, setCallIdsText] = useState((data.callIds ?? []).join(', '));
+  const [codecsText, setCodecsText] = useState((data.codecs ?? []).join(', '));
+  const [paramsText, setParamsText] = useState(formatAssignments(data.params));
+  const [fragmentNames, setFragmentNames] = useState<string[]>([]);
+  const [scenarioOptions, setScenarioOptions] = useState<Array<{ id: string; name: string }>>([]);
+  const currentScenarioId = useScenarioCurrentScenarioId();
+
+  useEffect(() => {
+    if (data.command !== 'CallScenario') return;
+    let cancelled = false;
+    Promise.all([ListFragments(), ListScenarios()])
+      .then(([fragments, scenarios]) => {
+        if (cancelled) return;
+        setFragmentNames(fragments.map((fragment) => fragment.name));
+        setScenarioOptions(
+          scenarios
+            .filter((scenario) => scenario.id !== currentScenarioId)
+            .map((scenario) => ({ id: scenario.id, name: scenario.name }))
+        );
+      })
+      .catch((err) => console.error('Failed to list callable flows:', err));
+    return () => {
+      cancelled = true;
+    };
+  }, [data.command, currentScenarioId]);
 
   // Filter SIP Instance nodes for instance assignment
   const sipInstanceNodes = nodes.filter((n) => n.type === 'sipInstance');
@@ -197,6 +253,466 @@ export function CommandProperties({ node, onUpdate }: CommandPropertiesProps) {
           </div>
         </>
       )}
+
+      {data.command === 'Bridge' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="peerInstance">Peer Number</Label>
+            <Select
+              value={data.peerInstanceId || 'same'}
+              onValueChange={(value) => onUpdate({ peerInstanceId: value === 'same' ? undefined : value })}
+            >
+              <SelectTrigger id="peerInstance">
+                <SelectValue placeholder="Select number..." />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="same">Same number</SelectItem>
+                {sipInstanceNodes.map((instance) => (
+                  <SelectItem key={instance.id} value={instance.id}>
+                    {getInstanceDisplayName(instance)}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="peerCallId">Peer Call ID</Label>
+            <Input
+              id="peerCallId"
+              value={data.peerCallId || ''}
+              onChange={(e) => onUpdate({ peerCallId: e.target.value })}
+              placeholder="consult"
+            />
+            <p className="text-xs text-muted-foreground">
+              Media of this call and the peer call is relayed until Unbridge or hangup
+            </p>
+          </div>
+        </>
+      )}
+
+      {data.command === 'Conference' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="conferenceAction">Action</Label>
+            <Select
+              value={data.conferenceAction || 'join'}
+              onValueChange={(value) =>
+                onUpdate({ conferenceAction: value as (typeof CONFERENCE_ACTIONS)[number] })
+              }
+            >
+              <SelectTrigger id="conferenceAction">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {CONFERENCE_ACTIONS.map((action) => (
+                  <SelectItem key={action} value={action}>
+                    {action === 'join' ? 'Join' : 'Leave'}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="conferenceCallIds">Call IDs</Label>
+            <Input
+              id="conferenceCallIds"
+              value={callIdsText}
+              onChange={(e) => {
+                setCallIdsText(e.target.value);
+                const callIds = e.target.value
+                  .split(',')
+                  .map((id) => id.trim())
+                  .filter(Boolean);
+                onUpdate({ callIds: callIds.length > 0 ? callIds : undefined });
+              }}
+              placeholder="call-1, call-2, call-3"
+            />
+            <p className="text-xs text-muted-foreground">
+              Comma-separated. Empty uses the node's call ID. Audio of all joined calls is mixed locally (PCMU/PCMA)
+            </p>
+          </div>
+        </>
+      )}
+
+      {(data.command === 'Park' || data.command === 'Unpark') && (
+        <div className="space-y-2">
+          <Label htmlFor="parkSlot">Park Slot</Label>
+          <Input
+            id="parkSlot"
+            value={data.parkSlot || ''}
+            onChange={(e) => onUpdate({ parkSlot: e.target.value || undefined })}
+            placeholder={data.command === 'Park' ? 'Assigned by PBX' : 'Last parked slot'}
+          />
+          <p className="text-xs text-muted-foreground">
+            Feature codes come from the project PBX profile
+          </p>
+        </div>
+      )}
+
+      {data.command === 'Pickup' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="pickupTarget">Target Number</Label>
+            <Input
+              id="pickupTarget"
+              value={data.pickupTarget || ''}
+              onChange={(e) => onUpdate({ pickupTarget: e.target.value })}
+              placeholder="200"
+            />
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="pickupMode">Mode</Label>
+            <Select
+              value={data.pickupMode || 'featureCode'}
+              onValueChange={(value) => onUpdate({ pickupMode: value as (typeof PICKUP_MODES)[number] })}
+            >
+              <SelectTrigger id="pickupMode">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {PICKUP_MODES.map((mode) => (
+                  <SelectItem key={mode} value={mode}>
+                    {mode === 'featureCode' ? 'Feature code' : 'Replaces (dialog-info)'}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+        </>
+      )}
+
+      {data.command === 'SetForwarding' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="forwardType">Forwarding</Label>
+            <Select
+              value={data.forwardType || 'always'}
+              onValueChange={(value) => onUpdate({ forwardType: value as (typeof FORWARD_TYPES)[number] })}
+            >
+              <SelectTrigger id="forwardType">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {FORWARD_TYPES.map((type) => (
+                  <SelectItem key={type} value={type}>
+                    {type === 'always' ? 'Always' : type === 'busy' ? 'Busy' : 'No answer'}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="forwardEnabled">State</Label>
+            <Select
+              value={data.forwardEnabled === false ? 'off' : 'on'}
+              onValueChange={(value) => onUpdate({ forwardEnabled: value === 'on' })}
+            >
+              <SelectTrigger id="forwardEnabled">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="on">Enable</SelectItem>
+                <SelectItem value="off">Disable</SelectItem>
+              </SelectContent>
+            </Select>
+          </div>
+
+          {data.forwardEnabled !== false && (
+            <div className="space-y-2">
+              <Label htmlFor="forwardTarget">Forward To</Label>
+              <Input
+                id="forwardTarget"
+                value={data.forwardTarget || ''}
+                onChange={(e) => onUpdate({ forwardTarget: e.target.value })}
+                placeholder="300"
+              />
+            </div>
+          )}
+        </>
+      )}
+
+      {(data.command === 'SendMessage' || data.command === 'SendOptions') && (
+        <div className="space-y-2">
+          <Label htmlFor="targetUri">Target Number / URI</Label>
+          <Input
+            id="targetUri"
+            value={data.targetUri || ''}
+            onChange={(e) => onUpdate({ targetUri: e.target.value })}
+            placeholder="200 또는 sip:user@domain"
+          />
+        </div>
+      )}
+
+      {data.command === 'SendMessage' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="contentType">Content-Type</Label>
+            <Input
+              id="contentType"
+              value={data.contentType ?? 'text/plain'}
+              onChange={(e) => onUpdate({ contentType: e.target.value })}
+              placeholder="text/plain"
+            />
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="body">Body</Label>
+            <Input
+              id="body"
+              value={data.body || ''}
+              onChange={(e) => onUpdate({ body: e.target.value })}
+              placeholder="Hello"
+            />
+            <p className="text-xs text-muted-foreground">
+              Sent as a SIP MESSAGE outside any call. Fails unless the target answers 2xx
+            </p>
+          </div>
+        </>
+      )}
+
+      {data.command === 'SendOptions' && (
+        <div className="space-y-2">
+          <Label htmlFor="expectedStatus">Expected Status</Label>
+          <Input
+            id="expectedStatus"
+            type="number"
+            value={data.expectedStatus ?? 200}
+            onChange={(e) => {
+              const val = parseInt(e.target.value, 10);
+              onUpdate({ expectedStatus: Number.isNaN(val) ? 200 : val });
+            }}
+            min={0}
+            max={699}
+          />
+          <p className="text-xs text-muted-foreground">
+            Response code the probe must get. Use 0 to accept any final response
+          </p>
+        </div>
+      )}
+
+      {(data.command === 'ReInvite' || data.command === 'Update') && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="withoutSdp">Offer</Label>
+            <Select
+              value={data.withoutSdp ? 'none' : 'sdp'}
+              onValueChange={(value) =>
+                onUpdate(
+                  value === 'none'
+                    ? { withoutSdp: true, direction: undefined, codecs: undefined, mediaAddress: undefined }
+                    : { withoutSdp: false }
+                )
+              }
+            >
+              <SelectTrigger id="withoutSdp">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="sdp">SDP offer</SelectItem>
+                <SelectItem value="none">Without SDP</SelectItem>
+              </SelectContent>
+            </Select>
+            {data.withoutSdp && (
+              <p className="text-xs text-muted-foreground">
+                {data.command === 'ReInvite'
+                  ? 'The peer offers SDP in its 200 OK and the answer goes in the ACK'
+                  : 'Sends an UPDATE without a body (e.g. a session refresh)'}
+              </p>
+            )}
+          </div>
+
+          {!data.withoutSdp && (
+            <>
+              <div className="space-y-2">
+                <Label htmlFor="direction">Direction</Label>
+                <Select
+                  value={data.direction || 'keep'}
+                  onValueChange={(value) =>
+                    onUpdate({ direction: value === 'keep' ? undefined : (value as (typeof MEDIA_DIRECTIONS)[number]) })
+                  }
+                >
+                  <SelectTrigger id="direction">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="keep">Keep current</SelectItem>
+                    {MEDIA_DIRECTIONS.map((direction) => (
+                      <SelectItem key={direction} value={direction}>
+                        {direction}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+
+              <div className="space-y-2">
+                <Label htmlFor="codecs">Codecs</Label>
+                <Input
+                  id="codecs"
+                  value={codecsText}
+                  onChange={(e) => {
+                    setCodecsText(e.target.value);
+                    const codecs = e.target.value
+                      .split(',')
+                      .map((codec) => codec.trim().toUpperCase())
+                      .filter(Boolean);
+                    onUpdate({ codecs: codecs.length > 0 ? codecs : undefined });
+                  }}
+                  placeholder={MEDIA_CODECS.join(', ')}
+                />
+                <p className="text-xs text-muted-foreground">
+                  Comma-separated, in preference order. Empty keeps the current codecs
+                </p>
+              </div>
+
+              <div className="space-y-2">
+                <Label htmlFor="mediaAddress">Media Address</Label>
+                <Input
+                  id="mediaAddress"
+                  value={data.mediaAddress || ''}
+                  onChange={(e) => onUpdate({ mediaAddress: e.target.value || undefined })}
+                  placeholder="192.0.2.10:40000"
+                />
+                <p className="text-xs text-muted-foreground">
+                  Only changes the address advertised in the SDP. RTP keeps using the current socket
+                </p>
+              </div>
+            </>
+          )}
+        </>
+      )}
+
+      {data.command === 'Signal' && (
+        <div className="space-y-2">
+          <Label htmlFor="signalName">Signal Name</Label>
+          <Input
+            id="signalName"
+            value={data.signalName || ''}
+            onChange={(e) => onUpdate({ signalName: e.target.value })}
+            placeholder="callee-ready"
+          />
+          <p className="text-xs text-muted-foreground">
+            Releases WaitSignal nodes with the same name on any chain. A signal stays raised until the run ends
+          </p>
+        </div>
+      )}
+
+      {data.command === 'CallScenario' && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="callTarget">Call</Label>
+            <Select
+              value={
+                data.fragmentName
+                  ? `fragment:${data.fragmentName}`
+                  : data.scenarioId
+                  ? `scenario:${data.scenarioId}`
+                  : 'none'
+              }
+              onValueChange={(value) => {
+                const [kind, ...rest] = value.split(':');
+                const key = rest.join(':');
+                onUpdate({
+                  fragmentName: kind === 'fragment' ? key : undefined,
+                  scenarioId: kind === 'scenario' ? key : undefined,
+                });
+              }}
+            >
+              <SelectTrigger id="callTarget">
+                <SelectValue placeholder="Select fragment or scenario..." />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="none">None</SelectItem>
+                {fragmentNames.map((name) => (
+                  <SelectItem key={`fragment:${name}`} value={`fragment:${name}`}>
+                    Fragment: {name}
+                  </SelectItem>
+                ))}
+                {scenarioOptions.map((scenario) => (
+                  <SelectItem key={`scenario:${scenario.id}`} value={`scenario:${scenario.id}`}>
+                    Scenario: {scenario.name}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <p className="text-xs text-muted-foreground">
+              Runs inline on this node's number. Unhandled failures take this node's failure branch
+            </p>
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="params">Parameters</Label>
+            <Input
+              id="params"
+              value={paramsText}
+              onChange={(e) => {
+                setParamsText(e.target.value);
+                onUpdate({ params: parseAssignments(e.target.value) });
+              }}
+              placeholder="target=200, callId=primary"
+            />
+            <p className="text-xs text-muted-foreground">
+              Bound to the callee's {'${name}'} variables
+            </p>
+          </div>
+        </>
+      )}
+
+      {(data.command === 'Subscribe' || data.command === 'Unsubscribe') && (
+        <>
+          <div className="space-y-2">
+            <Label htmlFor="subscribeTarget">Target</Label>
+            <Input
+              id="subscribeTarget"
+              value={data.subscribeTarget || ''}
+              onChange={(e) => onUpdate({ subscribeTarget: e.target.value })}
+              placeholder="2001"
+            />
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="eventPackage">Event Package</Label>
+            <Select
+              value={data.eventPackage || 'dialog'}
+              onValueChange={(value) => onUpdate({ eventPackage: value as (typeof EVENT_PACKAGES)[number] })}
+            >
+              <SelectTrigger id="eventPackage">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {EVENT_PACKAGES.map((eventPackage) => (
+                  <SelectItem key={eventPackage} value={eventPackage}>
+                    {eventPackage === 'dialog' ? 'dialog (BLF)' : eventPackage === 'message-summary' ? 'message-summary (MWI)' : 'presence'}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+
+          {data.command === 'Subscribe' && (
+            <div className="space-y-2">
+              <Label htmlFor="expires">Expires (s)</Label>
+              <Input
+                id="expires"
+                type="number"
+                value={data.expires ?? 3600}
+                onChange={(e) => onUpdate({ expires: Math.max(1, parseInt(e.target.value, 10) || 3600) })}
+                min={1}
+              />
+              <p className="text-xs text-muted-foreground">
+                The subscription is refreshed before it expires until Unsubscribe or the end of the run
+              </p>
+            </div>
+          )}
+        </>
+      )}
+
+      <Separator />
+
+      <RetryProperties data={data} onUpdate={onUpdate} />
     </div>
   );
 }
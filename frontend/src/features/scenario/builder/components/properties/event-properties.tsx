@@ -3,8 +3,15 @@ import { Label } from '@/components/ui/label';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { Separator } from '@/components/ui/separator';
 import { useFlowEditorNodes } from '../../store/flow-editor-context';
-import { type EventNode } from '../../types/scenario';
+import {
+  EVENT_PACKAGES,
+  EVENT_PACKAGE_STATES,
+  MEDIA_DIRECTIONS,
+  REFER_ACTIONS,
+  type EventNode,
+} from '../../types/scenario';
 import { getInstanceDisplayName } from '../../lib/instance-key';
+import { RetryProperties } from './retry-properties';
 
 interface EventPropertiesProps {
   node: EventNode;
@@ -111,10 +118,285 @@ export function EventProperties({ node, onUpdate }: EventPropertiesProps) {
         </>
       )}
 
-      {/* HELD/RETRIEVED/TRANSFERRED - timeout */}
-      {(data.event === 'HELD' || data.event === 'RETRIEVED' || data.event === 'TRANSFERRED') && (
+      {/* REGISTER_RECEIVED - registering DN filter + timeout */}
+      {data.event === 'REGISTER_RECEIVED' && (
         <>
           <Separator />
+          <div className="space-y-2">
+            <Label htmlFor="registerNumber">Registering Number</Label>
+            <Input
+              id="registerNumber"
+              value={data.number || ''}
+              onChange={(e) => onUpdate({ number: e.target.value || undefined })}
+              placeholder="Any"
+            />
+            <p className="text-xs text-muted-foreground">
+              Completes immediately if this number is already registered with the SIP server
+            </p>
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="timeout">Timeout (ms)</Label>
+            <Input
+              id="timeout"
+              type="number"
+              value={data.timeout ?? 10000}
+              onChange={(e) => {
+                const val = parseInt(e.target.value, 10) || 10000;
+                onUpdate({ timeout: Math.max(1000, val) });
+              }}
+              min={1000}
+              step={1000}
+            />
+          </div>
+        </>
+      )}
+
+      {/* MESSAGE_RECEIVED - sender + body pattern + timeout */}
+      {data.event === 'MESSAGE_RECEIVED' && (
+        <>
+          <Separator />
+          <div className="space-y-2">
+            <Label htmlFor="messageFrom">From</Label>
+            <Input
+              id="messageFrom"
+              value={data.messageFrom || ''}
+              onChange={(e) => onUpdate({ messageFrom: e.target.value })}
+              placeholder="Any sender"
+            />
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="messageMatch">Body Pattern</Label>
+            <Input
+              id="messageMatch"
+              value={data.messageMatch || ''}
+              onChange={(e) => onUpdate({ messageMatch: e.target.value })}
+              placeholder="code is \d+"
+            />
+            <p className="text-xs text-muted-foreground">
+              Regular expression the body must match. Other MESSAGEs are logged and skipped
+            </p>
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="timeout">Timeout (ms)</Label>
+            <Input
+              id="timeout"
+              type="number"
+              value={data.timeout ?? 10000}
+              onChange={(e) => {
+                const val = parseInt(e.target.value, 10) || 10000;
+                onUpdate({ timeout: Math.max(1000, val) });
+              }}
+              min={1000}
+              step={1000}
+            />
+          </div>
+        </>
+      )}
+
+      {/* NOTIFY_RECEIVED - subscription + expected body state + timeout */}
+      {data.event === 'NOTIFY_RECEIVED' && (
+        <>
+          <Separator />
+          <div className="space-y-2">
+            <Label htmlFor="subscribeTarget">Subscription Target</Label>
+            <Input
+              id="subscribeTarget"
+              value={data.subscribeTarget || ''}
+              onChange={(e) => onUpdate({ subscribeTarget: e.target.value })}
+              placeholder="2001"
+            />
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="eventPackage">Event Package</Label>
+            <Select
+              value={data.eventPackage || 'dialog'}
+              onValueChange={(value) =>
+                onUpdate({ eventPackage: value as (typeof EVENT_PACKAGES)[number], expectedState: undefined })
+              }
+            >
+              <SelectTrigger id="eventPackage">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {EVENT_PACKAGES.map((eventPackage) => (
+                  <SelectItem key={eventPackage} value={eventPackage}>
+                    {eventPackage}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="expectedState">Expected State</Label>
+            <Select
+              value={data.expectedState || 'any'}
+              onValueChange={(value) => onUpdate({ expectedState: value === 'any' ? undefined : value })}
+            >
+              <SelectTrigger id="expectedState">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="any">Any NOTIFY</SelectItem>
+                {EVENT_PACKAGE_STATES[data.eventPackage || 'dialog'].map((state) => (
+                  <SelectItem key={state} value={state}>
+                    {state}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <p className="text-xs text-muted-foreground">
+              Requires a Subscribe node for the same target and package. Other NOTIFYs are logged and skipped
+            </p>
+          </div>
+          <div className="space-y-2">
+            <Label htmlFor="timeout">Timeout (ms)</Label>
+            <Input
+              id="timeout"
+              type="number"
+              value={data.timeout ?? 10000}
+              onChange={(e) => {
+                const val = parseInt(e.target.value, 10) || 10000;
+                onUpdate({ timeout: Math.max(1000, val) });
+              }}
+              min={1000}
+              step={1000}
+            />
+          </div>
+        </>
+      )}
+
+      {/* WaitSignal/Barrier - sync name + timeout */}
+      {(data.event === 'WaitSignal' || data.event === 'Barrier') && (
+        <>
+          <Separator />
+          {data.event === 'WaitSignal' ? (
+            <div className="space-y-2">
+              <Label htmlFor="signalName">Signal Name</Label>
+              <Input
+                id="signalName"
+                value={data.signalName || ''}
+                onChange={(e) => onUpdate({ signalName: e.target.value })}
+                placeholder="callee-ready"
+              />
+              <p className="text-xs text-muted-foreground">
+                Passes at once if a Signal node already raised this name
+              </p>
+            </div>
+          ) : (
+            <>
+              <div className="space-y-2">
+                <Label htmlFor="barrierName">Barrier Name</Label>
+                <Input
+                  id="barrierName"
+                  value={data.barrierName || ''}
+                  onChange={(e) => onUpdate({ barrierName: e.target.value })}
+                  placeholder="all-connected"
+                />
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="parties">Parties</Label>
+                <Input
+                  id="parties"
+                  type="number"
+                  value={data.parties ?? ''}
+                  onChange={(e) => {
+                    const val = parseInt(e.target.value, 10);
+                    onUpdate({ parties: Number.isNaN(val) || val <= 0 ? undefined : val });
+                  }}
+                  min={1}
+                  placeholder="Barrier nodes with this name"
+                />
+                <p className="text-xs text-muted-foreground">
+                  Chains that must arrive before all of them continue
+                </p>
+              </div>
+            </>
+          )}
+          <div className="space-y-2">
+            <Label htmlFor="timeout">Timeout (ms)</Label>
+            <Input
+              id="timeout"
+              type="number"
+              value={data.timeout ?? 10000}
+              onChange={(e) => {
+                const val = parseInt(e.target.value, 10) || 10000;
+                onUpdate({ timeout: Math.max(1000, val) });
+              }}
+              min={1000}
+              step={1000}
+            />
+          </div>
+        </>
+      )}
+
+      {/* HELD/RETRIEVED/TRANSFERRED/REFER_RECEIVED/REPLACED/SESSION_REFRESH_FAILED/REINVITE_RECEIVED/UPDATE_RECEIVED - timeout */}
+      {(data.event === 'HELD' ||
+        data.event === 'RETRIEVED' ||
+        data.event === 'TRANSFERRED' ||
+        data.event === 'REFER_RECEIVED' ||
+        data.event === 'REPLACED' ||
+        data.event === 'SESSION_REFRESH_FAILED' ||
+        data.event === 'REINVITE_RECEIVED' ||
+        data.event === 'UPDATE_RECEIVED') && (
+        <>
+          <Separator />
+          {(data.event === 'REINVITE_RECEIVED' || data.event === 'UPDATE_RECEIVED') && (
+            <>
+              <div className="space-y-2">
+                <Label htmlFor="direction">Offered Direction</Label>
+                <Select
+                  value={data.direction || 'any'}
+                  onValueChange={(value) =>
+                    onUpdate({ direction: value === 'any' ? undefined : (value as (typeof MEDIA_DIRECTIONS)[number]) })
+                  }
+                >
+                  <SelectTrigger id="direction">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="any">Any</SelectItem>
+                    {MEDIA_DIRECTIONS.map((direction) => (
+                      <SelectItem key={direction} value={direction}>
+                        {direction}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="codec">Codec</Label>
+                <Input
+                  id="codec"
+                  value={data.codec || ''}
+                  onChange={(e) => onUpdate({ codec: e.target.value || undefined })}
+                  placeholder="Any codec"
+                />
+                <p className="text-xs text-muted-foreground">
+                  Offers that do not match are logged and skipped. An offer without SDP only matches when both are empty
+                </p>
+              </div>
+            </>
+          )}
+          {data.event === 'REFER_RECEIVED' && (
+            <div className="space-y-2">
+              <Label htmlFor="referAction">Response</Label>
+              <Select
+                value={data.referAction ?? 'accept'}
+                onValueChange={(value) => onUpdate({ referAction: value as (typeof REFER_ACTIONS)[number] })}
+              >
+                <SelectTrigger id="referAction">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  {REFER_ACTIONS.map((action) => (
+                    <SelectItem key={action} value={action}>
+                      {action === 'accept' ? 'Accept (202)' : 'Reject (603)'}
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+            </div>
+          )}
           <div className="space-y-2">
             <Label htmlFor="timeout">Timeout (ms)</Label>
             <Input
@@ -135,6 +417,10 @@ export function EventProperties({ node, onUpdate }: EventPropertiesProps) {
           </div>
         </>
       )}
+
+      <Separator />
+
+      <RetryProperties data={data} onUpdate={onUpdate} />
     </div>
   );
 }
@@ -0,0 +1,55 @@
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+
+interface RetryFields {
+  retryMaxAttempts?: number;
+  retryBackoffMs?: number;
+}
+
+interface RetryPropertiesProps {
+  data: RetryFields;
+  onUpdate: (data: RetryFields) => void;
+}
+
+export function RetryProperties({ data, onUpdate }: RetryPropertiesProps) {
+  const attempts = data.retryMaxAttempts ?? 1;
+
+  return (
+    <>
+      <div className="space-y-2">
+        <Label htmlFor="retryMaxAttempts">Attempts</Label>
+        <Input
+          id="retryMaxAttempts"
+          type="number"
+          value={attempts}
+          onChange={(e) => {
+            const val = Math.max(1, parseInt(e.target.value, 10) || 1);
+            onUpdate({ retryMaxAttempts: val > 1 ? val : undefined });
+          }}
+          min={1}
+        />
+        <p className="text-xs text-muted-foreground">
+          Total attempts including the first. The failure branch runs only after the last attempt fails
+        </p>
+      </div>
+
+      {attempts > 1 && (
+        <div className="space-y-2">
+          <Label htmlFor="retryBackoffMs">Retry Delay (ms)</Label>
+          <Input
+            id="retryBackoffMs"
+            type="number"
+            value={data.retryBackoffMs ?? 0}
+            onChange={(e) => {
+              const val = Math.max(0, parseInt(e.target.value, 10) || 0);
+              onUpdate({ retryBackoffMs: val > 0 ? val : undefined });
+            }}
+            min={0}
+            step={100}
+          />
+          <p className="text-xs text-muted-foreground">Wait before each retry (default: retry immediately)</p>
+        </div>
+      )}
+    </>
+  );
+}
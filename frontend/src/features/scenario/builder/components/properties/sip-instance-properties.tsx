@@ -1,12 +1,15 @@
+import { useEffect, useState } from 'react';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { Separator } from '@/components/ui/separator';
 import { Switch } from '@/components/ui/switch';
+import { Button } from '@/components/ui/button';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { usePbxInstances, type PbxInstanceSettings } from '@/features/settings/store/app-settings-store';
 import type { SipInstanceNode } from '../../types/scenario';
-import { DEFAULT_CODECS } from '../../types/scenario';
+import { DEFAULT_CODECS, SESSION_REFRESH_METHODS, SESSION_REFRESHERS } from '../../types/scenario';
 import { CodecListItem } from './codec-list-item';
+import { GetActiveProject, GetProjectSettings } from '../../../../../../wailsjs/go/binding/ScenarioBinding';
 
 interface SipInstancePropertiesProps {
   node: SipInstanceNode;
@@ -30,17 +33,36 @@ export function SipInstanceProperties({ node, onUpdate }: SipInstancePropertiesP
   const sipInstances = usePbxInstances();
   const numberValue = data.dn || (data.label && data.label !== 'SIP Instance' ? data.label : '');
   const selectedPbxInstanceId = data.pbxInstanceId || data.serverId || 'none';
+  const isServer = data.role === 'server';
+
+  const [projectCodecs, setProjectCodecs] = useState<string[]>(DEFAULT_CODECS);
+
+  // Unset codecs follow the project setting, so show that order until the user overrides it
+  useEffect(() => {
+    let cancelled = false;
+    GetActiveProject()
+      .then((project) => GetProjectSettings(project.id))
+      .then((settings) => {
+        if (!cancelled && settings.codecs && settings.codecs.length > 0) {
+          setProjectCodecs(settings.codecs);
+        }
+      })
+      .catch((err) => console.error('Failed to load project codecs:', err));
+    return () => {
+      cancelled = true;
+    };
+  }, []);
+
+  const usesProjectCodecs = !data.codecs || data.codecs.length === 0;
+  const displayCodecs = usesProjectCodecs ? projectCodecs : data.codecs!;
 
   const moveCodec = (fromIndex: number, toIndex: number) => {
-    const currentCodecs = data.codecs && data.codecs.length > 0 ? data.codecs : [...DEFAULT_CODECS];
-    const newCodecs = [...currentCodecs];
+    const newCodecs = [...displayCodecs];
     const [removed] = newCodecs.splice(fromIndex, 1);
     newCodecs.splice(toIndex, 0, removed);
     onUpdate({ codecs: newCodecs });
   };
 
-  const displayCodecs = data.codecs && data.codecs.length > 0 ? data.codecs : DEFAULT_CODECS;
-
   return (
     <div className="space-y-4 nodrag">
       <section className="space-y-3">
@@ -61,18 +83,40 @@ export function SipInstanceProperties({ node, onUpdate }: SipInstancePropertiesP
           />
         </div>
 
-        <Separator />
-
-        <div className="flex items-center justify-between gap-4 rounded-lg border border-border px-3 py-2">
-          <div>
-            <Label htmlFor="register-enabled">Register</Label>
-          </div>
-          <Switch
-            id="register-enabled"
-            checked={Boolean(data.register)}
-            onCheckedChange={(checked) => onUpdate({ register: checked })}
-          />
+        <div className="space-y-2">
+          <Label htmlFor="role">Role</Label>
+          <Select
+            value={data.role ?? 'ua'}
+            onValueChange={(value) =>
+              onUpdate(value === 'server' ? { role: 'server', register: false } : { role: 'ua' })
+            }
+          >
+            <SelectTrigger id="role">
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              <SelectItem value="ua">User Agent</SelectItem>
+              <SelectItem value="server">SIP Server</SelectItem>
+            </SelectContent>
+          </Select>
         </div>
+
+        {!isServer ? (
+          <>
+            <Separator />
+
+            <div className="flex items-center justify-between gap-4 rounded-lg border border-border px-3 py-2">
+              <div>
+                <Label htmlFor="register-enabled">Register</Label>
+              </div>
+              <Switch
+                id="register-enabled"
+                checked={Boolean(data.register)}
+                onCheckedChange={(checked) => onUpdate({ register: checked })}
+              />
+            </div>
+          </>
+        ) : null}
       </section>
 
       <Separator />
@@ -82,36 +126,182 @@ export function SipInstanceProperties({ node, onUpdate }: SipInstancePropertiesP
           <h4 className="text-base font-semibold text-foreground">연결 설정</h4>
         </div>
 
-        <div className="space-y-2">
-          <Label htmlFor="pbxInstanceId">SIP Instance</Label>
-          <Select
-            value={selectedPbxInstanceId}
-            onValueChange={(value) =>
-              onUpdate({
-                pbxInstanceId: value === 'none' ? undefined : value,
-                serverId: value === 'none' ? undefined : value,
-              })
-            }
-          >
-            <SelectTrigger id="pbxInstanceId">
-              <SelectValue placeholder="Select SIP instance..." />
-            </SelectTrigger>
-            <SelectContent>
-              <SelectItem value="none">None</SelectItem>
-              {sipInstances.map((instance) => (
-                <SelectItem key={instance.id} value={instance.id}>
-                  {getSipInstanceLabel(instance)}
-                </SelectItem>
-              ))}
-            </SelectContent>
-          </Select>
-          {sipInstances.length === 0 ? (
+        {isServer ? (
+          <>
+            <div className="grid grid-cols-[1fr_6rem] gap-2">
+              <div className="space-y-2">
+                <Label htmlFor="listenHost">Listen Host</Label>
+                <Input
+                  id="listenHost"
+                  value={data.listenHost ?? ''}
+                  onChange={(e) => onUpdate({ listenHost: e.target.value || undefined })}
+                  placeholder="0.0.0.0"
+                />
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="listenPort">Port</Label>
+                <Input
+                  id="listenPort"
+                  type="number"
+                  value={data.listenPort ?? ''}
+                  onChange={(e) => {
+                    const val = parseInt(e.target.value, 10);
+                    onUpdate({ listenPort: Number.isNaN(val) ? undefined : val });
+                  }}
+                  placeholder="5060"
+                />
+              </div>
+            </div>
+
+            <div className="space-y-2">
+              <Label htmlFor="authPassword">Digest Password</Label>
+              <Input
+                id="authPassword"
+                type="password"
+                value={data.authPassword ?? ''}
+                onChange={(e) => onUpdate({ authPassword: e.target.value || undefined })}
+                placeholder="No challenge"
+              />
+            </div>
+            {data.authPassword ? (
+              <div className="grid grid-cols-2 gap-2">
+                <div className="space-y-2">
+                  <Label htmlFor="authRealm">Realm</Label>
+                  <Input
+                    id="authRealm"
+                    value={data.authRealm ?? ''}
+                    onChange={(e) => onUpdate({ authRealm: e.target.value || undefined })}
+                    placeholder="sipflow"
+                  />
+                </div>
+                <div className="space-y-2">
+                  <Label htmlFor="authUsername">Username</Label>
+                  <Input
+                    id="authUsername"
+                    value={data.authUsername ?? ''}
+                    onChange={(e) => onUpdate({ authUsername: e.target.value || undefined })}
+                    placeholder="Registering number"
+                  />
+                </div>
+              </div>
+            ) : null}
+          </>
+        ) : (
+          <div className="space-y-2">
+            <Label htmlFor="pbxInstanceId">SIP Instance</Label>
+            <Select
+              value={selectedPbxInstanceId}
+              onValueChange={(value) =>
+                onUpdate({
+                  pbxInstanceId: value === 'none' ? undefined : value,
+                  serverId: value === 'none' ? undefined : value,
+                })
+              }
+            >
+              <SelectTrigger id="pbxInstanceId">
+                <SelectValue placeholder="Select SIP instance..." />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="none">None</SelectItem>
+                {sipInstances.map((instance) => (
+                  <SelectItem key={instance.id} value={instance.id}>
+                    {getSipInstanceLabel(instance)}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            {sipInstances.length === 0 ? (
+              <p className="text-xs text-muted-foreground">
+                Settings에서 SIP 인스턴스를 먼저 추가해 주세요.
+              </p>
+            ) : null}
+          </div>
+        )}
+      </section>
+
+      {!isServer ? (
+        <>
+          <Separator />
+
+          <section className="space-y-3">
+            <div className="space-y-1">
+              <h4 className="text-base font-semibold text-foreground">세션 타이머</h4>
+            </div>
+
+            <div className="grid grid-cols-2 gap-2">
+              <div className="space-y-2">
+                <Label htmlFor="session-expires">Session-Expires (s)</Label>
+                <Input
+                  id="session-expires"
+                  type="number"
+                  value={data.sessionExpires ?? 0}
+                  onChange={(e) => onUpdate({ sessionExpires: Math.max(0, parseInt(e.target.value, 10) || 0) })}
+                  min={0}
+                  step={60}
+                />
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="min-se">Min-SE (s)</Label>
+                <Input
+                  id="min-se"
+                  type="number"
+                  value={data.minSE ?? 90}
+                  onChange={(e) => onUpdate({ minSE: Math.max(90, parseInt(e.target.value, 10) || 90) })}
+                  min={90}
+                  disabled={!data.sessionExpires}
+                />
+              </div>
+            </div>
+
+            <div className="grid grid-cols-2 gap-2">
+              <div className="space-y-2">
+                <Label htmlFor="session-refresher">Refresher</Label>
+                <Select
+                  value={data.sessionRefresher ?? 'uac'}
+                  onValueChange={(value) => onUpdate({ sessionRefresher: value as (typeof SESSION_REFRESHERS)[number] })}
+                  disabled={!data.sessionExpires}
+                >
+                  <SelectTrigger id="session-refresher">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {SESSION_REFRESHERS.map((refresher) => (
+                      <SelectItem key={refresher} value={refresher}>
+                        {refresher === 'uac' ? 'uac (caller)' : 'uas (callee)'}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="session-refresh-method">Refresh With</Label>
+                <Select
+                  value={data.sessionRefreshMethod ?? 'UPDATE'}
+                  onValueChange={(value) =>
+                    onUpdate({ sessionRefreshMethod: value as (typeof SESSION_REFRESH_METHODS)[number] })
+                  }
+                  disabled={!data.sessionExpires}
+                >
+                  <SelectTrigger id="session-refresh-method">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {SESSION_REFRESH_METHODS.map((method) => (
+                      <SelectItem key={method} value={method}>
+                        {method === 'INVITE' ? 're-INVITE' : method}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+            </div>
             <p className="text-xs text-muted-foreground">
-              Settings에서 SIP 인스턴스를 먼저 추가해 주세요.
+              0 disables session timers. Established calls are refreshed every half interval; failures raise
+              SESSION_REFRESH_FAILED.
             </p>
-          ) : null}
-        </div>
-      </section>
+          </section>
+        </>
+      ) : null}
 
       <Separator />
 
@@ -122,12 +312,48 @@ export function SipInstanceProperties({ node, onUpdate }: SipInstancePropertiesP
 
         <div className="space-y-2">
           <Label>Preferred Codecs</Label>
-          <p className="text-xs text-muted-foreground">Drag to reorder priority.</p>
+          <p className="text-xs text-muted-foreground">
+            {usesProjectCodecs
+              ? 'Using the project default. Drag to reorder priority for this instance.'
+              : 'Drag to reorder priority.'}
+          </p>
           <div className="space-y-2">
             {displayCodecs.map((codec, index) => (
               <CodecListItem key={codec} codec={codec} index={index} onMove={moveCodec} />
             ))}
           </div>
+          {!usesProjectCodecs && (
+            <Button size="sm" variant="ghost" onClick={() => onUpdate({ codecs: undefined })}>
+              Use project default
+            </Button>
+          )}
+        </div>
+      </section>
+
+      <Separator />
+
+      <section className="space-y-3">
+        <div className="space-y-1">
+          <h4 className="text-base font-semibold text-foreground">실행 제한</h4>
+        </div>
+
+        <div className="space-y-2">
+          <Label htmlFor="chain-deadline">Chain Deadline (ms)</Label>
+          <Input
+            id="chain-deadline"
+            type="number"
+            value={data.chainDeadlineMs ?? 0}
+            onChange={(e) => {
+              const val = Math.max(0, parseInt(e.target.value, 10) || 0);
+              onUpdate({ chainDeadlineMs: val > 0 ? val : undefined });
+            }}
+            min={0}
+            step={1000}
+          />
+          <p className="text-xs text-muted-foreground">
+            The run fails with the running nodes and open dialogs if this instance's chain is still running (0 = no
+            limit)
+          </p>
         </div>
       </section>
     </div>
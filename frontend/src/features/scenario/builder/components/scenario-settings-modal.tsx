@@ -0,0 +1,38 @@
+import { Modal } from '@/components/modal/modal';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { useFlowEditorActions, useFlowEditorFlowSettings } from '../store/flow-editor-context';
+
+interface ScenarioSettingsModalProps {
+  isOpen: boolean;
+  onExit: () => void;
+}
+
+export function ScenarioSettingsModal({ isOpen, onExit }: ScenarioSettingsModalProps) {
+  const flowSettings = useFlowEditorFlowSettings();
+  const { updateFlowSettings } = useFlowEditorActions();
+  const maxDurationMs = typeof flowSettings.maxDurationMs === 'number' ? flowSettings.maxDurationMs : 0;
+
+  return (
+    <Modal isOpen={isOpen} onExit={onExit} title="Scenario Settings">
+      <div className="space-y-2 nodrag">
+        <Label htmlFor="maxDurationMs">Max Duration (ms)</Label>
+        <Input
+          id="maxDurationMs"
+          type="number"
+          value={maxDurationMs}
+          onChange={(e) => {
+            const val = Math.max(0, parseInt(e.target.value, 10) || 0);
+            updateFlowSettings({ maxDurationMs: val > 0 ? val : undefined });
+          }}
+          min={0}
+          step={1000}
+        />
+        <p className="text-xs text-muted-foreground">
+          The run fails once it exceeds this duration (0 = no limit). Registration-only scenarios without a
+          limit keep their registrations for 10 minutes
+        </p>
+      </div>
+    </Modal>
+  );
+}
@@ -36,6 +36,7 @@ export interface FlowEditorActions {
   addElements: (nodes: Node[], edges: Edge[]) => void;
   removeSelectedElements: () => void;
   updateNodeData: (nodeId: string, data: Partial<any>) => void;
+  updateFlowSettings: (settings: Record<string, unknown>) => void;
   setSelectedNode: (nodeId: string | null) => void;
   setDirty: (dirty: boolean) => void;
   saveNow: () => Promise<void>;
@@ -53,6 +54,7 @@ export interface FlowEditorContextValue {
   edges: Edge[];
   selectedNodeId: string | null;
   validationErrors: ValidationError[];
+  flowSettings: Record<string, unknown>;
   horizontalLine?: number;
   verticalLine?: number;
   canUndo: boolean;
@@ -84,6 +86,18 @@ function parseFlowJSON(json: string): FlowSnapshot {
   };
 }
 
+// Top-level flow fields other than nodes/edges (e.g. variables, maxDurationMs) are kept as-is on save
+function parseFlowSettings(json: string): Record<string, unknown> {
+  if (!json || json === '{}') {
+    return {};
+  }
+
+  const settings = JSON.parse(json) as Record<string, unknown>;
+  delete settings.nodes;
+  delete settings.edges;
+  return settings;
+}
+
 export function useFlowEditorController(): FlowEditorContextValue {
   const currentScenarioId = useScenarioCurrentScenarioId();
   const isDirty = useScenarioIsDirty();
@@ -94,11 +108,13 @@ export function useFlowEditorController(): FlowEditorContextValue {
   const [edges, setEdges, onEdgesStateChange] = useEdgesState<Edge>([]);
   const [selectedNodeId, setSelectedNodeId] = useState<string | null>(null);
   const [validationErrors, setValidationErrors] = useState<ValidationError[]>([]);
+  const [flowSettings, setFlowSettings] = useState<Record<string, unknown>>({});
   const [horizontalLine, setHorizontalLine] = useState<number | undefined>(undefined);
   const [verticalLine, setVerticalLine] = useState<number | undefined>(undefined);
 
   const nodesRef = useRef(nodes);
   const edgesRef = useRef(edges);
+  const flowSettingsRef = useRef<Record<string, unknown>>({});
   const selectedNodeIdRef = useRef(selectedNodeId);
   const { canUndo, canRedo, pushHistory, resetHistory, undo, redo } = useUndoRedo({
     nodesRef,
@@ -121,6 +137,11 @@ export function useFlowEditorController(): FlowEditorContextValue {
     selectedNodeIdRef.current = selectedNodeId;
   }, [selectedNodeId]);
 
+  const applyFlowSettings = useCallback((settings: Record<string, unknown>) => {
+    flowSettingsRef.current = settings;
+    setFlowSettings(settings);
+  }, []);
+
   const resetTransientState = useCallback(() => {
     setSelectedNodeId(null);
     setValidationErrors([]);
@@ -132,8 +153,9 @@ export function useFlowEditorController(): FlowEditorContextValue {
   const clearCanvas = useCallback(() => {
     setNodes([]);
     setEdges([]);
+    applyFlowSettings({});
     resetTransientState();
-  }, [resetTransientState, setEdges, setNodes]);
+  }, [applyFlowSettings, resetTransientState, setEdges, setNodes]);
 
   const loadFlowSnapshot = useCallback(
     (snapshot: FlowSnapshot) => {
@@ -185,6 +207,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
     });
 
     return JSON.stringify({
+      ...flowSettingsRef.current,
       nodes: nodesWithPbxSnapshot,
       edges: edgesRef.current,
     });
@@ -194,12 +217,13 @@ export function useFlowEditorController(): FlowEditorContextValue {
     (json: string) => {
       try {
         loadFlowSnapshot(parseFlowJSON(json));
+        applyFlowSettings(parseFlowSettings(json));
       } catch (error) {
         console.error('Failed to parse flow JSON:', error);
         throw error;
       }
     },
-    [loadFlowSnapshot]
+    [applyFlowSettings, loadFlowSnapshot]
   );
 
   const saveNow = useCallback(async () => {
@@ -241,6 +265,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
         }
 
         loadFlowSnapshot(parseFlowJSON(scenario.flow_data));
+        applyFlowSettings(parseFlowSettings(scenario.flow_data));
       } catch (error) {
         if (cancelled) {
           return;
@@ -256,7 +281,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
     return () => {
       cancelled = true;
     };
-  }, [clearCanvas, currentScenarioId, loadFlowSnapshot, setDirty, setSaveStatus]);
+  }, [applyFlowSettings, clearCanvas, currentScenarioId, loadFlowSnapshot, setDirty, setSaveStatus]);
 
   useEffect(() => {
     if (!currentScenarioId || !isDirty) {
@@ -272,7 +297,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
     return () => {
       window.clearTimeout(timeoutId);
     };
-  }, [currentScenarioId, edges, isDirty, nodes, saveNow]);
+  }, [currentScenarioId, edges, flowSettings, isDirty, nodes, saveNow]);
 
   const actions = useMemo<FlowEditorActions>(
     () => ({
@@ -439,6 +464,17 @@ export function useFlowEditorController(): FlowEditorContextValue {
         );
         setDirty(true);
       },
+      updateFlowSettings: (settings) => {
+        // Undefined values remove the field so the backend default applies
+        const next = { ...flowSettingsRef.current, ...settings };
+        Object.keys(next).forEach((key) => {
+          if (next[key] === undefined) {
+            delete next[key];
+          }
+        });
+        applyFlowSettings(next);
+        setDirty(true);
+      },
       setSelectedNode: (nodeId) => {
         setSelectedNodeId(nodeId);
       },
@@ -457,6 +493,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
       redo,
     }),
     [
+      applyFlowSettings,
       clearCanvas,
       loadFromJSON,
       onEdgesStateChange,
@@ -478,6 +515,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
       edges,
       selectedNodeId,
       validationErrors,
+      flowSettings,
       horizontalLine,
       verticalLine,
       canUndo,
@@ -489,6 +527,7 @@ export function useFlowEditorController(): FlowEditorContextValue {
       canRedo,
       canUndo,
       edges,
+      flowSettings,
       horizontalLine,
       nodes,
       selectedNodeId,
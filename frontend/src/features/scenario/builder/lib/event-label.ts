@@ -6,7 +6,17 @@ const EVENT_LABEL_OVERRIDES: Record<string, string> = {
   HELD: 'Held',
   RETRIEVED: 'Retrieved',
   TRANSFERRED: 'Transferred',
+  REFER_RECEIVED: 'ReferReceived',
+  REPLACED: 'Replaced',
+  SESSION_REFRESH_FAILED: 'SessionRefreshFailed',
+  REINVITE_RECEIVED: 'ReInviteReceived',
+  UPDATE_RECEIVED: 'UpdateReceived',
   DTMFReceived: 'DtmfReceived',
+  WaitSignal: 'WaitSignal',
+  Barrier: 'Barrier',
+  REGISTER_RECEIVED: 'RegisterReceived',
+  NOTIFY_RECEIVED: 'NotifyReceived',
+  MESSAGE_RECEIVED: 'MessageReceived',
 };
 
 export function formatEventLabel(eventName: string): string {
@@ -1,4 +1,5 @@
 import type { Node, Edge } from '@xyflow/react';
+import { MEDIA_CODECS } from '../types/scenario';
 
 export interface ValidationError {
   type: 'cycle' | 'isolated' | 'required-field' | 'instance-assignment';
@@ -281,6 +282,96 @@ export function validateRequiredFields(nodes: Node[]): ValidationError[] {
         }
       }
 
+      if (data.command === 'Bridge') {
+        if (!data.peerCallId || data.peerCallId.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'Bridge command requires peerCallId',
+          });
+        }
+      }
+
+      if (data.command === 'Pickup') {
+        if (!data.pickupTarget || data.pickupTarget.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'Pickup command requires pickupTarget',
+          });
+        }
+      }
+
+      if (data.command === 'SetForwarding' && data.forwardEnabled !== false) {
+        if (!data.forwardTarget || data.forwardTarget.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'SetForwarding command requires forwardTarget when enabling forwarding',
+          });
+        }
+      }
+
+      if (data.command === 'SendMessage' || data.command === 'SendOptions') {
+        if (!data.targetUri || data.targetUri.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: `${data.command} command requires targetUri`,
+          });
+        }
+      }
+
+      if (data.command === 'ReInvite' || data.command === 'Update') {
+        const unknownCodecs = (data.codecs ?? []).filter(
+          (codec: string) => !(MEDIA_CODECS as readonly string[]).includes(codec)
+        );
+        if (unknownCodecs.length > 0) {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: `${data.command} command has unsupported codecs: ${unknownCodecs.join(', ')}`,
+          });
+        }
+        if (data.withoutSdp && (data.direction || data.codecs?.length || data.mediaAddress)) {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: `${data.command} without SDP cannot change direction, codecs or media address`,
+          });
+        }
+      }
+
+      if (data.command === 'Signal') {
+        if (!data.signalName || data.signalName.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'Signal command requires signalName',
+          });
+        }
+      }
+
+      if (data.command === 'CallScenario') {
+        if (!data.fragmentName && !data.scenarioId) {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'CallScenario command requires a fragment or scenario',
+          });
+        }
+      }
+
+      if (data.command === 'Subscribe' || data.command === 'Unsubscribe') {
+        if (!data.subscribeTarget || data.subscribeTarget.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: `${data.command} command requires subscribeTarget`,
+          });
+        }
+      }
+
       if (data.command === 'MuteTransfer') {
         if (!data.primaryCallId || data.primaryCallId.trim() === '') {
           errors.push({
@@ -320,6 +411,48 @@ export function validateRequiredFields(nodes: Node[]): ValidationError[] {
         }
       }
 
+      if (data.event === 'MESSAGE_RECEIVED' && data.messageMatch) {
+        try {
+          new RegExp(data.messageMatch);
+        } catch {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'MESSAGE_RECEIVED event has an invalid messageMatch pattern',
+          });
+        }
+      }
+
+      if (data.event === 'WaitSignal') {
+        if (!data.signalName || data.signalName.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'WaitSignal event requires signalName',
+          });
+        }
+      }
+
+      if (data.event === 'Barrier') {
+        if (!data.barrierName || data.barrierName.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'Barrier event requires barrierName',
+          });
+        }
+      }
+
+      if (data.event === 'NOTIFY_RECEIVED') {
+        if (!data.subscribeTarget || data.subscribeTarget.trim() === '') {
+          errors.push({
+            type: 'required-field',
+            nodeId: node.id,
+            message: 'NOTIFY_RECEIVED event requires subscribeTarget',
+          });
+        }
+      }
+
       if (data.event === 'TIMEOUT') {
         if (!data.timeout || data.timeout <= 0) {
           errors.push({
@@ -349,6 +482,14 @@ export function validateRequiredFields(nodes: Node[]): ValidationError[] {
           usedDNs.set(dn, node.id);
         }
       }
+
+      if (data.role !== 'server' && data.sessionExpires > 0 && data.sessionExpires < (data.minSE ?? 90)) {
+        errors.push({
+          type: 'required-field',
+          nodeId: node.id,
+          message: `Session-Expires must be at least Min-SE (${data.minSE ?? 90}s)`,
+        });
+      }
     }
   });
 
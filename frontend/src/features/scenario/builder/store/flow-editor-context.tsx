@@ -38,6 +38,10 @@ export function useFlowEditorValidationErrors() {
   return useFlowEditorContext().validationErrors;
 }
 
+export function useFlowEditorFlowSettings() {
+  return useFlowEditorContext().flowSettings;
+}
+
 export function useFlowEditorCanUndo() {
   return useFlowEditorContext().canUndo;
 }
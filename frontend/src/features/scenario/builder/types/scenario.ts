@@ -7,8 +7,8 @@ export const NODE_CATEGORIES = {
   EVENT: 'event',
 } as const;
 
-// Command types (MVP Phase 2 + v1.2 Hold/Retrieve/BlindTransfer + v1.3 MuteTransfer UI)
-export const COMMAND_TYPES = ['MakeCall', 'Answer', 'Release', 'PlayAudio', 'SendDTMF', 'Hold', 'Retrieve', 'BlindTransfer', 'MuteTransfer'] as const;
+// Command types (MVP Phase 2 + v1.2 Hold/Retrieve/BlindTransfer + v1.3 MuteTransfer UI + Bridge/Unbridge + Conference + PBX features + Subscribe/Unsubscribe + SendMessage/SendOptions + ReInvite/Update + Signal + CallScenario)
+export const COMMAND_TYPES = ['MakeCall', 'Answer', 'Release', 'PlayAudio', 'SendDTMF', 'Hold', 'Retrieve', 'BlindTransfer', 'MuteTransfer', 'Bridge', 'Unbridge', 'Conference', 'Park', 'Unpark', 'Pickup', 'SetForwarding', 'Subscribe', 'Unsubscribe', 'SendMessage', 'SendOptions', 'ReInvite', 'Update', 'Signal', 'CallScenario'] as const;
 
 // Event types (backend-supported set)
 export const EVENT_TYPES = [
@@ -19,9 +19,22 @@ export const EVENT_TYPES = [
   'HELD',
   'RETRIEVED',
   'TRANSFERRED',
+  'REFER_RECEIVED',
+  'REPLACED',
+  'SESSION_REFRESH_FAILED',
+  'REINVITE_RECEIVED',
+  'UPDATE_RECEIVED',
   'DTMFReceived',
+  'WaitSignal',
+  'Barrier',
+  'REGISTER_RECEIVED',
+  'NOTIFY_RECEIVED',
+  'MESSAGE_RECEIVED',
 ] as const;
 
+// Instance roles: a user agent, or a SIP server that accepts registrations from external devices
+export const INSTANCE_ROLES = ['ua', 'server'] as const;
+
 // Instance color presets
 export const INSTANCE_COLORS = [
   '#3b82f6', // blue
@@ -52,8 +65,25 @@ export interface SipInstanceNodeData extends Record<string, unknown> {
   registerIntervalSeconds?: number;
   color: string;
   codecs?: string[]; // ["PCMU", "PCMA"] — codec priority order
+  chainDeadlineMs?: number; // watchdog deadline for this instance's chain (0/unset = none)
+  role?: (typeof INSTANCE_ROLES)[number]; // default 'ua'
+  listenHost?: string; // server: address to accept REGISTER/INVITE on (default 0.0.0.0)
+  listenPort?: number; // server: listen port (default 5060)
+  authRealm?: string; // server: digest realm
+  authUsername?: string; // server: digest username (unset = registering DN)
+  authPassword?: string; // server: digest password (unset = no challenge)
+  sessionExpires?: number; // ua: RFC 4028 Session-Expires in seconds (0/unset = no session timer)
+  minSE?: number; // ua: Min-SE in seconds (default 90)
+  sessionRefresher?: (typeof SESSION_REFRESHERS)[number]; // ua: which side refreshes (default 'uac')
+  sessionRefreshMethod?: (typeof SESSION_REFRESH_METHODS)[number]; // ua: refresh request (default 'UPDATE')
 }
 
+// RFC 4028 session timer refresher role: the calling side (uac) or the called side (uas)
+export const SESSION_REFRESHERS = ['uac', 'uas'] as const;
+
+// Session refresh requests
+export const SESSION_REFRESH_METHODS = ['UPDATE', 'INVITE'] as const;
+
 export type SipInstanceNode = Node<SipInstanceNodeData, 'sipInstance'>;
 
 // Command Node
@@ -71,19 +101,86 @@ export interface CommandNodeData extends Record<string, unknown> {
   targetHost?: string; // for BlindTransfer: target SIP host:port
   primaryCallId?: string; // for MuteTransfer: primary dialog call ID
   consultCallId?: string; // for MuteTransfer: consult dialog call ID
+  peerInstanceId?: string; // for Bridge: sipInstance node.id of the other leg (unset = same instance)
+  peerCallId?: string; // for Bridge: call ID of the other leg
+  conferenceAction?: (typeof CONFERENCE_ACTIONS)[number]; // for Conference (default 'join')
+  callIds?: string[]; // for Conference: call IDs to join/leave (unset = callId)
+  parkSlot?: string; // for Park/Unpark: slot to park on / retrieve (Unpark unset = last parked slot)
+  pickupTarget?: string; // for Pickup: DN whose ringing call is picked up
+  pickupMode?: (typeof PICKUP_MODES)[number]; // for Pickup (default 'featureCode')
+  forwardType?: (typeof FORWARD_TYPES)[number]; // for SetForwarding (default 'always')
+  forwardEnabled?: boolean; // for SetForwarding: enable (default) or disable forwarding
+  forwardTarget?: string; // for SetForwarding: number calls are forwarded to
+  subscribeTarget?: string; // for Subscribe/Unsubscribe: DN or SIP URI whose state is watched
+  eventPackage?: (typeof EVENT_PACKAGES)[number]; // for Subscribe/Unsubscribe (default 'dialog')
+  expires?: number; // for Subscribe: subscription duration in seconds (default 3600)
+  contentType?: string; // for SendMessage (default 'text/plain')
+  body?: string; // for SendMessage: message body
+  expectedStatus?: number; // for SendOptions: expected response code (default 200, 0 = any final response)
+  codecs?: string[]; // for ReInvite/Update: codecs to offer (unset = keep current)
+  direction?: (typeof MEDIA_DIRECTIONS)[number]; // for ReInvite/Update: SDP direction to offer (unset = keep current)
+  mediaAddress?: string; // for ReInvite/Update: ip or ip:port advertised in the SDP (unset = keep current)
+  withoutSdp?: boolean; // for ReInvite/Update: send without an SDP offer (re-INVITE answers the 200 OK offer in the ACK)
+  signalName?: string; // for Signal: named signal raised for WaitSignal nodes on other chains
+  fragmentName?: string; // for CallScenario: project fragment to inline (exclusive with scenarioId)
+  scenarioId?: string; // for CallScenario: saved scenario to inline (exclusive with fragmentName)
+  params?: Record<string, string | number | boolean>; // for CallScenario: values bound to the callee's ${name} variables
+  retryMaxAttempts?: number; // total attempts including the first (default 1 = no retry)
+  retryBackoffMs?: number; // delay before the first retry in milliseconds
 }
 
 export type CommandNode = Node<CommandNodeData, 'command'>;
 
+// Conference command actions
+export const CONFERENCE_ACTIONS = ['join', 'leave'] as const;
+
+// Pickup modes: dial the PBX pickup feature code, or INVITE with Replaces using dialog-info
+export const PICKUP_MODES = ['featureCode', 'replaces'] as const;
+
+// SetForwarding types (feature codes come from the project PBX profile)
+export const FORWARD_TYPES = ['always', 'busy', 'noAnswer'] as const;
+
+// SUBSCRIBE event packages: dialog (BLF), message-summary (MWI), presence
+export const EVENT_PACKAGES = ['dialog', 'message-summary', 'presence'] as const;
+
+// NOTIFY_RECEIVED states that can be waited for, per event package
+export const EVENT_PACKAGE_STATES: Record<(typeof EVENT_PACKAGES)[number], readonly string[]> = {
+  dialog: ['trying', 'proceeding', 'early', 'confirmed', 'terminated'],
+  'message-summary': ['yes', 'no'],
+  presence: ['open', 'closed'],
+};
+
+// SDP media directions for ReInvite/Update and REINVITE_RECEIVED/UPDATE_RECEIVED
+export const MEDIA_DIRECTIONS = ['sendrecv', 'sendonly', 'recvonly', 'inactive'] as const;
+
+// Codecs a ReInvite/Update can offer (telephone-event is always appended)
+export const MEDIA_CODECS = ['PCMU', 'PCMA'] as const;
+
+// REFER_RECEIVED responses: accept (202, follow Refer-To) or reject (603 Decline)
+export const REFER_ACTIONS = ['accept', 'reject'] as const;
+
 // Event Node
 export interface EventNodeData extends Record<string, unknown> {
   label: string;
   event: (typeof EVENT_TYPES)[number];
   sipInstanceId?: string; // sipInstance node.id (내부 PK)
-  number?: string; // for INCOMING: DN/number to wait for
+  number?: string; // for INCOMING: DN/number to wait for; for REGISTER_RECEIVED: registering DN (unset = any)
   callId?: string;
   timeout?: number; // for TIMEOUT event
   expectedDigit?: string; // for DTMFReceived: specific digit to wait for (empty = any digit)
+  referAction?: (typeof REFER_ACTIONS)[number]; // for REFER_RECEIVED: answer 202 or 603 (default 'accept')
+  subscribeTarget?: string; // for NOTIFY_RECEIVED: target of the Subscribe node to listen on
+  eventPackage?: (typeof EVENT_PACKAGES)[number]; // for NOTIFY_RECEIVED (default 'dialog')
+  expectedState?: string; // for NOTIFY_RECEIVED: body state to wait for (unset = any NOTIFY)
+  messageFrom?: string; // for MESSAGE_RECEIVED: sender DN to wait for (empty = any sender)
+  messageMatch?: string; // for MESSAGE_RECEIVED: regular expression the body must match (empty = any body)
+  direction?: (typeof MEDIA_DIRECTIONS)[number]; // for REINVITE_RECEIVED/UPDATE_RECEIVED: direction offered by the peer (unset = any)
+  codec?: string; // for REINVITE_RECEIVED/UPDATE_RECEIVED: codec the offer must contain (unset = any)
+  signalName?: string; // for WaitSignal: named signal to wait for (passes at once if already raised)
+  barrierName?: string; // for Barrier: chains sharing a name wait for each other
+  parties?: number; // for Barrier: chains needed to release (unset = number of Barrier nodes with this name)
+  retryMaxAttempts?: number; // total attempts including the first (default 1 = no retry)
+  retryBackoffMs?: number; // delay before the first retry in milliseconds
 }
 
 export type EventNode = Node<EventNodeData, 'event'>;
@@ -1,7 +1,19 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {engine} from '../models';
+import {binding} from '../models';
 import {context} from '../models';
 
+export function GetBreakpoints():Promise<Array<string>>;
+
+export function GetDebugSnapshot(arg1:string):Promise<engine.DebugSnapshot>;
+
+export function GetLocalPBXBindings(arg1:string):Promise<Array<engine.PBXBinding>>;
+
+export function GetRun(arg1:string):Promise<engine.RunInfo>;
+
+export function GetServerRegistrations(arg1:string,arg2:string):Promise<Array<engine.PBXBinding>>;
+
 export function GetSupportedCommands():Promise<Array<string>>;
 
 export function GetSupportedEvents():Promise<Array<string>>;
@@ -10,10 +22,36 @@ export function GetVersion():Promise<string>;
 
 export function IsRunning():Promise<boolean>;
 
+export function ListRuns():Promise<Array<engine.RunInfo>>;
+
+export function PauseScenario(arg1:string):Promise<void>;
+
 export function Ping():Promise<string>;
 
+export function ResumeScenario(arg1:string):Promise<void>;
+
+export function RunScenarioWithRetry(arg1:string,arg2:binding.RetryPolicyDTO):Promise<engine.RunResult>;
+
+export function RunSuite(arg1:string):Promise<engine.SuiteReport>;
+
+export function RunSuiteDryRun(arg1:string,arg2:binding.DryRunOptionsDTO):Promise<engine.SuiteReport>;
+
+export function SetBreakpoints(arg1:Array<string>):Promise<void>;
+
 export function SetContext(arg1:context.Context):Promise<void>;
 
-export function StartScenario(arg1:string):Promise<void>;
+export function StartScenario(arg1:string):Promise<string>;
+
+export function StartScenarioDryRun(arg1:string,arg2:binding.DryRunOptionsDTO):Promise<string>;
+
+export function StartScenarioFrom(arg1:string,arg2:Array<string>,arg3:binding.StartFromOptionsDTO):Promise<string>;
+
+export function StepScenario(arg1:string):Promise<void>;
+
+export function StopRun(arg1:string):Promise<void>;
 
 export function StopScenario():Promise<void>;
+
+export function StopSuite():Promise<void>;
+
+export function ValidateScenario(arg1:string,arg2:string):Promise<Array<engine.Diagnostic>>;
@@ -2,6 +2,26 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function GetBreakpoints() {
+  return window['go']['binding']['EngineBinding']['GetBreakpoints']();
+}
+
+export function GetDebugSnapshot(arg1) {
+  return window['go']['binding']['EngineBinding']['GetDebugSnapshot'](arg1);
+}
+
+export function GetLocalPBXBindings(arg1) {
+  return window['go']['binding']['EngineBinding']['GetLocalPBXBindings'](arg1);
+}
+
+export function GetRun(arg1) {
+  return window['go']['binding']['EngineBinding']['GetRun'](arg1);
+}
+
+export function GetServerRegistrations(arg1, arg2) {
+  return window['go']['binding']['EngineBinding']['GetServerRegistrations'](arg1, arg2);
+}
+
 export function GetSupportedCommands() {
   return window['go']['binding']['EngineBinding']['GetSupportedCommands']();
 }
@@ -18,10 +38,38 @@ export function IsRunning() {
   return window['go']['binding']['EngineBinding']['IsRunning']();
 }
 
+export function ListRuns() {
+  return window['go']['binding']['EngineBinding']['ListRuns']();
+}
+
+export function PauseScenario(arg1) {
+  return window['go']['binding']['EngineBinding']['PauseScenario'](arg1);
+}
+
 export function Ping() {
   return window['go']['binding']['EngineBinding']['Ping']();
 }
 
+export function ResumeScenario(arg1) {
+  return window['go']['binding']['EngineBinding']['ResumeScenario'](arg1);
+}
+
+export function RunScenarioWithRetry(arg1, arg2) {
+  return window['go']['binding']['EngineBinding']['RunScenarioWithRetry'](arg1, arg2);
+}
+
+export function RunSuite(arg1) {
+  return window['go']['binding']['EngineBinding']['RunSuite'](arg1);
+}
+
+export function RunSuiteDryRun(arg1, arg2) {
+  return window['go']['binding']['EngineBinding']['RunSuiteDryRun'](arg1, arg2);
+}
+
+export function SetBreakpoints(arg1) {
+  return window['go']['binding']['EngineBinding']['SetBreakpoints'](arg1);
+}
+
 export function SetContext(arg1) {
   return window['go']['binding']['EngineBinding']['SetContext'](arg1);
 }
@@ -30,6 +78,30 @@ export function StartScenario(arg1) {
   return window['go']['binding']['EngineBinding']['StartScenario'](arg1);
 }
 
+export function StartScenarioDryRun(arg1, arg2) {
+  return window['go']['binding']['EngineBinding']['StartScenarioDryRun'](arg1, arg2);
+}
+
+export function StartScenarioFrom(arg1, arg2, arg3) {
+  return window['go']['binding']['EngineBinding']['StartScenarioFrom'](arg1, arg2, arg3);
+}
+
+export function StepScenario(arg1) {
+  return window['go']['binding']['EngineBinding']['StepScenario'](arg1);
+}
+
+export function StopRun(arg1) {
+  return window['go']['binding']['EngineBinding']['StopRun'](arg1);
+}
+
 export function StopScenario() {
   return window['go']['binding']['EngineBinding']['StopScenario']();
 }
+
+export function StopSuite() {
+  return window['go']['binding']['EngineBinding']['StopSuite']();
+}
+
+export function ValidateScenario(arg1, arg2) {
+  return window['go']['binding']['EngineBinding']['ValidateScenario'](arg1, arg2);
+}
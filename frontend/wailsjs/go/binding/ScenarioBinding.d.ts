@@ -3,16 +3,72 @@
 import {binding} from '../models';
 import {context} from '../models';
 
+export function CopyScenario(arg1:string,arg2:string,arg3:string):Promise<binding.ScenarioDTO>;
+
+export function CreateFragment(arg1:string,arg2:string):Promise<binding.FragmentDTO>;
+
+export function CreateProject(arg1:string):Promise<binding.ProjectDTO>;
+
 export function CreateScenario(arg1:string):Promise<binding.ScenarioDTO>;
 
+export function CreateSuite(arg1:string):Promise<binding.SuiteDTO>;
+
+export function DeleteFragment(arg1:string):Promise<void>;
+
+export function DeleteProject(arg1:string):Promise<void>;
+
 export function DeleteScenario(arg1:string):Promise<void>;
 
+export function DeleteSuite(arg1:string):Promise<void>;
+
+export function DiffRevisions(arg1:number,arg2:number):Promise<binding.FlowDiffDTO>;
+
+export function ExportProject(arg1:boolean):Promise<string>;
+
+export function ExportScenarios(arg1:Array<string>,arg2:boolean):Promise<string>;
+
+export function GetActiveProject():Promise<binding.ProjectDTO>;
+
+export function GetProjectSettings(arg1:string):Promise<binding.ProjectSettingsDTO>;
+
+export function ImportBundle(arg1:string):Promise<binding.ImportResultDTO>;
+
+export function ListFragments():Promise<Array<binding.FragmentDTO>>;
+
+export function ListProjects():Promise<Array<binding.ProjectDTO>>;
+
+export function ListRevisions(arg1:string):Promise<Array<binding.RevisionListItemDTO>>;
+
 export function ListScenarios():Promise<Array<binding.ScenarioListItemDTO>>;
 
+export function ListSuites():Promise<Array<binding.SuiteListItemDTO>>;
+
+export function LoadFragment(arg1:string):Promise<binding.FragmentDTO>;
+
+export function LoadRevision(arg1:number):Promise<binding.RevisionDTO>;
+
 export function LoadScenario(arg1:string):Promise<binding.ScenarioDTO>;
 
+export function LoadSuite(arg1:string):Promise<binding.SuiteDTO>;
+
+export function MoveScenario(arg1:string,arg2:string):Promise<void>;
+
+export function RenameProject(arg1:string,arg2:string):Promise<void>;
+
 export function RenameScenario(arg1:string,arg2:string):Promise<void>;
 
+export function RestoreRevision(arg1:number):Promise<void>;
+
+export function SaveFragment(arg1:string,arg2:string):Promise<void>;
+
+export function SaveProjectSettings(arg1:binding.ProjectSettingsDTO):Promise<void>;
+
 export function SaveScenario(arg1:string,arg2:string):Promise<void>;
 
+export function SaveScenarioWithMessage(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function SaveSuite(arg1:binding.SuiteDTO):Promise<void>;
+
+export function SetActiveProject(arg1:string):Promise<void>;
+
 export function SetContext(arg1:context.Context):Promise<void>;
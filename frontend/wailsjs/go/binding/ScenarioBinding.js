@@ -2,30 +2,142 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CopyScenario(arg1, arg2, arg3) {
+  return window['go']['binding']['ScenarioBinding']['CopyScenario'](arg1, arg2, arg3);
+}
+
+export function CreateFragment(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['CreateFragment'](arg1, arg2);
+}
+
+export function CreateProject(arg1) {
+  return window['go']['binding']['ScenarioBinding']['CreateProject'](arg1);
+}
+
 export function CreateScenario(arg1) {
   return window['go']['binding']['ScenarioBinding']['CreateScenario'](arg1);
 }
 
+export function CreateSuite(arg1) {
+  return window['go']['binding']['ScenarioBinding']['CreateSuite'](arg1);
+}
+
+export function DeleteFragment(arg1) {
+  return window['go']['binding']['ScenarioBinding']['DeleteFragment'](arg1);
+}
+
+export function DeleteProject(arg1) {
+  return window['go']['binding']['ScenarioBinding']['DeleteProject'](arg1);
+}
+
 export function DeleteScenario(arg1) {
   return window['go']['binding']['ScenarioBinding']['DeleteScenario'](arg1);
 }
 
+export function DeleteSuite(arg1) {
+  return window['go']['binding']['ScenarioBinding']['DeleteSuite'](arg1);
+}
+
+export function DiffRevisions(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['DiffRevisions'](arg1, arg2);
+}
+
+export function ExportProject(arg1) {
+  return window['go']['binding']['ScenarioBinding']['ExportProject'](arg1);
+}
+
+export function ExportScenarios(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['ExportScenarios'](arg1, arg2);
+}
+
+export function GetActiveProject() {
+  return window['go']['binding']['ScenarioBinding']['GetActiveProject']();
+}
+
+export function GetProjectSettings(arg1) {
+  return window['go']['binding']['ScenarioBinding']['GetProjectSettings'](arg1);
+}
+
+export function ImportBundle(arg1) {
+  return window['go']['binding']['ScenarioBinding']['ImportBundle'](arg1);
+}
+
+export function ListFragments() {
+  return window['go']['binding']['ScenarioBinding']['ListFragments']();
+}
+
+export function ListProjects() {
+  return window['go']['binding']['ScenarioBinding']['ListProjects']();
+}
+
+export function ListRevisions(arg1) {
+  return window['go']['binding']['ScenarioBinding']['ListRevisions'](arg1);
+}
+
 export function ListScenarios() {
   return window['go']['binding']['ScenarioBinding']['ListScenarios']();
 }
 
+export function ListSuites() {
+  return window['go']['binding']['ScenarioBinding']['ListSuites']();
+}
+
+export function LoadFragment(arg1) {
+  return window['go']['binding']['ScenarioBinding']['LoadFragment'](arg1);
+}
+
+export function LoadRevision(arg1) {
+  return window['go']['binding']['ScenarioBinding']['LoadRevision'](arg1);
+}
+
 export function LoadScenario(arg1) {
   return window['go']['binding']['ScenarioBinding']['LoadScenario'](arg1);
 }
 
+export function LoadSuite(arg1) {
+  return window['go']['binding']['ScenarioBinding']['LoadSuite'](arg1);
+}
+
+export function MoveScenario(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['MoveScenario'](arg1, arg2);
+}
+
+export function RenameProject(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['RenameProject'](arg1, arg2);
+}
+
 export function RenameScenario(arg1, arg2) {
   return window['go']['binding']['ScenarioBinding']['RenameScenario'](arg1, arg2);
 }
 
+export function RestoreRevision(arg1) {
+  return window['go']['binding']['ScenarioBinding']['RestoreRevision'](arg1);
+}
+
+export function SaveFragment(arg1, arg2) {
+  return window['go']['binding']['ScenarioBinding']['SaveFragment'](arg1, arg2);
+}
+
+export function SaveProjectSettings(arg1) {
+  return window['go']['binding']['ScenarioBinding']['SaveProjectSettings'](arg1);
+}
+
 export function SaveScenario(arg1, arg2) {
   return window['go']['binding']['ScenarioBinding']['SaveScenario'](arg1, arg2);
 }
 
+export function SaveScenarioWithMessage(arg1, arg2, arg3) {
+  return window['go']['binding']['ScenarioBinding']['SaveScenarioWithMessage'](arg1, arg2, arg3);
+}
+
+export function SaveSuite(arg1) {
+  return window['go']['binding']['ScenarioBinding']['SaveSuite'](arg1);
+}
+
+export function SetActiveProject(arg1) {
+  return window['go']['binding']['ScenarioBinding']['SetActiveProject'](arg1);
+}
+
 export function SetContext(arg1) {
   return window['go']['binding']['ScenarioBinding']['SetContext'](arg1);
 }
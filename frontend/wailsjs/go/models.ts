@@ -1,5 +1,305 @@
 export namespace binding {
 	
+	export class DryRunOptionsDTO {
+	    latency_ms: number;
+	    node_latency_ms: Record<string, number>;
+	    answer_delay_ms: number;
+	    fail_nodes: Record<string, string>;
+	    flaky_nodes: Record<string, number>;
+	    failure_rate: number;
+	    seed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DryRunOptionsDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.latency_ms = source["latency_ms"];
+	        this.node_latency_ms = source["node_latency_ms"];
+	        this.answer_delay_ms = source["answer_delay_ms"];
+	        this.fail_nodes = source["fail_nodes"];
+	        this.flaky_nodes = source["flaky_nodes"];
+	        this.failure_rate = source["failure_rate"];
+	        this.seed = source["seed"];
+	    }
+	}
+	export class ElementDiffDTO {
+	    id: string;
+	    change: string;
+	    fields: Array<binding.FieldChangeDTO>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ElementDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.change = source["change"];
+	        this.fields = this.convertValues(source["fields"], binding.FieldChangeDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FeatureCodesDTO {
+	    park: string;
+	    park_slot_header: string;
+	    unpark: string;
+	    pickup: string;
+	    forward_always_on: string;
+	    forward_always_off: string;
+	    forward_busy_on: string;
+	    forward_busy_off: string;
+	    forward_no_answer_on: string;
+	    forward_no_answer_off: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeatureCodesDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.park = source["park"];
+	        this.park_slot_header = source["park_slot_header"];
+	        this.unpark = source["unpark"];
+	        this.pickup = source["pickup"];
+	        this.forward_always_on = source["forward_always_on"];
+	        this.forward_always_off = source["forward_always_off"];
+	        this.forward_busy_on = source["forward_busy_on"];
+	        this.forward_busy_off = source["forward_busy_off"];
+	        this.forward_no_answer_on = source["forward_no_answer_on"];
+	        this.forward_no_answer_off = source["forward_no_answer_off"];
+	    }
+	}
+	export class FieldChangeDTO {
+	    path: string;
+	    before: any;
+	    after: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChangeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class FlowDiffDTO {
+	    nodes: Array<binding.ElementDiffDTO>;
+	    edges: Array<binding.ElementDiffDTO>;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodes = this.convertValues(source["nodes"], binding.ElementDiffDTO);
+	        this.edges = this.convertValues(source["edges"], binding.ElementDiffDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FragmentDTO {
+	    id: string;
+	    project_id: string;
+	    name: string;
+	    flow_data: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FragmentDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.flow_data = source["flow_data"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class ImportResultDTO {
+	    imported: Array<string>;
+	    skipped: Array<string>;
+	    renamed: Record<string, string>;
+	    fragments: number;
+	    media_dir: string;
+	    media_files: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResultDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imported = source["imported"];
+	        this.skipped = source["skipped"];
+	        this.renamed = source["renamed"];
+	        this.fragments = source["fragments"];
+	        this.media_dir = source["media_dir"];
+	        this.media_files = source["media_files"];
+	    }
+	}
+	export class ProjectDTO {
+	    id: string;
+	    name: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class ProjectSettingsDTO {
+	    project_id: string;
+	    pbx_host: string;
+	    pbx_port: string;
+	    pbx_transport: string;
+	    codecs: Array<string>;
+	    feature_codes: binding.FeatureCodesDTO;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectSettingsDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.pbx_host = source["pbx_host"];
+	        this.pbx_port = source["pbx_port"];
+	        this.pbx_transport = source["pbx_transport"];
+	        this.codecs = source["codecs"];
+	        this.feature_codes = this.convertValues(source["feature_codes"], binding.FeatureCodesDTO);
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RetryPolicyDTO {
+	    max_attempts: number;
+	    backoff_ms: number;
+	    backoff_factor: number;
+	    max_backoff_ms: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetryPolicyDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.max_attempts = source["max_attempts"];
+	        this.backoff_ms = source["backoff_ms"];
+	        this.backoff_factor = source["backoff_factor"];
+	        this.max_backoff_ms = source["max_backoff_ms"];
+	    }
+	}
+	export class RevisionDTO {
+	    id: number;
+	    scenario_id: string;
+	    revision: number;
+	    message: string;
+	    flow_data: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RevisionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.scenario_id = source["scenario_id"];
+	        this.revision = source["revision"];
+	        this.message = source["message"];
+	        this.flow_data = source["flow_data"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class RevisionListItemDTO {
+	    id: number;
+	    scenario_id: string;
+	    revision: number;
+	    message: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RevisionListItemDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.scenario_id = source["scenario_id"];
+	        this.revision = source["revision"];
+	        this.message = source["message"];
+	        this.created_at = source["created_at"];
+	    }
+	}
 	export class ScenarioDTO {
 	    id: string;
 	    project_id: string;
@@ -42,6 +342,206 @@ export namespace binding {
 	        this.updated_at = source["updated_at"];
 	    }
 	}
+	export class ScheduleDTO {
+	    id: string;
+	    project_id: string;
+	    name: string;
+	    cron: string;
+	    target_kind: string;
+	    target_id: string;
+	    enabled: boolean;
+	    notify_url: string;
+	    next_run_at: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.cron = source["cron"];
+	        this.target_kind = source["target_kind"];
+	        this.target_id = source["target_id"];
+	        this.enabled = source["enabled"];
+	        this.notify_url = source["notify_url"];
+	        this.next_run_at = source["next_run_at"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class ScheduleInputDTO {
+	    project_id: string;
+	    name: string;
+	    cron: string;
+	    target_kind: string;
+	    target_id: string;
+	    enabled: boolean;
+	    notify_url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleInputDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.cron = source["cron"];
+	        this.target_kind = source["target_kind"];
+	        this.target_id = source["target_id"];
+	        this.enabled = source["enabled"];
+	        this.notify_url = source["notify_url"];
+	    }
+	}
+	export class ScheduleRunDTO {
+	    id: number;
+	    schedule_id: string;
+	    run_id: string;
+	    status: string;
+	    error: string;
+	    started_at: string;
+	    finished_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleRunDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.schedule_id = source["schedule_id"];
+	        this.run_id = source["run_id"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.started_at = source["started_at"];
+	        this.finished_at = source["finished_at"];
+	    }
+	}
+	export class StartFromOptionsDTO {
+	    run_setup: boolean;
+	    dry_run: boolean;
+	    simulation: binding.DryRunOptionsDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartFromOptionsDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.run_setup = source["run_setup"];
+	        this.dry_run = source["dry_run"];
+	        this.simulation = this.convertValues(source["simulation"], binding.DryRunOptionsDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SuiteDTO {
+	    id: string;
+	    project_id: string;
+	    name: string;
+	    setup_scenario_id: string;
+	    variables: Record<string, any>;
+	    stop_on_failure: boolean;
+	    retries: number;
+	    timeout_seconds: number;
+	    entries: Array<binding.SuiteEntryDTO>;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SuiteDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.setup_scenario_id = source["setup_scenario_id"];
+	        this.variables = source["variables"];
+	        this.stop_on_failure = source["stop_on_failure"];
+	        this.retries = source["retries"];
+	        this.timeout_seconds = source["timeout_seconds"];
+	        this.entries = this.convertValues(source["entries"], binding.SuiteEntryDTO);
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SuiteEntryDTO {
+	    scenario_id: string;
+	    variables: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new SuiteEntryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scenario_id = source["scenario_id"];
+	        this.variables = source["variables"];
+	    }
+	}
+	export class SuiteListItemDTO {
+	    id: string;
+	    project_id: string;
+	    name: string;
+	    entry_count: number;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SuiteListItemDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.entry_count = source["entry_count"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
 	export class WAVValidationResult {
 	    valid: boolean;
 	    error?: string;
@@ -61,3 +561,276 @@ export namespace binding {
 
 }
 
+export namespace engine {
+	
+	export class DebugDialog {
+	    instanceId: string;
+	    callId: string;
+	    sipCallId?: string;
+	    state: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugDialog(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.callId = source["callId"];
+	        this.sipCallId = source["sipCallId"];
+	        this.state = source["state"];
+	    }
+	}
+	export class DebugPausePoint {
+	    nodeId: string;
+	    instanceId: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugPausePoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodeId = source["nodeId"];
+	        this.instanceId = source["instanceId"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class DebugSnapshot {
+	    paused: boolean;
+	    pausePoint?: engine.DebugPausePoint;
+	    breakpoints: Array<string>;
+	    variables: Record<string, any>;
+	    signals: Array<string>;
+	    dialogs: Array<engine.DebugDialog>;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paused = source["paused"];
+	        this.pausePoint = this.convertValues(source["pausePoint"], engine.DebugPausePoint);
+	        this.breakpoints = source["breakpoints"];
+	        this.variables = source["variables"];
+	        this.signals = source["signals"];
+	        this.dialogs = this.convertValues(source["dialogs"], engine.DebugDialog);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Diagnostic {
+	    nodeId?: string;
+	    severity: string;
+	    code: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Diagnostic(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodeId = source["nodeId"];
+	        this.severity = source["severity"];
+	        this.code = source["code"];
+	        this.message = source["message"];
+	    }
+	}
+	export class PBXBinding {
+	    dn: string;
+	    contact: string;
+	    expiresAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PBXBinding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dn = source["dn"];
+	        this.contact = source["contact"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RunInfo {
+	    runId: string;
+	    scenarioId: string;
+	    dryRun: boolean;
+	    paused: boolean;
+	    portBase?: number;
+	    startedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RunInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.runId = source["runId"];
+	        this.scenarioId = source["scenarioId"];
+	        this.dryRun = source["dryRun"];
+	        this.paused = source["paused"];
+	        this.portBase = source["portBase"];
+	        this.startedAt = source["startedAt"];
+	    }
+	}
+	export class RunResult {
+	    runId: string;
+	    scenarioId: string;
+	    status: string;
+	    error?: string;
+	    attempts: number;
+	    nodeRetries: number;
+	    startedAt: any;
+	    finishedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RunResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.runId = source["runId"];
+	        this.scenarioId = source["scenarioId"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.attempts = source["attempts"];
+	        this.nodeRetries = source["nodeRetries"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.finishedAt = this.convertValues(source["finishedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SuiteEntryResult {
+	    scenarioId: string;
+	    setup?: boolean;
+	    status: string;
+	    attempts: number;
+	    nodeRetries: number;
+	    flaky?: boolean;
+	    runId?: string;
+	    error?: string;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SuiteEntryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scenarioId = source["scenarioId"];
+	        this.setup = source["setup"];
+	        this.status = source["status"];
+	        this.attempts = source["attempts"];
+	        this.nodeRetries = source["nodeRetries"];
+	        this.flaky = source["flaky"];
+	        this.runId = source["runId"];
+	        this.error = source["error"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class SuiteReport {
+	    suiteId: string;
+	    name: string;
+	    status: string;
+	    passed: number;
+	    failed: number;
+	    skipped: number;
+	    flaky: number;
+	    entries: Array<engine.SuiteEntryResult>;
+	    startedAt: any;
+	    finishedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SuiteReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.suiteId = source["suiteId"];
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.passed = source["passed"];
+	        this.failed = source["failed"];
+	        this.skipped = source["skipped"];
+	        this.flaky = source["flaky"];
+	        this.entries = this.convertValues(source["entries"], engine.SuiteEntryResult);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.finishedAt = this.convertValues(source["finishedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
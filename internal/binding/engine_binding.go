@@ -2,7 +2,9 @@ package binding
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	_ "github.com/emiago/diago" // SIP engine library - imported for dependency tracking
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -14,6 +16,9 @@ import (
 type EngineBinding struct {
 	ctx    context.Context
 	engine *engine.Engine
+
+	mu          sync.Mutex
+	suiteCancel context.CancelFunc // cancels the suite started from the frontend
 }
 
 // NewEngineBinding creates a new EngineBinding instance
@@ -49,17 +54,18 @@ func (e *EngineBinding) GetSupportedEvents() []string {
 	return engine.SupportedEvents()
 }
 
-// StartScenario starts a scenario execution
-func (e *EngineBinding) StartScenario(scenarioID string) error {
+// StartScenario starts a scenario execution and returns its run ID
+func (e *EngineBinding) StartScenario(scenarioID string) (string, error) {
 	runtime.LogInfo(e.ctx, fmt.Sprintf("Starting scenario: %s", scenarioID))
-	if err := e.engine.StartScenario(scenarioID); err != nil {
+	runID, err := e.engine.StartScenario(scenarioID)
+	if err != nil {
 		runtime.LogError(e.ctx, fmt.Sprintf("Failed to start scenario: %v", err))
-		return err
+		return "", err
 	}
-	return nil
+	return runID, nil
 }
 
-// StopScenario stops the running scenario
+// StopScenario stops every running scenario
 func (e *EngineBinding) StopScenario() error {
 	runtime.LogInfo(e.ctx, "Stopping scenario")
 	if err := e.engine.StopScenario(); err != nil {
@@ -69,7 +75,189 @@ func (e *EngineBinding) StopScenario() error {
 	return nil
 }
 
-// IsRunning returns whether a scenario is currently running
+// StopRun stops a single run, leaving other runs untouched
+func (e *EngineBinding) StopRun(runID string) error {
+	runtime.LogInfo(e.ctx, fmt.Sprintf("Stopping run: %s", runID))
+	if err := e.engine.StopRun(runID); err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to stop run: %v", err))
+		return err
+	}
+	return nil
+}
+
+// IsRunning returns whether any scenario is currently running
 func (e *EngineBinding) IsRunning() bool {
 	return e.engine.IsRunning()
 }
+
+// ListRuns returns the active runs ordered by start time
+func (e *EngineBinding) ListRuns() []engine.RunInfo {
+	return e.engine.ListRuns()
+}
+
+// GetRun returns a single active run
+func (e *EngineBinding) GetRun(runID string) (*engine.RunInfo, error) {
+	return e.engine.GetRun(runID)
+}
+
+// ValidateScenario runs every backend check against flow data and returns all diagnostics.
+// CallScenario nodes are expanded with the fragments and scenarios of the given project.
+func (e *EngineBinding) ValidateScenario(projectID, flowData string) []engine.Diagnostic {
+	diags := e.engine.ValidateScenario(projectID, flowData)
+	if len(diags) > 0 {
+		runtime.LogInfo(e.ctx, fmt.Sprintf("Scenario validation found %d problems", len(diags)))
+	}
+	return diags
+}
+
+// StartScenarioDryRun runs a scenario against an in-process simulated SIP backend.
+// No sockets are opened; node state and action log events match a real run.
+func (e *EngineBinding) StartScenarioDryRun(scenarioID string, opts DryRunOptionsDTO) (string, error) {
+	runtime.LogInfo(e.ctx, fmt.Sprintf("Starting dry run: %s", scenarioID))
+	runID, err := e.engine.StartScenarioDryRun(scenarioID, opts.toSimulationOptions())
+	if err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to start dry run: %v", err))
+		return "", err
+	}
+	return runID, nil
+}
+
+// StartScenarioFrom starts chains at the given nodes, optionally running the setup prefix quietly first
+func (e *EngineBinding) StartScenarioFrom(scenarioID string, nodeIDs []string, opts StartFromOptionsDTO) (string, error) {
+	runtime.LogInfo(e.ctx, fmt.Sprintf("Starting scenario %s from nodes %v", scenarioID, nodeIDs))
+	runID, err := e.engine.StartScenarioFrom(scenarioID, nodeIDs, opts.toStartFromOptions())
+	if err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to start scenario from nodes: %v", err))
+		return "", err
+	}
+	return runID, nil
+}
+
+// RunScenarioWithRetry runs a scenario to completion, rerunning it after a failure up to the policy's attempts.
+// The result reports the attempt count and node retries so flaky passes can be told apart.
+func (e *EngineBinding) RunScenarioWithRetry(scenarioID string, policy RetryPolicyDTO) (*engine.RunResult, error) {
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	runtime.LogInfo(e.ctx, fmt.Sprintf("Running scenario %s with up to %d attempts", scenarioID, policy.MaxAttempts))
+	result, err := e.engine.RunScenarioWithRetry(ctx, scenarioID, nil, policy.toRetryPolicy())
+	if err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to run scenario: %v", err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// RunSuite runs a suite sequentially and returns its aggregated report when it finishes
+func (e *EngineBinding) RunSuite(suiteID string) (*engine.SuiteReport, error) {
+	return e.runSuite(suiteID, nil)
+}
+
+// RunSuiteDryRun runs every scenario of a suite against the simulated SIP backend
+func (e *EngineBinding) RunSuiteDryRun(suiteID string, opts DryRunOptionsDTO) (*engine.SuiteReport, error) {
+	sim := opts.toSimulationOptions()
+	return e.runSuite(suiteID, &sim)
+}
+
+func (e *EngineBinding) runSuite(suiteID string, sim *engine.SimulationOptions) (*engine.SuiteReport, error) {
+	parent := e.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	e.mu.Lock()
+	if e.suiteCancel != nil {
+		e.mu.Unlock()
+		return nil, errors.New("a suite is already running")
+	}
+	ctx, cancel := context.WithCancel(parent)
+	e.suiteCancel = cancel
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.suiteCancel = nil
+		e.mu.Unlock()
+		cancel()
+	}()
+
+	runtime.LogInfo(e.ctx, fmt.Sprintf("Running suite: %s", suiteID))
+	report, err := e.engine.RunSuite(ctx, suiteID, sim)
+	if err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to run suite: %v", err))
+		return nil, err
+	}
+	return report, nil
+}
+
+// StopSuite stops the running suite; the current scenario is stopped and the rest are skipped
+func (e *EngineBinding) StopSuite() error {
+	e.mu.Lock()
+	cancel := e.suiteCancel
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return errors.New("no running suite")
+	}
+	cancel()
+	return nil
+}
+
+// GetLocalPBXBindings returns the DNs registered with a run's built-in registrar
+func (e *EngineBinding) GetLocalPBXBindings(runID string) ([]engine.PBXBinding, error) {
+	return e.engine.LocalPBXBindings(runID)
+}
+
+// GetServerRegistrations returns the contacts registered with a SIP server instance of a run
+func (e *EngineBinding) GetServerRegistrations(runID, instanceID string) ([]engine.PBXBinding, error) {
+	return e.engine.ServerRegistrations(runID, instanceID)
+}
+
+// SetBreakpoints replaces the set of node IDs where execution pauses
+func (e *EngineBinding) SetBreakpoints(nodeIDs []string) {
+	e.engine.SetBreakpoints(nodeIDs)
+}
+
+// GetBreakpoints returns the node IDs that currently have breakpoints
+func (e *EngineBinding) GetBreakpoints() []string {
+	return e.engine.Breakpoints()
+}
+
+// PauseScenario pauses a run at the next node boundary
+func (e *EngineBinding) PauseScenario(runID string) error {
+	if err := e.engine.PauseScenario(runID); err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to pause scenario: %v", err))
+		return err
+	}
+	return nil
+}
+
+// ResumeScenario resumes a paused run
+func (e *EngineBinding) ResumeScenario(runID string) error {
+	if err := e.engine.ResumeScenario(runID); err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to resume scenario: %v", err))
+		return err
+	}
+	return nil
+}
+
+// StepScenario runs the paused node and pauses again before the next node of the same chain
+func (e *EngineBinding) StepScenario(runID string) error {
+	if err := e.engine.StepScenario(runID); err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to step scenario: %v", err))
+		return err
+	}
+	return nil
+}
+
+// GetDebugSnapshot returns the pause point, active dialogs and raised signals of a run
+func (e *EngineBinding) GetDebugSnapshot(runID string) (*engine.DebugSnapshot, error) {
+	snapshot, err := e.engine.DebugSnapshot(runID)
+	if err != nil {
+		runtime.LogError(e.ctx, fmt.Sprintf("Failed to get debug snapshot: %v", err))
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,68 @@
+package binding
+
+import (
+	"time"
+
+	"sipflow/internal/engine"
+)
+
+// DryRunOptionsDTO configures the simulated SIP backend of a dry run.
+// Durations are in milliseconds so the frontend can pass plain numbers.
+type DryRunOptionsDTO struct {
+	LatencyMs     int               `json:"latency_ms"`
+	NodeLatencyMs map[string]int    `json:"node_latency_ms"`
+	AnswerDelayMs int               `json:"answer_delay_ms"`
+	FailNodes     map[string]string `json:"fail_nodes"`
+	FlakyNodes    map[string]int    `json:"flaky_nodes"`
+	FailureRate   float64           `json:"failure_rate"`
+	Seed          int64             `json:"seed"`
+}
+
+func (o DryRunOptionsDTO) toSimulationOptions() engine.SimulationOptions {
+	nodeLatency := make(map[string]time.Duration, len(o.NodeLatencyMs))
+	for nodeID, ms := range o.NodeLatencyMs {
+		nodeLatency[nodeID] = time.Duration(ms) * time.Millisecond
+	}
+	return engine.SimulationOptions{
+		Latency:     time.Duration(o.LatencyMs) * time.Millisecond,
+		NodeLatency: nodeLatency,
+		AnswerDelay: time.Duration(o.AnswerDelayMs) * time.Millisecond,
+		FailNodes:   o.FailNodes,
+		FlakyNodes:  o.FlakyNodes,
+		FailureRate: o.FailureRate,
+		Seed:        o.Seed,
+	}
+}
+
+// RetryPolicyDTO configures run-level retries of a whole scenario
+type RetryPolicyDTO struct {
+	MaxAttempts   int     `json:"max_attempts"`
+	BackoffMs     int     `json:"backoff_ms"`
+	BackoffFactor float64 `json:"backoff_factor"`
+	MaxBackoffMs  int     `json:"max_backoff_ms"`
+}
+
+func (o RetryPolicyDTO) toRetryPolicy() engine.RetryPolicy {
+	return engine.RetryPolicy{
+		MaxAttempts:   o.MaxAttempts,
+		Backoff:       time.Duration(o.BackoffMs) * time.Millisecond,
+		BackoffFactor: o.BackoffFactor,
+		MaxBackoff:    time.Duration(o.MaxBackoffMs) * time.Millisecond,
+	}
+}
+
+// StartFromOptionsDTO configures a partial run started from selected nodes
+type StartFromOptionsDTO struct {
+	RunSetup   bool             `json:"run_setup"`
+	DryRun     bool             `json:"dry_run"`
+	Simulation DryRunOptionsDTO `json:"simulation"`
+}
+
+func (o StartFromOptionsDTO) toStartFromOptions() engine.StartFromOptions {
+	opts := engine.StartFromOptions{RunSetup: o.RunSetup}
+	if o.DryRun {
+		sim := o.Simulation.toSimulationOptions()
+		opts.Simulation = &sim
+	}
+	return opts
+}
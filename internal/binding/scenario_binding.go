@@ -3,6 +3,9 @@ package binding
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"sipflow/internal/scenario"
 
@@ -13,12 +16,16 @@ import (
 type ScenarioBinding struct {
 	ctx  context.Context
 	repo *scenario.Repository
+
+	mu        sync.RWMutex
+	projectID string // active project for scenario/fragment operations
 }
 
 // NewScenarioBinding creates a new ScenarioBinding instance
 func NewScenarioBinding(repo *scenario.Repository) *ScenarioBinding {
 	return &ScenarioBinding{
-		repo: repo,
+		repo:      repo,
+		projectID: scenario.DefaultProjectID,
 	}
 }
 
@@ -31,7 +38,7 @@ func (s *ScenarioBinding) SetContext(ctx context.Context) {
 func (s *ScenarioBinding) CreateScenario(name string) (*ScenarioDTO, error) {
 	runtime.LogInfo(s.ctx, fmt.Sprintf("Creating scenario: %s", name))
 
-	sc, err := s.repo.CreateScenario("default", name)
+	sc, err := s.repo.CreateScenario(s.activeProjectID(), name)
 	if err != nil {
 		runtime.LogError(s.ctx, fmt.Sprintf("Failed to create scenario: %v", err))
 		return nil, err
@@ -55,6 +62,19 @@ func (s *ScenarioBinding) SaveScenario(id, flowData string) error {
 	return nil
 }
 
+// SaveScenarioWithMessage saves the flow data and labels the recorded revision with a message
+func (s *ScenarioBinding) SaveScenarioWithMessage(id, flowData, message string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Saving scenario: %s (%s)", id, message))
+
+	if err := s.repo.SaveScenarioWithMessage(id, flowData, message); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to save scenario: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Scenario saved: %s", id))
+	return nil
+}
+
 // LoadScenario loads a scenario by ID
 func (s *ScenarioBinding) LoadScenario(id string) (*ScenarioDTO, error) {
 	runtime.LogInfo(s.ctx, fmt.Sprintf("Loading scenario: %s", id))
@@ -69,11 +89,11 @@ func (s *ScenarioBinding) LoadScenario(id string) (*ScenarioDTO, error) {
 	return newScenarioDTO(sc), nil
 }
 
-// ListScenarios lists all scenarios in the default project
+// ListScenarios lists all scenarios in the active project
 func (s *ScenarioBinding) ListScenarios() ([]ScenarioListItemDTO, error) {
 	runtime.LogInfo(s.ctx, "Listing scenarios")
 
-	scenarios, err := s.repo.ListScenarios("default")
+	scenarios, err := s.repo.ListScenarios(s.activeProjectID())
 	if err != nil {
 		runtime.LogError(s.ctx, fmt.Sprintf("Failed to list scenarios: %v", err))
 		return nil, err
@@ -115,3 +135,477 @@ func (s *ScenarioBinding) RenameScenario(id, newName string) error {
 	runtime.LogInfo(s.ctx, fmt.Sprintf("Scenario renamed: %s -> %s", id, newName))
 	return nil
 }
+
+// ListRevisions lists the revision history of a scenario, newest first
+func (s *ScenarioBinding) ListRevisions(scenarioID string) ([]RevisionListItemDTO, error) {
+	revisions, err := s.repo.ListRevisions(scenarioID)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to list revisions: %v", err))
+		return nil, err
+	}
+
+	items := make([]RevisionListItemDTO, 0, len(revisions))
+	for _, rev := range revisions {
+		items = append(items, newRevisionListItemDTO(rev))
+	}
+
+	return items, nil
+}
+
+// LoadRevision loads a single revision including its flow data
+func (s *ScenarioBinding) LoadRevision(id int64) (*RevisionDTO, error) {
+	rev, err := s.repo.LoadRevision(id)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to load revision: %v", err))
+		return nil, err
+	}
+
+	return newRevisionDTO(rev), nil
+}
+
+// RestoreRevision makes a past revision the scenario's current flow data
+func (s *ScenarioBinding) RestoreRevision(id int64) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Restoring revision: %d", id))
+
+	if err := s.repo.RestoreRevision(id); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to restore revision: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Revision restored: %d", id))
+	return nil
+}
+
+// DiffRevisions computes a structural node/edge diff between two revisions
+func (s *ScenarioBinding) DiffRevisions(fromID, toID int64) (*FlowDiffDTO, error) {
+	diff, err := s.repo.DiffRevisions(fromID, toID)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to diff revisions: %v", err))
+		return nil, err
+	}
+
+	return newFlowDiffDTO(diff), nil
+}
+
+// CreateFragment creates a named, reusable flow fragment in the active project
+func (s *ScenarioBinding) CreateFragment(name, flowData string) (*FragmentDTO, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Creating fragment: %s", name))
+
+	fragment, err := s.repo.CreateFragment(s.activeProjectID(), name, flowData)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to create fragment: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Fragment created: %s (ID: %s)", name, fragment.ID))
+	return newFragmentDTO(fragment), nil
+}
+
+// SaveFragment saves the flow data for an existing fragment
+func (s *ScenarioBinding) SaveFragment(id, flowData string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Saving fragment: %s", id))
+
+	if err := s.repo.SaveFragment(id, flowData); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to save fragment: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Fragment saved: %s", id))
+	return nil
+}
+
+// LoadFragment loads a fragment by ID
+func (s *ScenarioBinding) LoadFragment(id string) (*FragmentDTO, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Loading fragment: %s", id))
+
+	fragment, err := s.repo.LoadFragment(id)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to load fragment: %v", err))
+		return nil, err
+	}
+
+	return newFragmentDTO(fragment), nil
+}
+
+// ListFragments lists all fragments in the active project
+func (s *ScenarioBinding) ListFragments() ([]FragmentDTO, error) {
+	runtime.LogInfo(s.ctx, "Listing fragments")
+
+	fragments, err := s.repo.ListFragments(s.activeProjectID())
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to list fragments: %v", err))
+		return nil, err
+	}
+
+	items := make([]FragmentDTO, 0, len(fragments))
+	for i := range fragments {
+		items = append(items, *newFragmentDTO(&fragments[i]))
+	}
+
+	return items, nil
+}
+
+// DeleteFragment deletes a fragment by ID
+func (s *ScenarioBinding) DeleteFragment(id string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Deleting fragment: %s", id))
+
+	if err := s.repo.DeleteFragment(id); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to delete fragment: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Fragment deleted: %s", id))
+	return nil
+}
+
+// CreateSuite creates an empty suite in the active project
+func (s *ScenarioBinding) CreateSuite(name string) (*SuiteDTO, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Creating suite: %s", name))
+
+	suite := &scenario.Suite{ProjectID: s.activeProjectID(), Name: name}
+	if err := s.repo.CreateSuite(suite); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to create suite: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Suite created: %s (ID: %s)", name, suite.ID))
+	return newSuiteDTO(suite), nil
+}
+
+// SaveSuite saves the settings and ordered entries of an existing suite
+func (s *ScenarioBinding) SaveSuite(suite SuiteDTO) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Saving suite: %s", suite.ID))
+
+	if err := s.repo.UpdateSuite(suite.toSuite()); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to save suite: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// LoadSuite loads a suite and its entries by ID
+func (s *ScenarioBinding) LoadSuite(id string) (*SuiteDTO, error) {
+	suite, err := s.repo.LoadSuite(id)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to load suite: %v", err))
+		return nil, err
+	}
+
+	return newSuiteDTO(suite), nil
+}
+
+// ListSuites lists all suites in the active project
+func (s *ScenarioBinding) ListSuites() ([]SuiteListItemDTO, error) {
+	suites, err := s.repo.ListSuites(s.activeProjectID())
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to list suites: %v", err))
+		return nil, err
+	}
+
+	items := make([]SuiteListItemDTO, 0, len(suites))
+	for _, suite := range suites {
+		items = append(items, newSuiteListItemDTO(suite))
+	}
+
+	return items, nil
+}
+
+// DeleteSuite deletes a suite by ID
+func (s *ScenarioBinding) DeleteSuite(id string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Deleting suite: %s", id))
+
+	if err := s.repo.DeleteSuite(id); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to delete suite: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// ExportScenarios writes the given scenarios to a portable bundle file chosen by the user.
+// Returns the written path, or an empty string if the dialog was cancelled.
+func (s *ScenarioBinding) ExportScenarios(ids []string, includeMedia bool) (string, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Exporting %d scenarios", len(ids)))
+
+	bundle, err := s.repo.ExportScenarios(ids, scenario.ExportOptions{IncludeMedia: includeMedia})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to export scenarios: %v", err))
+		return "", err
+	}
+
+	return s.writeBundle(bundle, "scenarios.sipflow.json")
+}
+
+// ExportProject writes every scenario and fragment in the active project to a bundle file.
+// Returns the written path, or an empty string if the dialog was cancelled.
+func (s *ScenarioBinding) ExportProject(includeMedia bool) (string, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Exporting project: %s", s.activeProjectID()))
+
+	bundle, err := s.repo.ExportProject(s.activeProjectID(), scenario.ExportOptions{IncludeMedia: includeMedia})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to export project: %v", err))
+		return "", err
+	}
+
+	return s.writeBundle(bundle, "project.sipflow.json")
+}
+
+// ImportBundle imports a bundle file chosen by the user into the active project.
+// conflictPolicy is one of "skip", "overwrite" or "copy". Returns nil if the dialog was cancelled.
+func (s *ScenarioBinding) ImportBundle(conflictPolicy string) (*ImportResultDTO, error) {
+	selected, err := runtime.OpenFileDialog(s.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Scenarios",
+		Filters: bundleFileFilters,
+	})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("File dialog error: %v", err))
+		return nil, err
+	}
+
+	if selected == "" {
+		// User cancelled
+		return nil, nil
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Importing bundle: %s", selected))
+
+	data, err := os.ReadFile(selected)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to read bundle: %v", err))
+		return nil, err
+	}
+
+	bundle, err := scenario.UnmarshalBundle(data)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to parse bundle: %v", err))
+		return nil, err
+	}
+
+	mediaDir, err := importedMediaDir()
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to resolve media directory: %v", err))
+		return nil, err
+	}
+
+	result, err := s.repo.ImportBundle(s.activeProjectID(), bundle, scenario.ImportOptions{
+		OnConflict: scenario.ConflictPolicy(conflictPolicy),
+		MediaDir:   mediaDir,
+	})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to import bundle: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Bundle imported: %d scenarios, %d skipped, %d fragments",
+		len(result.Imported), len(result.Skipped), result.Fragments))
+	return newImportResultDTO(result), nil
+}
+
+var bundleFileFilters = []runtime.FileFilter{
+	{
+		DisplayName: "SIPFlow Bundle (*.json)",
+		Pattern:     "*.json",
+	},
+}
+
+func (s *ScenarioBinding) writeBundle(bundle *scenario.Bundle, defaultFilename string) (string, error) {
+	data, err := scenario.MarshalBundle(bundle)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to encode bundle: %v", err))
+		return "", err
+	}
+
+	selected, err := runtime.SaveFileDialog(s.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Scenarios",
+		DefaultFilename: defaultFilename,
+		Filters:         bundleFileFilters,
+	})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("File dialog error: %v", err))
+		return "", err
+	}
+
+	if selected == "" {
+		// User cancelled
+		return "", nil
+	}
+
+	if err := os.WriteFile(selected, data, 0644); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to write bundle: %v", err))
+		return "", err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Bundle exported: %s (%d scenarios)", selected, len(bundle.Scenarios)))
+	return selected, nil
+}
+
+// importedMediaDir returns the directory where media files extracted from bundles are stored
+func importedMediaDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config dir: %w", err)
+	}
+	return filepath.Join(configDir, "sipflow", "media"), nil
+}
+
+func (s *ScenarioBinding) activeProjectID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.projectID
+}
+
+// SetActiveProject switches the project used by scenario, fragment and bundle operations
+func (s *ScenarioBinding) SetActiveProject(id string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Switching active project: %s", id))
+
+	if _, err := s.repo.LoadProject(id); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to switch project: %v", err))
+		return err
+	}
+
+	s.mu.Lock()
+	s.projectID = id
+	s.mu.Unlock()
+	return nil
+}
+
+// GetActiveProject returns the project used by scenario, fragment and bundle operations
+func (s *ScenarioBinding) GetActiveProject() (*ProjectDTO, error) {
+	project, err := s.repo.LoadProject(s.activeProjectID())
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to load active project: %v", err))
+		return nil, err
+	}
+
+	return newProjectDTO(project), nil
+}
+
+// CreateProject creates a new project with the given name
+func (s *ScenarioBinding) CreateProject(name string) (*ProjectDTO, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Creating project: %s", name))
+
+	project, err := s.repo.CreateProject(name)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to create project: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Project created: %s (ID: %s)", name, project.ID))
+	return newProjectDTO(project), nil
+}
+
+// ListProjects lists all projects, default project first
+func (s *ScenarioBinding) ListProjects() ([]ProjectDTO, error) {
+	runtime.LogInfo(s.ctx, "Listing projects")
+
+	projects, err := s.repo.ListProjects()
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to list projects: %v", err))
+		return nil, err
+	}
+
+	items := make([]ProjectDTO, 0, len(projects))
+	for i := range projects {
+		items = append(items, *newProjectDTO(&projects[i]))
+	}
+
+	return items, nil
+}
+
+// RenameProject renames a project
+func (s *ScenarioBinding) RenameProject(id, newName string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Renaming project %s to: %s", id, newName))
+
+	if err := s.repo.RenameProject(id, newName); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to rename project: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Project renamed: %s -> %s", id, newName))
+	return nil
+}
+
+// DeleteProject deletes a project and everything in it.
+// If the deleted project was active, the default project becomes active.
+func (s *ScenarioBinding) DeleteProject(id string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Deleting project: %s", id))
+
+	if err := s.repo.DeleteProject(id); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to delete project: %v", err))
+		return err
+	}
+
+	s.mu.Lock()
+	if s.projectID == id {
+		s.projectID = scenario.DefaultProjectID
+	}
+	s.mu.Unlock()
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Project deleted: %s", id))
+	return nil
+}
+
+// MoveScenario moves a scenario to another project
+func (s *ScenarioBinding) MoveScenario(id, projectID string) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Moving scenario %s to project: %s", id, projectID))
+
+	if err := s.repo.MoveScenario(id, projectID); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to move scenario: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Scenario moved: %s -> %s", id, projectID))
+	return nil
+}
+
+// CopyScenario copies a scenario into a project. An empty name keeps the original name.
+func (s *ScenarioBinding) CopyScenario(id, projectID, name string) (*ScenarioDTO, error) {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Copying scenario %s to project: %s", id, projectID))
+
+	sc, err := s.repo.CopyScenario(id, projectID, name)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to copy scenario: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Scenario copied: %s -> %s", id, sc.ID))
+	return newScenarioDTO(sc), nil
+}
+
+// GetProjectSettings loads the project-scoped defaults for SIP instances
+func (s *ScenarioBinding) GetProjectSettings(projectID string) (*ProjectSettingsDTO, error) {
+	settings, err := s.repo.LoadProjectSettings(projectID)
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to load project settings: %v", err))
+		return nil, err
+	}
+
+	return newProjectSettingsDTO(settings), nil
+}
+
+// SaveProjectSettings saves the project-scoped defaults for SIP instances
+func (s *ScenarioBinding) SaveProjectSettings(settings ProjectSettingsDTO) error {
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Saving project settings: %s", settings.ProjectID))
+
+	if _, err := s.repo.LoadProject(settings.ProjectID); err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to save project settings: %v", err))
+		return err
+	}
+
+	err := s.repo.SaveProjectSettings(&scenario.ProjectSettings{
+		ProjectID:    settings.ProjectID,
+		PBXHost:      settings.PBXHost,
+		PBXPort:      settings.PBXPort,
+		PBXTransport: settings.PBXTransport,
+		Codecs:       settings.Codecs,
+		FeatureCodes: scenario.FeatureCodes(settings.FeatureCodes),
+	})
+	if err != nil {
+		runtime.LogError(s.ctx, fmt.Sprintf("Failed to save project settings: %v", err))
+		return err
+	}
+
+	runtime.LogInfo(s.ctx, fmt.Sprintf("Project settings saved: %s", settings.ProjectID))
+	return nil
+}
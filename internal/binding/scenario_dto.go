@@ -6,6 +6,36 @@ import (
 	"sipflow/internal/scenario"
 )
 
+type ProjectDTO struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ProjectSettingsDTO struct {
+	ProjectID    string          `json:"project_id"`
+	PBXHost      string          `json:"pbx_host"`
+	PBXPort      string          `json:"pbx_port"`
+	PBXTransport string          `json:"pbx_transport"`
+	Codecs       []string        `json:"codecs"`
+	FeatureCodes FeatureCodesDTO `json:"feature_codes"`
+	UpdatedAt    string          `json:"updated_at"`
+}
+
+type FeatureCodesDTO struct {
+	Park               string `json:"park"`
+	ParkSlotHeader     string `json:"park_slot_header"`
+	Unpark             string `json:"unpark"`
+	Pickup             string `json:"pickup"`
+	ForwardAlwaysOn    string `json:"forward_always_on"`
+	ForwardAlwaysOff   string `json:"forward_always_off"`
+	ForwardBusyOn      string `json:"forward_busy_on"`
+	ForwardBusyOff     string `json:"forward_busy_off"`
+	ForwardNoAnswerOn  string `json:"forward_no_answer_on"`
+	ForwardNoAnswerOff string `json:"forward_no_answer_off"`
+}
+
 type ScenarioDTO struct {
 	ID        string `json:"id"`
 	ProjectID string `json:"project_id"`
@@ -23,6 +53,86 @@ type ScenarioListItemDTO struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+type FragmentDTO struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	FlowData  string `json:"flow_data"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type SuiteEntryDTO struct {
+	ScenarioID string                 `json:"scenario_id"`
+	Variables  map[string]interface{} `json:"variables"`
+}
+
+type SuiteDTO struct {
+	ID              string                 `json:"id"`
+	ProjectID       string                 `json:"project_id"`
+	Name            string                 `json:"name"`
+	SetupScenarioID string                 `json:"setup_scenario_id"`
+	Variables       map[string]interface{} `json:"variables"`
+	StopOnFailure   bool                   `json:"stop_on_failure"`
+	Retries         int                    `json:"retries"`
+	TimeoutSeconds  int                    `json:"timeout_seconds"`
+	Entries         []SuiteEntryDTO        `json:"entries"`
+	CreatedAt       string                 `json:"created_at"`
+	UpdatedAt       string                 `json:"updated_at"`
+}
+
+type SuiteListItemDTO struct {
+	ID         string `json:"id"`
+	ProjectID  string `json:"project_id"`
+	Name       string `json:"name"`
+	EntryCount int    `json:"entry_count"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type ImportResultDTO struct {
+	Imported   []string          `json:"imported"`
+	Skipped    []string          `json:"skipped"`
+	Renamed    map[string]string `json:"renamed"`
+	Fragments  int               `json:"fragments"`
+	MediaDir   string            `json:"media_dir"`
+	MediaFiles int               `json:"media_files"`
+}
+
+type RevisionDTO struct {
+	ID         int64  `json:"id"`
+	ScenarioID string `json:"scenario_id"`
+	Revision   int    `json:"revision"`
+	Message    string `json:"message"`
+	FlowData   string `json:"flow_data"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type RevisionListItemDTO struct {
+	ID         int64  `json:"id"`
+	ScenarioID string `json:"scenario_id"`
+	Revision   int    `json:"revision"`
+	Message    string `json:"message"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type FlowDiffDTO struct {
+	Nodes []ElementDiffDTO `json:"nodes"`
+	Edges []ElementDiffDTO `json:"edges"`
+}
+
+type ElementDiffDTO struct {
+	ID     string           `json:"id"`
+	Change string           `json:"change"`
+	Fields []FieldChangeDTO `json:"fields"`
+}
+
+type FieldChangeDTO struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
 func formatBindingTime(value time.Time) string {
 	if value.IsZero() {
 		return ""
@@ -31,6 +141,35 @@ func formatBindingTime(value time.Time) string {
 	return value.Format(time.RFC3339Nano)
 }
 
+func newProjectDTO(source *scenario.Project) *ProjectDTO {
+	if source == nil {
+		return nil
+	}
+
+	return &ProjectDTO{
+		ID:        source.ID,
+		Name:      source.Name,
+		CreatedAt: formatBindingTime(source.CreatedAt),
+		UpdatedAt: formatBindingTime(source.UpdatedAt),
+	}
+}
+
+func newProjectSettingsDTO(source *scenario.ProjectSettings) *ProjectSettingsDTO {
+	if source == nil {
+		return nil
+	}
+
+	return &ProjectSettingsDTO{
+		ProjectID:    source.ProjectID,
+		PBXHost:      source.PBXHost,
+		PBXPort:      source.PBXPort,
+		PBXTransport: source.PBXTransport,
+		Codecs:       source.Codecs,
+		FeatureCodes: FeatureCodesDTO(source.FeatureCodes),
+		UpdatedAt:    formatBindingTime(source.UpdatedAt),
+	}
+}
+
 func newScenarioDTO(source *scenario.Scenario) *ScenarioDTO {
 	if source == nil {
 		return nil
@@ -55,3 +194,136 @@ func newScenarioListItemDTO(source scenario.ScenarioListItem) ScenarioListItemDT
 		UpdatedAt: formatBindingTime(source.UpdatedAt),
 	}
 }
+
+func newFragmentDTO(source *scenario.Fragment) *FragmentDTO {
+	if source == nil {
+		return nil
+	}
+
+	return &FragmentDTO{
+		ID:        source.ID,
+		ProjectID: source.ProjectID,
+		Name:      source.Name,
+		FlowData:  source.FlowData,
+		CreatedAt: formatBindingTime(source.CreatedAt),
+		UpdatedAt: formatBindingTime(source.UpdatedAt),
+	}
+}
+
+func newSuiteDTO(source *scenario.Suite) *SuiteDTO {
+	if source == nil {
+		return nil
+	}
+
+	entries := make([]SuiteEntryDTO, 0, len(source.Entries))
+	for _, entry := range source.Entries {
+		entries = append(entries, SuiteEntryDTO{ScenarioID: entry.ScenarioID, Variables: entry.Variables})
+	}
+
+	return &SuiteDTO{
+		ID:              source.ID,
+		ProjectID:       source.ProjectID,
+		Name:            source.Name,
+		SetupScenarioID: source.SetupScenarioID,
+		Variables:       source.Variables,
+		StopOnFailure:   source.StopOnFailure,
+		Retries:         source.Retries,
+		TimeoutSeconds:  source.TimeoutSeconds,
+		Entries:         entries,
+		CreatedAt:       formatBindingTime(source.CreatedAt),
+		UpdatedAt:       formatBindingTime(source.UpdatedAt),
+	}
+}
+
+func (d *SuiteDTO) toSuite() *scenario.Suite {
+	entries := make([]scenario.SuiteEntry, 0, len(d.Entries))
+	for _, entry := range d.Entries {
+		entries = append(entries, scenario.SuiteEntry{ScenarioID: entry.ScenarioID, Variables: entry.Variables})
+	}
+
+	return &scenario.Suite{
+		ID:              d.ID,
+		ProjectID:       d.ProjectID,
+		Name:            d.Name,
+		SetupScenarioID: d.SetupScenarioID,
+		Variables:       d.Variables,
+		StopOnFailure:   d.StopOnFailure,
+		Retries:         d.Retries,
+		TimeoutSeconds:  d.TimeoutSeconds,
+		Entries:         entries,
+	}
+}
+
+func newSuiteListItemDTO(source scenario.SuiteListItem) SuiteListItemDTO {
+	return SuiteListItemDTO{
+		ID:         source.ID,
+		ProjectID:  source.ProjectID,
+		Name:       source.Name,
+		EntryCount: source.EntryCount,
+		CreatedAt:  formatBindingTime(source.CreatedAt),
+		UpdatedAt:  formatBindingTime(source.UpdatedAt),
+	}
+}
+
+func newImportResultDTO(source *scenario.ImportResult) *ImportResultDTO {
+	if source == nil {
+		return nil
+	}
+
+	return &ImportResultDTO{
+		Imported:   source.Imported,
+		Skipped:    source.Skipped,
+		Renamed:    source.Renamed,
+		Fragments:  source.Fragments,
+		MediaDir:   source.MediaDir,
+		MediaFiles: source.MediaFiles,
+	}
+}
+
+func newRevisionDTO(source *scenario.Revision) *RevisionDTO {
+	if source == nil {
+		return nil
+	}
+
+	return &RevisionDTO{
+		ID:         source.ID,
+		ScenarioID: source.ScenarioID,
+		Revision:   source.Revision,
+		Message:    source.Message,
+		FlowData:   source.FlowData,
+		CreatedAt:  formatBindingTime(source.CreatedAt),
+	}
+}
+
+func newRevisionListItemDTO(source scenario.RevisionListItem) RevisionListItemDTO {
+	return RevisionListItemDTO{
+		ID:         source.ID,
+		ScenarioID: source.ScenarioID,
+		Revision:   source.Revision,
+		Message:    source.Message,
+		CreatedAt:  formatBindingTime(source.CreatedAt),
+	}
+}
+
+func newFlowDiffDTO(source *scenario.FlowDiff) *FlowDiffDTO {
+	if source == nil {
+		return nil
+	}
+
+	convert := func(elements []scenario.ElementDiff) []ElementDiffDTO {
+		out := make([]ElementDiffDTO, 0, len(elements))
+		for _, el := range elements {
+			fields := make([]FieldChangeDTO, 0, len(el.Fields))
+			for _, f := range el.Fields {
+				fields = append(fields, FieldChangeDTO{Path: f.Path, Before: f.Before, After: f.After})
+			}
+			out = append(out, ElementDiffDTO{ID: el.ID, Change: el.Change, Fields: fields})
+		}
+		return out
+	}
+
+	return &FlowDiffDTO{
+		Nodes: convert(source.Nodes),
+		Edges: convert(source.Edges),
+	}
+}
@@ -0,0 +1,214 @@
+package binding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"sipflow/internal/scenario"
+	"sipflow/internal/scheduler"
+)
+
+// EventScheduleFailed is emitted to the frontend when a scheduled run fails
+const EventScheduleFailed = "schedule:failed"
+
+// ScheduleBinding provides frontend bindings for scheduled scenario runs
+type ScheduleBinding struct {
+	ctx       context.Context
+	repo      *scenario.Repository
+	scheduler *scheduler.Scheduler
+}
+
+// NewScheduleBinding creates a new ScheduleBinding and forwards scheduled run failures to the frontend
+func NewScheduleBinding(repo *scenario.Repository, sched *scheduler.Scheduler) *ScheduleBinding {
+	b := &ScheduleBinding{
+		repo:      repo,
+		scheduler: sched,
+	}
+	sched.AddNotifier(scheduler.NotifierFunc(b.emitFailure))
+	sched.SetErrorHandler(b.logSchedulerError)
+	return b
+}
+
+// SetContext sets the Wails runtime context
+func (b *ScheduleBinding) SetContext(ctx context.Context) {
+	b.ctx = ctx
+}
+
+func (b *ScheduleBinding) emitFailure(_ context.Context, failure scheduler.Failure) error {
+	if b.ctx == nil {
+		return nil
+	}
+	runtime.LogError(b.ctx, fmt.Sprintf("Scheduled run %s failed: %s", failure.Schedule.Name, failure.Run.Error))
+	runtime.EventsEmit(b.ctx, EventScheduleFailed, map[string]interface{}{
+		"scheduleId": failure.Schedule.ID,
+		"name":       failure.Schedule.Name,
+		"run":        newScheduleRunDTO(failure.Run),
+	})
+	return nil
+}
+
+func (b *ScheduleBinding) logSchedulerError(err error) {
+	if b.ctx == nil {
+		return
+	}
+	runtime.LogError(b.ctx, fmt.Sprintf("Scheduler error: %v", err))
+}
+
+// ListSchedules returns the schedules of a project; an empty project ID lists every schedule
+func (b *ScheduleBinding) ListSchedules(projectID string) ([]ScheduleDTO, error) {
+	schedules, err := b.repo.ListSchedules(projectID)
+	if err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to list schedules: %v", err))
+		return nil, err
+	}
+
+	result := make([]ScheduleDTO, 0, len(schedules))
+	for i := range schedules {
+		result = append(result, b.newScheduleDTO(&schedules[i]))
+	}
+	return result, nil
+}
+
+// CreateSchedule validates the cron expression and stores a new schedule
+func (b *ScheduleBinding) CreateSchedule(input ScheduleInputDTO) (*ScheduleDTO, error) {
+	sched := &scenario.Schedule{ProjectID: input.ProjectID}
+	if sched.ProjectID == "" {
+		sched.ProjectID = scenario.DefaultProjectID
+	}
+	input.apply(sched)
+	if err := b.validateSchedule(sched); err != nil {
+		return nil, err
+	}
+
+	if err := b.repo.CreateSchedule(sched); err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to create schedule: %v", err))
+		return nil, err
+	}
+
+	runtime.LogInfo(b.ctx, fmt.Sprintf("Schedule created: %s (%s)", sched.Name, sched.Cron))
+	dto := b.newScheduleDTO(sched)
+	return &dto, nil
+}
+
+// UpdateSchedule replaces the editable fields of a schedule
+func (b *ScheduleBinding) UpdateSchedule(id string, input ScheduleInputDTO) (*ScheduleDTO, error) {
+	sched, err := b.repo.LoadSchedule(id)
+	if err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to load schedule: %v", err))
+		return nil, err
+	}
+	input.apply(sched)
+	if err := b.validateSchedule(sched); err != nil {
+		return nil, err
+	}
+
+	if err := b.repo.UpdateSchedule(sched); err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to update schedule: %v", err))
+		return nil, err
+	}
+
+	dto := b.newScheduleDTO(sched)
+	return &dto, nil
+}
+
+// SetScheduleEnabled pauses or resumes a schedule
+func (b *ScheduleBinding) SetScheduleEnabled(id string, enabled bool) error {
+	sched, err := b.repo.LoadSchedule(id)
+	if err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to load schedule: %v", err))
+		return err
+	}
+
+	sched.Enabled = enabled
+	if err := b.repo.UpdateSchedule(sched); err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to update schedule: %v", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteSchedule removes a schedule and its run history
+func (b *ScheduleBinding) DeleteSchedule(id string) error {
+	if err := b.repo.DeleteSchedule(id); err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to delete schedule: %v", err))
+		return err
+	}
+	return nil
+}
+
+// RunScheduleNow triggers a schedule immediately in the background
+func (b *ScheduleBinding) RunScheduleNow(id string) error {
+	runtime.LogInfo(b.ctx, fmt.Sprintf("Running schedule now: %s", id))
+	if err := b.scheduler.RunNow(id); err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to run schedule: %v", err))
+		return err
+	}
+	return nil
+}
+
+// ListScheduleRuns returns a schedule's run history, newest first
+func (b *ScheduleBinding) ListScheduleRuns(id string, limit int) ([]ScheduleRunDTO, error) {
+	runs, err := b.repo.ListScheduleRuns(id, limit)
+	if err != nil {
+		runtime.LogError(b.ctx, fmt.Sprintf("Failed to list schedule runs: %v", err))
+		return nil, err
+	}
+
+	result := make([]ScheduleRunDTO, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, newScheduleRunDTO(run))
+	}
+	return result, nil
+}
+
+func (b *ScheduleBinding) validateSchedule(sched *scenario.Schedule) error {
+	if sched.Name == "" {
+		return errors.New("schedule name is required")
+	}
+	if sched.TargetID == "" {
+		return errors.New("schedule target is required")
+	}
+	var targetProjectID string
+	switch sched.TargetKind {
+	case scenario.ScheduleTargetScenario:
+		scn, err := b.repo.LoadScenario(sched.TargetID)
+		if err != nil {
+			return fmt.Errorf("failed to load schedule target scenario %s: %w", sched.TargetID, err)
+		}
+		targetProjectID = scn.ProjectID
+	case scenario.ScheduleTargetSuite:
+		suite, err := b.repo.LoadSuite(sched.TargetID)
+		if err != nil {
+			return fmt.Errorf("failed to load schedule target suite %s: %w", sched.TargetID, err)
+		}
+		targetProjectID = suite.ProjectID
+	default:
+		return fmt.Errorf("unknown schedule target kind %q", sched.TargetKind)
+	}
+	if targetProjectID != sched.ProjectID {
+		return fmt.Errorf("schedule target %s belongs to another project", sched.TargetID)
+	}
+	if _, err := scheduler.ParseCron(sched.Cron); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *ScheduleBinding) newScheduleDTO(source *scenario.Schedule) ScheduleDTO {
+	return ScheduleDTO{
+		ID:         source.ID,
+		ProjectID:  source.ProjectID,
+		Name:       source.Name,
+		Cron:       source.Cron,
+		TargetKind: source.TargetKind,
+		TargetID:   source.TargetID,
+		Enabled:    source.Enabled,
+		NotifyURL:  source.NotifyURL,
+		NextRunAt:  formatBindingTime(b.scheduler.NextRun(source)),
+		CreatedAt:  formatBindingTime(source.CreatedAt),
+		UpdatedAt:  formatBindingTime(source.UpdatedAt),
+	}
+}
@@ -0,0 +1,65 @@
+package binding
+
+import (
+	"sipflow/internal/scenario"
+)
+
+// ScheduleDTO is a stored schedule; next_run_at is empty when the schedule is disabled
+type ScheduleDTO struct {
+	ID         string `json:"id"`
+	ProjectID  string `json:"project_id"`
+	Name       string `json:"name"`
+	Cron       string `json:"cron"`
+	TargetKind string `json:"target_kind"`
+	TargetID   string `json:"target_id"`
+	Enabled    bool   `json:"enabled"`
+	NotifyURL  string `json:"notify_url"`
+	NextRunAt  string `json:"next_run_at"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// ScheduleInputDTO carries the editable fields of a schedule
+type ScheduleInputDTO struct {
+	ProjectID  string `json:"project_id"`
+	Name       string `json:"name"`
+	Cron       string `json:"cron"`
+	TargetKind string `json:"target_kind"`
+	TargetID   string `json:"target_id"`
+	Enabled    bool   `json:"enabled"`
+	NotifyURL  string `json:"notify_url"`
+}
+
+type ScheduleRunDTO struct {
+	ID         int64  `json:"id"`
+	ScheduleID string `json:"schedule_id"`
+	RunID      string `json:"run_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+func (in ScheduleInputDTO) apply(target *scenario.Schedule) {
+	target.Name = in.Name
+	target.Cron = in.Cron
+	target.TargetKind = in.TargetKind
+	if target.TargetKind == "" {
+		target.TargetKind = scenario.ScheduleTargetScenario
+	}
+	target.TargetID = in.TargetID
+	target.Enabled = in.Enabled
+	target.NotifyURL = in.NotifyURL
+}
+
+func newScheduleRunDTO(source scenario.ScheduleRun) ScheduleRunDTO {
+	return ScheduleRunDTO{
+		ID:         source.ID,
+		ScheduleID: source.ScheduleID,
+		RunID:      source.RunID,
+		Status:     source.Status,
+		Error:      source.Error,
+		StartedAt:  formatBindingTime(source.StartedAt),
+		FinishedAt: formatBindingTime(source.FinishedAt),
+	}
+}
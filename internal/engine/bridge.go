@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/emiago/diago"
+)
+
+// bridgeLeg는 브리지에 연결된 dialog 하나
+type bridgeLeg struct {
+	instanceID string
+	callID     string
+	dialog     diago.DialogSession
+}
+
+func (l bridgeLeg) String() string {
+	return l.instanceID + "/" + l.callID
+}
+
+// mediaBridge는 두 dialog의 RTP payload를 양방향으로 중계한다 (B2BUA).
+// diago.Bridge는 한 번 연결하면 dialog가 끝날 때까지 분리할 수 없으므로, Unbridge를 위해
+// 각 dialog의 AudioReader/AudioWriter를 직접 이어 붙인다. 트랜스코딩은 하지 않는다.
+type mediaBridge struct {
+	legs [2]bridgeLeg
+	stop chan struct{}
+	once sync.Once
+}
+
+// startMediaBridge는 두 dialog의 미디어를 연결한다. 두 dialog의 협상 코덱이 같아야 한다.
+func startMediaBridge(a, b bridgeLeg) (*mediaBridge, error) {
+	if codecA, codecB := negotiatedCodec(a.dialog), negotiatedCodec(b.dialog); codecA != "" && codecB != "" && codecA != codecB {
+		return nil, fmt.Errorf("cannot bridge %s (%s) with %s (%s): transcoding is not supported", a, codecA, b, codecB)
+	}
+
+	readerA, err := a.dialog.Media().AudioReader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio reader for %s: %w", a, err)
+	}
+	writerA, err := a.dialog.Media().AudioWriter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio writer for %s: %w", a, err)
+	}
+	readerB, err := b.dialog.Media().AudioReader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio reader for %s: %w", b, err)
+	}
+	writerB, err := b.dialog.Media().AudioWriter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio writer for %s: %w", b, err)
+	}
+
+	br := &mediaBridge{legs: [2]bridgeLeg{a, b}, stop: make(chan struct{})}
+	go br.pump(readerA, writerB)
+	go br.pump(readerB, writerA)
+	return br, nil
+}
+
+// negotiatedCodec은 dialog의 협상된 첫 번째 코덱 이름을 반환한다 (알 수 없으면 빈 문자열)
+func negotiatedCodec(dialog diago.DialogSession) string {
+	m := dialog.Media()
+	if m == nil {
+		return ""
+	}
+	session := m.MediaSession()
+	if session == nil || len(session.Codecs) == 0 {
+		return ""
+	}
+	return session.Codecs[0].Name
+}
+
+// pump는 r에서 읽은 패킷을 w로 쓴다. 읽기가 블로킹되므로 정지 요청은 다음 패킷을 받은 뒤 반영된다.
+func (b *mediaBridge) pump(r io.Reader, w io.Writer) {
+	buf := make([]byte, 1500)
+	for {
+		n, err := r.Read(buf)
+		if err != nil || b.stopped() {
+			return
+		}
+		if _, err := w.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+func (b *mediaBridge) close() {
+	b.once.Do(func() { close(b.stop) })
+}
+
+func (b *mediaBridge) stopped() bool {
+	select {
+	case <-b.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *mediaBridge) String() string {
+	return b.legs[0].String() + " <-> " + b.legs[1].String()
+}
+
+// addBridge는 두 leg를 브리지 목록에 등록하고, 어느 한쪽 dialog가 끝나면 브리지를 해제한다
+func (ex *Executor) addBridge(br *mediaBridge) {
+	ex.bridgeMu.Lock()
+	for _, leg := range br.legs {
+		ex.bridges[sessionKey(leg.instanceID, leg.callID)] = br
+	}
+	ex.bridgeMu.Unlock()
+
+	go func() {
+		select {
+		case <-br.legs[0].dialog.Context().Done():
+		case <-br.legs[1].dialog.Context().Done():
+		case <-br.stop:
+		}
+		ex.removeBridge(br)
+	}()
+}
+
+// removeBridge는 브리지를 정지하고 목록에서 제거한다
+func (ex *Executor) removeBridge(br *mediaBridge) {
+	br.close()
+
+	ex.bridgeMu.Lock()
+	defer ex.bridgeMu.Unlock()
+	for _, leg := range br.legs {
+		key := sessionKey(leg.instanceID, leg.callID)
+		if ex.bridges[key] == br {
+			delete(ex.bridges, key)
+		}
+	}
+}
+
+func (ex *Executor) bridgeFor(instanceID, callID string) (*mediaBridge, bool) {
+	ex.bridgeMu.Lock()
+	defer ex.bridgeMu.Unlock()
+	br, exists := ex.bridges[sessionKey(instanceID, callID)]
+	return br, exists
+}
+
+// closeBridges는 run 정리 시 남은 브리지를 모두 정지한다
+func (ex *Executor) closeBridges() {
+	ex.bridgeMu.Lock()
+	bridges := make([]*mediaBridge, 0, len(ex.bridges))
+	for _, br := range ex.bridges {
+		bridges = append(bridges, br)
+	}
+	ex.bridgeMu.Unlock()
+
+	for _, br := range bridges {
+		ex.removeBridge(br)
+	}
+}
+
+// bridgePeer는 Bridge 노드의 상대 leg 위치를 반환한다 (peerInstanceId가 비면 같은 인스턴스)
+func bridgePeer(instanceID string, node *GraphNode) (string, string) {
+	peerInstanceID := node.PeerInstanceID
+	if peerInstanceID == "" {
+		peerInstanceID = instanceID
+	}
+	return peerInstanceID, node.PeerCallID
+}
+
+// executeBridge는 노드의 callID dialog와 peer dialog의 미디어를 연결한다
+func (ex *Executor) executeBridge(ctx context.Context, instanceID string, node *GraphNode) error {
+	callID := callIDOrDefault(node)
+	peerInstanceID, peerCallID := bridgePeer(instanceID, node)
+	if peerCallID == "" {
+		return fmt.Errorf("Bridge requires peerCallId")
+	}
+	if peerInstanceID == instanceID && peerCallID == callID {
+		return fmt.Errorf("Bridge cannot connect call %s to itself", callID)
+	}
+
+	a := bridgeLeg{instanceID: instanceID, callID: callID}
+	b := bridgeLeg{instanceID: peerInstanceID, callID: peerCallID}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Bridge %s <-> %s", a, b), "info")
+
+	for _, leg := range []*bridgeLeg{&a, &b} {
+		dialog, exists := ex.sessions.GetDialog(leg.instanceID, leg.callID)
+		if !exists {
+			return fmt.Errorf("Bridge: no active dialog for %s", leg)
+		}
+		if err := dialog.Context().Err(); err != nil {
+			return fmt.Errorf("Bridge: dialog %s already terminated", leg)
+		}
+		if existing, bridged := ex.bridgeFor(leg.instanceID, leg.callID); bridged {
+			return fmt.Errorf("Bridge: %s is already bridged (%s)", leg, existing)
+		}
+		if ex.inConference(leg.instanceID, leg.callID) {
+			return fmt.Errorf("Bridge: %s is in a conference, remove it first", leg)
+		}
+		leg.dialog = dialog
+	}
+
+	br, err := startMediaBridge(a, b)
+	if err != nil {
+		return fmt.Errorf("Bridge failed: %w", err)
+	}
+	ex.addBridge(br)
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Bridged %s", br), "info")
+	return nil
+}
+
+// executeUnbridge는 노드의 callID가 속한 브리지를 해제한다. 두 dialog는 유지된다.
+func (ex *Executor) executeUnbridge(ctx context.Context, instanceID string, node *GraphNode) error {
+	callID := callIDOrDefault(node)
+	br, bridged := ex.bridgeFor(instanceID, callID)
+	if !bridged {
+		return fmt.Errorf("Unbridge: %s/%s is not bridged", instanceID, callID)
+	}
+
+	ex.removeBridge(br)
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unbridged %s", br), "info")
+	return nil
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func attendantDryRunFlow() ([]FlowNode, []FlowEdge) {
+	nodes := []FlowNode{
+		{ID: "agent", Type: "sipInstance", Data: map[string]interface{}{"label": "Agent", "dn": "100"}},
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "200"}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "agent", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Answer"}},
+		{ID: "consult", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "MakeCall", "targetUri": "300", "callId": "call-2"}},
+		{ID: "bridge", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Bridge", "peerCallId": "call-2"}},
+		{ID: "unbridge", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Unbridge", "callId": "call-2"}},
+		{ID: "release-2", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Release", "callId": "call-2"}},
+		{ID: "release-1", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Release"}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "100"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "DISCONNECTED"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "agent", Target: "incoming"},
+		{ID: "e2", Source: "incoming", Target: "answer"},
+		{ID: "e3", Source: "answer", Target: "consult"},
+		{ID: "e4", Source: "consult", Target: "bridge"},
+		{ID: "e5", Source: "bridge", Target: "unbridge"},
+		{ID: "e6", Source: "unbridge", Target: "release-2"},
+		{ID: "e7", Source: "release-2", Target: "release-1"},
+		{ID: "e8", Source: "caller", Target: "call"},
+		{ID: "e9", Source: "call", Target: "disconnected"},
+	}
+	return nodes, edges
+}
+
+func TestDryRun_BridgeAndUnbridge(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := attendantDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+	for _, id := range []string{"bridge", "unbridge"} {
+		if !waitForNodeState(t, te, id, NodeStateCompleted, time.Second) {
+			t.Errorf("expected node %s to complete", id)
+		}
+	}
+}
+
+func TestDryRun_UnbridgeWithoutBridgeFails(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := attendantDryRunFlow()
+	// bridge 노드를 건너뛴다
+	for i := range edges {
+		if edges[i].Source == "consult" {
+			edges[i].Target = "unbridge"
+		}
+	}
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForNodeState(t, te, "unbridge", NodeStateFailed, 5*time.Second) {
+		t.Fatal("expected Unbridge without a bridge to fail")
+	}
+}
+
+func TestParseScenario_BridgeRequiresPeerCallID(t *testing.T) {
+	nodes, edges := attendantDryRunFlow()
+	for _, node := range nodes {
+		if node.ID == "bridge" {
+			delete(node.Data, "peerCallId")
+		}
+	}
+	if _, err := ParseScenario(buildTestFlowData(t, nodes, edges)); err == nil || !strings.Contains(err.Error(), "Bridge requires peerCallId") {
+		t.Fatalf("expected missing peerCallId error, got %v", err)
+	}
+}
+
+// packetReader는 Read마다 패킷 하나를 돌려주고, 소진되면 unblock될 때까지 대기한다
+type packetReader struct {
+	packets chan []byte
+}
+
+func (r *packetReader) Read(b []byte) (int, error) {
+	p, ok := <-r.packets
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(b, p), nil
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *lockedBuffer) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(b)
+}
+
+func (w *lockedBuffer) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestMediaBridge_PumpStopsAfterClose(t *testing.T) {
+	br := &mediaBridge{stop: make(chan struct{})}
+	r := &packetReader{packets: make(chan []byte, 4)}
+	w := &lockedBuffer{}
+
+	done := make(chan struct{})
+	go func() {
+		br.pump(r, w)
+		close(done)
+	}()
+
+	r.packets <- []byte("aa")
+	r.packets <- []byte("bb")
+	deadline := time.Now().Add(time.Second)
+	for w.String() != "aabb" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := w.String(); got != "aabb" {
+		t.Fatalf("expected packets relayed while bridged, got %q", got)
+	}
+
+	br.close()
+	br.close() // 중복 해제는 무시된다
+	r.packets <- []byte("cc")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pump did not stop after close")
+	}
+	if got := w.String(); got != "aabb" {
+		t.Errorf("expected no packets relayed after unbridge, got %q", got)
+	}
+}
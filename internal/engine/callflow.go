@@ -0,0 +1,348 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// DialogState는 정적 분석에서 추적하는 인스턴스/callID별 dialog 상태
+type DialogState uint8
+
+const (
+	DialogNone       DialogState = 1 << iota // 아직 생성되지 않음
+	DialogRinging                            // INCOMING 수신, 아직 Answer 전
+	DialogConfirmed                          // 통화 연결됨
+	DialogHeld                               // Hold 중
+	DialogTerminated                         // 종료됨
+)
+
+var dialogStateNames = []struct {
+	state DialogState
+	name  string
+}{
+	{DialogNone, "none"},
+	{DialogRinging, "ringing"},
+	{DialogConfirmed, "confirmed"},
+	{DialogHeld, "held"},
+	{DialogTerminated, "terminated"},
+}
+
+// dialogStateSet은 여러 경로에서 합류한 가능한 상태 집합 (비트마스크)
+type dialogStateSet uint8
+
+func (s dialogStateSet) String() string {
+	names := []string{}
+	for _, entry := range dialogStateNames {
+		if s&dialogStateSet(entry.state) != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+func states(list ...DialogState) dialogStateSet {
+	var set dialogStateSet
+	for _, s := range list {
+		set |= dialogStateSet(s)
+	}
+	return set
+}
+
+var (
+	dialogIdle   = states(DialogNone, DialogTerminated)
+	dialogActive = states(DialogConfirmed, DialogHeld)
+	dialogLive   = states(DialogRinging, DialogConfirmed, DialogHeld)
+)
+
+// callOp는 노드 하나가 특정 callID에 요구하는 상태와 성공 시 전이 상태
+type callOp struct {
+	callID   string
+	requires dialogStateSet
+	to       DialogState
+	creates  bool // MakeCall/INCOMING처럼 dialog를 생성하는 동작
+	lenient  bool // 요구 상태가 아니어도 실행기가 경고 후 성공 처리하는 동작 (Release)
+}
+
+// callOpsFor는 노드가 수행하는 dialog 동작 목록을 반환한다. dialog와 무관한 노드는 nil.
+func callOpsFor(node *flowNode) []callOp {
+	switch node.name {
+	case string(SIPCommandMakeCall):
+		return []callOp{{callID: node.callID, requires: dialogIdle, to: DialogConfirmed, creates: true}}
+	case string(eventhandler.SIPEventIncoming):
+		return []callOp{{callID: node.callID, requires: dialogIdle, to: DialogRinging, creates: true}}
+	case string(SIPCommandAnswer):
+		return []callOp{{callID: node.callID, requires: states(DialogRinging), to: DialogConfirmed}}
+	case string(SIPCommandRelease):
+		return []callOp{{callID: node.callID, requires: dialogLive, to: DialogTerminated, lenient: true}}
+	case string(eventhandler.SIPEventDisconnected):
+		return []callOp{{callID: node.callID, requires: dialogLive, to: DialogTerminated}}
+	case string(SIPCommandHold):
+		return []callOp{{callID: node.callID, requires: states(DialogConfirmed), to: DialogHeld}}
+	case string(SIPCommandRetrieve):
+		return []callOp{{callID: node.callID, requires: states(DialogHeld), to: DialogConfirmed}}
+	case string(eventhandler.SIPEventHeld):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: DialogHeld}}
+	case string(eventhandler.SIPEventRetrieved), string(eventhandler.SIPEventTransferred), string(eventhandler.SIPEventReplaced):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: DialogConfirmed}}
+	case string(eventhandler.SIPEventReferReceived), string(eventhandler.SIPEventReInvite), string(eventhandler.SIPEventUpdate):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: 0}}
+	case string(SIPCommandReInvite), string(SIPCommandUpdate):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: 0}}
+	case string(SIPCommandPlayAudio), string(SIPCommandSendDTMF), string(eventhandler.SIPEventDTMFReceived):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: 0}}
+	case string(SIPCommandBlindTransfer), string(SIPCommandPark):
+		return []callOp{{callID: node.callID, requires: dialogActive, to: DialogTerminated}}
+	case string(SIPCommandUnpark), string(SIPCommandPickup):
+		return []callOp{{callID: node.callID, requires: dialogIdle, to: DialogConfirmed, creates: true}}
+	case string(SIPCommandMuteTransfer):
+		primary := getStringField(node.data, "primaryCallId", "")
+		if primary == "" {
+			primary = node.callID
+		}
+		ops := []callOp{{callID: primary, requires: dialogActive, to: DialogTerminated}}
+		if consult := getStringField(node.data, "consultCallId", ""); consult != "" {
+			ops = append(ops, callOp{callID: consult, requires: dialogActive, to: DialogTerminated})
+		}
+		return ops
+	case string(SIPCommandBridge):
+		ops := []callOp{{callID: node.callID, requires: dialogActive, to: 0}}
+		// 다른 인스턴스의 dialog는 그 인스턴스 체인에서 추적하므로 같은 인스턴스의 peer만 검사한다
+		peerInstance := getStringField(node.data, "peerInstanceId", "")
+		if peer := getStringField(node.data, "peerCallId", ""); peer != "" && (peerInstance == "" || peerInstance == node.instanceID) {
+			ops = append(ops, callOp{callID: peer, requires: dialogActive, to: 0})
+		}
+		return ops
+	case string(SIPCommandConference):
+		// leave는 끊긴 참가자가 이미 빠졌을 수 있으므로 dialog 상태를 요구하지 않는다
+		if getStringField(node.data, "conferenceAction", ConferenceActionJoin) != ConferenceActionJoin {
+			return nil
+		}
+		ops := []callOp{}
+		for _, callID := range getStringArrayField(node.data, "callIds", []string{node.callID}) {
+			ops = append(ops, callOp{callID: callID, requires: dialogActive, to: 0})
+		}
+		return ops
+	}
+	return nil
+}
+
+// callFlowState는 노드 진입 시점의 callID별 가능한 상태 집합. 키가 없으면 DialogNone.
+type callFlowState map[string]dialogStateSet
+
+func (s callFlowState) get(callID string) dialogStateSet {
+	if set, ok := s[callID]; ok {
+		return set
+	}
+	return dialogStateSet(DialogNone)
+}
+
+func (s callFlowState) clone() callFlowState {
+	out := make(callFlowState, len(s))
+	for k, v := range s {
+		out[k] = v
+	}
+	return out
+}
+
+// mergeInto는 s를 target에 합집합으로 병합하고 변경 여부를 반환한다
+func (s callFlowState) mergeInto(target callFlowState) bool {
+	changed := false
+	keys := make(map[string]bool, len(s)+len(target))
+	for k := range s {
+		keys[k] = true
+	}
+	for k := range target {
+		keys[k] = true
+	}
+	for k := range keys {
+		merged := target.get(k) | s.get(k)
+		if merged != target.get(k) {
+			target[k] = merged
+			changed = true
+		}
+	}
+	return changed
+}
+
+// AnalyzeCallFlow는 ExecutionGraph의 모든 체인에서 인스턴스/callID별 dialog 상태를 경로마다 추적하여
+// 실행 전에 불가능하거나 일부 경로에서 실패할 호 제어 동작을 보고한다.
+func AnalyzeCallFlow(graph *ExecutionGraph) []Diagnostic {
+	nodes := make(map[string]*flowNode, len(graph.Nodes))
+	order := make([]string, 0, len(graph.Nodes))
+	for id, gnode := range graph.Nodes {
+		fn := &flowNode{
+			id:         id,
+			instanceID: gnode.InstanceID,
+			callID:     callIDOrDefault(gnode),
+			data:       gnode.Data,
+		}
+		if gnode.Type == "command" {
+			fn.name = gnode.Command
+		} else {
+			fn.name = gnode.Event
+		}
+		if gnode.SuccessNext != nil {
+			fn.success = []string{gnode.SuccessNext.ID}
+		}
+		if gnode.FailureNext != nil {
+			fn.failure = []string{gnode.FailureNext.ID}
+		}
+		nodes[id] = fn
+		order = append(order, id)
+	}
+	sort.Strings(order)
+
+	instanceIDs := make([]string, 0, len(graph.Instances))
+	for id := range graph.Instances {
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Strings(instanceIDs)
+
+	startChains := make([][]string, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		starts := make([]string, 0, len(graph.Instances[id].StartNodes))
+		for _, start := range graph.Instances[id].StartNodes {
+			starts = append(starts, start.ID)
+		}
+		startChains = append(startChains, starts)
+	}
+
+	return analyzeCallFlow(nodes, order, startChains)
+}
+
+// analyzeCallFlow는 시작 노드에서 고정점까지 상태를 전파한 뒤 각 노드의 요구 상태와 비교한다.
+// 성공 분기는 전이 후 상태를, 실패 분기는 전이 전 상태를 전달한다.
+// startChains는 인스턴스별 시작 노드 목록이다. 한 인스턴스의 시작 체인은 순서대로 실행되므로
+// 첫 체인은 모든 callID가 none인 상태로, 다음 체인은 앞 체인이 끝난 상태로 출발한다.
+// 모든 경로에서 불가능한 동작은 error, 일부 경로에서만 불가능한 동작은 warning으로 보고한다.
+func analyzeCallFlow(nodes map[string]*flowNode, order []string, startChains [][]string) []Diagnostic {
+	key := func(instanceID, callID string) string { return instanceID + "/" + callID }
+
+	// 인스턴스별로 한 번이라도 생성되는 callID (오타 판별용)
+	created := make(map[string]bool)
+	for _, id := range order {
+		node := nodes[id]
+		for _, op := range callOpsFor(node) {
+			if op.creates {
+				created[key(node.instanceID, op.callID)] = true
+			}
+		}
+	}
+
+	// runChain은 start부터 고정점까지 전파하여 노드별 진입 상태를 in에 합치고,
+	// 체인이 정상 종료하는 상태(성공 분기가 없는 노드의 전이 후 상태)를 반환한다.
+	// 실패 분기가 없는 실패는 run을 중단시키므로 다음 체인으로 이어지지 않는다.
+	in := make(map[string]callFlowState)
+	runChain := func(start string, entry callFlowState) callFlowState {
+		chainIn := map[string]callFlowState{start: entry.clone()}
+		worklist := []string{start}
+		var exit callFlowState
+
+		propagate := func(targets []string, state callFlowState) {
+			for _, target := range targets {
+				existing, seen := chainIn[target]
+				if !seen {
+					chainIn[target] = state.clone()
+					worklist = append(worklist, target)
+					continue
+				}
+				if state.mergeInto(existing) {
+					worklist = append(worklist, target)
+				}
+			}
+		}
+
+		for len(worklist) > 0 {
+			id := worklist[0]
+			worklist = worklist[1:]
+			node := nodes[id]
+			before := chainIn[id]
+
+			after := before.clone()
+			for _, op := range callOpsFor(node) {
+				if op.to != 0 {
+					after[key(node.instanceID, op.callID)] = dialogStateSet(op.to)
+				}
+			}
+
+			if len(node.success) == 0 {
+				if exit == nil {
+					exit = after.clone()
+				} else {
+					after.mergeInto(exit)
+				}
+			}
+			propagate(node.success, after)
+			propagate(node.failure, before)
+		}
+
+		for id, state := range chainIn {
+			if existing, seen := in[id]; seen {
+				state.mergeInto(existing)
+			} else {
+				in[id] = state
+			}
+		}
+		if exit == nil {
+			return callFlowState{}
+		}
+		return exit
+	}
+
+	for _, starts := range startChains {
+		entry := callFlowState{}
+		for _, start := range starts {
+			entry = runChain(start, entry)
+		}
+	}
+
+	diags := []Diagnostic{}
+	for _, id := range order {
+		before, reachable := in[id]
+		if !reachable {
+			continue
+		}
+		node := nodes[id]
+		for _, op := range callOpsFor(node) {
+			current := before.get(key(node.instanceID, op.callID))
+			if current&^op.requires == 0 {
+				continue
+			}
+
+			severity, verb := SeverityWarning, "may be"
+			if current&op.requires == 0 && !op.lenient {
+				severity, verb = SeverityError, "is"
+			}
+
+			switch {
+			case node.name == string(SIPCommandAnswer):
+				diags = append(diags, Diagnostic{
+					NodeID:   id,
+					Severity: severity,
+					Code:     DiagAnswerWithoutIncoming,
+					Message:  fmt.Sprintf("Answer on callId %s requires a preceding INCOMING, but the dialog %s %s", op.callID, verb, current&^op.requires),
+				})
+			case !op.creates && !created[key(node.instanceID, op.callID)]:
+				diags = append(diags, Diagnostic{
+					NodeID:   id,
+					Severity: SeverityError,
+					Code:     DiagUnknownCallID,
+					Message:  fmt.Sprintf("%s uses callId %s, which is never created by MakeCall or INCOMING on this instance", node.name, op.callID),
+				})
+			default:
+				diags = append(diags, Diagnostic{
+					NodeID:   id,
+					Severity: severity,
+					Code:     DiagInvalidCallState,
+					Message: fmt.Sprintf("%s on callId %s requires %s, but the dialog %s %s",
+						node.name, op.callID, op.requires, verb, current&^op.requires),
+				})
+			}
+		}
+	}
+
+	return diags
+}
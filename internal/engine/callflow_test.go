@@ -0,0 +1,195 @@
+package engine
+
+import "testing"
+
+func analyzeFlow(t *testing.T, flowJSON string) []Diagnostic {
+	t.Helper()
+	graph, err := ParseScenario(flowJSON)
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	return AnalyzeCallFlow(graph)
+}
+
+func findDiagnostic(diags []Diagnostic, nodeID string) (Diagnostic, bool) {
+	for _, d := range diags {
+		if d.NodeID == nodeID {
+			return d, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+func TestAnalyzeCallFlow_ValidLifecycle(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}},
+    {"id": "retrieve", "type": "command", "data": {"command": "Retrieve", "sipInstanceId": "inst-a"}},
+    {"id": "play", "type": "command", "data": {"command": "PlayAudio", "sipInstanceId": "inst-a", "filePath": "/tmp/a.wav"}},
+    {"id": "release", "type": "command", "data": {"command": "Release", "sipInstanceId": "inst-a"}},
+    {"id": "again", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:300@pbx"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "make", "target": "hold"},
+    {"id": "e3", "source": "hold", "target": "retrieve"},
+    {"id": "e4", "source": "retrieve", "target": "play"},
+    {"id": "e5", "source": "play", "target": "release"},
+    {"id": "e6", "source": "release", "target": "again"}
+  ]
+}`
+
+	if diags := analyzeFlow(t, flowJSON); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diags)
+	}
+}
+
+func TestAnalyzeCallFlow_ImpossibleOperations(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "inst-b", "type": "sipInstance", "data": {"dn": "200"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "typo", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a", "callId": "call-l"}},
+    {"id": "retrieve", "type": "command", "data": {"command": "Retrieve", "sipInstanceId": "inst-a"}},
+    {"id": "release", "type": "command", "data": {"command": "Release", "sipInstanceId": "inst-a"}},
+    {"id": "dtmf", "type": "command", "data": {"command": "SendDTMF", "sipInstanceId": "inst-a", "digits": "1"}},
+    {"id": "answer", "type": "command", "data": {"command": "Answer", "sipInstanceId": "inst-b"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "make", "target": "typo"},
+    {"id": "e3", "source": "typo", "target": "retrieve"},
+    {"id": "e4", "source": "retrieve", "target": "release"},
+    {"id": "e5", "source": "release", "target": "dtmf"},
+    {"id": "e6", "source": "inst-b", "target": "answer"}
+  ]
+}`
+
+	diags := analyzeFlow(t, flowJSON)
+
+	tests := []struct {
+		nodeID   string
+		code     string
+		severity DiagnosticSeverity
+	}{
+		{"typo", DiagUnknownCallID, SeverityError},
+		{"retrieve", DiagInvalidCallState, SeverityError},
+		{"dtmf", DiagInvalidCallState, SeverityError},
+		{"answer", DiagAnswerWithoutIncoming, SeverityError},
+	}
+	for _, tt := range tests {
+		d, ok := findDiagnostic(diags, tt.nodeID)
+		if !ok {
+			t.Errorf("expected diagnostic on %s, got %+v", tt.nodeID, diags)
+			continue
+		}
+		if d.Code != tt.code || d.Severity != tt.severity {
+			t.Errorf("%s: expected %s/%s, got %s/%s (%s)", tt.nodeID, tt.severity, tt.code, d.Severity, d.Code, d.Message)
+		}
+	}
+
+	if _, ok := findDiagnostic(diags, "release"); ok {
+		t.Errorf("expected release of a confirmed call to be valid, got %+v", diags)
+	}
+}
+
+func TestAnalyzeCallFlow_FailureBranchKeepsPreviousState(t *testing.T) {
+	// MakeCall 실패 분기에서는 dialog가 생성되지 않았으므로 Hold는 불가능, Release는 경고만
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "cleanup", "type": "command", "data": {"command": "Release", "sipInstanceId": "inst-a"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "make", "target": "hold"},
+    {"id": "e3", "source": "make", "target": "cleanup", "sourceHandle": "failure"}
+  ]
+}`
+
+	diags := analyzeFlow(t, flowJSON)
+
+	if _, ok := findDiagnostic(diags, "hold"); ok {
+		t.Errorf("expected Hold on success branch to be valid, got %+v", diags)
+	}
+	d, ok := findDiagnostic(diags, "cleanup")
+	if !ok || d.Severity != SeverityWarning || d.Code != DiagInvalidCallState {
+		t.Errorf("expected warning on cleanup Release, got %+v", diags)
+	}
+}
+
+func TestAnalyzeCallFlow_MergedPathsWarn(t *testing.T) {
+	// 한쪽 경로에서만 Hold된 상태로 합류하면 Retrieve는 일부 경로에서만 가능하다
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}},
+    {"id": "retrieve", "type": "command", "data": {"command": "Retrieve", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "make", "target": "hold"},
+    {"id": "e3", "source": "hold", "target": "retrieve"},
+    {"id": "e4", "source": "hold", "target": "retrieve", "sourceHandle": "failure"}
+  ]
+}`
+
+	diags := analyzeFlow(t, flowJSON)
+	d, ok := findDiagnostic(diags, "retrieve")
+	if !ok || d.Severity != SeverityWarning || d.Code != DiagInvalidCallState {
+		t.Fatalf("expected warning on retrieve, got %+v", diags)
+	}
+}
+
+func TestDialogStateSetString(t *testing.T) {
+	if got := states(DialogNone, DialogHeld).String(); got != "none|held" {
+		t.Errorf("expected none|held, got %s", got)
+	}
+}
+
+func TestAnalyzeCallFlow_LaterStartChainContinuesFromEarlierChain(t *testing.T) {
+	// inst-a의 시작 체인은 순서대로 실행되므로 두 번째 체인은 첫 체인이 만든 호를 이어받는다
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "inst-a", "target": "hold"}
+  ]
+}`
+
+	if diags := analyzeFlow(t, flowJSON); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diags)
+	}
+	for _, d := range ValidateScenario(flowJSON) {
+		if d.NodeID == "hold" {
+			t.Fatalf("expected no diagnostic on hold from ValidateScenario, got %+v", d)
+		}
+	}
+
+	// 순서를 바꾸면 첫 체인은 여전히 dialog 없이 시작한다
+	reversed := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "hold"},
+    {"id": "e2", "source": "inst-a", "target": "make"}
+  ]
+}`
+	d, ok := findDiagnostic(analyzeFlow(t, reversed), "hold")
+	if !ok || d.Severity != SeverityError || d.Code != DiagInvalidCallState {
+		t.Fatalf("expected invalid_call_state error on hold, got %+v (found=%v)", d, ok)
+	}
+}
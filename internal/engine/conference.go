@@ -0,0 +1,334 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/emiago/diago"
+)
+
+// Conference 노드 동작 (conferenceAction)
+const (
+	ConferenceActionJoin  = "join"
+	ConferenceActionLeave = "leave"
+)
+
+const (
+	confFrameSamples  = 160                   // 8kHz 20ms
+	confFrameInterval = 20 * time.Millisecond // 믹서 tick 간격
+	confMaxPending    = confFrameSamples * 5  // 100ms 이상 밀린 샘플은 버려 지연이 쌓이지 않게 한다
+)
+
+// confParty는 컨퍼런스에 참여한 dialog 하나. 수신 payload를 PCM으로 풀어 다음 믹싱 tick까지 보관한다.
+type confParty struct {
+	callID string
+	decode func(byte) int16
+	encode func(int16) byte
+	writer io.Writer
+	stop   chan struct{}
+	once   sync.Once
+
+	mu      sync.Mutex
+	pending []int16
+}
+
+func newConfParty(callID, codec string, writer io.Writer) (*confParty, error) {
+	decode, encode, ok := g711Codec(codec)
+	if !ok {
+		return nil, fmt.Errorf("codec %q cannot be mixed (PCMU/PCMA only)", codec)
+	}
+	return &confParty{callID: callID, decode: decode, encode: encode, writer: writer, stop: make(chan struct{})}, nil
+}
+
+// receive는 r에서 읽은 payload를 디코딩해 쌓는다. 읽기가 블로킹되므로 정지 요청은 다음 패킷을 받은 뒤 반영된다.
+func (p *confParty) receive(r io.Reader) {
+	buf := make([]byte, 1500)
+	for {
+		n, err := r.Read(buf)
+		if err != nil || p.stopped() {
+			return
+		}
+		p.mu.Lock()
+		for _, b := range buf[:n] {
+			p.pending = append(p.pending, p.decode(b))
+		}
+		if over := len(p.pending) - confMaxPending; over > 0 {
+			p.pending = p.pending[over:]
+		}
+		p.mu.Unlock()
+	}
+}
+
+// takeFrame은 보관된 샘플에서 한 프레임을 꺼낸다. 모자라면 무음으로 채운다.
+func (p *confParty) takeFrame(frame []int16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := copy(frame, p.pending)
+	for i := n; i < len(frame); i++ {
+		frame[i] = 0
+	}
+	p.pending = p.pending[n:]
+}
+
+func (p *confParty) close() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+func (p *confParty) stopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// conference는 한 인스턴스가 가진 N개 dialog의 오디오를 로컬에서 믹싱한다.
+// 각 참가자에게는 자신을 뺀 나머지 참가자의 합을 보낸다 (mix-minus). 코덱은 G.711만 지원한다.
+type conference struct {
+	mu      sync.Mutex
+	parties map[string]*confParty // callID -> 참가자
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func newConference() *conference {
+	c := &conference{parties: make(map[string]*confParty), stop: make(chan struct{})}
+	go c.run()
+	return c
+}
+
+func (c *conference) run() {
+	ticker := time.NewTicker(confFrameInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.mix()
+		}
+	}
+}
+
+// mix는 참가자마다 한 프레임씩 꺼내 합산하고, 자신의 소리를 뺀 결과를 인코딩해 보낸다
+func (c *conference) mix() {
+	c.mu.Lock()
+	parties := make([]*confParty, 0, len(c.parties))
+	for _, p := range c.parties {
+		parties = append(parties, p)
+	}
+	c.mu.Unlock()
+	if len(parties) == 0 {
+		return
+	}
+
+	frames := make([][]int16, len(parties))
+	total := make([]int32, confFrameSamples)
+	for i, p := range parties {
+		frames[i] = make([]int16, confFrameSamples)
+		p.takeFrame(frames[i])
+		for j, s := range frames[i] {
+			total[j] += int32(s)
+		}
+	}
+
+	out := make([]byte, confFrameSamples)
+	for i, p := range parties {
+		for j := range out {
+			out[j] = p.encode(clampSample(total[j] - int32(frames[i][j])))
+		}
+		// 쓰기 실패는 dialog 종료로 이어지므로 참가자 정리는 종료 감시에 맡긴다
+		_, _ = p.writer.Write(out)
+	}
+}
+
+func clampSample(v int32) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// add는 참가자를 등록하고 r에서 수신을 시작한다
+func (c *conference) add(p *confParty, r io.Reader) int {
+	c.mu.Lock()
+	c.parties[p.callID] = p
+	count := len(c.parties)
+	c.mu.Unlock()
+
+	go p.receive(r)
+	return count
+}
+
+// remove는 참가자를 내보내고 남은 참가자 수를 반환한다
+func (c *conference) remove(callID string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, exists := c.parties[callID]
+	if !exists {
+		return len(c.parties), false
+	}
+	p.close()
+	delete(c.parties, callID)
+	return len(c.parties), true
+}
+
+func (c *conference) has(callID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.parties[callID]
+	return exists
+}
+
+// close는 믹서와 모든 참가자의 수신을 정지한다
+func (c *conference) close() {
+	c.once.Do(func() {
+		close(c.stop)
+		c.mu.Lock()
+		for _, p := range c.parties {
+			p.close()
+		}
+		c.parties = make(map[string]*confParty)
+		c.mu.Unlock()
+	})
+}
+
+// conferenceCallIDs는 Conference 노드가 대상으로 하는 callID 목록을 반환한다 (callIds가 비면 callId 하나)
+func conferenceCallIDs(node *GraphNode) []string {
+	if len(node.ConfCallIDs) > 0 {
+		return node.ConfCallIDs
+	}
+	return []string{callIDOrDefault(node)}
+}
+
+func (ex *Executor) inConference(instanceID, callID string) bool {
+	ex.confMu.Lock()
+	defer ex.confMu.Unlock()
+	conf, exists := ex.conferences[instanceID]
+	return exists && conf.has(callID)
+}
+
+// joinConference는 dialog를 인스턴스의 컨퍼런스에 추가한다. 컨퍼런스가 없으면 믹서를 시작한다.
+func (ex *Executor) joinConference(instanceID, callID string, dialog diago.DialogSession) (int, error) {
+	m := dialog.Media()
+	if m == nil {
+		return 0, fmt.Errorf("%s/%s has no media session", instanceID, callID)
+	}
+	reader, err := m.AudioReader()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get audio reader for %s/%s: %w", instanceID, callID, err)
+	}
+	writer, err := m.AudioWriter()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get audio writer for %s/%s: %w", instanceID, callID, err)
+	}
+	party, err := newConfParty(callID, negotiatedCodec(dialog), writer)
+	if err != nil {
+		return 0, fmt.Errorf("%s/%s: %w", instanceID, callID, err)
+	}
+
+	ex.confMu.Lock()
+	conf, exists := ex.conferences[instanceID]
+	if !exists {
+		conf = newConference()
+		ex.conferences[instanceID] = conf
+	}
+	if conf.has(callID) {
+		ex.confMu.Unlock()
+		return 0, fmt.Errorf("%s/%s is already in the conference", instanceID, callID)
+	}
+	count := conf.add(party, reader)
+	ex.confMu.Unlock()
+
+	// 참가자의 dialog가 끝나면 자동으로 내보낸다
+	go func() {
+		select {
+		case <-dialog.Context().Done():
+			if remaining, left := ex.leaveConference(instanceID, callID, party); left {
+				ex.emitNodeActionLog(nil, instanceID, fmt.Sprintf("Conference: %s left on hangup (%d parties)", callID, remaining), "info", WithCallID(callID))
+			}
+		case <-party.stop:
+		}
+	}()
+	return count, nil
+}
+
+// leaveConference는 참가자를 내보내고 남은 참가자 수를 반환한다. 마지막 참가자가 나가면 믹서를 정지한다.
+// only가 주어지면 그 참가자가 여전히 등록된 경우에만 내보낸다 (재참가한 dialog를 잘못 내보내지 않도록).
+func (ex *Executor) leaveConference(instanceID, callID string, only *confParty) (int, bool) {
+	ex.confMu.Lock()
+	defer ex.confMu.Unlock()
+
+	conf, exists := ex.conferences[instanceID]
+	if !exists {
+		return 0, false
+	}
+	if only != nil {
+		conf.mu.Lock()
+		current := conf.parties[callID]
+		conf.mu.Unlock()
+		if current != only {
+			return 0, false
+		}
+	}
+	remaining, left := conf.remove(callID)
+	if left && remaining == 0 {
+		conf.close()
+		delete(ex.conferences, instanceID)
+	}
+	return remaining, left
+}
+
+// closeConferences는 run 정리 시 남은 컨퍼런스를 모두 정지한다
+func (ex *Executor) closeConferences() {
+	ex.confMu.Lock()
+	defer ex.confMu.Unlock()
+	for instanceID, conf := range ex.conferences {
+		conf.close()
+		delete(ex.conferences, instanceID)
+	}
+}
+
+// executeConference는 노드의 callID(들)를 인스턴스의 컨퍼런스에 넣거나 뺀다
+func (ex *Executor) executeConference(ctx context.Context, instanceID string, node *GraphNode) error {
+	callIDs := conferenceCallIDs(node)
+
+	if node.ConfAction == ConferenceActionLeave {
+		for _, callID := range callIDs {
+			remaining, left := ex.leaveConference(instanceID, callID, nil)
+			if !left {
+				return fmt.Errorf("Conference: %s/%s is not in the conference", instanceID, callID)
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Conference: %s left (%d parties)", callID, remaining), "info")
+		}
+		return nil
+	}
+
+	for _, callID := range callIDs {
+		dialog, exists := ex.sessions.GetDialog(instanceID, callID)
+		if !exists {
+			return fmt.Errorf("Conference: no active dialog for %s/%s", instanceID, callID)
+		}
+		if err := dialog.Context().Err(); err != nil {
+			return fmt.Errorf("Conference: dialog %s/%s already terminated", instanceID, callID)
+		}
+		if br, bridged := ex.bridgeFor(instanceID, callID); bridged {
+			return fmt.Errorf("Conference: %s/%s is bridged (%s), unbridge it first", instanceID, callID, br)
+		}
+
+		count, err := ex.joinConference(instanceID, callID, dialog)
+		if err != nil {
+			return fmt.Errorf("Conference join failed: %w", err)
+		}
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Conference: %s joined (%d parties)", callID, count), "info")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestG711_RoundTrip(t *testing.T) {
+	for _, name := range []string{"PCMU", "PCMA"} {
+		decode, encode, ok := g711Codec(name)
+		if !ok {
+			t.Fatalf("%s should be supported", name)
+		}
+		for code := 0; code < 256; code++ {
+			sample := decode(byte(code))
+			if sample == 0 {
+				continue // µ-law은 +0/-0 두 코드가 있다
+			}
+			if got := encode(sample); got != byte(code) {
+				t.Errorf("%s: encode(decode(0x%02x)) = 0x%02x", name, code, got)
+			}
+		}
+		for _, sample := range []int16{-32768, -12000, -1000, -100, 100, 1000, 12000, 32767} {
+			got := decode(encode(sample))
+			if diff := int(got) - int(sample); diff*diff > int(sample)*int(sample)/100 {
+				t.Errorf("%s: %d decoded as %d (more than 10%% off)", name, sample, got)
+			}
+		}
+	}
+	if _, _, ok := g711Codec("opus"); ok {
+		t.Error("opus should not be mixable")
+	}
+}
+
+func TestConference_MixMinus(t *testing.T) {
+	conf := &conference{parties: make(map[string]*confParty), stop: make(chan struct{})}
+	writers := map[string]*lockedBuffer{}
+	for callID, level := range map[string]int16{"a": 1000, "b": 2000, "c": 0} {
+		w := &lockedBuffer{}
+		writers[callID] = w
+		p, err := newConfParty(callID, "PCMU", w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < confFrameSamples; i++ {
+			p.pending = append(p.pending, level)
+		}
+		conf.parties[callID] = p
+	}
+
+	conf.mix()
+
+	want := map[string]int{"a": 2000, "b": 1000, "c": 3000}
+	for callID, w := range writers {
+		out := w.String()
+		if len(out) != confFrameSamples {
+			t.Fatalf("%s: expected one %d-sample frame, got %d bytes", callID, confFrameSamples, len(out))
+		}
+		got := int(ulawDecode(out[0]))
+		if diff := got - want[callID]; diff < -want[callID]/20 || diff > want[callID]/20 {
+			t.Errorf("%s hears %d, want about %d (everyone but itself)", callID, got, want[callID])
+		}
+	}
+
+	if remaining, left := conf.remove("c"); !left || remaining != 2 {
+		t.Errorf("remove(c) = %d, %v", remaining, left)
+	}
+	if _, left := conf.remove("c"); left {
+		t.Error("removing a party twice should report false")
+	}
+}
+
+func conferenceDryRunFlow() ([]FlowNode, []FlowEdge) {
+	nodes := []FlowNode{
+		{ID: "host", Type: "sipInstance", Data: map[string]interface{}{"label": "Host", "dn": "100"}},
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "200"}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "host", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "Answer"}},
+		{ID: "dial-2", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "MakeCall", "targetUri": "300", "callId": "call-2"}},
+		{ID: "dial-3", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "MakeCall", "targetUri": "400", "callId": "call-3"}},
+		{ID: "join", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "Conference", "callIds": []interface{}{"call-1", "call-2", "call-3"}}},
+		{ID: "leave", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "Conference", "conferenceAction": "leave", "callId": "call-2"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "host", "command": "Release"}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "100"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "DISCONNECTED"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "host", Target: "incoming"},
+		{ID: "e2", Source: "incoming", Target: "answer"},
+		{ID: "e3", Source: "answer", Target: "dial-2"},
+		{ID: "e4", Source: "dial-2", Target: "dial-3"},
+		{ID: "e5", Source: "dial-3", Target: "join"},
+		{ID: "e6", Source: "join", Target: "leave"},
+		{ID: "e7", Source: "leave", Target: "release"},
+		{ID: "e8", Source: "caller", Target: "call"},
+		{ID: "e9", Source: "call", Target: "disconnected"},
+	}
+	return nodes, edges
+}
+
+func TestDryRun_ConferenceJoinAndLeave(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := conferenceDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+
+	var joined, left int
+	for _, ev := range te.GetEventsByName(EventActionLog) {
+		msg, _ := ev.Data["message"].(string)
+		if strings.Contains(msg, "joined") {
+			joined++
+		}
+		if strings.Contains(msg, "call-2 left (2 parties") {
+			left++
+		}
+	}
+	if joined != 3 || left != 1 {
+		t.Errorf("expected 3 join logs and 1 leave log, got %d and %d", joined, left)
+	}
+}
+
+func TestParseScenario_ConferenceAction(t *testing.T) {
+	nodes, edges := conferenceDryRunFlow()
+	graph, err := ParseScenario(buildTestFlowData(t, nodes, edges))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	if got := graph.Nodes["join"]; got.ConfAction != ConferenceActionJoin || len(got.ConfCallIDs) != 3 {
+		t.Errorf("unexpected join node: action=%q callIds=%v", got.ConfAction, got.ConfCallIDs)
+	}
+	if got := conferenceCallIDs(graph.Nodes["leave"]); len(got) != 1 || got[0] != "call-2" {
+		t.Errorf("leave should target callId, got %v", got)
+	}
+
+	for _, node := range nodes {
+		if node.ID == "leave" {
+			node.Data["conferenceAction"] = "kick"
+		}
+	}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "unsupported conferenceAction") {
+		t.Errorf("expected unsupported conferenceAction error, got %v", err)
+	}
+	if diags := ValidateScenario(flow); !hasCode(diags, "leave", DiagInvalidConference) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidConference, diags)
+	}
+}
@@ -0,0 +1,301 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 일시정지 사유
+const (
+	PauseReasonBreakpoint = "breakpoint" // 브레이크포인트 노드 도달
+	PauseReasonStep       = "step"       // step-over 후 다음 노드 도달
+	PauseReasonManual     = "pause"      // PauseScenario 요청
+)
+
+var (
+	ErrNotPaused     = errors.New("scenario is not paused")
+	ErrAlreadyPaused = errors.New("scenario is already paused")
+)
+
+// DebugPausePoint는 실행이 멈춘 노드 경계
+type DebugPausePoint struct {
+	NodeID     string `json:"nodeId"`
+	InstanceID string `json:"instanceId"`
+	Reason     string `json:"reason"`
+}
+
+// DebugDialog는 일시정지 중 조회한 활성 dialog 정보
+type DebugDialog struct {
+	InstanceID string `json:"instanceId"`
+	CallID     string `json:"callId"`
+	SIPCallID  string `json:"sipCallId,omitempty"`
+	State      string `json:"state"`
+}
+
+// DebugSnapshot은 실행 중 시나리오의 디버거 상태와 런타임 상태 스냅샷
+type DebugSnapshot struct {
+	Paused      bool                   `json:"paused"`
+	PausePoint  *DebugPausePoint       `json:"pausePoint,omitempty"`
+	Breakpoints []string               `json:"breakpoints"`
+	Variables   map[string]interface{} `json:"variables"` // 정지 노드에서 보이는 변수 (시나리오 변수 + 바인딩된 fragment 변수)
+	Signals     []string               `json:"signals"`   // 발행된 named signal
+	Dialogs     []DebugDialog          `json:"dialogs"`
+}
+
+// Debugger는 노드 경계에서 체인 실행을 멈추는 브레이크포인트/step 제어기.
+// 정지는 노드 사이에서만 일어나므로 진행 중인 노드의 대기와 타임아웃은 그대로 유지되고,
+// dialog와 UA는 정지 중에도 살아 있어 diago가 SIP 타이머(재전송, session refresh)를 계속 처리한다.
+// 노드별 타임아웃은 노드 실행 시작 시점에 생성되므로 정지 시간은 타임아웃에 포함되지 않는다.
+type Debugger struct {
+	mu             sync.Mutex
+	breakpoints    map[string]bool
+	paused         bool
+	pauseRequested bool
+	stepInstance   string        // step-over 중인 체인의 인스턴스 — 이 체인의 다음 노드에서 정지
+	resume         chan struct{} // 정지 중일 때만 유효, 재개 시 close
+	point          *DebugPausePoint
+	pausedNode     *GraphNode
+	onPause        func(DebugPausePoint)
+}
+
+// NewDebugger는 새로운 Debugger를 생성한다
+func NewDebugger() *Debugger {
+	return &Debugger{
+		breakpoints: make(map[string]bool),
+	}
+}
+
+// SetBreakpoints는 브레이크포인트 노드 목록을 교체한다. 실행 중에도 호출할 수 있다.
+func (d *Debugger) SetBreakpoints(nodeIDs []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints = make(map[string]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		d.breakpoints[id] = true
+	}
+}
+
+// Breakpoints는 설정된 브레이크포인트 노드 ID를 정렬하여 반환한다
+func (d *Debugger) Breakpoints() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breakpointsLocked()
+}
+
+func (d *Debugger) breakpointsLocked() []string {
+	ids := make([]string, 0, len(d.breakpoints))
+	for id := range d.breakpoints {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// reset은 새 실행 시작 시 정지/step 상태를 초기화한다. 브레이크포인트는 유지한다.
+func (d *Debugger) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.paused {
+		close(d.resume)
+	}
+	d.paused = false
+	d.pauseRequested = false
+	d.stepInstance = ""
+	d.resume = nil
+	d.point = nil
+	d.pausedNode = nil
+}
+
+// checkpoint는 노드 실행 직전에 호출된다. 정지 조건이면 재개될 때까지 블로킹하며,
+// 다른 체인이 이미 정지시킨 경우에도 이 체인은 노드 경계에서 함께 대기한다.
+func (d *Debugger) checkpoint(ctx context.Context, instanceID string, node *GraphNode) error {
+	d.mu.Lock()
+	reason := ""
+	switch {
+	case d.paused:
+	case d.pauseRequested:
+		reason = PauseReasonManual
+	case d.stepInstance == instanceID:
+		reason = PauseReasonStep
+	case d.breakpoints[node.ID]:
+		reason = PauseReasonBreakpoint
+	default:
+		d.mu.Unlock()
+		return nil
+	}
+
+	var point DebugPausePoint
+	if reason != "" {
+		point = DebugPausePoint{NodeID: node.ID, InstanceID: instanceID, Reason: reason}
+		d.paused = true
+		d.pauseRequested = false
+		d.stepInstance = ""
+		d.resume = make(chan struct{})
+		d.point = &point
+		d.pausedNode = node
+	}
+	resume := d.resume
+	onPause := d.onPause
+	d.mu.Unlock()
+
+	if reason != "" && onPause != nil {
+		onPause(point)
+	}
+
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause는 어느 체인이든 다음 노드 경계에서 실행을 멈추도록 요청한다
+func (d *Debugger) Pause() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.paused {
+		return ErrAlreadyPaused
+	}
+	d.pauseRequested = true
+	return nil
+}
+
+// Resume은 정지된 모든 체인을 재개한다
+func (d *Debugger) Resume() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.paused {
+		return ErrNotPaused
+	}
+	d.resumeLocked()
+	return nil
+}
+
+// Step은 정지 지점의 노드를 실행하고 같은 체인의 다음 노드에서 다시 멈춘다.
+// 다른 체인도 함께 재개되어야 상대방 이벤트(INCOMING 등)를 기다리는 노드가 교착되지 않는다.
+func (d *Debugger) Step() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.paused {
+		return ErrNotPaused
+	}
+	d.stepInstance = d.point.InstanceID
+	d.resumeLocked()
+	return nil
+}
+
+func (d *Debugger) resumeLocked() {
+	close(d.resume)
+	d.paused = false
+	d.resume = nil
+	d.point = nil
+	d.pausedNode = nil
+}
+
+// state는 스냅샷용 디버거 상태를 반환한다
+func (d *Debugger) state() (paused bool, point *DebugPausePoint, node *GraphNode, breakpoints []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.point != nil {
+		copied := *d.point
+		point = &copied
+	}
+	return d.paused, point, d.pausedNode, d.breakpointsLocked()
+}
+
+// SetBreakpoints는 브레이크포인트 노드 목록을 교체한다. 이후 시작하는 run과 실행 중인 모든 run에 적용된다.
+func (e *Engine) SetBreakpoints(nodeIDs []string) {
+	e.mu.Lock()
+	e.breakpoints = append([]string(nil), nodeIDs...)
+	e.mu.Unlock()
+
+	for _, run := range e.activeRuns() {
+		run.debugger.SetBreakpoints(nodeIDs)
+	}
+}
+
+// Breakpoints는 설정된 브레이크포인트 노드 ID 목록을 반환한다
+func (e *Engine) Breakpoints() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ids := append([]string{}, e.breakpoints...)
+	sort.Strings(ids)
+	return ids
+}
+
+// PauseScenario는 run을 다음 노드 경계에서 일시정지한다
+func (e *Engine) PauseScenario(runID string) error {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return err
+	}
+	return run.debugger.Pause()
+}
+
+// ResumeScenario는 일시정지된 run을 재개한다
+func (e *Engine) ResumeScenario(runID string) error {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return err
+	}
+	if err := run.debugger.Resume(); err != nil {
+		return err
+	}
+	e.emitScenarioResumed(runID, false)
+	return nil
+}
+
+// StepScenario는 정지 지점의 노드 하나를 실행하고 같은 체인의 다음 노드에서 다시 정지한다
+func (e *Engine) StepScenario(runID string) error {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return err
+	}
+	if err := run.debugger.Step(); err != nil {
+		return err
+	}
+	e.emitScenarioResumed(runID, true)
+	return nil
+}
+
+// DebugSnapshot은 run의 디버거 상태와 활성 dialog, 발행된 signal을 조회한다
+func (e *Engine) DebugSnapshot(runID string) (*DebugSnapshot, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	run.mu.Lock()
+	ex := run.executor
+	run.mu.Unlock()
+	if ex == nil {
+		return nil, fmt.Errorf("run %s is not executing", runID)
+	}
+
+	paused, point, node, breakpoints := run.debugger.state()
+	snapshot := &DebugSnapshot{
+		Paused:      paused,
+		PausePoint:  point,
+		Breakpoints: breakpoints,
+		Variables:   ex.variables.Resolve(""),
+		Signals:     ex.syncs.RaisedSignals(),
+		Dialogs:     ex.sessions.Snapshot(),
+	}
+	if node != nil {
+		snapshot.Variables = ex.variables.Resolve(node.ID)
+	}
+	if ex.sim != nil {
+		snapshot.Dialogs = append(snapshot.Dialogs, ex.sim.snapshot()...)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// waitForPause waits for a scenario:paused event at the given node
+func waitForPause(t *testing.T, te *TestEventEmitter, nodeID, reason string, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, e := range te.GetEventsByName(EventPaused) {
+			if e.Data["nodeId"] == nodeID && e.Data["reason"] == reason {
+				return true
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestDebugger_BreakpointStepResume(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	var flow FlowData
+	if err := json.Unmarshal([]byte(buildTestFlowData(t, nodes, edges)), &flow); err != nil {
+		t.Fatal(err)
+	}
+	flow.Variables = map[string]interface{}{"greeting": "hello"}
+	flowData, err := json.Marshal(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scn, err := repo.CreateScenario("default", "debug")
+	if err != nil {
+		t.Fatalf("CreateScenario failed: %v", err)
+	}
+	if err := repo.SaveScenario(scn.ID, string(flowData)); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	scenarioID := scn.ID
+
+	eng.SetBreakpoints([]string{"answer"})
+	runID, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{})
+	if err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+
+	if !waitForPause(t, te, "answer", PauseReasonBreakpoint, 2*time.Second) {
+		t.Fatal("expected pause at answer breakpoint")
+	}
+
+	snapshot, err := eng.DebugSnapshot(runID)
+	if err != nil {
+		t.Fatalf("DebugSnapshot failed: %v", err)
+	}
+	if !snapshot.Paused || snapshot.PausePoint.InstanceID != "inst-b" {
+		t.Errorf("unexpected pause point: %+v", snapshot.PausePoint)
+	}
+	if snapshot.Variables["greeting"] != "hello" || snapshot.Variables["command"] != nil {
+		t.Errorf("expected scenario variables in snapshot, got %v", snapshot.Variables)
+	}
+	foundRinging := false
+	for _, d := range snapshot.Dialogs {
+		if d.InstanceID == "inst-b" && d.State == "ringing" {
+			foundRinging = true
+		}
+	}
+	if !foundRinging {
+		t.Errorf("expected ringing dialog on inst-b while paused, got %+v", snapshot.Dialogs)
+	}
+	for _, e := range te.GetEventsByName(EventNodeState) {
+		if e.Data["nodeId"] == "answer" {
+			t.Fatalf("answer should not run while paused, got %v", e.Data)
+		}
+	}
+
+	if err := eng.StepScenario(runID); err != nil {
+		t.Fatalf("StepScenario failed: %v", err)
+	}
+	if !waitForPause(t, te, "dtmf", PauseReasonStep, 2*time.Second) {
+		t.Fatal("expected pause at next node of the stepped chain")
+	}
+	if !waitForNodeState(t, te, "answer", NodeStateCompleted, time.Second) {
+		t.Error("expected answer to complete during step")
+	}
+
+	if err := eng.ResumeScenario(runID); err != nil {
+		t.Fatalf("ResumeScenario failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatal("scenario did not complete after resume")
+	}
+	if err := eng.ResumeScenario(runID); err == nil {
+		t.Error("expected ResumeScenario to fail after the run completed")
+	}
+}
+
+func TestDebugger_StopWhilePaused(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	eng.SetBreakpoints([]string{"make-call"})
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForPause(t, te, "make-call", PauseReasonBreakpoint, 2*time.Second) {
+		t.Fatal("expected pause at make-call breakpoint")
+	}
+
+	if err := eng.StopScenario(); err != nil {
+		t.Fatalf("StopScenario failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventStopped, 2*time.Second) {
+		t.Fatal("expected scenario:stopped while paused")
+	}
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dialogInfo는 application/dialog-info+xml 문서 (RFC 4235)
+type dialogInfo struct {
+	XMLName xml.Name           `xml:"dialog-info"`
+	Entity  string             `xml:"entity,attr"`
+	Dialogs []dialogInfoDialog `xml:"dialog"`
+}
+
+// dialogInfoDialog는 dialog-info의 dialog 한 건. 태그는 감시 대상(entity) 기준이다.
+type dialogInfoDialog struct {
+	ID        string `xml:"id,attr"`
+	CallID    string `xml:"call-id,attr"`
+	LocalTag  string `xml:"local-tag,attr"`
+	RemoteTag string `xml:"remote-tag,attr"`
+	Direction string `xml:"direction,attr"`
+	State     string `xml:"state"`
+}
+
+func parseDialogInfo(body []byte) (*dialogInfo, error) {
+	var info dialogInfo
+	if err := xml.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("invalid dialog-info body: %w", err)
+	}
+	return &info, nil
+}
+
+// ringingDialog는 감시 대상이 착신 중(early, recipient)인 dialog를 찾는다
+func (info *dialogInfo) ringingDialog() (dialogInfoDialog, bool) {
+	for _, d := range info.Dialogs {
+		state := strings.ToLower(strings.TrimSpace(d.State))
+		if (state == "early" || state == "proceeding") && d.Direction != "initiator" && d.CallID != "" {
+			return d, true
+		}
+	}
+	return dialogInfoDialog{}, false
+}
+
+// replaces는 착신 dialog를 당겨받는 INVITE의 Replaces 헤더 값 (RFC 3891, early-only)
+func (d dialogInfoDialog) replaces() string {
+	return fmt.Sprintf("%s;to-tag=%s;from-tag=%s;early-only", d.CallID, d.LocalTag, d.RemoteTag)
+}
+
+// fetchDialogInfo는 target의 dialog 상태를 SUBSCRIBE(Event: dialog, Expires: 0)로 한 번 조회한다 (RFC 6665 fetch)
+func (ex *Executor) fetchDialogInfo(ctx context.Context, instanceID, target string, timeout time.Duration) (*dialogInfo, error) {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	recipient, err := ex.resolveSubscribeTarget(instanceID, target)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := newEventSubscriber(instance.Config.DN, resolveBindHost(instance.Config), EventPackageDialog)
+	if err != nil {
+		return nil, err
+	}
+	defer subscriber.close()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, err := newSubscription(subscriber, EventPackageDialog, target, recipient).send(fetchCtx, 0); err != nil {
+		return nil, err
+	}
+	select {
+	case req := <-subscriber.notifies:
+		return parseDialogInfo(req.Body())
+	case <-fetchCtx.Done():
+		return nil, fmt.Errorf("no NOTIFY for dialog subscription: %w", fetchCtx.Err())
+	}
+}
@@ -4,29 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"sipflow/internal/pkg/eventhandler"
+	"github.com/emiago/diago"
+	"github.com/google/uuid"
+
 	"sipflow/internal/scenario"
 )
 
-// Engine는 시나리오 실행 엔진
+// Engine는 시나리오 실행 엔진. 여러 시나리오를 동시에 실행할 수 있으며,
+// 각 실행(run)은 run ID로 구분되고 독립된 InstanceManager 포트 범위와 SessionStore를 가진다.
 type Engine struct {
-	ctx        context.Context
-	repo       *scenario.Repository
-	emitter    EventEmitter
-	im         *InstanceManager
-	executor   *Executor
-	mu         sync.Mutex
-	running    bool
-	scenarioID string
-	cancelFunc context.CancelFunc
-	runDone    chan struct{}
-	terminal   scenarioTerminalState
-	wg         sync.WaitGroup
+	ctx           context.Context
+	repo          *scenario.Repository
+	emitter       EventEmitter
+	mu            sync.Mutex
+	runs          map[string]*scenarioRun // run ID -> 실행 컨텍스트
+	breakpoints   []string                // 새 run의 디버거에 적용할 브레이크포인트
+	portBase      int                     // run별 포트 범위의 시작 포트
+	portRangeSize int                     // run 하나에 할당하는 포트 수
+	portSlots     map[int]bool            // 사용 중인 포트 범위 슬롯
+	regHold       time.Duration           // maxDurationMs 없는 등록 전용 run의 등록 유지 시간
 }
 
+const (
+	defaultPortBase      = 15060
+	defaultPortRangeSize = 200 // 인스턴스당 2포트 간격으로 최대 100개 인스턴스
+
+	// defaultRegistrationHold는 체인 없이 등록만 하는 run이 maxDurationMs 없이 등록을 유지하는 시간
+	defaultRegistrationHold = 10 * time.Minute
+)
+
 type scenarioTerminalState string
 
 const (
@@ -38,9 +49,13 @@ const (
 // NewEngine는 새로운 Engine을 생성한다
 func NewEngine(repo *scenario.Repository) *Engine {
 	return &Engine{
-		repo:    repo,
-		emitter: nil, // SetContext에서 자동 설정
-		im:      NewInstanceManager(),
+		repo:          repo,
+		emitter:       nil, // SetContext에서 자동 설정
+		runs:          make(map[string]*scenarioRun),
+		portBase:      defaultPortBase,
+		portRangeSize: defaultPortRangeSize,
+		portSlots:     make(map[int]bool),
+		regHold:       defaultRegistrationHold,
 	}
 }
 
@@ -55,33 +70,213 @@ func (e *Engine) SetEventEmitter(emitter EventEmitter) {
 	e.emitter = emitter
 }
 
-// StartScenario는 시나리오 실행을 시작한다
-func (e *Engine) StartScenario(scenarioID string) error {
+// StartScenario는 시나리오 실행을 시작하고 run ID를 반환한다
+func (e *Engine) StartScenario(scenarioID string) (string, error) {
+	return e.startRun(scenarioID, runOptions{})
+}
+
+// ValidateScenario는 프로젝트의 fragment/시나리오로 CallScenario 노드를 전개하여 flow 데이터를 검증한다
+func (e *Engine) ValidateScenario(projectID, flowData string) []Diagnostic {
+	return ValidateScenarioWithResolver(flowData, &repositoryFlowResolver{repo: e.repo, projectID: projectID})
+}
+
+// StartScenarioDryRun은 네트워크 소켓 없이 시뮬레이션 SIP 백엔드로 시나리오를 실행한다.
+// UA 생성/Listen/REGISTER를 건너뛰고, 실제 실행과 동일한 노드 상태/액션 로그 이벤트를 발행한다.
+func (e *Engine) StartScenarioDryRun(scenarioID string, opts SimulationOptions) (string, error) {
+	return e.startRun(scenarioID, runOptions{sim: &opts})
+}
+
+// StartScenarioFrom은 선택한 노드부터 체인을 시작한다. 인스턴스당 하나의 노드를 선택할 수 있으며
+// 선택되지 않은 인스턴스의 체인은 실행하지 않는다. RunSetup이면 각 선택 노드에 이르는 경로를
+// 먼저 조용히 실행하고, 선택 노드에서 도달할 수 없는 노드는 skipped 상태로 표시한다.
+func (e *Engine) StartScenarioFrom(scenarioID string, nodeIDs []string, opts StartFromOptions) (string, error) {
+	if len(nodeIDs) == 0 {
+		return "", errors.New("at least one start node is required")
+	}
+	return e.startRun(scenarioID, runOptions{
+		sim:       opts.Simulation,
+		startFrom: nodeIDs,
+		runSetup:  opts.RunSetup,
+	})
+}
+
+// newRun은 run을 등록하고 포트 범위를 할당한다. 같은 시나리오도 run마다 독립적으로 동시에 실행할 수 있다.
+func (e *Engine) newRun(scenarioID string, dryRun bool) (*scenarioRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run := &scenarioRun{
+		id:         uuid.New().String(),
+		scenarioID: scenarioID,
+		dryRun:     dryRun,
+		startedAt:  time.Now(),
+		portSlot:   -1,
+		debugger:   NewDebugger(),
+		done:       make(chan struct{}),
+	}
+
+	// dry-run은 소켓을 열지 않으므로 포트 범위를 예약하지 않는다
+	if dryRun {
+		run.im = NewInstanceManager()
+	} else {
+		slot := 0
+		for e.portSlots[slot] {
+			slot++
+		}
+		e.portSlots[slot] = true
+		run.portSlot = slot
+		run.im = newRangeInstanceManager(e.portBase+slot*e.portRangeSize, e.portRangeSize)
+	}
+
+	run.debugger.SetBreakpoints(e.breakpoints)
+	run.debugger.onPause = func(point DebugPausePoint) {
+		e.emitScenarioPaused(run.id, point)
+	}
+	e.runs[run.id] = run
+	return run, nil
+}
+
+// removeRun은 run 등록과 포트 범위 예약을 해제한다
+func (e *Engine) removeRun(run *scenarioRun) {
 	e.mu.Lock()
-	if e.running {
-		e.mu.Unlock()
-		return errors.New("scenario already running")
+	defer e.mu.Unlock()
+
+	delete(e.runs, run.id)
+	if run.portSlot >= 0 {
+		delete(e.portSlots, run.portSlot)
+	}
+}
+
+// RunScenario는 시나리오를 실행하고 종료될 때까지 기다려 결과를 반환한다.
+// sim이 nil이 아니면 dry-run으로 실행한다. ctx가 취소되면 run을 중지한다.
+func (e *Engine) RunScenario(ctx context.Context, scenarioID string, sim *SimulationOptions) (*RunResult, error) {
+	return e.runScenario(ctx, scenarioID, runOptions{sim: sim})
+}
+
+// RunScenarioWithRetry는 RunScenario와 같지만 run이 실패하면 policy에 따라 시나리오 전체를 다시 실행한다.
+// 시작 자체가 실패한 경우(시나리오 없음, 파싱 오류 등)는 재시도하지 않는다.
+func (e *Engine) RunScenarioWithRetry(ctx context.Context, scenarioID string, sim *SimulationOptions, policy RetryPolicy) (*RunResult, error) {
+	return e.runScenarioWithRetry(ctx, scenarioID, runOptions{sim: sim}, policy)
+}
+
+func (e *Engine) runScenarioWithRetry(ctx context.Context, scenarioID string, opts runOptions, policy RetryPolicy) (*RunResult, error) {
+	maxAttempts := policy.attempts()
+	for attempt := 1; ; attempt++ {
+		result, err := e.runScenario(ctx, scenarioID, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Attempts = attempt
+		if result.Status != RunStatusFailed || attempt >= maxAttempts || ctx.Err() != nil {
+			return result, nil
+		}
+
+		delay := policy.delay(attempt)
+		e.emitActionLog("", "", fmt.Sprintf("Run attempt %d/%d failed: %s (retrying in %s)", attempt, maxAttempts, result.Error, delay), "warn",
+			WithRunID(result.RunID))
+		if sleepContext(ctx, delay) != nil {
+			return result, nil
+		}
+	}
+}
+
+func (e *Engine) runScenario(ctx context.Context, scenarioID string, opts runOptions) (*RunResult, error) {
+	run, err := e.startScenario(scenarioID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-run.done:
+	case <-ctx.Done():
+		e.stopRun(run)
+		<-run.done
+	}
+	result := run.result
+	return &result, nil
+}
+
+func (e *Engine) startRun(scenarioID string, opts runOptions) (string, error) {
+	run, err := e.startScenario(scenarioID, opts)
+	if err != nil {
+		return "", err
+	}
+	return run.id, nil
+}
+
+func (e *Engine) startScenario(scenarioID string, opts runOptions) (*scenarioRun, error) {
+	sim := opts.sim
+
+	run, err := e.newRun(scenarioID, sim != nil)
+	if err != nil {
+		return nil, err
 	}
-	e.running = true
-	e.mu.Unlock()
 
 	scn, err := e.repo.LoadScenario(scenarioID)
 	if err != nil {
-		e.mu.Lock()
-		e.running = false
-		e.mu.Unlock()
-		return err
+		e.cleanupOnError(run)
+		return nil, err
 	}
 
-	graph, err := ParseScenario(scn.FlowData)
+	settings, err := e.repo.LoadProjectSettings(scn.ProjectID)
 	if err != nil {
-		e.cleanupOnError()
-		return err
+		e.cleanupOnError(run)
+		return nil, fmt.Errorf("failed to load project settings: %w", err)
 	}
 
-	if err := e.im.CreateInstances(graph); err != nil {
-		e.cleanupOnError()
-		return err
+	graph, err := ParseScenarioWithOptions(scn.FlowData, ParseOptions{
+		Resolver:  &repositoryFlowResolver{repo: e.repo, projectID: scn.ProjectID},
+		Variables: opts.variables,
+		Defaults: InstanceDefaults{
+			PBXHost:      settings.PBXHost,
+			PBXPort:      settings.PBXPort,
+			PBXTransport: settings.PBXTransport,
+			Codecs:       settings.Codecs,
+		},
+	})
+	if err != nil {
+		e.cleanupOnError(run)
+		return nil, err
+	}
+
+	// 실행 전 정적 분석 — 일부 경로에서만 실패하는 동작(warning)은 실패 분기를 의도했을 수 있으므로 로그로만 알리고,
+	// 모든 경로에서 불가능한 동작(error)이 있으면 실행하지 않는다
+	var callFlowErrors []string
+	for _, diag := range AnalyzeCallFlow(graph) {
+		level := "warn"
+		if diag.Severity == SeverityError {
+			level = "error"
+			callFlowErrors = append(callFlowErrors, fmt.Sprintf("node %s: %s", diag.NodeID, diag.Message))
+		}
+		e.emitActionLog(diag.NodeID, graph.Nodes[diag.NodeID].InstanceID, fmt.Sprintf("Call flow check: %s", diag.Message), level,
+			WithRunID(run.id))
+	}
+	if len(callFlowErrors) > 0 {
+		e.cleanupOnError(run)
+		return nil, fmt.Errorf("call flow check failed: %s", strings.Join(callFlowErrors, "; "))
+	}
+
+	chains, skipped, err := planChains(graph, opts)
+	if err != nil {
+		e.cleanupOnError(run)
+		return nil, err
+	}
+
+	if sim == nil {
+		if graph.LocalPBX {
+			if err := e.startLocalPBX(run, graph); err != nil {
+				e.cleanupOnError(run)
+				return nil, err
+			}
+		}
+		if err := run.im.CreateInstances(graph); err != nil {
+			e.cleanupOnError(run)
+			return nil, err
+		}
+		if err := e.startSIPServers(run, graph); err != nil {
+			e.cleanupOnError(run)
+			return nil, err
+		}
 	}
 
 	parentCtx := context.Background()
@@ -90,17 +285,17 @@ func (e *Engine) StartScenario(scenarioID string) error {
 	}
 	execCtx, cancel := context.WithCancel(parentCtx)
 
-	e.mu.Lock()
-	e.scenarioID = scenarioID
-	e.cancelFunc = cancel
-	e.runDone = make(chan struct{})
-	e.terminal = terminalStateRunning
-	e.mu.Unlock()
+	run.mu.Lock()
+	run.cancel = cancel
+	run.terminal = terminalStateRunning
+	run.mu.Unlock()
 
-	if err := e.im.StartServing(execCtx); err != nil {
+	if sim != nil {
+		e.emitActionLog("", "", "Dry-run mode: SIP traffic is simulated in-process", "info", WithRunID(run.id))
+	} else if err := run.im.StartServing(execCtx); err != nil {
 		cancel()
-		e.cleanupOnError()
-		return err
+		e.cleanupOnError(run)
+		return nil, err
 	}
 
 	registerLoops := make([]struct {
@@ -115,17 +310,21 @@ func (e *Engine) StartScenario(scenarioID string) error {
 		}
 
 		hasRegistrations = true
-		e.emitActionLog("", instanceID, fmt.Sprintf("Registering DN %s", chain.Config.DN), "info")
+		e.emitActionLog("", instanceID, fmt.Sprintf("Registering DN %s", chain.Config.DN), "info", WithRunID(run.id))
+		if sim != nil {
+			e.emitActionLog("", instanceID, fmt.Sprintf("Registered DN %s (simulated)", chain.Config.DN), "info", WithRunID(run.id))
+			continue
+		}
 
 		keepAlive := len(chain.StartNodes) > 0
-		regErrCh, err := e.im.StartRegistration(execCtx, instanceID, keepAlive)
+		regErrCh, err := run.im.StartRegistration(execCtx, instanceID, keepAlive)
 		if err != nil {
 			cancel()
-			e.cleanupOnError()
-			return fmt.Errorf("instance %s register failed: %w", instanceID, err)
+			e.cleanupOnError(run)
+			return nil, fmt.Errorf("instance %s register failed: %w", instanceID, err)
 		}
 
-		e.emitActionLog("", instanceID, fmt.Sprintf("Registered DN %s", chain.Config.DN), "info")
+		e.emitActionLog("", instanceID, fmt.Sprintf("Registered DN %s", chain.Config.DN), "info", WithRunID(run.id))
 		if regErrCh != nil {
 			registerLoops = append(registerLoops, struct {
 				instanceID string
@@ -137,24 +336,68 @@ func (e *Engine) StartScenario(scenarioID string) error {
 		}
 	}
 
-	e.emitScenarioStarted(scenarioID)
-	e.executor = NewExecutor(e, e.im)
+	e.emitScenarioStarted(run.id, scenarioID)
+	for _, nodeID := range skipped {
+		e.emitNodeState(run.id, nodeID, NodeStatePending, NodeStateSkipped)
+	}
+
+	executor := NewExecutor(e, run.im)
+	executor.runID = run.id
+	executor.debug = run.debugger
+	executor.variables = graph.Variables
+	executor.features = FeatureCodes(settings.FeatureCodes).withDefaults()
+	if sim != nil {
+		executor.sim = newSimBackend(graph, *sim)
+	} else {
+		run.im.SetReplacesHandler(func(instanceID string, inDialog *diago.DialogServerSession) {
+			executor.handleReplacesInvite(instanceID, inDialog)
+		})
+		run.im.SetUpdateHandler(executor.handleUpdate)
+		run.im.SetReferNotifyHandler(executor.handleReferNotify)
+	}
+	for _, chain := range chains {
+		for _, step := range chain.setup {
+			executor.quiet[step.node.ID] = true
+		}
+	}
+	run.mu.Lock()
+	run.executor = executor
+	run.mu.Unlock()
 
-	errCh := make(chan error, len(graph.Instances))
+	errCh := make(chan error, len(graph.Instances)+1) // 인스턴스별 에러 + watchdog 진단
 	hasStartNodes := false
 
-	for instanceID, chain := range graph.Instances {
-		if len(chain.StartNodes) == 0 {
-			continue
+	wd := &watchdog{engine: e, run: run, ex: executor, errCh: errCh, cancel: cancel}
+	disarm := wd.arm(graph.MaxDuration, fmt.Sprintf("scenario exceeded max duration %s", graph.MaxDuration))
+
+	// 등록만 하는 run은 maxDurationMs가 없으면 기본 유지 시간이 지나면 정상 종료한다
+	if len(chains) == 0 && hasRegistrations && graph.MaxDuration <= 0 {
+		e.emitActionLog("", "", fmt.Sprintf("No max duration set; holding registrations for %s", e.regHold), "info", WithRunID(run.id))
+		hold := time.AfterFunc(e.regHold, cancel)
+		disarmRun := disarm
+		disarm = func() {
+			hold.Stop()
+			disarmRun()
 		}
+	}
 
+	for instanceID, chain := range chains {
 		hasStartNodes = true
-		e.wg.Add(1)
-		go func(id string, ch *InstanceChain) {
-			defer e.wg.Done()
-			for _, startNode := range ch.StartNodes {
-				if err := e.executor.ExecuteChain(execCtx, id, startNode); err != nil {
-					e.markTerminalState(terminalStateFailed)
+		run.wg.Add(1)
+		go func(id string, chain *chainRun) {
+			defer run.wg.Done()
+			deadline := graph.Instances[id].Config.ChainDeadline
+			disarmChain := wd.arm(deadline, fmt.Sprintf("instance %s chain exceeded deadline %s", id, deadline))
+			defer disarmChain()
+			if err := executor.executeSetup(execCtx, id, chain.setup); err != nil {
+				run.markTerminalState(terminalStateFailed)
+				errCh <- fmt.Errorf("instance %s: %w", id, err)
+				cancel()
+				return
+			}
+			for _, startNode := range chain.starts {
+				if err := executor.ExecuteChain(execCtx, id, startNode); err != nil {
+					run.markTerminalState(terminalStateFailed)
 					errCh <- fmt.Errorf("instance %s: %w", id, err)
 					cancel()
 					return
@@ -169,7 +412,7 @@ func (e *Engine) StartScenario(scenarioID string) error {
 				if err == nil {
 					continue
 				}
-				e.markTerminalState(terminalStateFailed)
+				run.markTerminalState(terminalStateFailed)
 				errCh <- fmt.Errorf("instance %s register loop: %w", instanceID, err)
 				cancel()
 				return
@@ -182,24 +425,21 @@ func (e *Engine) StartScenario(scenarioID string) error {
 
 		switch {
 		case hasStartNodes:
-			e.wg.Wait()
+			run.wg.Wait()
 		case hasRegistrations:
 			<-execCtx.Done()
 		}
 
+		disarm()
 		cancel()
-		e.cleanup()
-
-		e.mu.Lock()
-		currentScenarioID := e.scenarioID
-		terminalState := e.terminal
-		runDone := e.runDone
-		e.running = false
-		e.scenarioID = ""
-		e.cancelFunc = nil
-		e.runDone = nil
-		e.terminal = ""
-		e.mu.Unlock()
+		e.cleanup(run)
+		run.debugger.reset()
+
+		run.mu.Lock()
+		terminalState := run.terminal
+		run.terminal = ""
+		run.mu.Unlock()
+		e.removeRun(run)
 
 		select {
 		case err := <-errCh:
@@ -207,113 +447,185 @@ func (e *Engine) StartScenario(scenarioID string) error {
 		default:
 		}
 
+		run.result = RunResult{
+			RunID:       run.id,
+			ScenarioID:  scenarioID,
+			Status:      RunStatusCompleted,
+			Attempts:    1,
+			NodeRetries: int(executor.nodeRetries.Load()),
+			StartedAt:   run.startedAt,
+			FinishedAt:  time.Now(),
+		}
 		switch terminalState {
 		case terminalStateFailed:
+			run.result.Status = RunStatusFailed
+			run.result.Error = "scenario failed"
 			if finalErr != nil {
-				e.emitScenarioFailed(finalErr.Error())
-			} else {
-				e.emitScenarioFailed("scenario failed")
+				run.result.Error = finalErr.Error()
 			}
+			e.emitScenarioFailed(run.id, run.result.Error)
 		case terminalStateStopped:
-			e.emitScenarioStopped()
+			run.result.Status = RunStatusStopped
+			e.emitScenarioStopped(run.id)
 		default:
-			e.emitScenarioCompleted(currentScenarioID)
+			e.emitScenarioCompleted(run.id, scenarioID)
 		}
 
-		if runDone != nil {
-			close(runDone)
-		}
+		close(run.done)
 	}()
 
+	return run, nil
+}
+
+// startLocalPBX는 run의 내장 registrar/proxy를 띄우고 모든 인스턴스가 이를 PBX로 사용하도록 설정한다.
+// 프로젝트/인스턴스에 지정된 PBX 주소보다 우선한다.
+func (e *Engine) startLocalPBX(run *scenarioRun, graph *ExecutionGraph) error {
+	host, port, err := run.im.StartLocalPBX(func(message, level string, opts ...ActionLogOption) {
+		e.emitActionLog("", "", "Local PBX: "+message, level, append(opts, WithRunID(run.id))...)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, chain := range graph.Instances {
+		chain.Config.PBXHost = host
+		chain.Config.PBXPort = strconv.Itoa(port)
+	}
+	e.emitActionLog("", "", fmt.Sprintf("Local PBX listening on %s:%d (udp/tcp)", host, port), "info", WithRunID(run.id))
+	return nil
+}
+
+// startSIPServers는 SIP Server 역할 인스턴스의 registrar/proxy를 설정된 listen 주소에서 시작한다
+func (e *Engine) startSIPServers(run *scenarioRun, graph *ExecutionGraph) error {
+	for instanceID, chain := range graph.Instances {
+		if !chain.Config.IsServer() {
+			continue
+		}
+		id := instanceID
+		addr, err := run.im.StartSIPServer(id, func(message, level string, opts ...ActionLogOption) {
+			e.emitActionLog("", id, "SIP server: "+message, level, append(opts, WithRunID(run.id))...)
+		})
+		if err != nil {
+			return err
+		}
+		auth := "no auth"
+		if chain.Config.AuthPassword != "" {
+			auth = fmt.Sprintf("digest realm %q", chain.Config.AuthRealm)
+		}
+		e.emitActionLog("", id, fmt.Sprintf("SIP server listening on %s (udp/tcp, %s)", addr, auth), "info", WithRunID(run.id))
+	}
 	return nil
 }
 
-// StopScenario는 실행 중인 시나리오를 중지한다
+// ServerRegistrations는 run의 SIP Server 인스턴스에 등록된 contact 목록을 반환한다
+func (e *Engine) ServerRegistrations(runID, instanceID string) ([]PBXBinding, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	return run.im.ServerRegistrations(instanceID)
+}
+
+// StopScenario는 실행 중인 모든 run을 중지한다
 func (e *Engine) StopScenario() error {
-	e.mu.Lock()
-	if !e.running {
-		e.mu.Unlock()
+	runs := e.activeRuns()
+	if len(runs) == 0 {
 		return errors.New("no running scenario")
 	}
-	cancelFunc := e.cancelFunc
-	runDone := e.runDone
-	e.terminal = terminalStateStopped
-	e.mu.Unlock()
+
+	for _, run := range runs {
+		e.stopRun(run)
+	}
+	return nil
+}
+
+// StopRun은 지정한 run만 중지한다
+func (e *Engine) StopRun(runID string) error {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return err
+	}
+
+	e.stopRun(run)
+	return nil
+}
+
+func (e *Engine) stopRun(run *scenarioRun) {
+	run.mu.Lock()
+	cancelFunc := run.cancel
+	run.terminal = terminalStateStopped
+	run.mu.Unlock()
 
 	if cancelFunc != nil {
 		cancelFunc()
 	}
 
 	select {
-	case <-runDone:
+	case <-run.done:
 	case <-time.After(10 * time.Second):
-		e.emitActionLog("", "", "StopScenario timeout - forced shutdown", "warn")
+		e.emitActionLog("", "", "StopScenario timeout - forced shutdown", "warn", WithRunID(run.id))
 	}
-
-	return nil
 }
 
-// cleanupOnError는 에러 발생 시 리소스를 정리한다
-func (e *Engine) cleanupOnError() {
-	e.im.Cleanup()
-	e.mu.Lock()
-	e.running = false
-	e.scenarioID = ""
-	e.cancelFunc = nil
-	e.runDone = nil
-	e.terminal = ""
-	e.mu.Unlock()
+// cleanupOnError는 시작 중 에러 발생 시 run의 리소스를 정리한다
+func (e *Engine) cleanupOnError(run *scenarioRun) {
+	run.im.Cleanup()
+	e.removeRun(run)
+	close(run.done)
 }
 
-// cleanup은 시나리오 실행 종료 후 모든 리소스를 정리한다
-func (e *Engine) cleanup() {
-	e.emitActionLog("", "", "Starting cleanup", "info")
+// cleanup은 run 종료 후 dialog와 UA를 정리한다
+func (e *Engine) cleanup(run *scenarioRun) {
+	e.emitActionLog("", "", "Starting cleanup", "info", WithRunID(run.id))
+
+	run.mu.Lock()
+	executor := run.executor
+	run.mu.Unlock()
 
-	if e.executor != nil {
+	if executor != nil {
+		executor.closeBridges()
+		executor.closeConferences()
+		executor.closeSubscriptions()
+		executor.closeSessionTimers()
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		e.executor.sessions.HangupAll(ctx)
+		executor.sessions.HangupAll(ctx)
 		cancel()
-		e.executor.sessions.CloseAll()
+		executor.sessions.CloseAll()
 	}
 
-	e.im.Cleanup()
-	e.emitActionLog("", "", "Cleanup completed", "info")
+	run.im.Cleanup()
+	e.emitActionLog("", "", "Cleanup completed", "info", WithRunID(run.id))
 
-	e.mu.Lock()
-	e.executor = nil
-	e.mu.Unlock()
+	run.mu.Lock()
+	run.executor = nil
+	run.mu.Unlock()
 }
 
-func (e *Engine) markTerminalState(next scenarioTerminalState) {
+// IsRunning은 실행 중인 run이 하나라도 있는지 확인한다
+func (e *Engine) IsRunning() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-
-	switch e.terminal {
-	case terminalStateStopped, terminalStateFailed:
-		return
-	case terminalStateRunning:
-		e.terminal = next
-	}
+	return len(e.runs) > 0
 }
 
-// emitSIPEvent는 SessionStore의 SIP 이벤트 버스를 통해 이벤트를 전달한다
-func (e *Engine) emitSIPEvent(instanceID string, eventType eventhandler.SIPEventType, callID string) {
-	e.mu.Lock()
-	ex := e.executor
-	e.mu.Unlock()
-	if ex != nil {
-		ex.sessions.EmitSIPEvent(instanceID, eventType, callID)
+// LocalPBXBindings는 run의 내장 PBX에 등록된 DN 목록을 반환한다 (내장 PBX를 쓰지 않는 run은 빈 목록)
+func (e *Engine) LocalPBXBindings(runID string) ([]PBXBinding, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
 	}
+	bindings := run.im.LocalPBXBindings()
+	if bindings == nil {
+		bindings = []PBXBinding{}
+	}
+	return bindings, nil
 }
 
-// IsRunning은 시나리오가 실행 중인지 확인한다
-func (e *Engine) IsRunning() bool {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	return e.running
-}
-
-// GetInstanceManager는 InstanceManager를 반환한다
-func (e *Engine) GetInstanceManager() *InstanceManager {
-	return e.im
+// GetInstanceManager는 run의 InstanceManager를 반환한다
+func (e *Engine) GetInstanceManager(runID string) (*InstanceManager, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	return run.im, nil
 }
@@ -15,6 +15,8 @@ const (
 	EventCompleted  = "scenario:completed"
 	EventFailed     = "scenario:failed"
 	EventStopped    = "scenario:stopped"
+	EventPaused     = "scenario:paused"
+	EventResumed    = "scenario:resumed"
 )
 
 // 노드 상태 상수
@@ -23,6 +25,8 @@ const (
 	NodeStateRunning   = "running"
 	NodeStateCompleted = "completed"
 	NodeStateFailed    = "failed"
+	NodeStateSkipped   = "skipped" // 부분 실행에서 실행 대상이 아닌 노드
+	NodeStateRetrying  = "retrying" // 실패 후 재시도 대기 중
 )
 
 // EventEmitter는 이벤트 발행을 추상화한다.
@@ -43,9 +47,10 @@ func (we *WailsEventEmitter) Emit(eventName string, data map[string]interface{})
 }
 
 // emitNodeState는 노드 상태 변경 이벤트를 발행한다
-func (e *Engine) emitNodeState(nodeID, prevState, newState string) {
+func (e *Engine) emitNodeState(runID, nodeID, prevState, newState string) {
 	if e.emitter != nil {
 		e.emitter.Emit(EventNodeState, map[string]interface{}{
+			"runId":         runID,
 			"nodeId":        nodeID,
 			"previousState": prevState,
 			"newState":      newState,
@@ -54,9 +59,35 @@ func (e *Engine) emitNodeState(nodeID, prevState, newState string) {
 	}
 }
 
+// emitNodeRetrying은 노드 시도 실패 후 재시도 대기 상태를 발행한다
+func (e *Engine) emitNodeRetrying(runID, nodeID string, attempt, maxAttempts int, cause error, delay time.Duration) {
+	if e.emitter != nil {
+		e.emitter.Emit(EventNodeState, map[string]interface{}{
+			"runId":         runID,
+			"nodeId":        nodeID,
+			"previousState": NodeStateRunning,
+			"newState":      NodeStateRetrying,
+			"attempt":       attempt,
+			"maxAttempts":   maxAttempts,
+			"error":         cause.Error(),
+			"retryInMs":     delay.Milliseconds(),
+			"timestamp":     time.Now().UnixMilli(),
+		})
+	}
+}
+
 // ActionLogOption은 emitActionLog의 functional option이다
 type ActionLogOption func(data map[string]interface{})
 
+// WithRunID는 이벤트가 속한 run ID를 액션 로그에 기록한다.
+func WithRunID(runID string) ActionLogOption {
+	return func(data map[string]interface{}) {
+		if runID != "" {
+			data["runId"] = runID
+		}
+	}
+}
+
 // WithCallID는 logical call ID를 액션 로그 최상위 필드와 SIP 메시지 상세 정보에 기록한다.
 func WithCallID(callID string) ActionLogOption {
 	return func(data map[string]interface{}) {
@@ -115,9 +146,10 @@ func (e *Engine) emitActionLog(nodeID, instanceID, message, level string, opts .
 }
 
 // emitScenarioStarted는 시나리오 시작 이벤트를 발행한다
-func (e *Engine) emitScenarioStarted(scenarioID string) {
+func (e *Engine) emitScenarioStarted(runID, scenarioID string) {
 	if e.emitter != nil {
 		e.emitter.Emit(EventStarted, map[string]interface{}{
+			"runId":      runID,
 			"scenarioId": scenarioID,
 			"timestamp":  time.Now().UnixMilli(),
 		})
@@ -125,9 +157,10 @@ func (e *Engine) emitScenarioStarted(scenarioID string) {
 }
 
 // emitScenarioCompleted는 시나리오 완료 이벤트를 발행한다
-func (e *Engine) emitScenarioCompleted(scenarioID string) {
+func (e *Engine) emitScenarioCompleted(runID, scenarioID string) {
 	if e.emitter != nil {
 		e.emitter.Emit(EventCompleted, map[string]interface{}{
+			"runId":      runID,
 			"scenarioId": scenarioID,
 			"timestamp":  time.Now().UnixMilli(),
 		})
@@ -135,9 +168,10 @@ func (e *Engine) emitScenarioCompleted(scenarioID string) {
 }
 
 // emitScenarioFailed는 시나리오 실패 이벤트를 발행한다
-func (e *Engine) emitScenarioFailed(errMsg string) {
+func (e *Engine) emitScenarioFailed(runID, errMsg string) {
 	if e.emitter != nil {
 		e.emitter.Emit(EventFailed, map[string]interface{}{
+			"runId":     runID,
 			"error":     errMsg,
 			"timestamp": time.Now().UnixMilli(),
 		})
@@ -145,9 +179,34 @@ func (e *Engine) emitScenarioFailed(errMsg string) {
 }
 
 // emitScenarioStopped는 시나리오 중지 이벤트를 발행한다
-func (e *Engine) emitScenarioStopped() {
+func (e *Engine) emitScenarioStopped(runID string) {
 	if e.emitter != nil {
 		e.emitter.Emit(EventStopped, map[string]interface{}{
+			"runId":     runID,
+			"timestamp": time.Now().UnixMilli(),
+		})
+	}
+}
+
+// emitScenarioPaused는 디버거 일시정지 이벤트를 발행한다
+func (e *Engine) emitScenarioPaused(runID string, point DebugPausePoint) {
+	if e.emitter != nil {
+		e.emitter.Emit(EventPaused, map[string]interface{}{
+			"runId":      runID,
+			"nodeId":     point.NodeID,
+			"instanceId": point.InstanceID,
+			"reason":     point.Reason,
+			"timestamp":  time.Now().UnixMilli(),
+		})
+	}
+}
+
+// emitScenarioResumed는 디버거 재개 이벤트를 발행한다
+func (e *Engine) emitScenarioResumed(runID string, step bool) {
+	if e.emitter != nil {
+		e.emitter.Emit(EventResumed, map[string]interface{}{
+			"runId":     runID,
+			"step":      step,
 			"timestamp": time.Now().UnixMilli(),
 		})
 	}
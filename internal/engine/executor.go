@@ -7,8 +7,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/emiago/diago"
@@ -125,12 +127,15 @@ func callIDOrDefault(node *GraphNode) string {
 
 func (ex *Executor) emitNodeActionLog(node *GraphNode, instanceID, message, level string, opts ...ActionLogOption) {
 	if node == nil {
-		ex.engine.emitActionLog("", instanceID, message, level, opts...)
+		ex.engine.emitActionLog("", instanceID, message, level, append(opts, WithRunID(ex.runID))...)
+		return
+	}
+	if ex.quiet[node.ID] && level != "error" {
 		return
 	}
 
 	mergedOpts := append([]ActionLogOption{}, opts...)
-	mergedOpts = append(mergedOpts, WithCallID(callIDOrDefault(node)))
+	mergedOpts = append(mergedOpts, WithCallID(callIDOrDefault(node)), WithRunID(ex.runID))
 	ex.engine.emitActionLog(node.ID, instanceID, message, level, mergedOpts...)
 }
 
@@ -144,6 +149,34 @@ func (ss *SessionStore) CloseAll() {
 	}
 }
 
+// Snapshot은 디버거 조회용으로 저장된 dialog 목록을 인스턴스/callID 순으로 반환한다
+func (ss *SessionStore) Snapshot() []DebugDialog {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	dialogs := make([]DebugDialog, 0, len(ss.dialogs))
+	for key, dialog := range ss.dialogs {
+		instanceID, callID, _ := strings.Cut(key, ":")
+		state := "active"
+		if dialog.Context().Err() != nil {
+			state = "terminated"
+		}
+		dialogs = append(dialogs, DebugDialog{
+			InstanceID: instanceID,
+			CallID:     callID,
+			SIPCallID:  ss.sipCallMappings[key],
+			State:      state,
+		})
+	}
+	sort.Slice(dialogs, func(i, j int) bool {
+		if dialogs[i].InstanceID != dialogs[j].InstanceID {
+			return dialogs[i].InstanceID < dialogs[j].InstanceID
+		}
+		return dialogs[i].CallID < dialogs[j].CallID
+	})
+	return dialogs
+}
+
 func (ss *SessionStore) GetSIPCallID(instanceID, callID string) (string, bool) {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
@@ -152,16 +185,22 @@ func (ss *SessionStore) GetSIPCallID(instanceID, callID string) (string, bool) {
 	return sipCallID, exists
 }
 
-func (ss *SessionStore) emitSIPEventBySIPCallID(sipCallID, instanceID string, eventType eventhandler.SIPEventType, logicalCallID string, statusCode int) {
-	if sipCallID == "" {
-		return
-	}
+// FindCallID는 인스턴스에서 SIP Call-ID에 매핑된 logical call ID를 찾는다
+func (ss *SessionStore) FindCallID(instanceID, sipCallID string) (string, bool) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
 
-	ss.mu.Lock()
-	dispatcher := ss.ensureDispatcherLocked(sipCallID)
-	ss.mu.Unlock()
+	prefix := instanceID + ":"
+	for key, mapped := range ss.sipCallMappings {
+		if mapped == sipCallID && strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix), true
+		}
+	}
+	return "", false
+}
 
-	dispatcher.Notify(eventhandler.Event{
+func (ss *SessionStore) emitSIPEventBySIPCallID(sipCallID, instanceID string, eventType eventhandler.SIPEventType, logicalCallID string, statusCode int) {
+	ss.notifySIPEvent(eventhandler.Event{
 		Type:          eventType,
 		SIPCallID:     sipCallID,
 		InstanceID:    instanceID,
@@ -170,6 +209,19 @@ func (ss *SessionStore) emitSIPEventBySIPCallID(sipCallID, instanceID string, ev
 	})
 }
 
+// notifySIPEvent는 event.SIPCallID의 dispatcher로 이벤트를 보낸다 (Detail 등 부가 정보 포함)
+func (ss *SessionStore) notifySIPEvent(event eventhandler.Event) {
+	if event.SIPCallID == "" {
+		return
+	}
+
+	ss.mu.Lock()
+	dispatcher := ss.ensureDispatcherLocked(event.SIPCallID)
+	ss.mu.Unlock()
+
+	dispatcher.Notify(event)
+}
+
 // emitSIPEvent는 logical call ID에 매핑된 SIP Call-ID dispatcher로 이벤트를 보낸다.
 func (ss *SessionStore) emitSIPEvent(instanceID, eventType, callID string) {
 	sipCallID, exists := ss.GetSIPCallID(instanceID, callID)
@@ -227,9 +279,42 @@ func (ss *SessionStore) UnsubscribeSIPEventHandler(sipCallID string, listener ev
 
 // Executor는 시나리오 그래프의 노드를 실행한다
 type Executor struct {
-	engine   *Engine          // 이벤트 발행용 부모 참조
-	im       *InstanceManager // UA 조회용
-	sessions *SessionStore    // 활성 세션 저장소
+	engine    *Engine          // 이벤트 발행용 부모 참조
+	runID     string           // 이벤트에 태깅할 run ID
+	im        *InstanceManager // UA 조회용
+	sessions  *SessionStore    // 활성 세션 저장소
+	syncs     *SyncRegistry    // 체인 간 signal/barrier 저장소
+	sim       *simBackend      // dry-run 모드의 시뮬레이션 SIP 백엔드 (nil이면 실제 네트워크 사용)
+	debug     *Debugger        // 브레이크포인트/step 제어 (nil이면 정지 없음)
+	quiet     map[string]bool  // setup prefix 노드 — 노드 상태와 error 외 액션 로그를 발행하지 않음
+	variables VariableScope    // 디버거 조회용 변수 스코프
+
+	nodeRetries atomic.Int64 // 노드 재시도 횟수 (run 결과의 flaky 판단용)
+
+	activeMu sync.Mutex
+	active   map[string]string // 실행 중인 노드 ID -> 인스턴스 ID (watchdog 진단용)
+
+	bridgeMu sync.Mutex
+	bridges  map[string]*mediaBridge // sessionKey -> 연결된 브리지 (양쪽 leg 모두 등록)
+
+	confMu      sync.Mutex
+	conferences map[string]*conference // 인스턴스 ID -> 믹싱 중인 컨퍼런스
+
+	referMu      sync.Mutex
+	referActions map[string]string // sessionKey -> 대기 중인 REFER_RECEIVED 노드의 referAction
+
+	features   FeatureCodes // Park/Unpark/Pickup/SetForwarding이 다이얼할 PBX feature code
+	parkMu     sync.Mutex
+	parkedSlot string // 이 run에서 마지막으로 주차한 slot
+
+	referNotifyMu sync.Mutex
+	referNotifies map[string]chan *sip.Request // SIP Call-ID -> Park가 기다리는 REFER NOTIFY
+
+	subMu         sync.Mutex
+	subscriptions map[string]*subscription // subscriptionKey -> Subscribe로 만든 구독
+
+	timerMu       sync.Mutex
+	sessionTimers map[string]*sessionTimer // sessionKey -> 갱신 중인 RFC 4028 session timer
 }
 
 type answerReferDialog interface {
@@ -254,6 +339,21 @@ func NewExecutor(engine *Engine, im *InstanceManager) *Executor {
 		engine:   engine,
 		im:       im,
 		sessions: NewSessionStore(),
+		syncs:    NewSyncRegistry(),
+		quiet:    make(map[string]bool),
+		active:   make(map[string]string),
+		bridges:  make(map[string]*mediaBridge),
+
+		conferences: make(map[string]*conference),
+
+		referActions: make(map[string]string),
+
+		features:      defaultFeatureCodes,
+		referNotifies: make(map[string]chan *sip.Request),
+
+		subscriptions: make(map[string]*subscription),
+
+		sessionTimers: make(map[string]*sessionTimer),
 	}
 }
 
@@ -269,6 +369,13 @@ func (ex *Executor) ExecuteChain(ctx context.Context, instanceID string, startNo
 		default:
 		}
 
+		// 브레이크포인트/일시정지 확인 (노드 경계에서만 정지)
+		if ex.debug != nil {
+			if err := ex.debug.checkpoint(ctx, instanceID, currentNode); err != nil {
+				return err
+			}
+		}
+
 		// 현재 노드 실행
 		err := ex.executeNode(ctx, instanceID, currentNode)
 		if err != nil {
@@ -288,34 +395,78 @@ func (ex *Executor) ExecuteChain(ctx context.Context, instanceID string, startNo
 	return nil
 }
 
-// executeNode는 단일 노드를 실행한다
+// executeNode는 단일 노드를 실행한다. 노드에 재시도 정책이 있으면 실패 시 backoff 후 다시 실행한다.
 func (ex *Executor) executeNode(ctx context.Context, instanceID string, node *GraphNode) error {
+	quiet := ex.quiet[node.ID]
+
+	ex.activeMu.Lock()
+	ex.active[node.ID] = instanceID
+	ex.activeMu.Unlock()
+	defer func() {
+		ex.activeMu.Lock()
+		delete(ex.active, node.ID)
+		ex.activeMu.Unlock()
+	}()
+
 	// 노드 상태를 "running"으로 변경
-	ex.engine.emitNodeState(node.ID, NodeStatePending, NodeStateRunning)
+	if !quiet {
+		ex.engine.emitNodeState(ex.runID, node.ID, NodeStatePending, NodeStateRunning)
+	}
 
+	maxAttempts := node.Retry.attempts()
 	var err error
-	switch node.Type {
-	case "command":
-		err = ex.executeCommand(ctx, instanceID, node)
-	case "event":
-		err = ex.executeEvent(ctx, instanceID, node)
-	default:
-		err = nil // unknown type은 무시 (향후 확장)
+	for attempt := 1; ; attempt++ {
+		err = ex.executeNodeOnce(ctx, instanceID, node)
+		if err == nil || attempt >= maxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		delay := node.Retry.delay(attempt)
+		ex.nodeRetries.Add(1)
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Attempt %d/%d failed: %v (retrying in %s)", attempt, maxAttempts, err, delay), "warn")
+		if !quiet {
+			ex.engine.emitNodeRetrying(ex.runID, node.ID, attempt, maxAttempts, err, delay)
+		}
+		if sleepContext(ctx, delay) != nil {
+			break
+		}
+		if !quiet {
+			ex.engine.emitNodeState(ex.runID, node.ID, NodeStateRetrying, NodeStateRunning)
+		}
+	}
+
+	if quiet {
+		return err
 	}
 
 	if err != nil {
 		// 실패 이벤트 발행
-		ex.engine.emitNodeState(node.ID, NodeStateRunning, NodeStateFailed)
+		ex.engine.emitNodeState(ex.runID, node.ID, NodeStateRunning, NodeStateFailed)
 		return err
 	}
 
 	// 성공 이벤트 발행
-	ex.engine.emitNodeState(node.ID, NodeStateRunning, NodeStateCompleted)
+	ex.engine.emitNodeState(ex.runID, node.ID, NodeStateRunning, NodeStateCompleted)
 	return nil
 }
 
+func (ex *Executor) executeNodeOnce(ctx context.Context, instanceID string, node *GraphNode) error {
+	switch node.Type {
+	case "command":
+		return ex.executeCommand(ctx, instanceID, node)
+	case "event":
+		return ex.executeEvent(ctx, instanceID, node)
+	default:
+		return nil // unknown type은 무시 (향후 확장)
+	}
+}
+
 // executeCommand는 Command 노드를 실행한다
 func (ex *Executor) executeCommand(ctx context.Context, instanceID string, node *GraphNode) error {
+	if ex.sim != nil && ex.sim.handles(node) {
+		return ex.sim.executeCommand(ctx, ex, instanceID, node)
+	}
+
 	switch node.Command {
 	case string(SIPCommandMakeCall):
 		return ex.executeMakeCall(ctx, instanceID, node)
@@ -335,6 +486,34 @@ func (ex *Executor) executeCommand(ctx context.Context, instanceID string, node
 		return ex.executeBlindTransfer(ctx, instanceID, node)
 	case string(SIPCommandMuteTransfer):
 		return ex.executeMuteTransfer(ctx, instanceID, node)
+	case string(SIPCommandBridge):
+		return ex.executeBridge(ctx, instanceID, node)
+	case string(SIPCommandUnbridge):
+		return ex.executeUnbridge(ctx, instanceID, node)
+	case string(SIPCommandConference):
+		return ex.executeConference(ctx, instanceID, node)
+	case string(SIPCommandPark):
+		return ex.executePark(ctx, instanceID, node)
+	case string(SIPCommandUnpark):
+		return ex.executeUnpark(ctx, instanceID, node)
+	case string(SIPCommandPickup):
+		return ex.executePickup(ctx, instanceID, node)
+	case string(SIPCommandSetForwarding):
+		return ex.executeSetForwarding(ctx, instanceID, node)
+	case string(SIPCommandSubscribe):
+		return ex.executeSubscribe(ctx, instanceID, node)
+	case string(SIPCommandUnsubscribe):
+		return ex.executeUnsubscribe(ctx, instanceID, node)
+	case string(SIPCommandSendMessage):
+		return ex.executeSendMessage(ctx, instanceID, node)
+	case string(SIPCommandSendOptions):
+		return ex.executeSendOptions(ctx, instanceID, node)
+	case string(SIPCommandReInvite), string(SIPCommandUpdate):
+		return ex.executeMediaUpdate(ctx, instanceID, node)
+	case SyncCommandSignal:
+		return ex.executeSignal(ctx, instanceID, node)
+	case CommandCallScenario:
+		return ex.executeCallScenario(ctx, instanceID, node)
 	default:
 		return fmt.Errorf("unknown command: %s", node.Command)
 	}
@@ -350,47 +529,25 @@ func (ex *Executor) executeMakeCall(ctx context.Context, instanceID string, node
 		return fmt.Errorf("MakeCall requires a targetUri")
 	}
 
-	resolvedTargetURI, err := ex.im.ResolveTarget(node.TargetURI)
-	if err != nil {
-		return fmt.Errorf("failed to resolve target %q: %w", node.TargetURI, err)
-	}
-	if !strings.HasPrefix(resolvedTargetURI, "sip:") {
-		return fmt.Errorf("resolved targetUri must start with sip: scheme")
-	}
-
-	// URI 파싱
-	var recipient sip.Uri
-	if err := sip.ParseUri(resolvedTargetURI, &recipient); err != nil {
-		return fmt.Errorf("invalid targetUri %q: %w", resolvedTargetURI, err)
-	}
-
-	// 인스턴스 조회
-	instance, err := ex.im.GetInstance(instanceID)
-	if err != nil {
-		return fmt.Errorf("failed to get instance: %w", err)
-	}
-
 	// 타임아웃 설정 (기본 30초)
 	timeout := 30 * time.Second
 	if node.Timeout > 0 {
 		timeout = node.Timeout
 	}
-	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
 
-	// Invite 호출
-	dialog, err := instance.UA.Invite(timeoutCtx, recipient, diago.InviteOptions{})
+	dialog, recipient, resolvedTargetURI, err := ex.inviteTarget(ctx, instanceID, node.TargetURI, timeout, diago.InviteOptions{})
 	if err != nil {
-		return fmt.Errorf("Invite failed: %w", err)
+		return err
 	}
 
 	// Dialog 저장
 	ex.sessions.StoreDialog(instanceID, callIDOrDefault(node), dialog)
+	ex.startSessionTimer(instanceID, callIDOrDefault(node), node, dialog, SessionRefresherUAC)
 
 	// 성공 로그 (SIP 메시지 상세 정보 포함)
 	// Note: diago DialogSession 인터페이스에서 Call-ID 접근이 제한되어 빈 문자열 사용
-	fromURI := instance.Config.DN // 발신자는 인스턴스의 DN
-	toURI := recipient.User       // 수신자는 TargetURI의 User
+	fromURI := ex.instanceDN(instanceID) // 발신자는 인스턴스의 DN
+	toURI := recipient.User              // 수신자는 TargetURI의 User
 	successMessage := "MakeCall succeeded"
 	if node.TargetURI != resolvedTargetURI {
 		successMessage = fmt.Sprintf("MakeCall succeeded (%s -> %s)", node.TargetURI, resolvedTargetURI)
@@ -400,6 +557,40 @@ func (ex *Executor) executeMakeCall(ctx context.Context, instanceID string, node
 	return nil
 }
 
+// inviteTarget은 target(DN 또는 sip: URI)을 인스턴스 기준으로 해석해 INVITE를 보내고 응답한 dialog를 반환한다
+func (ex *Executor) inviteTarget(ctx context.Context, instanceID, target string, timeout time.Duration, opts diago.InviteOptions) (*diago.DialogClientSession, sip.Uri, string, error) {
+	resolvedTargetURI, err := ex.im.ResolveTargetFor(instanceID, target)
+	if err != nil {
+		return nil, sip.Uri{}, "", fmt.Errorf("failed to resolve target %q: %w", target, err)
+	}
+	if !strings.HasPrefix(resolvedTargetURI, "sip:") {
+		return nil, sip.Uri{}, "", fmt.Errorf("resolved targetUri must start with sip: scheme")
+	}
+
+	// URI 파싱
+	var recipient sip.Uri
+	if err := sip.ParseUri(resolvedTargetURI, &recipient); err != nil {
+		return nil, sip.Uri{}, "", fmt.Errorf("invalid targetUri %q: %w", resolvedTargetURI, err)
+	}
+
+	// 인스턴스 조회
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return nil, sip.Uri{}, "", fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Invite 호출 (session timer를 쓰는 인스턴스는 Session-Expires/Min-SE를 함께 보낸다)
+	opts.Headers = append(opts.Headers, sessionTimerHeaders(instance.Config)...)
+	dialog, err := instance.UA.Invite(timeoutCtx, recipient, opts)
+	if err != nil {
+		return nil, sip.Uri{}, "", fmt.Errorf("Invite failed: %w", err)
+	}
+	return dialog, recipient, resolvedTargetURI, nil
+}
+
 // executeAnswer는 Answer 커맨드를 실행한다 (AnswerOptions 기반)
 func (ex *Executor) executeAnswer(ctx context.Context, instanceID string, node *GraphNode) error {
 	// 액션 로그 발행
@@ -417,9 +608,44 @@ func (ex *Executor) executeAnswer(ctx context.Context, instanceID string, node *
 		return fmt.Errorf("dialog for callID %s is %T, not DialogServerSession", callID, dialog)
 	}
 
-	// AnswerOptions 구성 (OnMediaUpdate, OnRefer 콜백 등록)
-	opts := diago.AnswerOptions{
-		// OnMediaUpdate: Hold/Retrieve 감지를 위한 콜백
+	// AnswerOptions 호출 (OnMediaUpdate, OnRefer 콜백 등록, session timer를 쓰면 2xx에 Session-Expires 추가)
+	ex.addSessionTimerAnswerHeaders(instanceID, serverSession)
+	if err := serverSession.AnswerOptions(ex.answerOptions(instanceID, callID, node)); err != nil {
+		// 코덱 협상 실패 감지 (에러 메시지에 "codec" 또는 "media" 관련 문자열 포함 여부)
+		errMsg := err.Error()
+		if strings.Contains(strings.ToLower(errMsg), "codec") ||
+			strings.Contains(strings.ToLower(errMsg), "media") ||
+			strings.Contains(strings.ToLower(errMsg), "negotiat") {
+			// 인스턴스 코덱 정보 조회 (디버깅용)
+			instance, instErr := ex.im.GetInstance(instanceID)
+			if instErr == nil {
+				ex.emitNodeActionLog(node, instanceID,
+					fmt.Sprintf("Instance codecs: %v", instance.Config.Codecs), "debug")
+			}
+			ex.emitNodeActionLog(node, instanceID,
+				fmt.Sprintf("Codec negotiation failed (488 Not Acceptable): %v", err), "error")
+			return fmt.Errorf("codec negotiation failed (488 Not Acceptable): %w", err)
+		}
+		return fmt.Errorf("Answer failed: %w", err)
+	}
+
+	// Server session을 dialog로도 저장
+	ex.sessions.StoreDialog(instanceID, callID, serverSession)
+	ex.startSessionTimer(instanceID, callID, node, serverSession, SessionRefresherUAS)
+
+	// 성공 로그 (SIP 메시지 상세 정보 포함)
+	fromUser := serverSession.FromUser()
+	toUser := serverSession.ToUser()
+	ex.emitNodeActionLog(node, instanceID, "Answer succeeded", "info",
+		WithSIPMessage("received", "INVITE", 200, "", fromUser, toUser))
+	return nil
+}
+
+// answerOptions는 수신 dialog 응답 시 사용할 AnswerOptions를 구성한다 (OnMediaUpdate, OnRefer 콜백 등록).
+// Replaces INVITE로 대체된 dialog도 같은 콜백을 쓴다.
+func (ex *Executor) answerOptions(instanceID, callID string, node *GraphNode) diago.AnswerOptions {
+	return diago.AnswerOptions{
+		// OnMediaUpdate: Hold/Retrieve 및 REINVITE_RECEIVED 감지를 위한 콜백
 		// 반드시 goroutine으로 분리해야 함 — 콜백은 d.mu.Lock() 안에서 호출되므로
 		// 동일 goroutine에서 d.MediaSession()(내부적으로 d.mu.Lock()) 호출 시 데드락 발생
 		OnMediaUpdate: func(d *diago.DialogMedia) {
@@ -439,15 +665,16 @@ func (ex *Executor) executeAnswer(ctx context.Context, instanceID string, node *
 
 				if strings.Contains(localSDP, "a=recvonly") {
 					// 상대방이 Hold 요청 (sendonly) → 우리는 recvonly → HELD 이벤트
-					ex.engine.emitSIPEvent(instanceID, eventhandler.SIPEventHeld, callID)
+					ex.sessions.EmitSIPEvent(instanceID, eventhandler.SIPEventHeld, callID)
 					ex.emitNodeActionLog(node, instanceID, "Call HELD by remote party", "info",
 						WithSIPMessage("received", "INVITE", 200, "", "", "", "recvonly"))
 				} else if strings.Contains(localSDP, "a=sendrecv") {
 					// 상대방이 Retrieve 요청 (sendrecv) → RETRIEVED 이벤트
-					ex.engine.emitSIPEvent(instanceID, eventhandler.SIPEventRetrieved, callID)
+					ex.sessions.EmitSIPEvent(instanceID, eventhandler.SIPEventRetrieved, callID)
 					ex.emitNodeActionLog(node, instanceID, "Call RETRIEVED by remote party", "info",
 						WithSIPMessage("received", "INVITE", 200, "", "", "", "sendrecv"))
 				}
+				ex.notifyReInviteReceived(instanceID, callID, msess.LocalSDP())
 			}()
 		},
 		// OnRefer: 상대방 REFER 수신 시 콜백 (Refer-To URI 추출 + 새 dialog 활성화 + SessionStore 교체)
@@ -455,36 +682,6 @@ func (ex *Executor) executeAnswer(ctx context.Context, instanceID string, node *
 			return ex.handleAnswerRefer(instanceID, callID, node, referDialog)
 		},
 	}
-
-	// AnswerOptions 호출
-	if err := serverSession.AnswerOptions(opts); err != nil {
-		// 코덱 협상 실패 감지 (에러 메시지에 "codec" 또는 "media" 관련 문자열 포함 여부)
-		errMsg := err.Error()
-		if strings.Contains(strings.ToLower(errMsg), "codec") ||
-			strings.Contains(strings.ToLower(errMsg), "media") ||
-			strings.Contains(strings.ToLower(errMsg), "negotiat") {
-			// 인스턴스 코덱 정보 조회 (디버깅용)
-			instance, instErr := ex.im.GetInstance(instanceID)
-			if instErr == nil {
-				ex.emitNodeActionLog(node, instanceID,
-					fmt.Sprintf("Instance codecs: %v", instance.Config.Codecs), "debug")
-			}
-			ex.emitNodeActionLog(node, instanceID,
-				fmt.Sprintf("Codec negotiation failed (488 Not Acceptable): %v", err), "error")
-			return fmt.Errorf("codec negotiation failed (488 Not Acceptable): %w", err)
-		}
-		return fmt.Errorf("Answer failed: %w", err)
-	}
-
-	// Server session을 dialog로도 저장
-	ex.sessions.StoreDialog(instanceID, callID, serverSession)
-
-	// 성공 로그 (SIP 메시지 상세 정보 포함)
-	fromUser := serverSession.FromUser()
-	toUser := serverSession.ToUser()
-	ex.emitNodeActionLog(node, instanceID, "Answer succeeded", "info",
-		WithSIPMessage("received", "INVITE", 200, "", fromUser, toUser))
-	return nil
 }
 
 func referToURIString(referDialog answerReferDialog) string {
@@ -518,9 +715,23 @@ func (ex *Executor) handleAnswerRefer(instanceID, callID string, node *GraphNode
 		receivedMessage = fmt.Sprintf("REFER received with Replaces: Refer-To=%s", referToURIStr)
 	}
 
+	// REFER_RECEIVED 노드가 대기 중이면 그 노드의 수락/거절 설정을 따른다 (기본: 수락)
+	statusCode := 202
+	accepted := ex.referActionFor(instanceID, callID) != ReferActionReject
+	if !accepted {
+		statusCode = 603
+	}
+
 	ex.emitNodeActionLog(node, instanceID,
 		receivedMessage, "info",
-		WithSIPMessage("received", "REFER", 202, "", "", referToURIStr))
+		WithSIPMessage("received", "REFER", statusCode, "", "", referToURIStr))
+	ex.notifyReferReceived(instanceID, callID, referToURIStr, statusCode)
+
+	if !accepted {
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("REFER declined (603): transfer to %s not performed", referToURIStr), "info")
+		return errReferDeclined
+	}
 
 	inviteCtx := referDialog.Context()
 	if err := referDialog.Invite(inviteCtx, diago.InviteClientOptions{}); err != nil {
@@ -535,7 +746,7 @@ func (ex *Executor) handleAnswerRefer(instanceID, callID string, node *GraphNode
 	}
 
 	ex.sessions.StoreDialog(instanceID, callID, referDialog)
-	ex.engine.emitSIPEvent(instanceID, eventhandler.SIPEventTransferred, callID)
+	ex.sessions.EmitSIPEvent(instanceID, eventhandler.SIPEventTransferred, callID)
 
 	successMessage := fmt.Sprintf("TransferEvent: session replaced with new dialog (Refer-To: %s)", referToURIStr)
 	if hasReplaces {
@@ -587,6 +798,10 @@ func (ex *Executor) executeEvent(ctx context.Context, instanceID string, node *G
 	// 액션 로그 발행
 	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Waiting for %s (timeout: %v)", node.Event, timeout), "info")
 
+	if ex.sim != nil && ex.sim.handles(node) {
+		return ex.sim.executeEvent(timeoutCtx, ex, instanceID, node, timeout)
+	}
+
 	switch node.Event {
 	case string(eventhandler.SIPEventIncoming):
 		return ex.executeIncoming(timeoutCtx, instanceID, node, timeout)
@@ -604,6 +819,24 @@ func (ex *Executor) executeEvent(ctx context.Context, instanceID string, node *G
 		return ex.executeWaitSIPEvent(timeoutCtx, instanceID, node, eventhandler.SIPEventRetrieved, timeout)
 	case string(eventhandler.SIPEventTransferred):
 		return ex.executeWaitSIPEvent(timeoutCtx, instanceID, node, eventhandler.SIPEventTransferred, timeout)
+	case string(eventhandler.SIPEventReferReceived):
+		return ex.executeReferReceived(timeoutCtx, instanceID, node, timeout)
+	case string(eventhandler.SIPEventReplaced):
+		return ex.executeReplaced(timeoutCtx, instanceID, node, timeout)
+	case string(eventhandler.SIPEventRefreshFailed):
+		return ex.executeSessionRefreshFailed(timeoutCtx, instanceID, node, timeout)
+	case string(eventhandler.SIPEventReInvite), string(eventhandler.SIPEventUpdate):
+		return ex.executeMediaUpdateReceived(timeoutCtx, instanceID, node, eventhandler.SIPEventType(node.Event), timeout)
+	case SyncEventWaitSignal:
+		return ex.executeWaitSignal(timeoutCtx, instanceID, node, timeout)
+	case SyncEventBarrier:
+		return ex.executeBarrier(timeoutCtx, instanceID, node, timeout)
+	case ServerEventRegisterReceived:
+		return ex.executeRegisterReceived(timeoutCtx, instanceID, node, timeout)
+	case SubscriptionEventNotifyReceived:
+		return ex.executeNotifyReceived(timeoutCtx, instanceID, node, timeout)
+	case MessageEventReceived:
+		return ex.executeMessageReceived(timeoutCtx, instanceID, node, timeout)
 	default:
 		return fmt.Errorf("event type %s is not supported", node.Event)
 	}
@@ -1296,12 +1529,28 @@ func (ex *Executor) executeMuteTransfer(ctx context.Context, instanceID string,
 // executeWaitSIPEvent는 SessionStore SIP 이벤트 버스에서 특정 이벤트를 블로킹 대기한다
 func (ex *Executor) executeWaitSIPEvent(ctx context.Context, instanceID string, node *GraphNode, eventType eventhandler.SIPEventType, timeout time.Duration) error {
 	callID := callIDOrDefault(node)
+	return ex.waitSIPEvent(ctx, instanceID, callID, eventType, timeout, func(event eventhandler.Event) {
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("%s event received (callID: %s, sipCallID: %s)", eventType, callID, event.SIPCallID), "info")
+	})
+}
+
+// waitSIPEvent는 callID dialog의 SIP 이벤트 버스에서 eventType을 한 번 받을 때까지 대기하고 onEvent를 호출한다
+func (ex *Executor) waitSIPEvent(ctx context.Context, instanceID, callID string, eventType eventhandler.SIPEventType, timeout time.Duration, onEvent func(eventhandler.Event)) error {
+	return ex.waitSIPEventMatch(ctx, instanceID, callID, eventType, timeout, func(event eventhandler.Event) bool {
+		onEvent(event)
+		return true
+	})
+}
+
+// waitSIPEventMatch는 match가 true를 반환하는 eventType 이벤트를 받을 때까지 대기한다 (false면 다음 이벤트를 기다린다)
+func (ex *Executor) waitSIPEventMatch(ctx context.Context, instanceID, callID string, eventType eventhandler.SIPEventType, timeout time.Duration, match func(eventhandler.Event) bool) error {
 	handler := eventhandler.NewHandler(4)
 	handler.SetTimer(timeout)
 	handler.SetHandler(eventType, func(handlerCtx context.Context, event eventhandler.Event, done eventhandler.DoneFn) error {
-		ex.emitNodeActionLog(node, instanceID,
-			fmt.Sprintf("%s event received (callID: %s, sipCallID: %s)", eventType, callID, event.SIPCallID), "info")
-		done()
+		if match(event) {
+			done()
+		}
 		return nil
 	})
 	defer handler.Close()
@@ -224,7 +224,7 @@ func newTestExecutor(t *testing.T) (*Executor, *TestEventEmitter) {
 	te := &TestEventEmitter{}
 	eng.SetEventEmitter(te)
 
-	executor := NewExecutor(eng, eng.im)
+	executor := NewExecutor(eng, NewInstanceManager())
 	return executor, te
 }
 
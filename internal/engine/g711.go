@@ -0,0 +1,94 @@
+package engine
+
+// G.711 µ-law/A-law 변환 (ITU-T G.711 참조 구현). 컨퍼런스 믹싱에서 payload를 16-bit PCM으로 풀고 다시 인코딩하는 데 쓴다.
+
+const (
+	ulawBias = 0x84
+	ulawClip = 32635
+)
+
+var alawSegEnd = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+
+func ulawDecode(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0f) << 3) + ulawBias
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(ulawBias - t)
+	}
+	return int16(t - ulawBias)
+}
+
+func ulawEncode(sample int16) byte {
+	v := int(sample)
+	var sign byte
+	if v < 0 {
+		v = -v
+		sign = 0x80
+	}
+	if v > ulawClip {
+		v = ulawClip
+	}
+	v += ulawBias
+
+	exp := byte(7)
+	for mask := 0x4000; v&mask == 0 && exp > 0; mask >>= 1 {
+		exp--
+	}
+	mantissa := byte(v>>(exp+3)) & 0x0f
+	return ^(sign | exp<<4 | mantissa)
+}
+
+func alawDecode(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0f) << 4
+	switch seg := (a & 0x70) >> 4; seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+func alawEncode(sample int16) byte {
+	v := int(sample) >> 3
+	mask := byte(0xD5)
+	if v < 0 {
+		mask = 0x55
+		v = -v - 1
+	}
+
+	seg := 0
+	for seg < len(alawSegEnd) && v > alawSegEnd[seg] {
+		seg++
+	}
+	if seg >= len(alawSegEnd) {
+		return 0x7F ^ mask
+	}
+
+	aval := byte(seg << 4)
+	if seg < 2 {
+		aval |= byte(v>>1) & 0x0f
+	} else {
+		aval |= byte(v>>seg) & 0x0f
+	}
+	return aval ^ mask
+}
+
+// g711Codec은 코덱 이름에 맞는 디코더/인코더를 반환한다 (PCMU/PCMA 외에는 false)
+func g711Codec(name string) (func(byte) int16, func(int16) byte, bool) {
+	switch name {
+	case "PCMU":
+		return ulawDecode, ulawEncode, true
+	case "PCMA":
+		return alawDecode, alawEncode, true
+	}
+	return nil, nil, false
+}
@@ -3,14 +3,20 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"slices"
 	"time"
+
+	"sipflow/internal/pkg/eventhandler"
 )
 
 // FlowData는 프론트엔드에서 저장하는 JSON 구조를 파싱하기 위한 타입
 type FlowData struct {
-	Nodes []FlowNode
-	Edges []FlowEdge
+	Nodes         []FlowNode
+	Edges         []FlowEdge
+	Variables     map[string]interface{} // fragment 변수 선언 및 기본값 (CallScenario params로 바인딩)
+	MaxDurationMs float64                // 시나리오 최대 실행 시간 (0이면 제한 없음, CallScenario fragment의 값은 무시)
+	LocalPBX      bool                   // run마다 내장 registrar/proxy를 띄워 모든 인스턴스의 PBX로 사용
 }
 
 // FlowNode는 JSON 노드 표현
@@ -35,20 +41,51 @@ type GraphNode struct {
 	Type           string // command|event
 	InstanceID     string
 	CallID         string
-	Command        string                 // MakeCall|Answer|Release|PlayAudio|SendDTMF|Hold|Retrieve|BlindTransfer|MuteTransfer (command 노드 전용)
+	Command        string                 // MakeCall|Answer|Release|PlayAudio|SendDTMF|Hold|Retrieve|BlindTransfer|MuteTransfer|Bridge|Unbridge|Conference|Park|Unpark|Pickup|SetForwarding|Subscribe|Unsubscribe|SendMessage|SendOptions|ReInvite|Update|Signal|CallScenario (command 노드 전용)
 	TargetURI      string                 // MakeCall 대상 URI (command 노드 전용)
 	FilePath       string                 // PlayAudio WAV 파일 경로 (command 노드 전용)
 	Digits         string                 // SendDTMF 전송할 DTMF digit 문자열 (command 노드 전용)
 	IntervalMs     float64                // SendDTMF digit 간 전송 간격 ms (command 노드 전용)
-	Event          string                 // INCOMING|DISCONNECTED|RINGING|TIMEOUT|DTMFReceived|HELD|RETRIEVED|TRANSFERRED (event 노드 전용)
+	Event          string                 // INCOMING|DISCONNECTED|RINGING|TIMEOUT|DTMFReceived|HELD|RETRIEVED|TRANSFERRED|REFER_RECEIVED|REPLACED|SESSION_REFRESH_FAILED|REINVITE_RECEIVED|UPDATE_RECEIVED|WaitSignal|Barrier|REGISTER_RECEIVED|NOTIFY_RECEIVED|MESSAGE_RECEIVED (event 노드 전용)
 	ExpectedDigit  string                 // DTMFReceived 대기할 특정 digit (event 노드 전용)
 	IncomingNumber string                 // INCOMING 대기 번호 (event 노드 전용)
+	RegisterNumber string                 // REGISTER_RECEIVED 대기할 DN (비면 모든 DN, event 노드 전용)
 	Timeout        time.Duration          // 타임아웃 (기본 10초)
 	TransferTarget string                 // 레거시 (Phase 10 대비)
 	TargetUser     string                 // BlindTransfer 대상 user 부분 (Phase 11)
 	TargetHost     string                 // BlindTransfer 대상 host:port (Phase 11)
 	PrimaryCallID  string                 // MuteTransfer 대상 primary dialog call ID
 	ConsultCallID  string                 // MuteTransfer 대상 consult dialog call ID
+	PeerInstanceID string                 // Bridge 상대 dialog의 인스턴스 (비면 같은 인스턴스)
+	PeerCallID     string                 // Bridge 상대 dialog call ID
+	ConfAction     string                 // Conference 동작 join|leave
+	ConfCallIDs    []string               // Conference 대상 call ID 목록 (비면 CallID)
+	ParkSlot       string                 // Park/Unpark slot (Unpark에서 비면 마지막으로 주차한 slot)
+	PickupTarget   string                 // Pickup 대상 DN
+	PickupMode     string                 // Pickup 방식 featureCode|replaces
+	ForwardType    string                 // SetForwarding 종류 always|busy|noAnswer
+	ForwardEnabled bool                   // SetForwarding 설정(true)/해제(false)
+	ForwardTarget  string                 // SetForwarding 전환 대상 번호
+	ReferAction    string                 // REFER_RECEIVED 응답 방식 accept|reject (event 노드 전용)
+	EventPackage   string                 // Subscribe/Unsubscribe/NOTIFY_RECEIVED 이벤트 패키지 dialog|message-summary|presence
+	SubTarget      string                 // Subscribe/Unsubscribe/NOTIFY_RECEIVED 구독 대상 DN/URI
+	SubExpires     int                    // Subscribe 구독 기간 초 (기본 3600)
+	ExpectedState  string                 // NOTIFY_RECEIVED 대기할 본문 상태 (비면 아무 NOTIFY)
+	ContentType    string                 // SendMessage Content-Type (기본 text/plain)
+	MessageBody    string                 // SendMessage 본문
+	ExpectedStatus int                    // SendOptions 기대 응답 코드 (기본 200, 0이면 아무 최종 응답)
+	MessageFrom    string                 // MESSAGE_RECEIVED 발신자 DN (비면 아무 발신자)
+	MessageMatch   string                 // MESSAGE_RECEIVED 본문 정규식 (비면 아무 본문)
+	MediaCodecs    []string               // ReInvite/Update로 제안할 코덱 목록 (비면 현재 코덱 유지)
+	MediaDirection string                 // ReInvite/Update SDP 방향, REINVITE_RECEIVED/UPDATE_RECEIVED 대기할 상대 방향 (비면 유지/아무 방향)
+	MediaAddress   string                 // ReInvite/Update SDP 미디어 주소 ip 또는 ip:port (비면 유지)
+	WithoutSDP     bool                   // ReInvite/Update를 SDP 없이 전송 (ReInvite는 200의 offer에 ACK로 응답)
+	MediaCodec     string                 // REINVITE_RECEIVED/UPDATE_RECEIVED 대기할 코덱 (비면 아무 코덱)
+	SignalName     string                 // Signal 발행/WaitSignal 대기 신호 이름
+	BarrierName    string                 // Barrier 이름 (event 노드 전용)
+	BarrierParties int                    // Barrier 해제에 필요한 체인 수 (event 노드 전용)
+	SubScenarioRef string                 // CallScenario 참조 대상 (scenario:<id>|fragment:<name>)
+	Retry          RetryPolicy            // 실패 시 노드 재시도 정책 (기본: 재시도 없음)
 	SuccessNext    *GraphNode             // 성공 분기 다음 노드
 	FailureNext    *GraphNode             // 실패 분기 다음 노드
 	Data           map[string]interface{} // 원본 노드 데이터 (executePlayAudio에서 필요)
@@ -66,6 +103,27 @@ type SipInstanceConfig struct {
 	PBXPort                 string
 	PBXTransport            string
 	RegisterIntervalSeconds int
+	ChainDeadline           time.Duration // 이 인스턴스 체인의 최대 실행 시간 (0이면 제한 없음)
+	Role                    string        // InstanceRoleUA|InstanceRoleServer
+	ListenHost              string        // SIP Server가 REGISTER/INVITE를 받을 주소 (server 전용)
+	ListenPort              int           // SIP Server listen 포트 (server 전용)
+	AuthRealm               string        // REGISTER digest realm (server 전용)
+	AuthUsername            string        // REGISTER digest username (비면 등록 DN, server 전용)
+	AuthPassword            string        // REGISTER digest password (비면 인증 없음, server 전용)
+	SessionExpires          int           // RFC 4028 Session-Expires 초 (0이면 session timer 미사용, ua 전용)
+	MinSE                   int           // Min-SE 초 (기본 90)
+	SessionRefresher        string        // SessionRefresherUAC|SessionRefresherUAS (기본 uac)
+	RefreshMethod           string        // SessionRefreshUpdate|SessionRefreshInvite (기본 UPDATE)
+}
+
+const (
+	InstanceRoleUA     = "ua"     // 기본값 — PBX에 등록하고 호를 거는 user agent
+	InstanceRoleServer = "server" // 외부 단말의 REGISTER를 받는 registrar/proxy
+)
+
+// IsServer는 인스턴스가 SIP Server 역할인지 반환한다
+func (c SipInstanceConfig) IsServer() bool {
+	return c.Role == InstanceRoleServer
 }
 
 // InstanceChain은 인스턴스별 실행 체인
@@ -76,22 +134,96 @@ type InstanceChain struct {
 
 // ExecutionGraph는 전체 실행 그래프
 type ExecutionGraph struct {
-	Instances map[string]*InstanceChain // instanceID -> 체인
-	Nodes     map[string]*GraphNode     // nodeID -> 노드
+	Instances   map[string]*InstanceChain // instanceID -> 체인
+	Nodes       map[string]*GraphNode     // nodeID -> 노드
+	MaxDuration time.Duration             // 시나리오 최대 실행 시간 (0이면 제한 없음)
+	LocalPBX    bool                      // 내장 registrar/proxy 사용 여부
+	Variables   VariableScope             // 시나리오 변수와 CallScenario별 fragment 변수
+}
+
+// VariableScope는 실행 그래프의 변수 스코프.
+// 변수는 파싱 시점에 노드 데이터로 바인딩되므로 런타임에 바뀌지 않으며, 디버거 조회에 사용된다.
+type VariableScope struct {
+	Scenario  map[string]interface{}            // 시나리오 변수 (선언 기본값, override)
+	Fragments map[string]map[string]interface{} // CallScenario 노드 ID -> 바인딩된 fragment 변수 (기본값, params)
+}
+
+// Resolve는 nodeID 노드에서 보이는 변수를 반환한다.
+// 시나리오 변수 위에 노드 ID 경로("call-1/call-2/x")의 CallScenario 스코프를 바깥쪽부터 차례로 덮어쓴다.
+func (s VariableScope) Resolve(nodeID string) map[string]interface{} {
+	vars := make(map[string]interface{}, len(s.Scenario))
+	for name, value := range s.Scenario {
+		vars[name] = value
+	}
+	for i := range len(nodeID) {
+		if nodeID[i] != '/' {
+			continue
+		}
+		for name, value := range s.Fragments[nodeID[:i]] {
+			vars[name] = value
+		}
+	}
+	return vars
 }
 
 const defaultCallID = "call-1"
 
 // ParseScenario는 FlowData JSON 문자열을 ExecutionGraph로 변환한다
 func ParseScenario(flowData string) (*ExecutionGraph, error) {
+	return ParseScenarioWithResolver(flowData, nil)
+}
+
+// InstanceDefaults는 sipInstance 노드에서 비워 둔 필드에 적용할 프로젝트 기본값
+type InstanceDefaults struct {
+	PBXHost      string
+	PBXPort      string
+	PBXTransport string
+	Codecs       []string
+}
+
+// ParseOptions는 ParseScenarioWithOptions의 파싱 옵션
+type ParseOptions struct {
+	Resolver  FlowResolver           // CallScenario 전개용 (nil이면 CallScenario 사용 시 에러)
+	Defaults  InstanceDefaults       // 프로젝트 설정에서 가져온 인스턴스 기본값
+	Variables map[string]interface{} // 시나리오 최상위 ${name} 참조에 바인딩할 값 (suite 항목별 override)
+}
+
+// ParseScenarioWithResolver는 CallScenario 노드를 resolver로 조회한 flow로 전개한 뒤 ExecutionGraph로 변환한다
+func ParseScenarioWithResolver(flowData string, resolver FlowResolver) (*ExecutionGraph, error) {
+	return ParseScenarioWithOptions(flowData, ParseOptions{Resolver: resolver})
+}
+
+// ParseScenarioWithOptions는 CallScenario 전개와 인스턴스 기본값 적용을 거쳐 ExecutionGraph로 변환한다
+func ParseScenarioWithOptions(flowData string, opts ParseOptions) (*ExecutionGraph, error) {
 	var flow FlowData
 	if err := json.Unmarshal([]byte(flowData), &flow); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal flowData: %w", err)
 	}
 
+	// override가 주어진 경우에만 최상위 변수를 바인딩한다 (선언된 기본값과 병합)
+	values := make(map[string]interface{}, len(flow.Variables))
+	for name, value := range flow.Variables {
+		values[name] = value
+	}
+	if len(opts.Variables) > 0 {
+		bound, err := bindFragmentVariables(&flow, opts.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind scenario variables: %w", err)
+		}
+		values = bound
+	}
+
+	flow, scope, err := expandCallScenarios(flow, opts.Resolver, nil, values)
+	if err != nil {
+		return nil, err
+	}
+
 	graph := &ExecutionGraph{
-		Instances: make(map[string]*InstanceChain),
-		Nodes:     make(map[string]*GraphNode),
+		Instances:   make(map[string]*InstanceChain),
+		Nodes:       make(map[string]*GraphNode),
+		MaxDuration: time.Duration(flow.MaxDurationMs) * time.Millisecond,
+		LocalPBX:    flow.LocalPBX,
+		Variables:   scope,
 	}
 
 	// 1. sipInstance 노드를 SipInstanceConfig로 변환
@@ -101,18 +233,40 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 		nodeTypeMap[node.ID] = node.Type
 
 		if node.Type == "sipInstance" {
+			role := getStringField(node.Data, "role", InstanceRoleUA)
+			if role != InstanceRoleUA && role != InstanceRoleServer {
+				return nil, fmt.Errorf("instance %s has unsupported role %q", node.ID, role)
+			}
 			config := SipInstanceConfig{
 				ID:                      node.ID,
 				Label:                   getStringField(node.Data, "label", ""),
 				DN:                      getStringField(node.Data, "dn", ""),
-				Register:                getBoolField(node.Data, "register", true),
+				Register:                role == InstanceRoleUA && getBoolField(node.Data, "register", true),
 				Color:                   getStringField(node.Data, "color", ""),
-				Codecs:                  getStringArrayField(node.Data, "codecs", []string{"PCMU", "PCMA"}),
+				Codecs:                  getStringArrayField(node.Data, "codecs", opts.Defaults.codecs()),
 				PBXHost:                 getStringField(node.Data, "pbxHost", ""),
 				PBXPort:                 getStringField(node.Data, "pbxPort", ""),
-				PBXTransport:            getStringField(node.Data, "pbxTransport", "UDP"),
+				PBXTransport:            getStringField(node.Data, "pbxTransport", opts.Defaults.pbxTransport()),
 				RegisterIntervalSeconds: int(getFloatField(node.Data, "registerIntervalSeconds", 300)),
+				ChainDeadline:           time.Duration(getFloatField(node.Data, "chainDeadlineMs", 0)) * time.Millisecond,
+				Role:                    role,
 			}
+			if role == InstanceRoleServer {
+				config.ListenHost = getStringField(node.Data, "listenHost", "0.0.0.0")
+				config.ListenPort = int(getFloatField(node.Data, "listenPort", 5060))
+				config.AuthRealm = getStringField(node.Data, "authRealm", "sipflow")
+				config.AuthUsername = getStringField(node.Data, "authUsername", "")
+				config.AuthPassword = getStringField(node.Data, "authPassword", "")
+			} else {
+				config.SessionExpires = int(getFloatField(node.Data, "sessionExpires", 0))
+				config.MinSE = int(getFloatField(node.Data, "minSE", minSessionExpires))
+				config.SessionRefresher = getStringField(node.Data, "sessionRefresher", SessionRefresherUAC)
+				config.RefreshMethod = getStringField(node.Data, "sessionRefreshMethod", SessionRefreshUpdate)
+				if err := validateSessionTimer(config); err != nil {
+					return nil, fmt.Errorf("instance %s: %w", node.ID, err)
+				}
+			}
+			opts.Defaults.applyTo(&config)
 			graph.Instances[node.ID] = &InstanceChain{
 				Config:     config,
 				StartNodes: []*GraphNode{},
@@ -150,6 +304,7 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 				InstanceID: sipInstanceID,
 				CallID:     getStringField(node.Data, "callId", defaultCallID),
 				Data:       node.Data, // 원본 데이터 저장
+				Retry:      parseRetryPolicy(node.Data),
 			}
 
 			if node.Type == "command" {
@@ -166,6 +321,63 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 				gnode.TargetHost = getStringField(node.Data, "targetHost", "")
 				gnode.PrimaryCallID = getStringField(node.Data, "primaryCallId", "")
 				gnode.ConsultCallID = getStringField(node.Data, "consultCallId", "")
+				gnode.PeerInstanceID = getStringField(node.Data, "peerInstanceId", "")
+				gnode.PeerCallID = getStringField(node.Data, "peerCallId", "")
+				if gnode.Command == string(SIPCommandBridge) && gnode.PeerCallID == "" {
+					return nil, fmt.Errorf("node %s: Bridge requires peerCallId", node.ID)
+				}
+				if gnode.PeerInstanceID != "" {
+					if _, exists := graph.Instances[gnode.PeerInstanceID]; !exists {
+						return nil, fmt.Errorf("node %s references unknown peer instance %s", node.ID, gnode.PeerInstanceID)
+					}
+				}
+				if gnode.Command == string(SIPCommandConference) {
+					gnode.ConfAction = getStringField(node.Data, "conferenceAction", ConferenceActionJoin)
+					if gnode.ConfAction != ConferenceActionJoin && gnode.ConfAction != ConferenceActionLeave {
+						return nil, fmt.Errorf("node %s: unsupported conferenceAction %q", node.ID, gnode.ConfAction)
+					}
+					gnode.ConfCallIDs = getStringArrayField(node.Data, "callIds", nil)
+				}
+				gnode.ParkSlot = getStringField(node.Data, "parkSlot", "")
+				if gnode.Command == string(SIPCommandPickup) {
+					gnode.PickupTarget = getStringField(node.Data, "pickupTarget", "")
+					gnode.PickupMode = getStringField(node.Data, "pickupMode", PickupModeFeatureCode)
+					if gnode.PickupMode != PickupModeFeatureCode && gnode.PickupMode != PickupModeReplaces {
+						return nil, fmt.Errorf("node %s: unsupported pickupMode %q", node.ID, gnode.PickupMode)
+					}
+				}
+				if gnode.Command == string(SIPCommandSetForwarding) {
+					gnode.ForwardType = getStringField(node.Data, "forwardType", ForwardTypeAlways)
+					if _, err := defaultFeatureCodes.forwardCode(gnode.ForwardType, true); err != nil {
+						return nil, fmt.Errorf("node %s: %w", node.ID, err)
+					}
+					gnode.ForwardEnabled = getBoolField(node.Data, "forwardEnabled", true)
+					gnode.ForwardTarget = getStringField(node.Data, "forwardTarget", "")
+				}
+				if gnode.Command == string(SIPCommandSubscribe) || gnode.Command == string(SIPCommandUnsubscribe) {
+					if err := parseSubscriptionFields(gnode, node.Data); err != nil {
+						return nil, fmt.Errorf("node %s: %w", node.ID, err)
+					}
+				}
+				if gnode.Command == string(SIPCommandSendMessage) {
+					gnode.ContentType = getStringField(node.Data, "contentType", defaultMessageContentType)
+					gnode.MessageBody = getStringField(node.Data, "body", "")
+				}
+				if gnode.Command == string(SIPCommandSendOptions) {
+					gnode.ExpectedStatus = int(getFloatField(node.Data, "expectedStatus", 200))
+				}
+				if gnode.Command == string(SIPCommandReInvite) || gnode.Command == string(SIPCommandUpdate) {
+					if err := parseMediaUpdateFields(gnode, node.Data); err != nil {
+						return nil, fmt.Errorf("node %s: %w", node.ID, err)
+					}
+				}
+				gnode.SignalName = getStringField(node.Data, "signalName", "")
+				if gnode.Command == SyncCommandSignal && gnode.SignalName == "" {
+					return nil, fmt.Errorf("node %s: Signal requires signalName", node.ID)
+				}
+				if gnode.Command == CommandCallScenario {
+					gnode.SubScenarioRef, _ = callScenarioRef(node.Data)
+				}
 				timeoutMs := getFloatField(node.Data, "timeout", 10000)
 				gnode.Timeout = time.Duration(timeoutMs) * time.Millisecond
 			} else if node.Type == "event" {
@@ -175,6 +387,41 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 				}
 				gnode.ExpectedDigit = getStringField(node.Data, "expectedDigit", "")
 				gnode.IncomingNumber = incomingNumber
+				if gnode.Event == ServerEventRegisterReceived {
+					gnode.RegisterNumber = getStringField(node.Data, "number", "")
+				}
+				if gnode.Event == string(eventhandler.SIPEventReferReceived) {
+					gnode.ReferAction = getStringField(node.Data, "referAction", ReferActionAccept)
+					if gnode.ReferAction != ReferActionAccept && gnode.ReferAction != ReferActionReject {
+						return nil, fmt.Errorf("node %s: unsupported referAction %q", node.ID, gnode.ReferAction)
+					}
+				}
+				if gnode.Event == SubscriptionEventNotifyReceived {
+					if err := parseSubscriptionFields(gnode, node.Data); err != nil {
+						return nil, fmt.Errorf("node %s: %w", node.ID, err)
+					}
+				}
+				if gnode.Event == string(eventhandler.SIPEventReInvite) || gnode.Event == string(eventhandler.SIPEventUpdate) {
+					if err := parseMediaUpdateFields(gnode, node.Data); err != nil {
+						return nil, fmt.Errorf("node %s: %w", node.ID, err)
+					}
+				}
+				if gnode.Event == MessageEventReceived {
+					gnode.MessageFrom = getStringField(node.Data, "messageFrom", "")
+					gnode.MessageMatch = getStringField(node.Data, "messageMatch", "")
+					if _, err := regexp.Compile(gnode.MessageMatch); err != nil {
+						return nil, fmt.Errorf("node %s: invalid messageMatch: %w", node.ID, err)
+					}
+				}
+				gnode.SignalName = getStringField(node.Data, "signalName", "")
+				gnode.BarrierName = getStringField(node.Data, "barrierName", "")
+				gnode.BarrierParties = int(getFloatField(node.Data, "parties", 0))
+				if gnode.Event == SyncEventWaitSignal && gnode.SignalName == "" {
+					return nil, fmt.Errorf("node %s: WaitSignal requires signalName", node.ID)
+				}
+				if gnode.Event == SyncEventBarrier && gnode.BarrierName == "" {
+					return nil, fmt.Errorf("node %s: Barrier requires barrierName", node.ID)
+				}
 				timeoutMs := getFloatField(node.Data, "timeout", 10000)
 				gnode.Timeout = time.Duration(timeoutMs) * time.Millisecond
 			}
@@ -188,13 +435,8 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 		sourceType := nodeTypeMap[edge.Source]
 		targetNode, targetExists := graph.Nodes[edge.Target]
 
-		// failure 분기 판단
-		isFailure := edge.SourceHandle == "failure"
-		if !isFailure {
-			// edge.Data의 branchType도 확인
-			branchType := getStringField(edge.Data, "branchType", "")
-			isFailure = branchType == "failure"
-		}
+		// failure 분기 판단 (sourceHandle 또는 edge.Data의 branchType)
+		isFailure := isFailureEdge(edge)
 
 		if sourceType == "sipInstance" {
 			// sipInstance -> command/event: StartNodes에 추가
@@ -228,14 +470,89 @@ func ParseScenario(flowData string) (*ExecutionGraph, error) {
 		}
 	}
 
+	// REGISTER_RECEIVED는 SIP Server 인스턴스에서만 받을 수 있다
+	for _, node := range graph.Nodes {
+		if node.Event == ServerEventRegisterReceived && !graph.Instances[node.InstanceID].Config.IsServer() {
+			return nil, fmt.Errorf("node %s: %s requires a SIP server instance", node.ID, ServerEventRegisterReceived)
+		}
+	}
+
 	// 5. 검증: 인스턴스가 0개이면 에러
 	if len(graph.Instances) == 0 {
 		return nil, fmt.Errorf("no sipInstance nodes found")
 	}
 
+	// 6. Barrier 참여 체인 수 결정 (parties 미지정 시 같은 이름의 Barrier 노드 수)
+	if err := resolveBarrierParties(graph); err != nil {
+		return nil, err
+	}
+
 	return graph, nil
 }
 
+// resolveBarrierParties는 Barrier 노드의 참여 체인 수를 결정하고 이름별로 일관성을 검증한다
+func resolveBarrierParties(graph *ExecutionGraph) error {
+	counts := make(map[string]int)
+	declared := make(map[string]int)
+	declaredBy := make(map[string]string)
+
+	for _, node := range graph.Nodes {
+		if node.Type != "event" || node.Event != SyncEventBarrier {
+			continue
+		}
+		counts[node.BarrierName]++
+		if node.BarrierParties <= 0 {
+			continue
+		}
+		if existing, ok := declared[node.BarrierName]; ok && existing != node.BarrierParties {
+			return fmt.Errorf("barrier %q has conflicting parties (%s: %d, %s: %d)",
+				node.BarrierName, declaredBy[node.BarrierName], existing, node.ID, node.BarrierParties)
+		}
+		declared[node.BarrierName] = node.BarrierParties
+		declaredBy[node.BarrierName] = node.ID
+	}
+
+	for _, node := range graph.Nodes {
+		if node.Type != "event" || node.Event != SyncEventBarrier {
+			continue
+		}
+		if parties, ok := declared[node.BarrierName]; ok {
+			node.BarrierParties = parties
+		} else {
+			node.BarrierParties = counts[node.BarrierName]
+		}
+	}
+
+	return nil
+}
+
+// applyTo는 노드에서 빈 문자열로 남겨 둔 PBX 필드를 프로젝트 기본값으로 채운다
+func (d InstanceDefaults) applyTo(config *SipInstanceConfig) {
+	if config.PBXHost == "" {
+		config.PBXHost = d.PBXHost
+	}
+	if config.PBXPort == "" {
+		config.PBXPort = d.PBXPort
+	}
+	if config.PBXTransport == "" {
+		config.PBXTransport = d.pbxTransport()
+	}
+}
+
+func (d InstanceDefaults) codecs() []string {
+	if len(d.Codecs) > 0 {
+		return d.Codecs
+	}
+	return []string{"PCMU", "PCMA"}
+}
+
+func (d InstanceDefaults) pbxTransport() string {
+	if d.PBXTransport != "" {
+		return d.PBXTransport
+	}
+	return "UDP"
+}
+
 // getStringField는 map[string]interface{}에서 안전하게 string 값을 추출한다
 func getStringField(data map[string]interface{}, key, defaultVal string) string {
 	if val, ok := data[key]; ok {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -1305,3 +1306,119 @@ func TestParseScenario_InstanceNodeIDUsesInternalKey(t *testing.T) {
 		t.Fatalf("expected command InstanceID to be inst-a, got %s", graph.Nodes["cmd-makecall"].InstanceID)
 	}
 }
+
+func TestParseScenario_SyncNodes(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"label": "A", "dn": "100"}},
+    {"id": "inst-b", "type": "sipInstance", "data": {"label": "B", "dn": "200"}},
+    {"id": "cmd-signal", "type": "command", "data": {"command": "Signal", "sipInstanceId": "inst-a", "signalName": "a-ready"}},
+    {"id": "evt-wait", "type": "event", "data": {"event": "WaitSignal", "sipInstanceId": "inst-b", "signalName": "a-ready", "timeout": 5000}},
+    {"id": "evt-barrier-a", "type": "event", "data": {"event": "Barrier", "sipInstanceId": "inst-a", "barrierName": "idle"}},
+    {"id": "evt-barrier-b", "type": "event", "data": {"event": "Barrier", "sipInstanceId": "inst-b", "barrierName": "idle"}}
+  ],
+  "edges": []
+}`
+
+	graph, err := ParseScenario(flowJSON)
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+
+	if got := graph.Nodes["cmd-signal"].SignalName; got != "a-ready" {
+		t.Errorf("expected Signal signalName a-ready, got %q", got)
+	}
+	wait := graph.Nodes["evt-wait"]
+	if wait.SignalName != "a-ready" || wait.Timeout != 5*time.Second {
+		t.Errorf("unexpected WaitSignal fields: name=%q timeout=%v", wait.SignalName, wait.Timeout)
+	}
+	// parties 미지정 시 같은 이름의 Barrier 노드 수로 결정된다
+	for _, id := range []string{"evt-barrier-a", "evt-barrier-b"} {
+		if got := graph.Nodes[id].BarrierParties; got != 2 {
+			t.Errorf("%s: expected 2 barrier parties, got %d", id, got)
+		}
+	}
+}
+
+func TestParseScenario_SyncNodeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     string
+		extra    string
+		contains string
+	}{
+		{
+			name:     "signal without name",
+			node:     `{"id": "n1", "type": "command", "data": {"command": "Signal", "sipInstanceId": "inst-a"}}`,
+			contains: "Signal requires signalName",
+		},
+		{
+			name:     "wait signal without name",
+			node:     `{"id": "n1", "type": "event", "data": {"event": "WaitSignal", "sipInstanceId": "inst-a"}}`,
+			contains: "WaitSignal requires signalName",
+		},
+		{
+			name:     "barrier without name",
+			node:     `{"id": "n1", "type": "event", "data": {"event": "Barrier", "sipInstanceId": "inst-a"}}`,
+			contains: "Barrier requires barrierName",
+		},
+		{
+			name:     "conflicting barrier parties",
+			node:     `{"id": "n1", "type": "event", "data": {"event": "Barrier", "sipInstanceId": "inst-a", "barrierName": "x", "parties": 2}}`,
+			extra:    `,{"id": "n2", "type": "event", "data": {"event": "Barrier", "sipInstanceId": "inst-a", "barrierName": "x", "parties": 3}}`,
+			contains: "conflicting parties",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flowJSON := `{"nodes": [{"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},` + tt.node + tt.extra + `], "edges": []}`
+			_, err := ParseScenario(flowJSON)
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Fatalf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+// TestParseScenarioWithOptions_InstanceDefaults tests project defaults fill only unset instance fields
+func TestParseScenarioWithOptions_InstanceDefaults(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {
+      "id": "inst-a",
+      "type": "sipInstance",
+      "data": {"label": "A", "dn": "100", "pbxHost": ""}
+    },
+    {
+      "id": "inst-b",
+      "type": "sipInstance",
+      "data": {"label": "B", "dn": "200", "pbxHost": "10.0.0.9", "pbxTransport": "TLS", "codecs": ["PCMU"]}
+    }
+  ],
+  "edges": []
+}`
+
+	graph, err := ParseScenarioWithOptions(flowJSON, ParseOptions{
+		Defaults: InstanceDefaults{PBXHost: "10.0.0.5", PBXPort: "5080", PBXTransport: "TCP", Codecs: []string{"PCMA"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseScenarioWithOptions failed: %v", err)
+	}
+
+	instA := graph.Instances["inst-a"].Config
+	if instA.PBXHost != "10.0.0.5" || instA.PBXPort != "5080" || instA.PBXTransport != "TCP" {
+		t.Errorf("inst-a: expected project PBX defaults, got %s:%s/%s", instA.PBXHost, instA.PBXPort, instA.PBXTransport)
+	}
+	if len(instA.Codecs) != 1 || instA.Codecs[0] != "PCMA" {
+		t.Errorf("inst-a: expected project codecs [PCMA], got %v", instA.Codecs)
+	}
+
+	instB := graph.Instances["inst-b"].Config
+	if instB.PBXHost != "10.0.0.9" || instB.PBXPort != "5080" || instB.PBXTransport != "TLS" {
+		t.Errorf("inst-b: expected node values to win, got %s:%s/%s", instB.PBXHost, instB.PBXPort, instB.PBXTransport)
+	}
+	if len(instB.Codecs) != 1 || instB.Codecs[0] != "PCMU" {
+		t.Errorf("inst-b: expected node codecs [PCMU], got %v", instB.Codecs)
+	}
+}
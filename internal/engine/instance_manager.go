@@ -32,6 +32,10 @@ var newRegisterTransaction = func(ctx context.Context, dg *diago.Diago, recipien
 
 func shouldBindLoopback(config SipInstanceConfig) bool {
 	host := strings.TrimSpace(config.PBXHost)
+	if config.IsServer() {
+		// SIP Server의 UA는 registrar와 같은 인터페이스에서 받는다
+		host = strings.TrimSpace(config.ListenHost)
+	}
 	if host == "" {
 		return true
 	}
@@ -53,6 +57,17 @@ func resolveBindHost(config SipInstanceConfig) string {
 	return "0.0.0.0"
 }
 
+// advertisedHost는 Via/Contact에 쓸 주소를 반환한다. 모든 인터페이스에 바인딩했으면 기본 인터페이스 IP를 쓴다.
+func advertisedHost(bindHost string) string {
+	if ip := net.ParseIP(bindHost); ip == nil || !ip.IsUnspecified() {
+		return bindHost
+	}
+	if ifaceIP, _, err := sip.ResolveInterfacesIP("ip4", nil); err == nil && ifaceIP != nil {
+		return ifaceIP.String()
+	}
+	return "127.0.0.1"
+}
+
 // ManagedInstance는 관리되는 diago SIP UA 인스턴스
 type ManagedInstance struct {
 	Config     SipInstanceConfig
@@ -62,6 +77,9 @@ type ManagedInstance struct {
 	incomingCh chan *diago.DialogServerSession
 	cancel     context.CancelFunc
 	registerTx registerTransaction
+	server     *localPBX            // SIP Server 인스턴스의 registrar/proxy (StartSIPServer에서 설정)
+	registerCh chan pbxRegistration // SIP Server가 받은 REGISTER (REGISTER_RECEIVED 대기용)
+	messageCh  chan receivedMessage // dialog 밖에서 받은 MESSAGE (MESSAGE_RECEIVED 대기용)
 }
 
 // InstanceManager는 diago SIP UA 인스턴스를 생성하고 관리한다
@@ -71,7 +89,17 @@ type InstanceManager struct {
 	dnToID     map[string]string
 	basePort   int
 	nextPort   int
+	maxPort    int // 0이면 상한 없음 — run별 포트 범위를 넘지 않도록 제한
 	maxRetries int
+	pbx        *localPBX // 내장 registrar/proxy (nil이면 사용 안 함)
+
+	replacesHandler func(instanceID string, inDialog *diago.DialogServerSession) // INVITE with Replaces 처리기 (없으면 INCOMING으로 전달)
+
+	// dialog 안 UPDATE 처리기 (없으면 481)
+	updateHandler func(instanceID string, req *sip.Request, tx sip.ServerTransaction)
+
+	// REFER 진행 NOTIFY 관찰자 (응답은 diago가 보낸다)
+	referNotifyHandler func(instanceID string, req *sip.Request)
 }
 
 // NewInstanceManager는 새로운 InstanceManager를 생성한다
@@ -85,6 +113,15 @@ func NewInstanceManager() *InstanceManager {
 	}
 }
 
+// newRangeInstanceManager는 [basePort, basePort+size) 범위의 포트만 사용하는 InstanceManager를 생성한다
+func newRangeInstanceManager(basePort, size int) *InstanceManager {
+	im := NewInstanceManager()
+	im.basePort = basePort
+	im.nextPort = basePort
+	im.maxPort = basePort + size - 1
+	return im
+}
+
 // stringToCodecs는 코덱 이름 문자열 배열을 media.Codec 배열로 변환한다
 func stringToCodecs(codecNames []string) []media.Codec {
 	codecs := make([]media.Codec, 0, len(codecNames)+1)
@@ -158,6 +195,24 @@ func (im *InstanceManager) CreateInstances(graph *ExecutionGraph) error {
 			return fmt.Errorf("failed to create UA for instance %s: %w", instanceID, err)
 		}
 
+		// diago가 처리하지 않는 dialog 밖 MESSAGE는 같은 서버 핸들에서 받는다
+		messageCh := make(chan receivedMessage, 32)
+		srv, err := sipgo.NewServer(ua)
+		if err != nil {
+			for _, inst := range createdInstances {
+				if inst.cancel != nil {
+					inst.cancel()
+				}
+			}
+			return fmt.Errorf("failed to create server for instance %s: %w", instanceID, err)
+		}
+		srv.OnMessage(messageHandler(messageCh))
+		srv.OnOptions(optionsHandler)
+		// re-INVITE와 달리 dialog 안 UPDATE(미디어 변경, session refresh)도 diago가 처리하지 않는다
+		srv.OnUpdate(im.updateRequestHandler(instanceID))
+		// REFER NOTIFY는 diago가 응답하고 sipfrag 상태 코드만 넘겨주므로 transport에서 헤더와 본문을 함께 관찰한다
+		ua.TransportLayer().OnMessage(im.referNotifyTap(instanceID))
+
 		// 코덱 문자열 → media.Codec 변환
 		codecs := stringToCodecs(chain.Config.Codecs)
 
@@ -171,6 +226,7 @@ func (im *InstanceManager) CreateInstances(graph *ExecutionGraph) error {
 			diago.WithMediaConfig(diago.MediaConfig{
 				Codecs: codecs,
 			}),
+			diago.WithServer(srv),
 		)
 
 		// ManagedInstance 생성
@@ -180,13 +236,21 @@ func (im *InstanceManager) CreateInstances(graph *ExecutionGraph) error {
 			SIPUA:      ua,
 			Port:       port,
 			incomingCh: make(chan *diago.DialogServerSession, 4),
+			messageCh:  messageCh,
 			cancel:     nil, // StartServing에서 설정
 		}
+		if chain.Config.IsServer() {
+			managedInst.registerCh = make(chan pbxRegistration, 16)
+		}
 
 		im.instances[instanceID] = managedInst
 		if dn != "" {
 			im.dnToID[dn] = instanceID
 		}
+		if im.pbx != nil && dn != "" && !chain.Config.Register {
+			// 등록하지 않는 인스턴스도 PBX를 통해 호출받을 수 있도록 정적 바인딩을 둔다
+			im.pbx.bindStatic(dn, sip.Uri{Scheme: "sip", User: dn, Host: bindHost, Port: port})
+		}
 		createdInstances = append(createdInstances, managedInst)
 	}
 
@@ -210,6 +274,9 @@ func (im *InstanceManager) allocatePort(transport string, bindHost string) (int,
 
 	for i := 0; i < im.maxRetries; i++ {
 		port := im.nextPort + (i * 2)
+		if im.maxPort > 0 && port > im.maxPort {
+			return 0, fmt.Errorf("port range %d-%d exhausted", im.basePort, im.maxPort)
+		}
 
 		// 포트 가용성 테스트
 		addr := fmt.Sprintf("%s:%d", bindHost, port)
@@ -248,23 +315,127 @@ func (im *InstanceManager) allocatePort(transport string, bindHost string) (int,
 	return 0, fmt.Errorf("failed to allocate port after %d retries", im.maxRetries)
 }
 
+// StartLocalPBX는 포트 범위에서 내장 registrar/proxy를 띄운다.
+// 이후 DN 대상 호출은 인스턴스로 직접 가지 않고 PBX를 거친다. CreateInstances 전에 호출해야 한다.
+func (im *InstanceManager) StartLocalPBX(logf pbxLogFunc) (host string, port int, err error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if im.pbx != nil {
+		return im.pbx.host, im.pbx.port, nil
+	}
+
+	host = "127.0.0.1"
+	port, err = im.allocatePort("udp", host)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to allocate local PBX port: %w", err)
+	}
+	pbx, err := startLocalPBX(host, port, logf)
+	if err != nil {
+		return "", 0, err
+	}
+	im.pbx = pbx
+	return host, port, nil
+}
+
+// LocalPBXBindings는 내장 PBX의 현재 등록 목록을 반환한다 (PBX가 없으면 nil)
+func (im *InstanceManager) LocalPBXBindings() []PBXBinding {
+	im.mu.Lock()
+	pbx := im.pbx
+	im.mu.Unlock()
+
+	if pbx == nil {
+		return nil
+	}
+	return pbx.Bindings()
+}
+
+// SetReplacesHandler는 INVITE with Replaces를 받을 처리기를 설정한다 (executor 생성 후 engine이 설정)
+func (im *InstanceManager) SetReplacesHandler(handler func(instanceID string, inDialog *diago.DialogServerSession)) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.replacesHandler = handler
+}
+
+func (im *InstanceManager) getReplacesHandler() func(instanceID string, inDialog *diago.DialogServerSession) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return im.replacesHandler
+}
+
+// SetUpdateHandler는 dialog 안 UPDATE를 받을 처리기를 설정한다 (executor 생성 후 engine이 설정)
+func (im *InstanceManager) SetUpdateHandler(handler func(instanceID string, req *sip.Request, tx sip.ServerTransaction)) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.updateHandler = handler
+}
+
+// updateRequestHandler는 instanceID 인스턴스 서버에 등록할 UPDATE 핸들러를 만든다
+func (im *InstanceManager) updateRequestHandler(instanceID string) sipgo.RequestHandler {
+	return func(req *sip.Request, tx sip.ServerTransaction) {
+		im.mu.Lock()
+		handler := im.updateHandler
+		im.mu.Unlock()
+		if handler == nil {
+			_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil))
+			return
+		}
+		handler(instanceID, req, tx)
+	}
+}
+
+// SetReferNotifyHandler는 dialog 안 REFER NOTIFY(Event: refer)를 관찰할 처리기를 설정한다 (executor 생성 후 engine이 설정)
+func (im *InstanceManager) SetReferNotifyHandler(handler func(instanceID string, req *sip.Request)) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.referNotifyHandler = handler
+}
+
+// referNotifyTap은 instanceID 인스턴스 transport에 등록할 REFER NOTIFY 관찰자를 만든다.
+// 재전송도 그대로 전달되므로 처리기는 같은 NOTIFY를 여러 번 받을 수 있다.
+func (im *InstanceManager) referNotifyTap(instanceID string) sip.MessageHandler {
+	return func(msg sip.Message) {
+		req, ok := msg.(*sip.Request)
+		if !ok || req.Method != sip.NOTIFY {
+			return
+		}
+		event := req.GetHeader("Event")
+		if event == nil || !strings.EqualFold(strings.TrimSpace(strings.SplitN(event.Value(), ";", 2)[0]), "refer") {
+			return
+		}
+		im.mu.Lock()
+		handler := im.referNotifyHandler
+		im.mu.Unlock()
+		if handler != nil {
+			handler(instanceID, req)
+		}
+	}
+}
+
 // StartServing은 모든 인스턴스의 Serve를 시작한다
 func (im *InstanceManager) StartServing(ctx context.Context) error {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
-	for _, inst := range im.instances {
+	for instanceID, inst := range im.instances {
 		// 각 인스턴스에 대해 별도 cancelable context 생성
 		instCtx, instCancel := context.WithCancel(ctx)
 		inst.cancel = instCancel
 
 		// goroutine으로 Serve 시작 (blocking)
-		go func(i *ManagedInstance, c context.Context) {
+		go func(id string, i *ManagedInstance, c context.Context) {
 			_ = i.UA.Serve(c, func(inDialog *diago.DialogServerSession) {
+				// 기존 dialog를 대체하는 INVITE는 INCOMING이 아니라 Replaces 처리기로 보낸다
+				if inviteReplacesCallID(inDialog) != "" {
+					if handler := im.getReplacesHandler(); handler != nil {
+						handler(id, inDialog)
+						return
+					}
+				}
 				// incoming 이벤트를 채널로 전달
 				i.incomingCh <- inDialog
 			})
-		}(inst, instCtx)
+		}(instanceID, inst, instCtx)
 	}
 
 	return nil
@@ -382,6 +553,14 @@ func (im *InstanceManager) ResolveTarget(target string) (string, error) {
 	defer im.mu.Unlock()
 
 	instanceID, exists := im.dnToID[resolved]
+	if im.pbx != nil {
+		// 내장 PBX가 DN을 라우팅한다 — 미등록 DN은 PBX가 404로 응답
+		uri := fmt.Sprintf("sip:%s@%s", resolved, im.pbx.addr())
+		if inst, ok := im.instances[instanceID]; exists && ok && normalizeTransport(inst.Config.PBXTransport) == "tcp" {
+			uri += ";transport=tcp"
+		}
+		return uri, nil
+	}
 	if !exists {
 		return "", fmt.Errorf("target DN not found: %s", resolved)
 	}
@@ -417,6 +596,16 @@ func (im *InstanceManager) Cleanup() error {
 		if inst.cancel != nil {
 			inst.cancel()
 		}
+		if inst.server != nil {
+			inst.server.close()
+			inst.server = nil
+		}
+	}
+
+	// 등록 해제가 끝난 뒤 내장 PBX 종료
+	if im.pbx != nil {
+		im.pbx.close()
+		im.pbx = nil
 	}
 
 	// 맵 초기화
@@ -430,6 +619,9 @@ func (im *InstanceManager) Cleanup() error {
 
 	im.instances = make(map[string]*ManagedInstance)
 	im.dnToID = make(map[string]string)
+	im.replacesHandler = nil
+	im.updateHandler = nil
+	im.referNotifyHandler = nil
 
 	// nextPort 리셋
 	im.nextPort = im.basePort
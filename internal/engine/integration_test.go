@@ -93,8 +93,7 @@ func newTestEngine(t *testing.T, basePort int) (*Engine, *scenario.Repository, *
 	t.Cleanup(func() { repo.Close() })
 
 	eng := NewEngine(repo)
-	eng.im.basePort = basePort
-	eng.im.nextPort = basePort
+	eng.portBase = basePort
 
 	te := &TestEventEmitter{}
 	eng.SetEventEmitter(te)
@@ -230,7 +229,7 @@ func TestIntegration_TwoPartyCall(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -337,7 +336,7 @@ func TestIntegration_SingleInstance(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -409,7 +408,7 @@ func TestIntegration_EventTimeout(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -484,7 +483,7 @@ func TestIntegration_FailureBranch(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -559,7 +558,7 @@ func TestIntegration_StopScenario(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -595,8 +594,8 @@ func TestIntegration_StopScenario(t *testing.T) {
 	time.Sleep(500 * time.Millisecond)
 }
 
-// TestIntegration_ConcurrentStartPrevention verifies concurrent execution prevention
-func TestIntegration_ConcurrentStartPrevention(t *testing.T) {
+// TestIntegration_ConcurrentStartSameScenario verifies that one scenario can run more than once concurrently
+func TestIntegration_ConcurrentStartSameScenario(t *testing.T) {
 	eng, repo, te := newTestEngine(t, 19060)
 
 	// Scenario: long-running wait
@@ -636,7 +635,8 @@ func TestIntegration_ConcurrentStartPrevention(t *testing.T) {
 	}
 
 	// Start first scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	firstRunID, err := eng.StartScenario(scn.ID)
+	if err != nil {
 		t.Fatalf("First StartScenario failed: %v", err)
 	}
 
@@ -646,22 +646,26 @@ func TestIntegration_ConcurrentStartPrevention(t *testing.T) {
 		t.Fatal("Engine should be running")
 	}
 
-	// Try to start again - should fail
-	err = eng.StartScenario(scn.ID)
-	if err == nil {
-		t.Fatal("Second StartScenario should have failed with 'already running' error")
+	// Start again - runs get their own port range and run ID
+	secondRunID, err := eng.StartScenario(scn.ID)
+	if err != nil {
+		t.Fatalf("Second StartScenario failed: %v", err)
+	}
+	if secondRunID == firstRunID {
+		t.Errorf("Expected distinct run IDs, got %s twice", firstRunID)
 	}
-	if err.Error() != "scenario already running" {
-		t.Errorf("Expected 'scenario already running' error, got: %v", err)
+	if runs := eng.ListRuns(); len(runs) != 2 {
+		t.Errorf("Expected 2 active runs, got %d", len(runs))
 	}
 
-	// Verify only one scenario:started event
+	// Verify one scenario:started event per run
+	time.Sleep(500 * time.Millisecond)
 	startedEvents := te.GetEventsByName(EventStarted)
-	if len(startedEvents) != 1 {
-		t.Errorf("Expected 1 scenario:started event, got %d", len(startedEvents))
+	if len(startedEvents) != 2 {
+		t.Errorf("Expected 2 scenario:started events, got %d", len(startedEvents))
 	}
 
-	// Stop the running scenario
+	// Stop both runs
 	if err := eng.StopScenario(); err != nil {
 		t.Fatalf("StopScenario failed: %v", err)
 	}
@@ -751,7 +755,7 @@ func TestIntegration_TwoPartyCallSimulation(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -834,7 +838,7 @@ func TestIntegration_EventStreamVerification(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -1002,7 +1006,7 @@ func TestIntegration_CleanupVerification(t *testing.T) {
 	}
 
 	// Start scenario
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -1050,7 +1054,7 @@ func TestIntegration_CleanupVerification(t *testing.T) {
 	}
 
 	// Start second scenario
-	if err := eng.StartScenario(scn2.ID); err != nil {
+	if _, err := eng.StartScenario(scn2.ID); err != nil {
 		t.Fatalf("StartScenario failed on restart: %v (cleanup may not have completed properly)", err)
 	}
 
@@ -1124,7 +1128,7 @@ func TestIntegration_V1_0_Compatibility(t *testing.T) {
 	}
 
 	// 시나리오 시작 (파싱 및 실행 검증)
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed (v1.0 format should execute): %v", err)
 	}
 
@@ -0,0 +1,582 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/icholy/digest"
+)
+
+// localPBXDefaultExpiry는 REGISTER에 Expires가 없을 때 적용하는 바인딩 유효 시간
+const localPBXDefaultExpiry = time.Hour
+
+// pbxNonceTTL은 digest challenge nonce의 유효 시간
+const pbxNonceTTL = 5 * time.Minute
+
+// pbxLogFunc는 내장 PBX가 본 트래픽을 액션 로그로 남기는 콜백
+type pbxLogFunc func(message, level string, opts ...ActionLogOption)
+
+// localPBX는 run마다 선택적으로 띄우는 내장 SIP registrar 겸 stateful proxy.
+// 인스턴스의 REGISTER를 받아 DN -> Contact 바인딩을 저장하고, 자신에게 온 최초 요청(INVITE/REFER 등)은
+// Request-URI의 DN으로 라우팅한다. Record-Route를 추가하므로 dialog 내 요청(ACK/BYE/re-INVITE/REFER)도 proxy를 거친다.
+// SIP Server 인스턴스도 같은 구현을 쓰며, 이때는 digest 인증과 미등록 DN의 fallback 경로를 옵션으로 켠다.
+type localPBX struct {
+	host         string // 광고 주소 (와일드카드로 listen하면 인터페이스 IP)
+	port         int
+	wildcard     bool // 0.0.0.0 등 모든 인터페이스에서 listen 중인지
+	ua           *sipgo.UserAgent
+	client       *sipgo.Client
+	logf         pbxLogFunc
+	ctx          context.Context
+	cancel       context.CancelFunc
+	closers      []io.Closer
+	auth         *pbxAuth              // nil이면 REGISTER를 인증 없이 받는다
+	fallback     *sip.Uri              // 미등록 DN으로 온 최초 요청의 목적지 (nil이면 404)
+	registerHook func(pbxRegistration) // REGISTER로 바인딩이 바뀔 때 호출
+
+	mu       sync.Mutex
+	bindings map[string]pbxBinding // DN(AOR user) -> 등록된 Contact
+	nonces   map[string]time.Time  // 발급한 digest nonce -> 발급 시각
+}
+
+// pbxAuth는 REGISTER digest 인증 설정. username이 비면 AOR의 DN을 username으로 쓴다.
+type pbxAuth struct {
+	realm    string
+	username string
+	password string
+}
+
+// pbxOption은 startLocalPBX의 선택 설정
+type pbxOption func(*localPBX)
+
+// withPBXAuth는 REGISTER에 digest 인증을 요구한다
+func withPBXAuth(realm, username, password string) pbxOption {
+	return func(p *localPBX) {
+		p.auth = &pbxAuth{realm: realm, username: username, password: password}
+	}
+}
+
+// withPBXFallback은 등록되지 않은 DN으로 온 최초 요청을 404 대신 uri로 보낸다
+func withPBXFallback(uri sip.Uri) pbxOption {
+	return func(p *localPBX) {
+		p.fallback = &uri
+	}
+}
+
+// withPBXRegisterHook은 REGISTER 처리 결과를 hook으로 전달한다
+func withPBXRegisterHook(hook func(pbxRegistration)) pbxOption {
+	return func(p *localPBX) {
+		p.registerHook = hook
+	}
+}
+
+// pbxRegistration은 REGISTER 한 건의 처리 결과
+type pbxRegistration struct {
+	PBXBinding
+	CallID     string
+	Source     string // 요청이 온 주소
+	Unregister bool   // Expires 0 요청
+}
+
+type pbxBinding struct {
+	contact sip.Uri
+	expires time.Time // zero면 만료 없음 (정적 바인딩)
+}
+
+func (b pbxBinding) expired(now time.Time) bool {
+	return !b.expires.IsZero() && now.After(b.expires)
+}
+
+// PBXBinding은 내장 PBX의 등록 정보 조회 결과
+type PBXBinding struct {
+	DN        string    `json:"dn"`
+	Contact   string    `json:"contact"`
+	ExpiresAt time.Time `json:"expiresAt"` // 정적 바인딩은 zero
+}
+
+// startLocalPBX는 host:port의 UDP/TCP에서 내장 PBX를 시작한다 (port가 0이면 임의 포트)
+func startLocalPBX(host string, port int, logf pbxLogFunc, opts ...pbxOption) (*localPBX, error) {
+	if logf == nil {
+		logf = func(string, string, ...ActionLogOption) {}
+	}
+
+	// 와일드카드 주소는 Via/Record-Route에 쓸 수 없으므로 인터페이스 IP를 광고한다
+	advertised, wildcard := host, false
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		wildcard = true
+		advertised = "127.0.0.1"
+		if ifaceIP, _, err := sip.ResolveInterfacesIP("ip4", nil); err == nil && ifaceIP != nil {
+			advertised = ifaceIP.String()
+		}
+	}
+
+	udpConn, err := net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("local PBX udp listen failed: %w", err)
+	}
+	port = udpConn.LocalAddr().(*net.UDPAddr).Port
+
+	tcpListener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		udpConn.Close()
+		return nil, fmt.Errorf("local PBX tcp listen failed: %w", err)
+	}
+
+	ua, err := sipgo.NewUA(sipgo.WithUserAgent("sipflow-local-pbx"), sipgo.WithUserAgentHostname(advertised))
+	if err != nil {
+		udpConn.Close()
+		tcpListener.Close()
+		return nil, fmt.Errorf("failed to create local PBX UA: %w", err)
+	}
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		ua.Close()
+		udpConn.Close()
+		tcpListener.Close()
+		return nil, fmt.Errorf("failed to create local PBX server: %w", err)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname(advertised), sipgo.WithClientPort(port))
+	if err != nil {
+		ua.Close()
+		udpConn.Close()
+		tcpListener.Close()
+		return nil, fmt.Errorf("failed to create local PBX client: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &localPBX{
+		host:     advertised,
+		port:     port,
+		wildcard: wildcard,
+		ua:       ua,
+		client:   client,
+		logf:     logf,
+		ctx:      ctx,
+		cancel:   cancel,
+		closers:  []io.Closer{udpConn, tcpListener},
+		bindings: make(map[string]pbxBinding),
+		nonces:   make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	srv.OnRegister(p.onRegister)
+	srv.OnAck(p.onAck)
+	for _, method := range []sip.RequestMethod{
+		sip.INVITE, sip.BYE, sip.REFER, sip.NOTIFY, sip.SUBSCRIBE, sip.INFO,
+		sip.MESSAGE, sip.OPTIONS, sip.UPDATE, sip.PRACK,
+	} {
+		srv.OnRequest(method, p.onRequest)
+	}
+
+	go srv.ServeUDP(udpConn)
+	go srv.ServeTCP(tcpListener)
+	return p, nil
+}
+
+// addr는 PBX의 host:port
+func (p *localPBX) addr() string {
+	return net.JoinHostPort(p.host, strconv.Itoa(p.port))
+}
+
+// close는 진행 중인 프록시 트랜잭션을 끝내고 리스너를 닫는다
+func (p *localPBX) close() {
+	p.cancel()
+	for _, c := range p.closers {
+		_ = c.Close()
+	}
+	_ = p.ua.Close()
+}
+
+// Bindings는 만료되지 않은 등록 정보를 DN 순으로 반환한다
+func (p *localPBX) Bindings() []PBXBinding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	list := make([]PBXBinding, 0, len(p.bindings))
+	for dn, b := range p.bindings {
+		if b.expired(now) {
+			continue
+		}
+		list = append(list, PBXBinding{DN: dn, Contact: b.contact.String(), ExpiresAt: b.expires})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].DN < list[j].DN })
+	return list
+}
+
+// bindStatic은 REGISTER 없이 DN을 contact에 바인딩한다 (run이 끝날 때까지 유지)
+func (p *localPBX) bindStatic(dn string, contact sip.Uri) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bindings[dn] = pbxBinding{contact: contact}
+}
+
+func (p *localPBX) lookup(dn string) (sip.Uri, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, exists := p.bindings[dn]
+	if !exists {
+		return sip.Uri{}, false
+	}
+	if b.expired(time.Now()) {
+		delete(p.bindings, dn)
+		return sip.Uri{}, false
+	}
+	return *b.contact.Clone(), true
+}
+
+// isSelf는 uri가 이 PBX를 가리키는지 확인한다
+func (p *localPBX) isSelf(uri sip.Uri) bool {
+	port := uri.Port
+	if port == 0 {
+		port = 5060
+	}
+	if port != p.port {
+		return false
+	}
+	return p.wildcard || uri.Host == p.host || uri.Host == "localhost"
+}
+
+// route는 최초 요청의 DN에 대한 목적지를 찾는다. 미등록 DN은 fallback이 있으면 그쪽으로 보낸다.
+func (p *localPBX) route(dn string) (sip.Uri, bool) {
+	if contact, ok := p.lookup(dn); ok {
+		return contact, true
+	}
+	if p.fallback == nil {
+		return sip.Uri{}, false
+	}
+	target := *p.fallback.Clone()
+	target.User = dn
+	return target, true
+}
+
+// popOwnRoute는 최상단 Route가 이 PBX이면 제거하고 true를 반환한다 (loose routing)
+func (p *localPBX) popOwnRoute(req *sip.Request) bool {
+	route := req.Route()
+	if route == nil || !p.isSelf(route.Address) {
+		return false
+	}
+	req.RemoveHeader("Route")
+	return true
+}
+
+func (p *localPBX) respond(tx sip.ServerTransaction, req *sip.Request, code int, reason string) {
+	_ = tx.Respond(sip.NewResponseFromRequest(req, code, reason, nil))
+}
+
+// onRegister는 REGISTER의 To user(DN)에 Contact를 바인딩한다. Expires 0이면 바인딩을 해제한다.
+func (p *localPBX) onRegister(req *sip.Request, tx sip.ServerTransaction) {
+	to := req.To()
+	if to == nil || to.Address.User == "" {
+		p.respond(tx, req, 400, "Missing Address Of Record")
+		return
+	}
+	dn := to.Address.User
+
+	if p.auth != nil && !p.authorize(req, tx, dn) {
+		return
+	}
+
+	contact := req.Contact()
+	if contact == nil {
+		// 바인딩 조회 요청
+		p.respond(tx, req, 200, "OK")
+		return
+	}
+
+	expiry := localPBXDefaultExpiry
+	if h := req.GetHeader("Expires"); h != nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(h.Value())); err == nil {
+			expiry = time.Duration(n) * time.Second
+		}
+	}
+	if v, ok := contact.Params.Get("expires"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			expiry = time.Duration(n) * time.Second
+		}
+	}
+
+	callID := ""
+	if h := req.CallID(); h != nil {
+		callID = h.Value()
+	}
+
+	res := sip.NewResponseFromRequest(req, 200, "OK", nil)
+	if expiry <= 0 {
+		p.mu.Lock()
+		delete(p.bindings, dn)
+		p.mu.Unlock()
+		p.logf(fmt.Sprintf("REGISTER %s unregistered", dn), "info",
+			WithSIPMessage("received", "REGISTER", 200, callID, dn, p.addr()))
+		_ = tx.Respond(res)
+		p.notifyRegister(pbxRegistration{PBXBinding: PBXBinding{DN: dn}, CallID: callID, Source: req.Source(), Unregister: true})
+		return
+	}
+
+	bound := *contact.Address.Clone()
+	if bound.Host == "" || bound.Host == "0.0.0.0" {
+		// 와일드카드 바인딩 주소는 요청이 온 주소로 대체한다
+		if host, _, err := net.SplitHostPort(req.Source()); err == nil {
+			bound.Host = host
+		}
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	p.mu.Lock()
+	p.bindings[dn] = pbxBinding{contact: bound, expires: expiresAt}
+	p.mu.Unlock()
+
+	resContact := &sip.ContactHeader{Address: bound}
+	resContact.Params = sip.NewParams()
+	resContact.Params.Add("expires", strconv.Itoa(int(expiry.Seconds())))
+	res.AppendHeader(resContact)
+	_ = tx.Respond(res)
+
+	p.logf(fmt.Sprintf("REGISTER %s bound to %s (expires %s)", dn, bound.String(), expiry), "info",
+		WithSIPMessage("received", "REGISTER", 200, callID, dn, p.addr()))
+	p.notifyRegister(pbxRegistration{
+		PBXBinding: PBXBinding{DN: dn, Contact: bound.String(), ExpiresAt: expiresAt},
+		CallID:     callID,
+		Source:     req.Source(),
+	})
+}
+
+func (p *localPBX) notifyRegister(reg pbxRegistration) {
+	if p.registerHook != nil {
+		p.registerHook(reg)
+	}
+}
+
+// authorize는 REGISTER의 digest 자격 증명을 검증한다.
+// 자격 증명이 없거나 틀리면 새 nonce로 401 challenge를 보내고 false를 반환한다.
+func (p *localPBX) authorize(req *sip.Request, tx sip.ServerTransaction, dn string) bool {
+	if h := req.GetHeader("Authorization"); h != nil {
+		creds, err := digest.ParseCredentials(h.Value())
+		if err == nil && p.verifyCredentials(req, creds, dn) {
+			return true
+		}
+		p.logf(fmt.Sprintf("REGISTER %s rejected: invalid credentials", dn), "warn", p.sipMessage("received", req, 401))
+	}
+
+	chal := &digest.Challenge{
+		Realm:     p.auth.realm,
+		Nonce:     p.issueNonce(),
+		Algorithm: "MD5",
+		QOP:       []string{"auth"},
+	}
+	res := sip.NewResponseFromRequest(req, sip.StatusUnauthorized, "Unauthorized", nil)
+	res.AppendHeader(sip.NewHeader("WWW-Authenticate", chal.String()))
+	_ = tx.Respond(res)
+	return false
+}
+
+func (p *localPBX) issueNonce() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	nonce := hex.EncodeToString(buf)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for n, issued := range p.nonces {
+		if now.Sub(issued) > pbxNonceTTL {
+			delete(p.nonces, n)
+		}
+	}
+	p.nonces[nonce] = now
+	return nonce
+}
+
+// verifyCredentials는 발급한 nonce로 응답 해시를 다시 계산해 비교한다 (qop=auth 또는 qop 없음)
+func (p *localPBX) verifyCredentials(req *sip.Request, creds *digest.Credentials, dn string) bool {
+	username := p.auth.username
+	if username == "" {
+		username = dn
+	}
+	if creds.Username != username || creds.Realm != p.auth.realm {
+		return false
+	}
+
+	p.mu.Lock()
+	issued, ok := p.nonces[creds.Nonce]
+	p.mu.Unlock()
+	if !ok || time.Since(issued) > pbxNonceTTL {
+		return false
+	}
+
+	chal := &digest.Challenge{Realm: p.auth.realm, Nonce: creds.Nonce, Algorithm: creds.Algorithm, Opaque: creds.Opaque}
+	switch creds.QOP {
+	case "":
+	case "auth":
+		chal.QOP = []string{"auth"}
+	default:
+		return false
+	}
+	expected, err := digest.Digest(chal, digest.Options{
+		Method:   string(req.Method),
+		URI:      creds.URI,
+		Username: username,
+		Password: p.auth.password,
+		Cnonce:   creds.Cnonce,
+		Count:    creds.Nc,
+	})
+	return err == nil && expected.Response == creds.Response
+}
+
+// onRequest는 REGISTER/ACK 외 요청을 stateful하게 중계한다
+func (p *localPBX) onRequest(req *sip.Request, tx sip.ServerTransaction) {
+	fwd := req.Clone()
+	inDialog := p.popOwnRoute(fwd)
+
+	if !inDialog && p.isSelf(fwd.Recipient) {
+		if fwd.Recipient.User == "" {
+			// PBX 자체에 대한 요청 (OPTIONS keepalive 등)
+			if req.Method == sip.OPTIONS {
+				p.respond(tx, req, 200, "OK")
+			} else {
+				p.respond(tx, req, 404, "Not Found")
+			}
+			return
+		}
+
+		contact, ok := p.route(fwd.Recipient.User)
+		if !ok {
+			p.logf(fmt.Sprintf("%s to %s rejected: DN is not registered", req.Method, fwd.Recipient.User), "warn",
+				p.sipMessage("received", req, 404))
+			p.respond(tx, req, 404, "Not Found")
+			return
+		}
+		fwd.Recipient = contact
+	}
+
+	recordRoute := !inDialog && (req.Method == sip.INVITE || req.Method == sip.SUBSCRIBE || req.Method == sip.REFER)
+	if to := req.To(); to != nil {
+		if tag, _ := to.Params.Get("tag"); tag != "" {
+			recordRoute = false
+		}
+	}
+	p.forward(req, fwd, tx, recordRoute)
+}
+
+// onAck는 2xx에 대한 ACK를 트랜잭션 없이 다음 홉으로 전달한다
+func (p *localPBX) onAck(req *sip.Request, _ sip.ServerTransaction) {
+	fwd := req.Clone()
+	if !p.popOwnRoute(fwd) && p.isSelf(fwd.Recipient) {
+		contact, ok := p.route(fwd.Recipient.User)
+		if !ok {
+			return
+		}
+		fwd.Recipient = contact
+	}
+	p.prepareForward(fwd)
+
+	if err := p.client.WriteRequest(fwd, sipgo.ClientRequestAddVia); err != nil {
+		p.logf(fmt.Sprintf("ACK relay to %s failed: %v", fwd.Recipient.String(), err), "warn")
+	}
+}
+
+// prepareForward는 수신 요청에서 복제된 주소 정보를 지워 다음 홉 기준으로 다시 계산되게 한다
+func (p *localPBX) prepareForward(fwd *sip.Request) {
+	fwd.Laddr = sip.Addr{}
+	fwd.SetDestination("")
+	fwd.SetTransport("")
+}
+
+// forward는 fwd를 다음 홉으로 보내고 응답을 원래 트랜잭션으로 중계한다
+func (p *localPBX) forward(req, fwd *sip.Request, tx sip.ServerTransaction, recordRoute bool) {
+	p.prepareForward(fwd)
+
+	opts := []sipgo.ClientRequestOption{sipgo.ClientRequestDecreaseMaxForward, sipgo.ClientRequestAddVia}
+	if recordRoute {
+		opts = append(opts, sipgo.ClientRequestAddRecordRoute)
+	}
+
+	p.logf(fmt.Sprintf("%s %s routed to %s", req.Method, fwd.Recipient.User, fwd.Recipient.String()), "info",
+		p.sipMessage("received", req, 0))
+
+	clTx, err := p.client.TransactionRequest(p.ctx, fwd, opts...)
+	if err != nil {
+		p.logf(fmt.Sprintf("%s relay to %s failed: %v", req.Method, fwd.Recipient.String(), err), "error")
+		p.respond(tx, req, 503, "Service Unavailable")
+		return
+	}
+	defer clTx.Terminate()
+
+	if req.IsInvite() {
+		tx.OnCancel(func(*sip.Request) {
+			go p.cancelForwarded(fwd)
+		})
+	}
+
+	for {
+		select {
+		case res, more := <-clTx.Responses():
+			if !more {
+				return
+			}
+			// 자신이 추가한 최상단 Via를 제거하고 요청이 온 곳으로 돌려보낸다
+			res.RemoveHeader("Via")
+			res.SetDestination(req.Source())
+			if !res.IsProvisional() || res.StatusCode != 100 {
+				p.logf(fmt.Sprintf("%d %s for %s", res.StatusCode, res.Reason, req.Method), "info",
+					p.sipMessage("sent", req, res.StatusCode))
+			}
+			_ = tx.Respond(res)
+		case <-clTx.Done():
+			return
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// cancelForwarded는 중계한 INVITE에 대한 CANCEL을 다음 홉으로 보낸다
+func (p *localPBX) cancelForwarded(fwd *sip.Request) {
+	cancelReq := sip.NewRequest(sip.CANCEL, *fwd.Recipient.Clone())
+	cancelReq.AppendHeader(sip.HeaderClone(fwd.Via()))
+	maxForwards := sip.MaxForwardsHeader(70)
+	cancelReq.AppendHeader(&maxForwards)
+	cancelReq.AppendHeader(sip.HeaderClone(fwd.From()))
+	cancelReq.AppendHeader(sip.HeaderClone(fwd.To()))
+	cancelReq.AppendHeader(sip.HeaderClone(fwd.CallID()))
+	cancelReq.AppendHeader(&sip.CSeqHeader{SeqNo: fwd.CSeq().SeqNo, MethodName: sip.CANCEL})
+	sip.CopyHeaders("Route", fwd, cancelReq)
+	cancelReq.SetDestination(fwd.Destination())
+	cancelReq.SetTransport(fwd.Transport())
+
+	ctx, cancel := context.WithTimeout(p.ctx, 5*time.Second)
+	defer cancel()
+	// 이미 빌드된 요청이므로 헤더 자동 추가를 막기 위해 옵션을 넘긴다
+	if _, err := p.client.Do(ctx, cancelReq, sipgo.ClientRequestDecreaseMaxForward); err != nil && !errors.Is(err, context.Canceled) {
+		p.logf(fmt.Sprintf("CANCEL relay to %s failed: %v", fwd.Recipient.String(), err), "warn")
+	}
+}
+
+func (p *localPBX) sipMessage(direction string, req *sip.Request, code int) ActionLogOption {
+	callID, from, to := "", "", ""
+	if h := req.CallID(); h != nil {
+		callID = h.Value()
+	}
+	if h := req.From(); h != nil {
+		from = h.Address.User
+	}
+	if h := req.To(); h != nil {
+		to = h.Address.User
+	}
+	return WithSIPMessage(direction, string(req.Method), code, callID, from, to)
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// newPBXTestUA는 127.0.0.1 임의 UDP 포트에서 요청을 받는 sipgo UA를 만든다
+func newPBXTestUA(t *testing.T, handle func(req *sip.Request, tx sip.ServerTransaction)) (*sipgo.Client, int) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	ua, err := sipgo.NewUA(sipgo.WithUserAgentHostname("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handle != nil {
+		srv.OnInvite(handle)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname("127.0.0.1"), sipgo.WithClientPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.ServeUDP(conn)
+	t.Cleanup(func() {
+		conn.Close()
+		ua.Close()
+	})
+	return client, port
+}
+
+func TestLocalPBX_RegisterAndRouteInvite(t *testing.T) {
+	var logMu sync.Mutex
+	var logs []string
+	pbx, err := startLocalPBX("127.0.0.1", 0, func(message, level string, opts ...ActionLogOption) {
+		logMu.Lock()
+		logs = append(logs, message)
+		logMu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("startLocalPBX failed: %v", err)
+	}
+	t.Cleanup(pbx.close)
+
+	invites := make(chan *sip.Request, 1)
+	callee, calleePort := newPBXTestUA(t, func(req *sip.Request, tx sip.ServerTransaction) {
+		invites <- req
+		tx.Respond(sip.NewResponseFromRequest(req, 200, "OK", nil))
+	})
+	caller, _ := newPBXTestUA(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	register := sip.NewRequest(sip.REGISTER, sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: pbx.port})
+	register.AppendHeader(&sip.ToHeader{Address: sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1"}})
+	register.AppendHeader(&sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: calleePort}})
+	register.AppendHeader(sip.NewHeader("Expires", "60"))
+	res, err := callee.Do(ctx, register)
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("REGISTER failed: res=%v err=%v", res, err)
+	}
+
+	bindings := pbx.Bindings()
+	if len(bindings) != 1 || bindings[0].DN != "200" || !strings.Contains(bindings[0].Contact, "127.0.0.1") {
+		t.Fatalf("unexpected bindings: %+v", bindings)
+	}
+
+	invite := sip.NewRequest(sip.INVITE, sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: pbx.port})
+	res, err = caller.Do(ctx, invite)
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("INVITE via PBX failed: res=%v err=%v", res, err)
+	}
+
+	select {
+	case req := <-invites:
+		if req.Recipient.Port != calleePort {
+			t.Errorf("expected Request-URI rewritten to contact port %d, got %s", calleePort, req.Recipient.String())
+		}
+		if rr := req.GetHeader("Record-Route"); rr == nil || !strings.Contains(rr.Value(), pbx.addr()) {
+			t.Errorf("expected Record-Route to the PBX, got %v", rr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("callee did not receive the proxied INVITE")
+	}
+
+	unknown := sip.NewRequest(sip.INVITE, sip.Uri{Scheme: "sip", User: "300", Host: "127.0.0.1", Port: pbx.port})
+	res, err = caller.Do(ctx, unknown)
+	if err != nil || res.StatusCode != 404 {
+		t.Fatalf("expected 404 for unregistered DN, got res=%v err=%v", res, err)
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	joined := strings.Join(logs, "\n")
+	for _, want := range []string{"REGISTER 200 bound to", "INVITE 200 routed to", "200 OK for INVITE", "INVITE to 300 rejected"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("PBX log missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestInstanceManager_LocalPBXRoutesDNTargets(t *testing.T) {
+	im := newRangeInstanceManager(25060, 20)
+	host, port, err := im.StartLocalPBX(nil)
+	if err != nil {
+		t.Fatalf("StartLocalPBX failed: %v", err)
+	}
+	defer im.Cleanup()
+
+	target, err := im.ResolveTarget("300")
+	if err != nil {
+		t.Fatalf("ResolveTarget failed: %v", err)
+	}
+	want := "sip:300@" + net.JoinHostPort(host, strconv.Itoa(port))
+	if target != want {
+		t.Errorf("ResolveTarget = %s, want %s", target, want)
+	}
+
+	im.Cleanup()
+	if im.LocalPBXBindings() != nil {
+		t.Error("expected the PBX to be closed by Cleanup")
+	}
+}
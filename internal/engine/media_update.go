@@ -0,0 +1,656 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/diago/media"
+	"github.com/emiago/diago/media/sdp"
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// mediaCodecNames는 ReInvite/Update codecs에 쓸 수 있는 코덱 (telephone-event는 항상 뒤에 붙는다)
+var mediaCodecNames = []string{"PCMU", "PCMA"}
+
+// staticPayloadNames는 rtpmap 없이 m= 줄에만 있는 정적 payload type의 코덱 이름 (RFC 3551)
+var staticPayloadNames = map[string]string{"0": "PCMU", "8": "PCMA", "9": "G722", "18": "G729"}
+
+// parseMediaUpdateFields는 ReInvite/Update 커맨드와 REINVITE_RECEIVED/UPDATE_RECEIVED 이벤트의 미디어 필드를 읽는다
+func parseMediaUpdateFields(gnode *GraphNode, data map[string]interface{}) error {
+	gnode.MediaDirection = strings.ToLower(getStringField(data, "direction", ""))
+	if gnode.Type == "event" {
+		gnode.MediaCodec = getStringField(data, "codec", "")
+		return validateMediaUpdate(gnode.MediaDirection, nil, "", false)
+	}
+	gnode.MediaCodecs = getStringArrayField(data, "codecs", nil)
+	gnode.MediaAddress = getStringField(data, "mediaAddress", "")
+	gnode.WithoutSDP = getBoolField(data, "withoutSdp", false)
+	return validateMediaUpdate(gnode.MediaDirection, gnode.MediaCodecs, gnode.MediaAddress, gnode.WithoutSDP)
+}
+
+// validateMediaUpdate는 방향/코덱/미디어 주소 값을 검증한다. withoutSdp는 SDP 변경과 함께 쓸 수 없다.
+func validateMediaUpdate(direction string, codecs []string, address string, withoutSDP bool) error {
+	switch direction {
+	case "", sdp.ModeSendrecv, sdp.ModeSendonly, sdp.ModeRecvonly, sdp.ModeInactive:
+	default:
+		return fmt.Errorf("unsupported direction %q (sendrecv|sendonly|recvonly|inactive)", direction)
+	}
+	for _, codec := range codecs {
+		if !slices.Contains(mediaCodecNames, codec) {
+			return fmt.Errorf("unsupported codec %q (%s)", codec, strings.Join(mediaCodecNames, "|"))
+		}
+	}
+	if address != "" {
+		if _, _, err := parseMediaAddress(address); err != nil {
+			return err
+		}
+	}
+	if withoutSDP && (direction != "" || len(codecs) > 0 || address != "") {
+		return fmt.Errorf("withoutSdp cannot be combined with direction, codecs or mediaAddress")
+	}
+	return nil
+}
+
+// parseMediaAddress는 "ip" 또는 "ip:port" 형식의 미디어 주소를 읽는다 (port 0이면 현재 포트 유지)
+func parseMediaAddress(address string) (net.IP, int, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		host, portText = address, ""
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, fmt.Errorf("invalid mediaAddress %q (want ip or ip:port)", address)
+	}
+	if portText == "" {
+		return ip, 0, nil
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, 0, fmt.Errorf("invalid mediaAddress port in %q", address)
+	}
+	return ip, port, nil
+}
+
+// describeMediaChange는 액션 로그용으로 노드가 바꾸는 SDP 항목을 요약한다
+func describeMediaChange(node *GraphNode) string {
+	if node.WithoutSDP {
+		return "without SDP"
+	}
+	var changes []string
+	if node.MediaDirection != "" {
+		changes = append(changes, node.MediaDirection)
+	}
+	if len(node.MediaCodecs) > 0 {
+		changes = append(changes, "codecs "+strings.Join(node.MediaCodecs, ","))
+	}
+	if node.MediaAddress != "" {
+		changes = append(changes, "address "+node.MediaAddress)
+	}
+	if len(changes) == 0 {
+		return "current SDP"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// applyMediaChange는 노드 설정대로 MediaSession의 방향/코덱/로컬 주소를 바꾸고 원래 값으로 되돌리는 함수를 반환한다.
+// 주소는 SDP의 c=/m= 값만 바뀌고 RTP 소켓은 그대로다.
+func applyMediaChange(mediaSess *media.MediaSession, node *GraphNode) func() {
+	mode, codecs, laddr := mediaSess.Mode, mediaSess.Codecs, mediaSess.Laddr
+	if node.MediaDirection != "" {
+		mediaSess.Mode = node.MediaDirection
+	}
+	if len(node.MediaCodecs) > 0 {
+		mediaSess.Codecs = stringToCodecs(node.MediaCodecs)
+	}
+	if node.MediaAddress != "" {
+		if ip, port, err := parseMediaAddress(node.MediaAddress); err == nil {
+			mediaSess.Laddr.IP = ip
+			if port > 0 {
+				mediaSess.Laddr.Port = port
+			}
+		}
+	}
+	return func() {
+		mediaSess.Mode, mediaSess.Codecs, mediaSess.Laddr = mode, codecs, laddr
+	}
+}
+
+// sdpMediaInfo는 SDP 본문의 방향과 audio 코덱 이름(m= 순서)을 읽는다. 방향 속성이 없으면 sendrecv이다.
+func sdpMediaInfo(body []byte) (string, []string) {
+	if len(body) == 0 {
+		return "", nil
+	}
+	direction := sdp.ModeSendrecv
+	var payloads []string
+	rtpmap := make(map[string]string)
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "m=audio "):
+			if fields := strings.Fields(line); len(fields) > 3 {
+				payloads = fields[3:]
+			}
+		case strings.HasPrefix(line, "a=rtpmap:"):
+			pt, encoding, found := strings.Cut(strings.TrimPrefix(line, "a=rtpmap:"), " ")
+			if found {
+				name, _, _ := strings.Cut(encoding, "/")
+				rtpmap[pt] = name
+			}
+		case line == "a="+sdp.ModeSendrecv, line == "a="+sdp.ModeSendonly, line == "a="+sdp.ModeRecvonly, line == "a="+sdp.ModeInactive:
+			direction = strings.TrimPrefix(line, "a=")
+		}
+	}
+
+	codecs := make([]string, 0, len(payloads))
+	for _, pt := range payloads {
+		if name, ok := rtpmap[pt]; ok {
+			codecs = append(codecs, name)
+		} else if name, ok := staticPayloadNames[pt]; ok {
+			codecs = append(codecs, name)
+		}
+	}
+	return direction, codecs
+}
+
+func directionSends(direction string) bool {
+	return direction == "" || direction == sdp.ModeSendrecv || direction == sdp.ModeSendonly
+}
+
+func directionReceives(direction string) bool {
+	return direction == "" || direction == sdp.ModeSendrecv || direction == sdp.ModeRecvonly
+}
+
+// answerDirection은 상대 offer 방향과 로컬 방향으로 answer 방향을 정한다 (RFC 3264 6.1)
+func answerDirection(offer, local string) string {
+	send := directionReceives(offer) && directionSends(local)
+	recv := directionSends(offer) && directionReceives(local)
+	switch {
+	case send && recv:
+		return sdp.ModeSendrecv
+	case send:
+		return sdp.ModeSendonly
+	case recv:
+		return sdp.ModeRecvonly
+	default:
+		return sdp.ModeInactive
+	}
+}
+
+// offerDirection은 로컬 answer 방향으로 상대가 보낸 offer 방향을 추정한다
+func offerDirection(answer string) string {
+	switch answer {
+	case sdp.ModeRecvonly:
+		return sdp.ModeSendonly
+	case sdp.ModeSendonly:
+		return sdp.ModeRecvonly
+	default:
+		return answer
+	}
+}
+
+// answerSDP는 로컬 SDP의 방향 속성을 offer에 맞춘 answer 방향으로 바꾼다
+func answerSDP(local []byte, offer string) []byte {
+	localDirection, _ := sdpMediaInfo(local)
+	answer := "a=" + answerDirection(offer, localDirection)
+	lines := strings.Split(string(local), "\r\n")
+	for i, line := range lines {
+		switch line {
+		case "a=" + sdp.ModeSendrecv, "a=" + sdp.ModeSendonly, "a=" + sdp.ModeRecvonly, "a=" + sdp.ModeInactive:
+			lines[i] = answer
+		}
+	}
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+// sdpRemoteAddress는 SDP의 audio 미디어 주소(c=, m=audio 포트)를 읽는다. m= 아래의 c=가 세션 c=보다 우선한다.
+func sdpRemoteAddress(body []byte) (net.IP, int, error) {
+	var sessionIP, mediaIP net.IP
+	port, inAudio := -1, false
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "m="):
+			inAudio = strings.HasPrefix(line, "m=audio ")
+			if fields := strings.Fields(line); inAudio && len(fields) > 1 {
+				value, err := strconv.Atoi(fields[1])
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid audio port %q", fields[1])
+				}
+				port = value
+			}
+		case strings.HasPrefix(line, "c="):
+			fields := strings.Fields(strings.TrimPrefix(line, "c="))
+			if len(fields) < 3 {
+				return nil, 0, fmt.Errorf("invalid connection line %q", line)
+			}
+			ip := net.ParseIP(strings.Split(fields[2], "/")[0])
+			if ip == nil {
+				return nil, 0, fmt.Errorf("invalid connection address %q", fields[2])
+			}
+			if inAudio {
+				mediaIP = ip
+			} else if port < 0 {
+				sessionIP = ip
+			}
+		}
+	}
+	if port < 0 {
+		return nil, 0, fmt.Errorf("no audio media line")
+	}
+	if port == 0 {
+		return nil, 0, fmt.Errorf("audio stream rejected (port 0)")
+	}
+	if mediaIP == nil {
+		mediaIP = sessionIP
+	}
+	if mediaIP == nil {
+		return nil, 0, fmt.Errorf("no connection address")
+	}
+	return mediaIP, port, nil
+}
+
+// applyRemoteSDP는 상대 SDP(offer 또는 answer)를 MediaSession에 반영하고 상대 SDP의 방향을 반환한다.
+// 코덱은 supported 중 상대가 제시한 것만 상대 순서대로 남기고, 상대 주소로 보내며, 방향은 local 방향과 상대 방향으로 정한다.
+// 반영할 수 없으면(공통 audio 코덱 없음, 주소 오류, 거부된 stream) MediaSession을 바꾸지 않고 오류를 반환한다.
+// c=0.0.0.0(RFC 2543 hold)이면 상대 주소는 그대로 둔다.
+func applyRemoteSDP(mediaSess *media.MediaSession, body []byte, supported []media.Codec, local string) (string, error) {
+	direction, names := sdpMediaInfo(body)
+	if direction == "" {
+		return "", fmt.Errorf("empty SDP")
+	}
+	ip, port, err := sdpRemoteAddress(body)
+	if err != nil {
+		return "", err
+	}
+
+	var codecs []media.Codec
+	var telephoneEvent *media.Codec
+	for _, name := range names {
+		for i, codec := range supported {
+			if !strings.EqualFold(codec.Name, name) || slices.ContainsFunc(codecs, func(c media.Codec) bool { return c.Name == codec.Name }) {
+				continue
+			}
+			if codec.Name == media.CodecTelephoneEvent8000.Name {
+				telephoneEvent = &supported[i]
+			} else {
+				codecs = append(codecs, codec)
+			}
+		}
+	}
+	if len(codecs) == 0 {
+		return "", fmt.Errorf("no common audio codec (remote offers %s)", strings.Join(names, ","))
+	}
+	if telephoneEvent != nil {
+		codecs = append(codecs, *telephoneEvent)
+	}
+
+	mediaSess.Codecs = codecs
+	if !ip.IsUnspecified() {
+		mediaSess.Raddr = net.UDPAddr{IP: ip, Port: port}
+	}
+	mediaSess.Mode = answerDirection(direction, local)
+	return direction, nil
+}
+
+// mediaDetail은 SIP 이벤트 Detail에 담을 "방향 코덱,..." 문자열을 만든다 (SDP가 없으면 빈 문자열)
+func mediaDetail(direction string, codecs []string) string {
+	if direction == "" {
+		return ""
+	}
+	return strings.TrimSpace(direction + " " + strings.Join(codecs, ","))
+}
+
+func parseMediaDetail(detail string) (string, []string) {
+	direction, codecs, _ := strings.Cut(detail, " ")
+	if codecs == "" {
+		return direction, nil
+	}
+	return direction, strings.Split(codecs, ",")
+}
+
+// matchesMediaUpdate는 REINVITE_RECEIVED/UPDATE_RECEIVED 노드의 방향/코덱 조건을 검사한다
+func matchesMediaUpdate(node *GraphNode, direction string, codecs []string) bool {
+	if node.MediaDirection != "" && node.MediaDirection != direction {
+		return false
+	}
+	if node.MediaCodec == "" {
+		return true
+	}
+	return slices.ContainsFunc(codecs, func(codec string) bool {
+		return strings.EqualFold(codec, node.MediaCodec)
+	})
+}
+
+// dialogResponseCode는 diago/sipgo dialog 요청 오류에 담긴 최종 응답 코드를 꺼낸다 (없으면 0)
+func dialogResponseCode(err error) int {
+	var resErr *sipgo.ErrDialogResponse
+	if errors.As(err, &resErr) && resErr.Res != nil {
+		return resErr.Res.StatusCode
+	}
+	var resValue sipgo.ErrDialogResponse
+	if errors.As(err, &resValue) && resValue.Res != nil {
+		return resValue.Res.StatusCode
+	}
+	return 0
+}
+
+// localContact는 dialog에서 인스턴스가 광고한 Contact를 반환한다 (착신 dialog는 200 OK, 발신 dialog는 INVITE의 Contact)
+func localContact(dialog diago.DialogSession) *sip.ContactHeader {
+	dialogSIP := dialog.DialogSIP()
+	if dialogSIP == nil {
+		return nil
+	}
+	if _, inbound := dialog.(*diago.DialogServerSession); inbound {
+		if dialogSIP.InviteResponse != nil {
+			return dialogSIP.InviteResponse.Contact()
+		}
+		return nil
+	}
+	if dialogSIP.InviteRequest != nil {
+		return dialogSIP.InviteRequest.Contact()
+	}
+	return nil
+}
+
+// executeMediaUpdate는 ReInvite/Update 커맨드를 실행한다 — MediaSession의 방향/코덱/주소를 노드 설정대로 바꾸고 새 offer를 보낸다.
+// withoutSdp면 offer 없이 보낸다 (re-INVITE는 200 OK의 offer에 ACK로 answer한다).
+func (ex *Executor) executeMediaUpdate(ctx context.Context, instanceID string, node *GraphNode) error {
+	method := sip.INVITE
+	if node.Command == string(SIPCommandUpdate) {
+		method = sip.UPDATE
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s: sending %s (%s)", node.Command, method, describeMediaChange(node)), "info")
+
+	dialog, exists := ex.sessions.GetDialog(instanceID, callIDOrDefault(node))
+	if !exists {
+		return fmt.Errorf("%s: no active dialog for instance %s", node.Command, instanceID)
+	}
+	if node.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, node.Timeout)
+		defer cancel()
+	}
+
+	if node.WithoutSDP {
+		direction, err := ex.sendWithoutSDP(ctx, instanceID, dialog, method)
+		if err != nil {
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s failed: %v", node.Command, err), "error")
+			return fmt.Errorf("%s: %w", node.Command, err)
+		}
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s without SDP succeeded", node.Command), "info",
+			WithSIPMessage("sent", string(method), 200, "", "", "", direction))
+		return nil
+	}
+
+	dialogMedia := dialog.Media()
+	if dialogMedia == nil || dialogMedia.MediaSession() == nil {
+		return fmt.Errorf("%s: no media session available", node.Command)
+	}
+	mediaSess := dialogMedia.MediaSession()
+	restore := applyMediaChange(mediaSess, node)
+
+	code := 200
+	var err error
+	if method == sip.INVITE {
+		// diago ReInvite가 현재 MediaSession으로 offer를 만들고 ACK까지 처리한다
+		type reInviter interface {
+			ReInvite(ctx context.Context) error
+		}
+		ri, ok := dialog.(reInviter)
+		if !ok {
+			restore()
+			return fmt.Errorf("ReInvite: dialog type %T does not support ReInvite", dialog)
+		}
+		if err = ri.ReInvite(ctx); err != nil {
+			code = dialogResponseCode(err)
+		}
+	} else {
+		code, err = sendInDialog(ctx, dialog, sip.UPDATE, mediaSess.LocalSDP())
+	}
+	if err != nil {
+		restore() // 실패 시 복원
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s failed: %v", node.Command, err), "error",
+			WithSIPMessage("received", string(method), code, "", "", ""))
+		if method == sip.UPDATE && code >= 200 && code < 300 {
+			// 상대는 offer를 받아들였지만 answer를 반영하지 못했으므로 이전 SDP로 다시 offer해 양쪽 상태를 맞춘다
+			if _, reofferErr := sendInDialog(ctx, dialog, sip.UPDATE, mediaSess.LocalSDP()); reofferErr != nil {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s: restoring previous SDP failed: %v", node.Command, reofferErr), "warn")
+			}
+		}
+		return fmt.Errorf("%s failed: %w", node.Command, err)
+	}
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s succeeded (%s)", node.Command, describeMediaChange(node)), "info",
+		WithSIPMessage("sent", string(method), code, "", "", "", mediaSess.Mode))
+	return nil
+}
+
+// sendInDialog는 dialog 안에서 body(있으면 SDP offer)를 담은 요청을 보내고 2xx인지 확인한다.
+// offer를 보냈으면 2xx의 answer를 MediaSession에 반영한다. answer가 없거나 반영할 수 없으면 2xx 코드와 함께 오류를 반환한다.
+func sendInDialog(ctx context.Context, dialog diago.DialogSession, method sip.RequestMethod, body []byte) (int, error) {
+	requester, ok := dialog.(inDialogRequester)
+	if !ok {
+		return 0, fmt.Errorf("dialog type %T does not support in-dialog requests", dialog)
+	}
+	remote := requester.RemoteContact()
+	if remote == nil {
+		return 0, fmt.Errorf("remote contact is missing")
+	}
+	req := sip.NewRequest(method, *remote.Address.Clone())
+	if contact := localContact(dialog); contact != nil {
+		req.AppendHeader(contact.Clone())
+	}
+	if len(body) > 0 {
+		req.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+		req.SetBody(body)
+	}
+	res, err := requester.Do(ctx, req)
+	if err != nil {
+		return 0, fmt.Errorf("%s failed: %w", method, err)
+	}
+	if !res.IsSuccess() {
+		return res.StatusCode, fmt.Errorf("%s rejected with %d %s", method, res.StatusCode, res.Reason)
+	}
+	if len(body) == 0 {
+		return res.StatusCode, nil
+	}
+
+	dialogMedia := dialog.Media()
+	if dialogMedia == nil || dialogMedia.MediaSession() == nil {
+		return res.StatusCode, fmt.Errorf("%s answer: no media session available", method)
+	}
+	if len(res.Body()) == 0 {
+		return res.StatusCode, fmt.Errorf("%s answer has no SDP", method)
+	}
+	mediaSess := dialogMedia.MediaSession()
+	if _, err := applyRemoteSDP(mediaSess, res.Body(), mediaSess.Codecs, mediaSess.Mode); err != nil {
+		return res.StatusCode, fmt.Errorf("%s answer could not be applied: %w", method, err)
+	}
+	return res.StatusCode, nil
+}
+
+// sendWithoutSDP는 SDP 없는 UPDATE/re-INVITE를 보낸다. re-INVITE의 200 OK에 담긴 offer에는 로컬 SDP로 ACK answer를 보내고
+// offer 방향을 반환한다.
+func (ex *Executor) sendWithoutSDP(ctx context.Context, instanceID string, dialog diago.DialogSession, method sip.RequestMethod) (string, error) {
+	if method == sip.UPDATE {
+		_, err := sendInDialog(ctx, dialog, sip.UPDATE, nil)
+		return "", err
+	}
+
+	requester, ok := dialog.(inDialogRequester)
+	if !ok {
+		return "", fmt.Errorf("dialog type %T does not support in-dialog requests", dialog)
+	}
+	type ackWriter interface {
+		WriteRequest(req *sip.Request) error
+	}
+	writer, ok := dialog.(ackWriter)
+	if !ok {
+		return "", fmt.Errorf("dialog type %T cannot send ACK", dialog)
+	}
+	remote := requester.RemoteContact()
+	if remote == nil {
+		return "", fmt.Errorf("remote contact is missing")
+	}
+
+	req := sip.NewRequest(sip.INVITE, *remote.Address.Clone())
+	if contact := localContact(dialog); contact != nil {
+		req.AppendHeader(contact.Clone())
+	}
+	res, err := requester.Do(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("INVITE failed: %w", err)
+	}
+	if !res.IsSuccess() {
+		return "", fmt.Errorf("INVITE rejected with %d %s", res.StatusCode, res.Reason)
+	}
+
+	// 200 OK의 offer를 MediaSession에 반영하고 그 SDP로 ACK answer를 보낸다.
+	// 반영할 수 없어도 ACK에는 answer가 있어야 하므로 현재 SDP의 방향만 맞춰 보내고 오류를 반환한다.
+	offer, _ := sdpMediaInfo(res.Body())
+	ack := sip.NewRequest(sip.ACK, *remote.Address.Clone())
+	var applyErr error
+	if dialogMedia := dialog.Media(); dialogMedia != nil && dialogMedia.MediaSession() != nil && offer != "" {
+		mediaSess := dialogMedia.MediaSession()
+		answer := answerSDP(mediaSess.LocalSDP(), offer)
+		if _, applyErr = applyRemoteSDP(mediaSess, res.Body(), ex.supportedCodecs(instanceID, mediaSess), sdp.ModeSendrecv); applyErr == nil {
+			answer = mediaSess.LocalSDP()
+		}
+		ack.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+		ack.SetBody(answer)
+	}
+	if err := writer.WriteRequest(ack); err != nil {
+		return offer, fmt.Errorf("ACK failed: %w", err)
+	}
+	if applyErr != nil {
+		return offer, fmt.Errorf("offer in 200 OK could not be applied: %w", applyErr)
+	}
+	return offer, nil
+}
+
+// notifyReInviteReceived는 상대 re-INVITE를 처리한 뒤의 로컬 answer SDP로 REINVITE_RECEIVED를 발행한다
+func (ex *Executor) notifyReInviteReceived(instanceID, callID string, localSDP []byte) {
+	sipCallID, exists := ex.sessions.GetSIPCallID(instanceID, callID)
+	if !exists {
+		return
+	}
+	direction, codecs := sdpMediaInfo(localSDP)
+	ex.sessions.notifySIPEvent(eventhandler.Event{
+		Type:          eventhandler.SIPEventReInvite,
+		SIPCallID:     sipCallID,
+		InstanceID:    instanceID,
+		LogicalCallID: callID,
+		Detail:        mediaDetail(offerDirection(direction), codecs),
+	})
+}
+
+// handleUpdate는 인스턴스가 받은 dialog 안 UPDATE에 응답하고 UPDATE_RECEIVED를 발행한다.
+// SDP offer는 MediaSession에 반영하고 그 SDP로 answer하며, 반영할 수 없으면 488로 거절한다.
+// Session-Expires에는 같은 값으로 응답한다 (RFC 4028 refresh).
+func (ex *Executor) handleUpdate(instanceID string, req *sip.Request, tx sip.ServerTransaction) {
+	sipCallID := ""
+	if header := req.CallID(); header != nil {
+		sipCallID = header.Value()
+	}
+	callID, found := ex.sessions.FindCallID(instanceID, sipCallID)
+	if !found {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil))
+		return
+	}
+
+	var answer []byte
+	direction, codecs := sdpMediaInfo(req.Body())
+	if direction != "" {
+		var err error
+		if answer, err = ex.applyUpdateOffer(instanceID, callID, req.Body()); err != nil {
+			res := sip.NewResponseFromRequest(req, sip.StatusNotAcceptableHere, "Not Acceptable Here", nil)
+			res.AppendHeader(sip.NewHeader("Warning", fmt.Sprintf("304 sipflow %q", err.Error())))
+			_ = tx.Respond(res)
+			return
+		}
+	}
+
+	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+	if seconds, refresher, found := parseSessionExpires(req); found {
+		if refresher == "" {
+			refresher = SessionRefresherUAC
+		}
+		res.AppendHeader(sip.NewHeader("Require", "timer"))
+		res.AppendHeader(sip.NewHeader("Session-Expires", fmt.Sprintf("%d;refresher=%s", seconds, refresher)))
+	}
+	if answer != nil {
+		res.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+		res.SetBody(answer)
+	}
+	_ = tx.Respond(res)
+
+	ex.sessions.notifySIPEvent(eventhandler.Event{
+		Type:          eventhandler.SIPEventUpdate,
+		SIPCallID:     sipCallID,
+		InstanceID:    instanceID,
+		LogicalCallID: callID,
+		Detail:        mediaDetail(direction, codecs),
+	})
+}
+
+// applyUpdateOffer는 UPDATE로 받은 offer를 dialog의 MediaSession에 반영하고 answer SDP를 반환한다
+func (ex *Executor) applyUpdateOffer(instanceID, callID string, offer []byte) ([]byte, error) {
+	dialog, exists := ex.sessions.GetDialog(instanceID, callID)
+	if !exists {
+		return nil, fmt.Errorf("no active dialog")
+	}
+	dialogMedia := dialog.Media()
+	if dialogMedia == nil || dialogMedia.MediaSession() == nil {
+		return nil, fmt.Errorf("no media session available")
+	}
+	mediaSess := dialogMedia.MediaSession()
+	if _, err := applyRemoteSDP(mediaSess, offer, ex.supportedCodecs(instanceID, mediaSess), sdp.ModeSendrecv); err != nil {
+		return nil, err
+	}
+	return mediaSess.LocalSDP(), nil
+}
+
+// supportedCodecs는 상대 offer에 답할 수 있는 코덱 — 인스턴스 설정 코덱, 없으면 현재 MediaSession 코덱
+func (ex *Executor) supportedCodecs(instanceID string, mediaSess *media.MediaSession) []media.Codec {
+	if instance, err := ex.im.GetInstance(instanceID); err == nil && len(instance.Config.Codecs) > 0 {
+		return stringToCodecs(instance.Config.Codecs)
+	}
+	return mediaSess.Codecs
+}
+
+// executeMediaUpdateReceived는 방향/코덱 조건에 맞는 re-INVITE 또는 UPDATE를 받을 때까지 대기한다
+func (ex *Executor) executeMediaUpdateReceived(ctx context.Context, instanceID string, node *GraphNode, eventType eventhandler.SIPEventType, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	method := "INVITE"
+	if eventType == eventhandler.SIPEventUpdate {
+		method = "UPDATE"
+	}
+	return ex.waitSIPEventMatch(ctx, instanceID, callID, eventType, timeout, func(event eventhandler.Event) bool {
+		direction, codecs := parseMediaDetail(event.Detail)
+		if !matchesMediaUpdate(node, direction, codecs) {
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s %q does not match, skipping", eventType, event.Detail), "info")
+			return false
+		}
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("%s event received: %s (callID: %s, sipCallID: %s)", eventType, describeMediaDetail(event.Detail), callID, event.SIPCallID), "info",
+			WithSIPMessage("received", method, 200, "", "", "", direction))
+		return true
+	})
+}
+
+func describeMediaDetail(detail string) string {
+	if detail == "" {
+		return "without SDP"
+	}
+	return detail
+}
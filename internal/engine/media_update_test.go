@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiago/diago/media"
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+const testOfferSDP = "v=0\r\n" +
+	"o=- 1 2 IN IP4 10.0.0.5\r\n" +
+	"s=-\r\n" +
+	"c=IN IP4 10.0.0.5\r\n" +
+	"t=0 0\r\n" +
+	"m=audio 40000 RTP/AVP 8 0 101\r\n" +
+	"a=rtpmap:101 telephone-event/8000\r\n" +
+	"a=sendonly\r\n"
+
+// fakeReInviteDialog answers an SDP-less re-INVITE with an offer and records the ACK
+type fakeReInviteDialog struct {
+	*fakeRefreshDialog
+	acks []*sip.Request
+}
+
+func (d *fakeReInviteDialog) Do(ctx context.Context, req *sip.Request) (*sip.Response, error) {
+	res, err := d.fakeRefreshDialog.Do(ctx, req)
+	if err == nil && res.IsSuccess() {
+		res.SetBody([]byte(testOfferSDP))
+	}
+	return res, err
+}
+
+func (d *fakeReInviteDialog) WriteRequest(req *sip.Request) error {
+	d.acks = append(d.acks, req)
+	return nil
+}
+
+func TestSDPMediaInfoAndAnswer(t *testing.T) {
+	direction, codecs := sdpMediaInfo([]byte(testOfferSDP))
+	if direction != "sendonly" || strings.Join(codecs, ",") != "PCMA,PCMU,telephone-event" {
+		t.Errorf("unexpected media info %s %v", direction, codecs)
+	}
+	if direction, codecs := sdpMediaInfo(nil); direction != "" || codecs != nil {
+		t.Errorf("expected no media info without SDP, got %s %v", direction, codecs)
+	}
+
+	tests := []struct{ offer, local, want string }{
+		{"sendonly", "sendrecv", "recvonly"},
+		{"recvonly", "sendrecv", "sendonly"},
+		{"sendrecv", "sendonly", "sendonly"},
+		{"sendonly", "sendonly", "inactive"},
+		{"inactive", "sendrecv", "inactive"},
+	}
+	for _, tt := range tests {
+		if got := answerDirection(tt.offer, tt.local); got != tt.want {
+			t.Errorf("answerDirection(%s, %s) = %s, want %s", tt.offer, tt.local, got, tt.want)
+		}
+	}
+
+	local := strings.Replace(testOfferSDP, "a=sendonly", "a=sendrecv", 1)
+	if answer := string(answerSDP([]byte(local), "sendonly")); !strings.Contains(answer, "a=recvonly\r\n") || strings.Contains(answer, "a=sendrecv") {
+		t.Errorf("expected recvonly answer, got %q", answer)
+	}
+}
+
+func TestApplyRemoteSDP(t *testing.T) {
+	mediaSess := &media.MediaSession{
+		Codecs: stringToCodecs([]string{"PCMU", "PCMA"}),
+		Raddr:  net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 30000},
+		Mode:   "sendrecv",
+	}
+	direction, err := applyRemoteSDP(mediaSess, []byte(testOfferSDP), mediaSess.Codecs, "sendrecv")
+	if err != nil {
+		t.Fatalf("applyRemoteSDP failed: %v", err)
+	}
+	if direction != "sendonly" || mediaSess.Mode != "recvonly" {
+		t.Errorf("expected sendonly offer answered recvonly, got %s / %s", direction, mediaSess.Mode)
+	}
+	if got := mediaSess.Raddr.String(); got != "10.0.0.5:40000" {
+		t.Errorf("Raddr = %s, want 10.0.0.5:40000", got)
+	}
+	names := make([]string, 0, len(mediaSess.Codecs))
+	for _, codec := range mediaSess.Codecs {
+		names = append(names, codec.Name)
+	}
+	if strings.Join(names, ",") != "PCMA,PCMU,telephone-event" {
+		t.Errorf("codecs = %v, want remote order PCMA,PCMU,telephone-event", names)
+	}
+
+	g729 := strings.Replace(testOfferSDP, "m=audio 40000 RTP/AVP 8 0 101", "m=audio 40000 RTP/AVP 18", 1)
+	rejected := strings.Replace(testOfferSDP, "m=audio 40000", "m=audio 0", 1)
+	for name, body := range map[string]string{"no common codec": g729, "rejected stream": rejected} {
+		before := *mediaSess
+		if _, err := applyRemoteSDP(mediaSess, []byte(body), stringToCodecs([]string{"PCMU"}), "sendrecv"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if mediaSess.Mode != before.Mode || mediaSess.Raddr.String() != before.Raddr.String() || len(mediaSess.Codecs) != len(before.Codecs) {
+			t.Errorf("%s: media session changed on failure", name)
+		}
+	}
+}
+
+func TestMatchesMediaUpdate(t *testing.T) {
+	codecs := []string{"PCMA", "telephone-event"}
+	tests := []struct {
+		name string
+		node GraphNode
+		want bool
+	}{
+		{name: "any", node: GraphNode{}, want: true},
+		{name: "direction", node: GraphNode{MediaDirection: "sendonly"}, want: true},
+		{name: "other direction", node: GraphNode{MediaDirection: "inactive"}, want: false},
+		{name: "codec", node: GraphNode{MediaCodec: "pcma"}, want: true},
+		{name: "missing codec", node: GraphNode{MediaDirection: "sendonly", MediaCodec: "PCMU"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesMediaUpdate(&tt.node, "sendonly", codecs); got != tt.want {
+				t.Errorf("matchesMediaUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteMediaUpdate_WithoutSDP(t *testing.T) {
+	ex, _ := newTestExecutor(t)
+	update := newFakeRefreshDialog("update-no-sdp", 200)
+	ex.sessions.StoreDialog("inst", "call-1", update)
+
+	if err := ex.executeMediaUpdate(context.Background(), "inst", &GraphNode{ID: "update", Command: "Update", CallID: "call-1", WithoutSDP: true}); err != nil {
+		t.Fatalf("Update without SDP failed: %v", err)
+	}
+	if sent := update.sent(); len(sent) != 1 || sent[0].Method != sip.UPDATE || len(sent[0].Body()) != 0 {
+		t.Fatalf("expected one UPDATE without body, got %v", sent)
+	}
+
+	reinvite := &fakeReInviteDialog{fakeRefreshDialog: newFakeRefreshDialog("reinvite-no-sdp", 200)}
+	ex.sessions.StoreDialog("inst", "call-2", reinvite)
+	if err := ex.executeMediaUpdate(context.Background(), "inst", &GraphNode{ID: "reinvite", Command: "ReInvite", CallID: "call-2", WithoutSDP: true}); err != nil {
+		t.Fatalf("ReInvite without SDP failed: %v", err)
+	}
+	if sent := reinvite.sent(); len(sent) != 1 || sent[0].Method != sip.INVITE || len(sent[0].Body()) != 0 {
+		t.Fatalf("expected one INVITE without body, got %v", sent)
+	}
+	if len(reinvite.acks) != 1 || reinvite.acks[0].Method != sip.ACK {
+		t.Errorf("expected an ACK for the 200 OK offer, got %v", reinvite.acks)
+	}
+
+	rejected := newFakeRefreshDialog("update-rejected", 488)
+	ex.sessions.StoreDialog("inst", "call-3", rejected)
+	err := ex.executeMediaUpdate(context.Background(), "inst", &GraphNode{ID: "rejected", Command: "Update", CallID: "call-3", WithoutSDP: true})
+	if err == nil || !strings.Contains(err.Error(), "488") {
+		t.Errorf("expected 488 failure, got %v", err)
+	}
+}
+
+func TestHandleUpdate_AnswersAndRaisesEvent(t *testing.T) {
+	ex, _ := newTestExecutor(t)
+	ex.sessions.StoreDialog("inst", "call-1", newFakeRefreshDialog("peer-update", 200))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	ua, err := sipgo.NewUA(sipgo.WithUserAgentHostname("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ua.Close()
+		conn.Close()
+	})
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.OnUpdate(func(req *sip.Request, tx sip.ServerTransaction) {
+		ex.handleUpdate("inst", req, tx)
+	})
+	go srv.ServeUDP(conn)
+
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname("127.0.0.1"), sipgo.WithClientPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	newUpdate := func(callID string) *sip.Request {
+		req := sip.NewRequest(sip.UPDATE, sip.Uri{Scheme: "sip", User: "100", Host: "127.0.0.1", Port: port})
+		header := sip.CallIDHeader(callID)
+		req.AppendHeader(&header)
+		return req
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		node := &GraphNode{ID: "update-received", Type: "event", CallID: "call-1"}
+		waitErr <- ex.executeMediaUpdateReceived(ctx, "inst", node, "UPDATE_RECEIVED", 3*time.Second)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	offer := newUpdate("peer-update")
+	offer.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+	offer.SetBody([]byte(testOfferSDP))
+	res, err := client.Do(ctx, offer)
+	if err != nil || res.StatusCode != sip.StatusNotAcceptableHere {
+		t.Fatalf("expected 488 for an offer without a media session, got %v (err %v)", res, err)
+	}
+
+	req := newUpdate("peer-update")
+	req.AppendHeader(sip.NewHeader("Session-Expires", "300;refresher=uas"))
+	res, err = client.Do(ctx, req)
+	if err != nil || res.StatusCode != sip.StatusOK {
+		t.Fatalf("expected 200 to UPDATE, got %v (err %v)", res, err)
+	}
+	if header := res.GetHeader("Session-Expires"); header == nil || header.Value() != "300;refresher=uas" {
+		t.Errorf("expected Session-Expires echoed, got %v", header)
+	}
+	if err := <-waitErr; err != nil {
+		t.Fatalf("UPDATE_RECEIVED was not raised: %v", err)
+	}
+
+	res, err = client.Do(ctx, newUpdate("unknown-call"))
+	if err != nil || res.StatusCode != sip.StatusCallTransactionDoesNotExists {
+		t.Errorf("expected 481 for an unknown dialog, got %v (err %v)", res, err)
+	}
+}
+
+func TestDryRun_ReInviteAndUpdate(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100", "codecs": []interface{}{"PCMU", "PCMA"}}},
+		{ID: "callee", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "codec", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "ReInvite", "codecs": []interface{}{"PCMA"}}},
+		{ID: "inactive", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "Update", "direction": "inactive"}},
+		{ID: "refresh", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "ReInvite", "withoutSdp": true}},
+
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "callee", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "callee", "command": "Answer"}},
+		{ID: "pcma", Type: "event", Data: map[string]interface{}{"sipInstanceId": "callee", "event": "REINVITE_RECEIVED", "codec": "PCMA"}},
+		{ID: "no-sdp", Type: "event", Data: map[string]interface{}{"sipInstanceId": "callee", "event": "REINVITE_RECEIVED"}},
+		{ID: "updated", Type: "event", Data: map[string]interface{}{"sipInstanceId": "callee", "event": "UPDATE_RECEIVED", "direction": "inactive"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "caller", Target: "call"},
+		{ID: "e2", Source: "call", Target: "codec"},
+		{ID: "e3", Source: "codec", Target: "inactive"},
+		{ID: "e4", Source: "inactive", Target: "refresh"},
+		{ID: "e5", Source: "callee", Target: "incoming"},
+		{ID: "e6", Source: "incoming", Target: "answer"},
+		{ID: "e7", Source: "answer", Target: "pcma"},
+		{ID: "e8", Source: "pcma", Target: "no-sdp"},
+		{ID: "e9", Source: "no-sdp", Target: "updated"},
+	}
+	te := runPBXFeatureDryRun(t, nodes, edges)
+
+	received := map[string]string{}
+	for _, log := range te.GetEventsByName(EventActionLog) {
+		if message := log.Data["message"].(string); strings.Contains(message, "event received") {
+			received[log.Data["nodeId"].(string)] = message
+		}
+	}
+	if !strings.Contains(received["pcma"], "sendrecv PCMA,telephone-event") {
+		t.Errorf("expected PCMA-only re-INVITE, got %q", received["pcma"])
+	}
+	if !strings.Contains(received["no-sdp"], "without SDP") {
+		t.Errorf("expected SDP-less re-INVITE, got %q", received["no-sdp"])
+	}
+}
+
+func TestParseScenario_MediaUpdateFields(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "inst", Type: "sipInstance", Data: map[string]interface{}{"label": "Phone", "dn": "100"}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "reinvite", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "ReInvite", "direction": "hold"}},
+		{ID: "update", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "Update", "withoutSdp": true, "codecs": []interface{}{"PCMU"}}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst", Target: "call"},
+		{ID: "e2", Source: "call", Target: "reinvite"},
+		{ID: "e3", Source: "reinvite", Target: "update"},
+	}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "unsupported direction") {
+		t.Errorf("expected unsupported direction error, got %v", err)
+	}
+	diags := ValidateScenario(flow)
+	for _, nodeID := range []string{"reinvite", "update"} {
+		if !hasCode(diags, nodeID, DiagInvalidMediaUpdate) {
+			t.Errorf("expected %s diagnostic on %s, got %+v", DiagInvalidMediaUpdate, nodeID, diags)
+		}
+	}
+
+	nodes[2].Data["direction"] = "inactive"
+	nodes[2].Data["codecs"] = []interface{}{"PCMA"}
+	nodes[2].Data["mediaAddress"] = "192.0.2.10:40000"
+	delete(nodes[3].Data, "codecs")
+	graph, err := ParseScenario(buildTestFlowData(t, nodes, edges))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	node := graph.Nodes["reinvite"]
+	if node.MediaDirection != "inactive" || len(node.MediaCodecs) != 1 || node.MediaAddress != "192.0.2.10:40000" {
+		t.Errorf("unexpected ReInvite fields %+v", node)
+	}
+	if !graph.Nodes["update"].WithoutSDP {
+		t.Error("expected withoutSdp on Update")
+	}
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// MessageEventReceived는 인스턴스가 dialog 밖 MESSAGE(RFC 3428)를 받을 때까지 대기하는 이벤트
+const MessageEventReceived = "MESSAGE_RECEIVED"
+
+// defaultMessageContentType은 contentType이 없는 SendMessage의 Content-Type
+const defaultMessageContentType = "text/plain"
+
+// instanceAllow는 인스턴스가 OPTIONS 응답에 광고하는 메서드
+const instanceAllow = "INVITE, ACK, CANCEL, BYE, REFER, NOTIFY, MESSAGE, OPTIONS, UPDATE"
+
+// receivedMessage는 인스턴스가 받은 MESSAGE 한 건
+type receivedMessage struct {
+	From        string // 발신 user (DN)
+	FromURI     string
+	ContentType string
+	Body        string
+	CallID      string
+}
+
+// messageHandler는 MESSAGE에 200으로 응답하고 ch로 전달한다. 대기 중인 노드가 없어 ch가 가득 차면 버린다.
+func messageHandler(ch chan<- receivedMessage) sipgo.RequestHandler {
+	return func(req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil))
+
+		msg := receivedMessage{Body: string(req.Body())}
+		if from := req.From(); from != nil {
+			msg.From = from.Address.User
+			msg.FromURI = from.Address.String()
+		}
+		if contentType := req.ContentType(); contentType != nil {
+			msg.ContentType = contentType.Value()
+		}
+		if callID := req.CallID(); callID != nil {
+			msg.CallID = callID.Value()
+		}
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// optionsHandler는 OPTIONS(keepalive/capability probe)에 Allow를 담아 200으로 응답한다
+func optionsHandler(req *sip.Request, tx sip.ServerTransaction) {
+	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+	res.AppendHeader(sip.NewHeader("Allow", instanceAllow))
+	res.AppendHeader(sip.NewHeader("Accept", "application/sdp, text/plain"))
+	_ = tx.Respond(res)
+}
+
+// matchesMessage는 MESSAGE_RECEIVED 노드의 발신자/본문 조건을 검사한다
+func matchesMessage(node *GraphNode, msg receivedMessage) bool {
+	if node.MessageFrom != "" && msg.From != simTargetUser(node.MessageFrom) {
+		return false
+	}
+	if node.MessageMatch == "" {
+		return true
+	}
+	re, err := regexp.Compile(node.MessageMatch)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(msg.Body)
+}
+
+// sendOutOfDialog는 인스턴스 UA로 dialog 밖 요청을 보내고 최종 응답을 반환한다.
+// From은 인스턴스 DN, Via는 인스턴스 listen 포트를 써서 응답이 인스턴스 UA로 돌아온다.
+func (ex *Executor) sendOutOfDialog(ctx context.Context, instanceID string, method sip.RequestMethod, target string, prepare func(req *sip.Request)) (*sip.Response, *sip.Request, error) {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	resolved, err := ex.im.ResolveTargetFor(instanceID, target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve target %q: %w", target, err)
+	}
+	var recipient sip.Uri
+	if err := sip.ParseUri(resolved, &recipient); err != nil {
+		return nil, nil, fmt.Errorf("invalid target URI %q: %w", resolved, err)
+	}
+
+	host := advertisedHost(resolveBindHost(instance.Config))
+	client, err := sipgo.NewClient(instance.SIPUA, sipgo.WithClientHostname(host), sipgo.WithClientPort(instance.Port))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	defer client.Close()
+
+	req := sip.NewRequest(method, recipient)
+	from := &sip.FromHeader{
+		Address: sip.Uri{Scheme: "sip", User: instance.Config.DN, Host: host},
+		Params:  sip.NewParams(),
+	}
+	from.Params.Add("tag", sip.GenerateTagN(16))
+	req.AppendHeader(from)
+	if prepare != nil {
+		prepare(req)
+	}
+
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, req, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return res, req, nil
+}
+
+// executeSendMessage는 targetUri로 MESSAGE를 보낸다. 2xx가 아니면 실패한다.
+func (ex *Executor) executeSendMessage(ctx context.Context, instanceID string, node *GraphNode) error {
+	if node.TargetURI == "" {
+		return fmt.Errorf("SendMessage requires a targetUri")
+	}
+	contentType := node.ContentType
+	if contentType == "" {
+		contentType = defaultMessageContentType
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendMessage to %s (%s, %d bytes)", node.TargetURI, contentType, len(node.MessageBody)), "info")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+	res, req, err := ex.sendOutOfDialog(timeoutCtx, instanceID, sip.MESSAGE, node.TargetURI, func(req *sip.Request) {
+		req.AppendHeader(sip.NewHeader("Content-Type", contentType))
+		req.SetBody([]byte(node.MessageBody))
+	})
+	if err != nil {
+		return fmt.Errorf("SendMessage: %w", err)
+	}
+	callID := req.CallID().Value()
+	if !res.IsSuccess() {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendMessage rejected with %d %s", res.StatusCode, res.Reason), "error",
+			WithSIPMessage("sent", "MESSAGE", res.StatusCode, callID, ex.instanceDN(instanceID), node.TargetURI))
+		return fmt.Errorf("SendMessage: rejected with %d %s", res.StatusCode, res.Reason)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendMessage succeeded (%d %s)", res.StatusCode, res.Reason), "info",
+		WithSIPMessage("sent", "MESSAGE", res.StatusCode, callID, ex.instanceDN(instanceID), node.TargetURI))
+	return nil
+}
+
+// executeSendOptions는 targetUri로 OPTIONS를 보내고 응답 코드가 expectedStatus인지 확인한다 (0이면 아무 최종 응답)
+func (ex *Executor) executeSendOptions(ctx context.Context, instanceID string, node *GraphNode) error {
+	if node.TargetURI == "" {
+		return fmt.Errorf("SendOptions requires a targetUri")
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendOptions to %s", node.TargetURI), "info")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+	start := time.Now()
+	res, req, err := ex.sendOutOfDialog(timeoutCtx, instanceID, sip.OPTIONS, node.TargetURI, func(req *sip.Request) {
+		req.AppendHeader(sip.NewHeader("Accept", "application/sdp"))
+	})
+	if err != nil {
+		return fmt.Errorf("SendOptions: %w", err)
+	}
+	rtt := time.Since(start).Round(time.Millisecond)
+	callID := req.CallID().Value()
+	logOpt := WithSIPMessage("sent", "OPTIONS", res.StatusCode, callID, ex.instanceDN(instanceID), node.TargetURI)
+
+	if node.ExpectedStatus != 0 && res.StatusCode != node.ExpectedStatus {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendOptions: got %d %s, expected %d", res.StatusCode, res.Reason, node.ExpectedStatus), "error", logOpt)
+		return fmt.Errorf("SendOptions: got %d %s, expected %d", res.StatusCode, res.Reason, node.ExpectedStatus)
+	}
+	capabilities := ""
+	if allow := res.GetHeader("Allow"); allow != nil {
+		capabilities = ", Allow: " + strings.TrimSpace(allow.Value())
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendOptions succeeded (%d %s in %v%s)", res.StatusCode, res.Reason, rtt, capabilities), "info", logOpt)
+	return nil
+}
+
+// executeMessageReceived는 messageFrom/messageMatch 조건에 맞는 MESSAGE를 받을 때까지 대기한다.
+// 조건에 맞지 않는 MESSAGE는 로그만 남기고 버린다.
+func (ex *Executor) executeMessageReceived(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	for {
+		select {
+		case msg := <-instance.messageCh:
+			logOpt := WithSIPMessage("received", "MESSAGE", 200, msg.CallID, msg.From, instance.Config.DN)
+			if !matchesMessage(node, msg) {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MESSAGE_RECEIVED: skipping MESSAGE from %s: %q", msg.From, msg.Body), "info", logOpt)
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MESSAGE_RECEIVED from %s (%s): %q", msg.From, msg.ContentType, msg.Body), "info", logOpt)
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("MESSAGE_RECEIVED event timeout after %v", timeout)
+		}
+	}
+}
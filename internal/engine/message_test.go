@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+func TestMessageHandlers_ReceiveMessageAndAnswerOptions(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	ua, err := sipgo.NewUA(sipgo.WithUserAgentHostname("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ua.Close()
+		conn.Close()
+	})
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan receivedMessage, 1)
+	srv.OnMessage(messageHandler(messages))
+	srv.OnOptions(optionsHandler)
+	go srv.ServeUDP(conn)
+
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname("127.0.0.1"), sipgo.WithClientPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: port}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	req := sip.NewRequest(sip.MESSAGE, recipient)
+	req.AppendHeader(&sip.FromHeader{Address: sip.Uri{Scheme: "sip", User: "100", Host: "127.0.0.1"}, Params: sip.NewParams()})
+	req.AppendHeader(sip.NewHeader("Content-Type", "text/plain"))
+	req.SetBody([]byte("your code is 4821"))
+	res, err := client.Do(ctx, req)
+	if err != nil || res.StatusCode != sip.StatusOK {
+		t.Fatalf("expected 200 to MESSAGE, got %v (err %v)", res, err)
+	}
+	select {
+	case msg := <-messages:
+		if msg.From != "100" || msg.ContentType != "text/plain" || msg.Body != "your code is 4821" {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("MESSAGE not delivered")
+	}
+
+	res, err = client.Do(ctx, sip.NewRequest(sip.OPTIONS, recipient))
+	if err != nil || res.StatusCode != sip.StatusOK {
+		t.Fatalf("expected 200 to OPTIONS, got %v (err %v)", res, err)
+	}
+	if allow := res.GetHeader("Allow"); allow == nil || !strings.Contains(allow.Value(), "MESSAGE") {
+		t.Errorf("expected Allow with MESSAGE, got %v", allow)
+	}
+}
+
+func TestMatchesMessage(t *testing.T) {
+	msg := receivedMessage{From: "100", Body: "your code is 4821"}
+	tests := []struct {
+		name string
+		node GraphNode
+		want bool
+	}{
+		{name: "any", node: GraphNode{}, want: true},
+		{name: "sender DN", node: GraphNode{MessageFrom: "100"}, want: true},
+		{name: "sender URI", node: GraphNode{MessageFrom: "sip:100@pbx"}, want: true},
+		{name: "other sender", node: GraphNode{MessageFrom: "300"}, want: false},
+		{name: "body pattern", node: GraphNode{MessageMatch: `code is \d{4}$`}, want: true},
+		{name: "body mismatch", node: GraphNode{MessageMatch: "^hello"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesMessage(&tt.node, msg); got != tt.want {
+				t.Errorf("matchesMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRun_MessageExchange(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "sender", Type: "sipInstance", Data: map[string]interface{}{"label": "Sender", "dn": "100"}},
+		{ID: "receiver", Type: "sipInstance", Data: map[string]interface{}{"label": "Receiver", "dn": "200"}},
+
+		{ID: "probe", Type: "command", Data: map[string]interface{}{"sipInstanceId": "sender", "command": "SendOptions", "targetUri": "200"}},
+		{ID: "noise", Type: "command", Data: map[string]interface{}{"sipInstanceId": "sender", "command": "SendMessage", "targetUri": "200", "body": "hello"}},
+		{ID: "otp", Type: "command", Data: map[string]interface{}{"sipInstanceId": "sender", "command": "SendMessage", "targetUri": "sip:200@pbx", "body": "your code is 4821"}},
+
+		{ID: "received", Type: "event", Data: map[string]interface{}{"sipInstanceId": "receiver", "event": "MESSAGE_RECEIVED", "messageFrom": "100", "messageMatch": `code is \d+`}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "sender", Target: "probe"},
+		{ID: "e2", Source: "probe", Target: "noise"},
+		{ID: "e3", Source: "noise", Target: "otp"},
+		{ID: "e4", Source: "receiver", Target: "received"},
+	}
+	te := runPBXFeatureDryRun(t, nodes, edges)
+
+	skipped := false
+	for _, log := range te.GetEventsByName(EventActionLog) {
+		if log.Data["nodeId"] == "received" && strings.Contains(log.Data["message"].(string), "skipping") {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Error("expected the non-matching MESSAGE to be skipped")
+	}
+}
+
+func TestParseScenario_MessageFields(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "inst", Type: "sipInstance", Data: map[string]interface{}{"label": "Phone", "dn": "100"}},
+		{ID: "options", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "SendOptions", "targetUri": "200", "expectedStatus": float64(99)}},
+		{ID: "received", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst", "event": "MESSAGE_RECEIVED", "messageMatch": "code ("}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst", Target: "options"},
+		{ID: "e2", Source: "options", Target: "received"},
+	}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "invalid messageMatch") {
+		t.Errorf("expected invalid messageMatch error, got %v", err)
+	}
+	diags := ValidateScenario(flow)
+	if !hasCode(diags, "received", DiagInvalidPattern) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidPattern, diags)
+	}
+	if !hasCode(diags, "options", DiagInvalidStatusCode) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidStatusCode, diags)
+	}
+}
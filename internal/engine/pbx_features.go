@@ -0,0 +1,444 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/sipgo/sip"
+)
+
+// FeatureCodes는 Park/Unpark/Pickup/SetForwarding 노드가 다이얼하는 PBX feature code 템플릿 (프로젝트 PBX 프로필).
+// {slot}, {target}, {number}(자기 DN)는 실행 시 치환한다.
+type FeatureCodes struct {
+	Park               string
+	ParkSlotHeader     string // Park의 최종 NOTIFY(헤더 또는 sipfrag 본문)나 REFER 응답에서 배정된 slot을 읽을 헤더 (비면 읽지 않음)
+	Unpark             string
+	Pickup             string
+	ForwardAlwaysOn    string
+	ForwardAlwaysOff   string
+	ForwardBusyOn      string
+	ForwardBusyOff     string
+	ForwardNoAnswerOn  string
+	ForwardNoAnswerOff string
+}
+
+// defaultFeatureCodes는 Asterisk/FreePBX 기본 feature code
+var defaultFeatureCodes = FeatureCodes{
+	Park:               "700",
+	Unpark:             "{slot}",
+	Pickup:             "**{target}",
+	ForwardAlwaysOn:    "*72{target}",
+	ForwardAlwaysOff:   "*73",
+	ForwardBusyOn:      "*90{target}",
+	ForwardBusyOff:     "*91",
+	ForwardNoAnswerOn:  "*52{target}",
+	ForwardNoAnswerOff: "*53",
+}
+
+// withDefaults는 비어 있는 템플릿을 기본값으로 채운다
+func (f FeatureCodes) withDefaults() FeatureCodes {
+	fill := func(value *string, fallback string) {
+		if strings.TrimSpace(*value) == "" {
+			*value = fallback
+		}
+	}
+	fill(&f.Park, defaultFeatureCodes.Park)
+	fill(&f.Unpark, defaultFeatureCodes.Unpark)
+	fill(&f.Pickup, defaultFeatureCodes.Pickup)
+	fill(&f.ForwardAlwaysOn, defaultFeatureCodes.ForwardAlwaysOn)
+	fill(&f.ForwardAlwaysOff, defaultFeatureCodes.ForwardAlwaysOff)
+	fill(&f.ForwardBusyOn, defaultFeatureCodes.ForwardBusyOn)
+	fill(&f.ForwardBusyOff, defaultFeatureCodes.ForwardBusyOff)
+	fill(&f.ForwardNoAnswerOn, defaultFeatureCodes.ForwardNoAnswerOn)
+	fill(&f.ForwardNoAnswerOff, defaultFeatureCodes.ForwardNoAnswerOff)
+	return f
+}
+
+// SetForwarding 노드의 착신전환 종류 (forwardType)
+const (
+	ForwardTypeAlways   = "always"
+	ForwardTypeBusy     = "busy"
+	ForwardTypeNoAnswer = "noAnswer"
+)
+
+// Pickup 노드의 당겨받기 방식 (pickupMode)
+const (
+	PickupModeFeatureCode = "featureCode" // 당겨받기 feature code 다이얼
+	PickupModeReplaces    = "replaces"    // dialog-info로 찾은 착신 dialog를 INVITE with Replaces로 대체
+)
+
+// forwardCode는 착신전환 종류와 설정/해제에 맞는 템플릿을 반환한다
+func (f FeatureCodes) forwardCode(forwardType string, enabled bool) (string, error) {
+	switch forwardType {
+	case ForwardTypeAlways:
+		if enabled {
+			return f.ForwardAlwaysOn, nil
+		}
+		return f.ForwardAlwaysOff, nil
+	case ForwardTypeBusy:
+		if enabled {
+			return f.ForwardBusyOn, nil
+		}
+		return f.ForwardBusyOff, nil
+	case ForwardTypeNoAnswer:
+		if enabled {
+			return f.ForwardNoAnswerOn, nil
+		}
+		return f.ForwardNoAnswerOff, nil
+	}
+	return "", fmt.Errorf("unsupported forwardType %q", forwardType)
+}
+
+var featurePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// expandFeatureCode는 템플릿의 {name}을 values로 치환한다. 값이 없는 placeholder가 남으면 에러.
+func expandFeatureCode(template string, values map[string]string) (string, error) {
+	var missing []string
+	code := featurePlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+		if value := values[name]; value != "" {
+			return value
+		}
+		missing = append(missing, name)
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("feature code %q needs {%s}", template, strings.Join(missing, "}, {"))
+	}
+	if code == "" {
+		return "", fmt.Errorf("feature code is empty")
+	}
+	return code, nil
+}
+
+// recordParkedSlot은 이 run에서 마지막으로 주차한 slot을 기억한다 (slot 없이 Unpark하면 사용)
+func (ex *Executor) recordParkedSlot(slot string) {
+	ex.parkMu.Lock()
+	defer ex.parkMu.Unlock()
+	ex.parkedSlot = slot
+}
+
+func (ex *Executor) lastParkedSlot() string {
+	ex.parkMu.Lock()
+	defer ex.parkMu.Unlock()
+	return ex.parkedSlot
+}
+
+// unparkSlot은 노드의 parkSlot, 없으면 마지막으로 주차한 slot을 반환한다
+func (ex *Executor) unparkSlot(node *GraphNode) string {
+	if node.ParkSlot != "" {
+		return node.ParkSlot
+	}
+	return ex.lastParkedSlot()
+}
+
+func (ex *Executor) instanceDN(instanceID string) string {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return ""
+	}
+	return instance.Config.DN
+}
+
+// inDialogRequester는 dialog 안에서 임의 요청을 보내고 최종 응답을 받을 수 있는 dialog
+type inDialogRequester interface {
+	diago.DialogSession
+	Do(ctx context.Context, req *sip.Request) (*sip.Response, error)
+	RemoteContact() *sip.ContactHeader
+}
+
+// executePark는 dialog의 상대방을 park feature code로 REFER하고, 최종 NOTIFY(sipfrag)를 받은 뒤 BYE를 보낸다.
+// 배정된 slot은 프로필의 ParkSlotHeader를 최종 NOTIFY 헤더, sipfrag 헤더, REFER 응답 헤더 순으로 찾고, 없으면 노드의 parkSlot을 쓴다.
+func (ex *Executor) executePark(ctx context.Context, instanceID string, node *GraphNode) error {
+	callID := callIDOrDefault(node)
+	dialog, exists := ex.sessions.GetDialog(instanceID, callID)
+	if !exists {
+		return fmt.Errorf("Park: no active dialog for %s/%s", instanceID, callID)
+	}
+	if err := dialog.Context().Err(); err != nil {
+		return fmt.Errorf("Park: dialog %s/%s already terminated", instanceID, callID)
+	}
+	requester, ok := dialog.(inDialogRequester)
+	if !ok {
+		return fmt.Errorf("Park: dialog type %T does not support in-dialog requests", dialog)
+	}
+	remote := requester.RemoteContact()
+	if remote == nil {
+		return fmt.Errorf("Park: remote contact of %s/%s is missing", instanceID, callID)
+	}
+	sipCallID := dialogSIPCallID(dialog)
+	if sipCallID == "" {
+		return fmt.Errorf("Park: no SIP Call-ID for dialog %s/%s", instanceID, callID)
+	}
+
+	code, err := expandFeatureCode(ex.features.Park, map[string]string{"slot": node.ParkSlot, "number": ex.instanceDN(instanceID)})
+	if err != nil {
+		return fmt.Errorf("Park: %w", err)
+	}
+	resolved, err := ex.im.ResolveTargetFor(instanceID, code)
+	if err != nil {
+		return fmt.Errorf("Park: failed to resolve park code %q: %w", code, err)
+	}
+	var referTo sip.Uri
+	if err := sip.ParseUri(resolved, &referTo); err != nil {
+		return fmt.Errorf("Park: invalid park URI %q: %w", resolved, err)
+	}
+
+	// REFER를 보내기 전에 등록해야 응답보다 먼저 도착한 NOTIFY도 받는다
+	notifyCh := ex.watchReferNotify(sipCallID)
+	defer ex.unwatchReferNotify(sipCallID)
+
+	req := sip.NewRequest(sip.REFER, *remote.Address.Clone())
+	req.AppendHeader(sip.NewHeader("Refer-To", "<"+referTo.String()+">"))
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park: sending REFER to %s", referTo.String()), "info")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+	res, err := requester.Do(timeoutCtx, req)
+	if err != nil {
+		return fmt.Errorf("Park: REFER failed: %w", err)
+	}
+	if !res.IsSuccess() {
+		return fmt.Errorf("Park: REFER rejected with %d %s", res.StatusCode, res.Reason)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park: REFER accepted with %d", res.StatusCode), "info",
+		WithSIPMessage("sent", "REFER", res.StatusCode, "", "", referTo.String()))
+
+	notifySlot, statusCode, err := ex.waitParkNotify(timeoutCtx, instanceID, node, dialog, notifyCh)
+	if err != nil {
+		return err
+	}
+
+	slot := notifySlot
+	if name := ex.features.ParkSlotHeader; slot == "" && name != "" {
+		if header := res.GetHeader(name); header != nil {
+			slot = strings.TrimSpace(header.Value())
+		}
+	}
+	if slot == "" {
+		slot = node.ParkSlot
+	}
+	if slot != "" {
+		ex.recordParkedSlot(slot)
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park succeeded (slot %s)", slot), "info",
+			WithSIPMessage("received", "NOTIFY", statusCode, "", "", ""))
+	} else {
+		ex.emitNodeActionLog(node, instanceID, "Park succeeded (slot assigned by PBX was not reported)", "warn",
+			WithSIPMessage("received", "NOTIFY", statusCode, "", "", ""))
+	}
+
+	hangupCtx, hangupCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer hangupCancel()
+	if err := dialog.Hangup(hangupCtx); err != nil {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park: BYE warning: %v", err), "warn")
+	} else {
+		ex.emitNodeActionLog(node, instanceID, "Park: BYE sent", "info",
+			WithSIPMessage("sent", "BYE", 200, "", "", ""))
+	}
+	return nil
+}
+
+// waitParkNotify는 Park REFER의 최종 NOTIFY를 기다려 ParkSlotHeader로 찾은 slot과 sipfrag 상태 코드를 반환한다.
+// 1xx sipfrag는 진행 로그만 남기고, 2xx가 아닌 최종 상태는 park 실패로 본다.
+func (ex *Executor) waitParkNotify(ctx context.Context, instanceID string, node *GraphNode, dialog diago.DialogSession, notifyCh <-chan *sip.Request) (string, int, error) {
+	for {
+		select {
+		case req := <-notifyCh:
+			frag, err := parseSipFrag(req.Body())
+			if err != nil {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park: ignoring NOTIFY: %v", err), "warn")
+				continue
+			}
+			if frag.StatusCode < 200 {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park: NOTIFY progress %d", frag.StatusCode), "info",
+					WithSIPMessage("received", "NOTIFY", frag.StatusCode, "", "", ""))
+				continue
+			}
+			if frag.StatusCode >= 300 {
+				return "", frag.StatusCode, fmt.Errorf("Park: final NOTIFY failed with status %d", frag.StatusCode)
+			}
+
+			slot := ""
+			if name := ex.features.ParkSlotHeader; name != "" {
+				if header := req.GetHeader(name); header != nil {
+					slot = strings.TrimSpace(header.Value())
+				}
+				if slot == "" {
+					slot = frag.Headers[strings.ToLower(name)]
+				}
+			}
+			return slot, frag.StatusCode, nil
+		case <-dialog.Context().Done():
+			return "", 0, fmt.Errorf("Park: dialog ended before the final NOTIFY")
+		case <-ctx.Done():
+			return "", 0, fmt.Errorf("Park: final NOTIFY timeout after %v", node.Timeout)
+		}
+	}
+}
+
+// watchReferNotify는 sipCallID dialog로 오는 REFER NOTIFY를 받을 채널을 등록한다
+func (ex *Executor) watchReferNotify(sipCallID string) <-chan *sip.Request {
+	ch := make(chan *sip.Request, 8)
+	ex.referNotifyMu.Lock()
+	ex.referNotifies[sipCallID] = ch
+	ex.referNotifyMu.Unlock()
+	return ch
+}
+
+func (ex *Executor) unwatchReferNotify(sipCallID string) {
+	ex.referNotifyMu.Lock()
+	delete(ex.referNotifies, sipCallID)
+	ex.referNotifyMu.Unlock()
+}
+
+// handleReferNotify는 InstanceManager가 관찰한 REFER NOTIFY를 기다리는 Park에 전달한다.
+// 응답은 diago가 보내므로 여기서는 전달만 하고, 기다리는 노드가 없거나 버퍼가 차면 버린다.
+func (ex *Executor) handleReferNotify(instanceID string, req *sip.Request) {
+	header := req.CallID()
+	if header == nil {
+		return
+	}
+	ex.referNotifyMu.Lock()
+	ch, exists := ex.referNotifies[header.Value()]
+	ex.referNotifyMu.Unlock()
+	if !exists {
+		return
+	}
+	select {
+	case ch <- req:
+	default:
+	}
+}
+
+// sipFrag는 REFER NOTIFY의 message/sipfrag 본문 (RFC 3420)
+type sipFrag struct {
+	StatusCode int
+	Headers    map[string]string // 소문자 헤더 이름 -> 값
+}
+
+// parseSipFrag는 "SIP/2.0 200 OK" 상태 줄과 뒤따르는 헤더를 읽는다
+func parseSipFrag(body []byte) (*sipFrag, error) {
+	lines := strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "SIP/") {
+		return nil, fmt.Errorf("invalid sipfrag status line %q", lines[0])
+	}
+	code, err := strconv.Atoi(fields[1])
+	if err != nil || code < 100 || code > 699 {
+		return nil, fmt.Errorf("invalid sipfrag status code %q", fields[1])
+	}
+
+	frag := &sipFrag{StatusCode: code, Headers: make(map[string]string)}
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		frag.Headers[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return frag, nil
+}
+
+// executeUnpark는 unpark feature code로 주차된 통화를 되찾아 callID dialog로 저장한다
+func (ex *Executor) executeUnpark(ctx context.Context, instanceID string, node *GraphNode) error {
+	slot := ex.unparkSlot(node)
+	code, err := expandFeatureCode(ex.features.Unpark, map[string]string{"slot": slot, "number": ex.instanceDN(instanceID)})
+	if err != nil {
+		return fmt.Errorf("Unpark: %w (set parkSlot or run Park first)", err)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unpark: dialing %s (slot %s)", code, slot), "info")
+
+	dialog, _, _, err := ex.inviteTarget(ctx, instanceID, code, node.Timeout, diago.InviteOptions{})
+	if err != nil {
+		return fmt.Errorf("Unpark: %w", err)
+	}
+	ex.sessions.StoreDialog(instanceID, callIDOrDefault(node), dialog)
+	ex.startSessionTimer(instanceID, callIDOrDefault(node), node, dialog, SessionRefresherUAC)
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unpark succeeded (slot %s)", slot), "info",
+		WithSIPMessage("sent", "INVITE", 200, "", ex.instanceDN(instanceID), code))
+	return nil
+}
+
+// executePickup은 pickupTarget에서 울리는 호를 당겨받는다.
+// featureCode 방식은 당겨받기 코드를 다이얼하고, replaces 방식은 dialog-info로 착신 dialog를 찾아 INVITE with Replaces를 보낸다.
+func (ex *Executor) executePickup(ctx context.Context, instanceID string, node *GraphNode) error {
+	if node.PickupTarget == "" {
+		return fmt.Errorf("Pickup: pickupTarget is required")
+	}
+
+	if node.PickupMode != PickupModeReplaces {
+		code, err := expandFeatureCode(ex.features.Pickup, map[string]string{"target": node.PickupTarget, "number": ex.instanceDN(instanceID)})
+		if err != nil {
+			return fmt.Errorf("Pickup: %w", err)
+		}
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup: dialing %s for %s", code, node.PickupTarget), "info")
+		dialog, _, _, err := ex.inviteTarget(ctx, instanceID, code, node.Timeout, diago.InviteOptions{})
+		if err != nil {
+			return fmt.Errorf("Pickup: %w", err)
+		}
+		ex.sessions.StoreDialog(instanceID, callIDOrDefault(node), dialog)
+		ex.startSessionTimer(instanceID, callIDOrDefault(node), node, dialog, SessionRefresherUAC)
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup succeeded (%s via %s)", node.PickupTarget, code), "info",
+			WithSIPMessage("sent", "INVITE", 200, "", ex.instanceDN(instanceID), code))
+		return nil
+	}
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup: fetching dialog-info of %s", node.PickupTarget), "info")
+	info, err := ex.fetchDialogInfo(ctx, instanceID, node.PickupTarget, node.Timeout)
+	if err != nil {
+		return fmt.Errorf("Pickup: %w", err)
+	}
+	ringing, found := info.ringingDialog()
+	if !found {
+		return fmt.Errorf("Pickup: %s has no ringing call", node.PickupTarget)
+	}
+	replaces := ringing.replaces()
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup: replacing ringing dialog %s", ringing.CallID), "info")
+
+	dialog, recipient, _, err := ex.inviteTarget(ctx, instanceID, node.PickupTarget, node.Timeout, diago.InviteOptions{
+		Headers: []sip.Header{sip.NewHeader("Replaces", replaces)},
+	})
+	if err != nil {
+		return fmt.Errorf("Pickup: %w", err)
+	}
+	ex.sessions.StoreDialog(instanceID, callIDOrDefault(node), dialog)
+	ex.startSessionTimer(instanceID, callIDOrDefault(node), node, dialog, SessionRefresherUAC)
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup succeeded (%s, Replaces: %s)", node.PickupTarget, replaces), "info",
+		WithSIPMessage("sent", "INVITE", 200, ringing.CallID, ex.instanceDN(instanceID), recipient.User))
+	return nil
+}
+
+// executeSetForwarding은 착신전환 feature code를 다이얼하고 PBX가 응답하면 끊는다
+func (ex *Executor) executeSetForwarding(ctx context.Context, instanceID string, node *GraphNode) error {
+	template, err := ex.features.forwardCode(node.ForwardType, node.ForwardEnabled)
+	if err != nil {
+		return fmt.Errorf("SetForwarding: %w", err)
+	}
+	code, err := expandFeatureCode(template, map[string]string{"target": node.ForwardTarget, "number": ex.instanceDN(instanceID)})
+	if err != nil {
+		return fmt.Errorf("SetForwarding: %w", err)
+	}
+	state := "off"
+	if node.ForwardEnabled {
+		state = "on -> " + node.ForwardTarget
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SetForwarding: dialing %s (%s %s)", code, node.ForwardType, state), "info")
+
+	dialog, _, _, err := ex.inviteTarget(ctx, instanceID, code, node.Timeout, diago.InviteOptions{})
+	if err != nil {
+		return fmt.Errorf("SetForwarding: %w", err)
+	}
+	hangupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := dialog.Hangup(hangupCtx); err != nil {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SetForwarding: BYE warning: %v", err), "warn")
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SetForwarding succeeded (%s %s)", node.ForwardType, state), "info",
+		WithSIPMessage("sent", "INVITE", 200, "", ex.instanceDN(instanceID), code))
+	return nil
+}
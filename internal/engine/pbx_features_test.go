@@ -0,0 +1,238 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandFeatureCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]string
+		want     string
+		wantErr  string
+	}{
+		{name: "no placeholder", template: "700", want: "700"},
+		{name: "target", template: "*72{target}", values: map[string]string{"target": "300"}, want: "*72300"},
+		{name: "slot only", template: "{slot}", values: map[string]string{"slot": "701"}, want: "701"},
+		{name: "missing value", template: "**{target}", wantErr: "needs {target}"},
+		{name: "empty", template: "", wantErr: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandFeatureCode(tt.template, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expandFeatureCode(%q) = %q, %v; want %q", tt.template, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeatureCodes_WithDefaultsAndForwardCode(t *testing.T) {
+	codes := FeatureCodes{Park: "*8", ForwardBusyOn: "*40{target}"}.withDefaults()
+	if codes.Park != "*8" || codes.Pickup != defaultFeatureCodes.Pickup {
+		t.Errorf("expected custom park and default pickup, got %+v", codes)
+	}
+	if code, _ := codes.forwardCode(ForwardTypeBusy, true); code != "*40{target}" {
+		t.Errorf("expected profile busy code, got %q", code)
+	}
+	if code, _ := codes.forwardCode(ForwardTypeNoAnswer, false); code != "*53" {
+		t.Errorf("expected default no-answer off code, got %q", code)
+	}
+	if _, err := codes.forwardCode("unavailable", true); err == nil {
+		t.Error("expected error for unsupported forwardType")
+	}
+}
+
+func TestParseDialogInfo_RingingDialog(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<dialog-info xmlns="urn:ietf:params:xml:ns:dialog-info" version="3" state="full" entity="sip:200@pbx">
+  <dialog id="d1" call-id="out-1" local-tag="l1" remote-tag="r1" direction="initiator"><state>early</state></dialog>
+  <dialog id="d2" call-id="in-1" local-tag="callee-tag" remote-tag="caller-tag" direction="recipient"><state>early</state></dialog>
+</dialog-info>`
+	info, err := parseDialogInfo([]byte(body))
+	if err != nil {
+		t.Fatalf("parseDialogInfo failed: %v", err)
+	}
+	ringing, found := info.ringingDialog()
+	if !found || ringing.CallID != "in-1" {
+		t.Fatalf("expected ringing dialog in-1, got %+v (found=%v)", ringing, found)
+	}
+	if got, want := ringing.replaces(), "in-1;to-tag=callee-tag;from-tag=caller-tag;early-only"; got != want {
+		t.Errorf("replaces() = %q, want %q", got, want)
+	}
+
+	if _, err := parseDialogInfo([]byte("not xml")); err == nil {
+		t.Error("expected error for invalid dialog-info body")
+	}
+}
+
+func TestParseSipFrag(t *testing.T) {
+	frag, err := parseSipFrag([]byte("SIP/2.0 200 OK\r\nX-Park-Slot: 701\r\n"))
+	if err != nil {
+		t.Fatalf("parseSipFrag failed: %v", err)
+	}
+	if frag.StatusCode != 200 {
+		t.Errorf("StatusCode = %d, want 200", frag.StatusCode)
+	}
+	if got := frag.Headers["x-park-slot"]; got != "701" {
+		t.Errorf("x-park-slot = %q, want 701", got)
+	}
+
+	for _, body := range []string{"", "hello", "SIP/2.0 abc Bad"} {
+		if _, err := parseSipFrag([]byte(body)); err == nil {
+			t.Errorf("expected error for sipfrag %q", body)
+		}
+	}
+}
+
+func runPBXFeatureDryRun(t *testing.T, nodes []FlowNode, edges []FlowEdge) *TestEventEmitter {
+	t.Helper()
+	eng, repo, te := newDryRunEngine(t)
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+	return te
+}
+
+func TestDryRun_ParkAndUnpark(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "agent", Type: "sipInstance", Data: map[string]interface{}{"label": "Agent", "dn": "200"}},
+		{ID: "retriever", Type: "sipInstance", Data: map[string]interface{}{"label": "Retriever", "dn": "300"}},
+
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "transferred", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "TRANSFERRED"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "DISCONNECTED"}},
+
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "agent", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Answer"}},
+		{ID: "park", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Park"}},
+		{ID: "parked", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Signal", "signalName": "parked"}},
+
+		{ID: "wait-parked", Type: "event", Data: map[string]interface{}{"sipInstanceId": "retriever", "event": "WaitSignal", "signalName": "parked"}},
+		{ID: "unpark", Type: "command", Data: map[string]interface{}{"sipInstanceId": "retriever", "command": "Unpark"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "retriever", "command": "Release"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "caller", Target: "call"},
+		{ID: "e2", Source: "call", Target: "transferred"},
+		{ID: "e3", Source: "transferred", Target: "disconnected"},
+		{ID: "e4", Source: "agent", Target: "incoming"},
+		{ID: "e5", Source: "incoming", Target: "answer"},
+		{ID: "e6", Source: "answer", Target: "park"},
+		{ID: "e7", Source: "park", Target: "parked"},
+		{ID: "e8", Source: "retriever", Target: "wait-parked"},
+		{ID: "e9", Source: "wait-parked", Target: "unpark"},
+		{ID: "e10", Source: "unpark", Target: "release"},
+	}
+	te := runPBXFeatureDryRun(t, nodes, edges)
+
+	found := false
+	for _, log := range te.GetEventsByName(EventActionLog) {
+		if log.Data["nodeId"] == "unpark" && strings.Contains(log.Data["message"].(string), "slot 701") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected Unpark to retrieve the auto-assigned slot 701")
+	}
+}
+
+func TestDryRun_PickupRingingCall(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "callee", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+		{ID: "picker", Type: "sipInstance", Data: map[string]interface{}{"label": "Picker", "dn": "300"}},
+
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "Release"}},
+
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "callee", "event": "INCOMING"}},
+		{ID: "ringing", Type: "command", Data: map[string]interface{}{"sipInstanceId": "callee", "command": "Signal", "signalName": "ringing"}},
+
+		{ID: "wait-ringing", Type: "event", Data: map[string]interface{}{"sipInstanceId": "picker", "event": "WaitSignal", "signalName": "ringing"}},
+		{ID: "pickup", Type: "command", Data: map[string]interface{}{"sipInstanceId": "picker", "command": "Pickup", "pickupTarget": "200", "pickupMode": "replaces"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "picker", "event": "DISCONNECTED"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "caller", Target: "call"},
+		{ID: "e2", Source: "call", Target: "release"},
+		{ID: "e3", Source: "callee", Target: "incoming"},
+		{ID: "e4", Source: "incoming", Target: "ringing"},
+		{ID: "e5", Source: "picker", Target: "wait-ringing"},
+		{ID: "e6", Source: "wait-ringing", Target: "pickup"},
+		{ID: "e7", Source: "pickup", Target: "disconnected"},
+	}
+	te := runPBXFeatureDryRun(t, nodes, edges)
+	for _, id := range []string{"call", "pickup", "disconnected"} {
+		if !waitForNodeState(t, te, id, NodeStateCompleted, time.Second) {
+			t.Errorf("expected node %s to complete", id)
+		}
+	}
+}
+
+func TestDryRun_SetForwardingRedirectsCalls(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "forwarder", Type: "sipInstance", Data: map[string]interface{}{"label": "Forwarder", "dn": "200"}},
+		{ID: "target", Type: "sipInstance", Data: map[string]interface{}{"label": "Target", "dn": "300"}},
+
+		{ID: "wait-forward", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "WaitSignal", "signalName": "forwarded"}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "Release"}},
+
+		{ID: "forward", Type: "command", Data: map[string]interface{}{"sipInstanceId": "forwarder", "command": "SetForwarding", "forwardType": "always", "forwardTarget": "300"}},
+		{ID: "forwarded", Type: "command", Data: map[string]interface{}{"sipInstanceId": "forwarder", "command": "Signal", "signalName": "forwarded"}},
+
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "target", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "target", "command": "Answer"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "target", "event": "DISCONNECTED"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "caller", Target: "wait-forward"},
+		{ID: "e2", Source: "wait-forward", Target: "call"},
+		{ID: "e3", Source: "call", Target: "release"},
+		{ID: "e4", Source: "forwarder", Target: "forward"},
+		{ID: "e5", Source: "forward", Target: "forwarded"},
+		{ID: "e6", Source: "target", Target: "incoming"},
+		{ID: "e7", Source: "incoming", Target: "answer"},
+		{ID: "e8", Source: "answer", Target: "disconnected"},
+	}
+	runPBXFeatureDryRun(t, nodes, edges)
+}
+
+func TestParseScenario_PBXFeatureOptions(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "inst", Type: "sipInstance", Data: map[string]interface{}{"label": "Phone", "dn": "100"}},
+		{ID: "pickup", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "Pickup", "pickupTarget": "200", "pickupMode": "directed"}},
+		{ID: "forward", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "SetForwarding", "forwardType": "busy"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst", Target: "pickup"},
+		{ID: "e2", Source: "pickup", Target: "forward"},
+	}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "unsupported pickupMode") {
+		t.Errorf("expected unsupported pickupMode error, got %v", err)
+	}
+	diags := ValidateScenario(flow)
+	if !hasCode(diags, "pickup", DiagInvalidFeatureOption) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidFeatureOption, diags)
+	}
+	if !hasCode(diags, "forward", DiagMissingField) {
+		t.Errorf("expected %s diagnostic for missing forwardTarget, got %+v", DiagMissingField, diags)
+	}
+}
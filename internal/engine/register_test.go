@@ -134,7 +134,7 @@ func TestStartScenario_RegisterOnlyWaitsUntilStopped(t *testing.T) {
 		t.Fatalf("SaveScenario failed: %v", err)
 	}
 
-	if err := eng.StartScenario(scn.ID); err != nil {
+	if _, err := eng.StartScenario(scn.ID); err != nil {
 		t.Fatalf("StartScenario failed: %v", err)
 	}
 
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy는 실패한 노드 또는 run의 재시도 정책
+type RetryPolicy struct {
+	MaxAttempts   int           // 최초 실행을 포함한 총 시도 횟수 (1 이하면 재시도 없음)
+	Backoff       time.Duration // 첫 재시도 전 대기 시간
+	BackoffFactor float64       // 재시도마다 대기 시간에 곱하는 값 (1 미만이면 고정 대기)
+	MaxBackoff    time.Duration // 대기 시간 상한 (0이면 제한 없음)
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// delay는 attempt번째 시도가 실패한 뒤 다음 시도까지의 대기 시간 (attempt는 1부터)
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(p.Backoff)
+	if p.BackoffFactor > 1 {
+		for i := 1; i < attempt; i++ {
+			d *= p.BackoffFactor
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// sleepContext는 d만큼 대기하며 ctx 취소 시 즉시 반환한다
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryPolicy는 노드 데이터의 retryMaxAttempts/retryBackoffMs/retryBackoffFactor/retryMaxBackoffMs를 읽는다
+func parseRetryPolicy(data map[string]interface{}) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   int(getFloatField(data, "retryMaxAttempts", 1)),
+		Backoff:       time.Duration(getFloatField(data, "retryBackoffMs", 0)) * time.Millisecond,
+		BackoffFactor: getFloatField(data, "retryBackoffFactor", 1),
+		MaxBackoff:    time.Duration(getFloatField(data, "retryMaxBackoffMs", 0)) * time.Millisecond,
+	}
+}
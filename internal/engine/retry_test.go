@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, Backoff: 100 * time.Millisecond, BackoffFactor: 2, MaxBackoff: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := p.delay(i + 1); got != w {
+			t.Errorf("delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+	if got := (RetryPolicy{}).attempts(); got != 1 {
+		t.Errorf("zero policy attempts = %d, want 1", got)
+	}
+}
+
+func TestNodeRetry_FlakyNodeRecovers(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	nodes[2].Data["retryMaxAttempts"] = float64(2)
+	nodes[2].Data["retryBackoffMs"] = float64(10)
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	result, err := eng.RunScenario(context.Background(), scenarioID, &SimulationOptions{FlakyNodes: map[string]int{"make-call": 1}})
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusCompleted {
+		t.Fatalf("expected completed run after node retry, got %+v", result)
+	}
+	if result.NodeRetries != 1 || !result.Flaky() {
+		t.Errorf("expected one node retry and a flaky result, got %+v", result)
+	}
+
+	var retrying bool
+	for _, e := range te.GetEventsByName(EventNodeState) {
+		if e.Data["nodeId"] == "make-call" && e.Data["newState"] == NodeStateRetrying {
+			retrying = true
+			if e.Data["attempt"] != 1 || e.Data["maxAttempts"] != 2 {
+				t.Errorf("unexpected retrying event: %v", e.Data)
+			}
+		}
+	}
+	if !retrying {
+		t.Error("expected a retrying node-state event for make-call")
+	}
+}
+
+func TestRunScenarioWithRetry_CountsAttempts(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	opts := &SimulationOptions{FailNodes: map[string]string{"make-call": "503 Service Unavailable"}}
+	result, err := eng.RunScenarioWithRetry(context.Background(), scenarioID, opts, RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunScenarioWithRetry failed: %v", err)
+	}
+	if result.Status != RunStatusFailed || result.Attempts != 3 || result.Flaky() {
+		t.Errorf("expected failed result after 3 attempts, got %+v", result)
+	}
+
+	result, err = eng.RunScenarioWithRetry(context.Background(), scenarioID, &SimulationOptions{}, RetryPolicy{MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("RunScenarioWithRetry failed: %v", err)
+	}
+	if result.Status != RunStatusCompleted || result.Attempts != 1 || result.Flaky() {
+		t.Errorf("expected first-attempt pass, got %+v", result)
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scenarioRun은 시나리오 한 번의 실행 컨텍스트.
+// run마다 InstanceManager(포트 범위)와 Executor(SessionStore, SyncRegistry), Debugger가 분리된다.
+type scenarioRun struct {
+	id         string
+	scenarioID string
+	dryRun     bool
+	startedAt  time.Time
+	portSlot   int // 예약한 포트 범위 슬롯 (dry-run은 -1)
+	im         *InstanceManager
+	debugger   *Debugger
+	done       chan struct{} // run 종료(정리 및 종료 이벤트 발행 완료) 시 close
+
+	mu       sync.Mutex
+	executor *Executor
+	cancel   context.CancelFunc
+	terminal scenarioTerminalState
+	wg       sync.WaitGroup
+	result   RunResult // done이 close된 후에만 유효
+}
+
+// markTerminalState는 실행 중인 run의 종료 상태를 정하고, 상태가 바뀌었는지 반환한다
+func (r *scenarioRun) markTerminalState(next scenarioTerminalState) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.terminal != terminalStateRunning {
+		return false
+	}
+	r.terminal = next
+	return true
+}
+
+// RunInfo는 실행 중인 run의 요약 정보
+type RunInfo struct {
+	RunID      string `json:"runId"`
+	ScenarioID string `json:"scenarioId"`
+	DryRun     bool   `json:"dryRun"`
+	Paused     bool   `json:"paused"`
+	PortBase   int    `json:"portBase,omitempty"` // 할당된 포트 범위 시작 (dry-run은 0)
+	StartedAt  int64  `json:"startedAt"`          // unix ms (이벤트 timestamp와 동일한 단위)
+}
+
+// run 종료 상태
+const (
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	RunStatusStopped   = "stopped"
+)
+
+// RunResult는 종료된 run의 결과
+type RunResult struct {
+	RunID       string    `json:"runId"`
+	ScenarioID  string    `json:"scenarioId"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	Attempts    int       `json:"attempts"`    // run 단위 재시도를 포함한 시도 횟수
+	NodeRetries int       `json:"nodeRetries"` // 노드 재시도 횟수 (마지막 시도 기준)
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+}
+
+// Flaky는 재시도 끝에 성공한 run인지 반환한다 (실제 회귀와 구분하기 위함)
+func (r *RunResult) Flaky() bool {
+	return r.Status == RunStatusCompleted && (r.Attempts > 1 || r.NodeRetries > 0)
+}
+
+func (r *scenarioRun) info() RunInfo {
+	paused, _, _, _ := r.debugger.state()
+	info := RunInfo{
+		RunID:      r.id,
+		ScenarioID: r.scenarioID,
+		DryRun:     r.dryRun,
+		Paused:     paused,
+		StartedAt:  r.startedAt.UnixMilli(),
+	}
+	if r.portSlot >= 0 {
+		info.PortBase = r.im.basePort
+	}
+	return info
+}
+
+func (e *Engine) getRun(runID string) (*scenarioRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, exists := e.runs[runID]
+	if !exists {
+		return nil, fmt.Errorf("run %s not found", runID)
+	}
+	return run, nil
+}
+
+// activeRuns는 실행 중인 run을 시작 시각 순으로 반환한다
+func (e *Engine) activeRuns() []*scenarioRun {
+	e.mu.Lock()
+	runs := make([]*scenarioRun, 0, len(e.runs))
+	for _, run := range e.runs {
+		runs = append(runs, run)
+	}
+	e.mu.Unlock()
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].startedAt.Before(runs[j].startedAt) })
+	return runs
+}
+
+// ListRuns는 실행 중인 모든 run의 정보를 시작 시각 순으로 반환한다
+func (e *Engine) ListRuns() []RunInfo {
+	runs := e.activeRuns()
+	infos := make([]RunInfo, 0, len(runs))
+	for _, run := range runs {
+		infos = append(infos, run.info())
+	}
+	return infos
+}
+
+// GetRun은 run 하나의 정보를 반환한다
+func (e *Engine) GetRun(runID string) (*RunInfo, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	info := run.info()
+	return &info, nil
+}
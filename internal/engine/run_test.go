@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitForRunEvent waits for an event with the given name tagged with runID
+func waitForRunEvent(t *testing.T, te *TestEventEmitter, name, runID string, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, e := range te.GetEventsByName(name) {
+			if e.Data["runId"] == runID {
+				return true
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestConcurrentRuns_IsolatedEventsAndStop(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+
+	nodes, edges := twoPartyDryRunFlow()
+	callScenario := saveDryRunScenario(t, repo, nodes, edges)
+
+	// INCOMING never arrives, so this run stays active until stopped
+	waitScenario := saveDryRunScenario(t, repo, []FlowNode{
+		{ID: "inst-w", Type: "sipInstance", Data: map[string]interface{}{"label": "Waiter", "dn": "300"}},
+		{ID: "wait-incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-w", "event": "INCOMING", "timeout": 30000}},
+	}, []FlowEdge{
+		{ID: "e1", Source: "inst-w", Target: "wait-incoming"},
+	})
+
+	waitRun, err := eng.StartScenarioDryRun(waitScenario, SimulationOptions{})
+	if err != nil {
+		t.Fatalf("StartScenarioDryRun(wait) failed: %v", err)
+	}
+	callRun, err := eng.StartScenarioDryRun(callScenario, SimulationOptions{})
+	if err != nil {
+		t.Fatalf("StartScenarioDryRun(call) failed: %v", err)
+	}
+	if waitRun == callRun {
+		t.Fatalf("expected distinct run IDs, got %s twice", waitRun)
+	}
+
+	if !waitForRunEvent(t, te, EventCompleted, callRun, 5*time.Second) {
+		t.Fatalf("call run did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+	for _, e := range te.GetEventsByName(EventNodeState) {
+		want := callRun
+		if e.Data["nodeId"] == "wait-incoming" {
+			want = waitRun
+		}
+		if e.Data["runId"] != want {
+			t.Errorf("node %v tagged with run %v, want %s", e.Data["nodeId"], e.Data["runId"], want)
+		}
+	}
+
+	runs := eng.ListRuns()
+	if len(runs) != 1 || runs[0].RunID != waitRun || runs[0].ScenarioID != waitScenario {
+		t.Fatalf("expected only the waiting run to be active, got %+v", runs)
+	}
+	if _, err := eng.GetRun(callRun); err == nil {
+		t.Error("expected completed run to be removed")
+	}
+
+	if err := eng.StopRun(waitRun); err != nil {
+		t.Fatalf("StopRun failed: %v", err)
+	}
+	if !waitForRunEvent(t, te, EventStopped, waitRun, 2*time.Second) {
+		t.Fatal("expected scenario:stopped for the waiting run")
+	}
+	if waitForRunEvent(t, te, EventStopped, callRun, 100*time.Millisecond) {
+		t.Error("stopping one run should not stop the other")
+	}
+	if eng.IsRunning() {
+		t.Error("expected no active runs after StopRun")
+	}
+}
+
+func TestRunScenario_WaitsForResult(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	result, err := eng.RunScenario(context.Background(), scenarioID, &SimulationOptions{})
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusCompleted || result.ScenarioID != scenarioID || result.RunID == "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	opts := &SimulationOptions{FailNodes: map[string]string{"make-call": "503 Service Unavailable"}}
+	result, err = eng.RunScenario(context.Background(), scenarioID, opts)
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusFailed || result.Error == "" {
+		t.Errorf("expected failed result with error, got %+v", result)
+	}
+}
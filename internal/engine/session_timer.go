@@ -0,0 +1,362 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// RFC 4028 session timer의 refresher 역할
+const (
+	SessionRefresherUAC = "uac" // 발신측(INVITE를 보낸 쪽)이 갱신
+	SessionRefresherUAS = "uas" // 착신측이 갱신
+)
+
+// session refresh 요청 메서드
+const (
+	SessionRefreshUpdate = "UPDATE" // SDP 없는 UPDATE (RFC 3311)
+	SessionRefreshInvite = "INVITE" // 현재 SDP로 보내는 re-INVITE
+)
+
+// minSessionExpires는 RFC 4028이 허용하는 Min-SE 하한 (초)
+const minSessionExpires = 90
+
+// sessionRefreshTimeout은 refresh 요청 한 건의 최종 응답 대기 시간
+const sessionRefreshTimeout = 10 * time.Second
+
+// validateSessionTimer는 인스턴스의 session timer 설정을 검증한다. SessionExpires가 0이면 사용하지 않는다.
+func validateSessionTimer(config SipInstanceConfig) error {
+	if config.SessionExpires == 0 {
+		return nil
+	}
+	if config.MinSE < minSessionExpires {
+		return fmt.Errorf("minSE must be at least %d seconds, got %d", minSessionExpires, config.MinSE)
+	}
+	if config.SessionExpires < config.MinSE {
+		return fmt.Errorf("sessionExpires %d is below minSE %d", config.SessionExpires, config.MinSE)
+	}
+	switch config.SessionRefresher {
+	case SessionRefresherUAC, SessionRefresherUAS:
+	default:
+		return fmt.Errorf("unsupported sessionRefresher %q (want %s or %s)", config.SessionRefresher, SessionRefresherUAC, SessionRefresherUAS)
+	}
+	switch config.RefreshMethod {
+	case SessionRefreshUpdate, SessionRefreshInvite:
+	default:
+		return fmt.Errorf("unsupported sessionRefreshMethod %q (want %s or %s)", config.RefreshMethod, SessionRefreshUpdate, SessionRefreshInvite)
+	}
+	return nil
+}
+
+// sessionTimerHeaders는 session timer를 쓰는 인스턴스의 INVITE에 붙일 헤더를 반환한다
+func sessionTimerHeaders(config SipInstanceConfig) []sip.Header {
+	if config.SessionExpires == 0 {
+		return nil
+	}
+	return []sip.Header{
+		sip.NewHeader("Supported", "timer"),
+		sip.NewHeader("Session-Expires", fmt.Sprintf("%d;refresher=%s", config.SessionExpires, config.SessionRefresher)),
+		sip.NewHeader("Min-SE", strconv.Itoa(config.MinSE)),
+	}
+}
+
+// headerGetter는 헤더를 조회할 수 있는 SIP 요청/응답
+type headerGetter interface {
+	GetHeader(name string) sip.Header
+	GetHeaders(name string) []sip.Header
+}
+
+// supportsSessionTimer는 메시지의 Supported(compact form k) 또는 Require에 timer가 있는지 확인한다
+func supportsSessionTimer(msg headerGetter) bool {
+	for _, name := range []string{"Supported", "k", "Require"} {
+		for _, header := range msg.GetHeaders(name) {
+			for _, option := range strings.Split(header.Value(), ",") {
+				if strings.EqualFold(strings.TrimSpace(option), "timer") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// parseSessionExpires는 "1800;refresher=uac" 형식의 Session-Expires(compact form x) 값을 읽는다
+func parseSessionExpires(msg headerGetter) (int, string, bool) {
+	header := msg.GetHeader("Session-Expires")
+	if header == nil {
+		header = msg.GetHeader("x")
+	}
+	if header == nil {
+		return 0, "", false
+	}
+	parts := strings.Split(header.Value(), ";")
+	seconds, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || seconds <= 0 {
+		return 0, "", false
+	}
+	refresher := ""
+	for _, param := range parts[1:] {
+		if name, value, found := strings.Cut(strings.TrimSpace(param), "="); found && strings.EqualFold(name, "refresher") {
+			refresher = strings.ToLower(strings.TrimSpace(value))
+		}
+	}
+	return seconds, refresher, true
+}
+
+// negotiateSessionTimer는 확정된 dialog의 session 간격(초)과 refresher를 정한다.
+// role은 dialog에서 인스턴스의 역할(uac: 발신, uas: 착신)이다. 발신측은 2xx, 착신측은 INVITE의 Session-Expires를 따른다.
+// 상대가 Session-Expires를 보내지 않았으면 상대는 갱신하지 않으므로 인스턴스가 갱신한다 (장시간 통화 keepalive).
+// 착신측은 INVITE에 refresher가 없을 때 설정을 따르되, 상대가 timer를 지원하지 않으면 직접 갱신한다 (RFC 4028 9절).
+func negotiateSessionTimer(config SipInstanceConfig, role string, dialogSIP *sipgo.Dialog) (int, string) {
+	var msg headerGetter
+	if dialogSIP != nil {
+		if role == SessionRefresherUAC && dialogSIP.InviteResponse != nil {
+			msg = dialogSIP.InviteResponse
+		} else if role == SessionRefresherUAS && dialogSIP.InviteRequest != nil {
+			msg = dialogSIP.InviteRequest
+		}
+	}
+	if msg == nil {
+		return config.SessionExpires, role
+	}
+	seconds, refresher, found := parseSessionExpires(msg)
+	if !found {
+		return config.SessionExpires, role
+	}
+	if refresher == "" {
+		refresher = role
+		if role == SessionRefresherUAS && supportsSessionTimer(msg) {
+			refresher = config.SessionRefresher
+		}
+	}
+	return seconds, refresher
+}
+
+// sessionTimerAnswerHeaders는 착신 INVITE의 2xx에 붙일 Session-Expires와 Require를 반환한다.
+// 상대가 timer를 지원할 때만 Require: timer를 붙인다 (RFC 4028 9절).
+func sessionTimerAnswerHeaders(config SipInstanceConfig, invite *sip.Request) []sip.Header {
+	if config.SessionExpires == 0 || invite == nil {
+		return nil
+	}
+	seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAS, &sipgo.Dialog{InviteRequest: invite})
+	headers := []sip.Header{sip.NewHeader("Session-Expires", fmt.Sprintf("%d;refresher=%s", seconds, refresher))}
+	if supportsSessionTimer(invite) {
+		headers = append(headers, sip.NewHeader("Require", "timer"))
+	}
+	return headers
+}
+
+// addSessionTimerAnswerHeaders는 diago가 보낼 2xx에 session timer 헤더를 붙이도록 등록한다.
+// AnswerOptions는 2xx 헤더를 받지 않으므로, dialog가 Established로 바뀌는 시점(2xx 전송 직전)에 InviteResponse를 고친다.
+func (ex *Executor) addSessionTimerAnswerHeaders(instanceID string, dialog diago.DialogSession) {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil || dialog.DialogSIP() == nil {
+		return
+	}
+	dialogSIP := dialog.DialogSIP()
+	headers := sessionTimerAnswerHeaders(instance.Config, dialogSIP.InviteRequest)
+	if len(headers) == 0 {
+		return
+	}
+	var once sync.Once
+	dialogSIP.OnState(func(state sip.DialogState) {
+		if state != sip.DialogStateEstablished {
+			return
+		}
+		once.Do(func() {
+			res := dialogSIP.InviteResponse
+			if res == nil || !res.IsSuccess() || res.GetHeader("Session-Expires") != nil {
+				return
+			}
+			for _, header := range headers {
+				res.AppendHeader(header)
+			}
+		})
+	})
+}
+
+// sessionTimer는 dialog 하나의 주기적 session refresh
+type sessionTimer struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (t *sessionTimer) stop() {
+	t.cancel()
+	<-t.done
+}
+
+// startSessionTimer는 인스턴스가 session timer를 쓰면 확정된 dialog의 refresh를 시작한다.
+// 상대가 refresher이면 로그만 남긴다. refresh는 dialog가 끝나거나 run이 정리될 때 멈춘다.
+func (ex *Executor) startSessionTimer(instanceID, callID string, node *GraphNode, dialog diago.DialogSession, role string) {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil || instance.Config.SessionExpires == 0 {
+		return
+	}
+	seconds, refresher := negotiateSessionTimer(instance.Config, role, dialog.DialogSIP())
+	if refresher != role {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Session timer: %ds, refreshed by the peer (%s)", seconds, refresher), "info")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(dialog.Context())
+	timer := &sessionTimer{cancel: cancel, done: make(chan struct{})}
+	key := sessionKey(instanceID, callID)
+	ex.timerMu.Lock()
+	previous := ex.sessionTimers[key]
+	ex.sessionTimers[key] = timer
+	ex.timerMu.Unlock()
+	if previous != nil {
+		previous.stop()
+	}
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Session timer: %ds, refreshing with %s every %v", seconds, instance.Config.RefreshMethod, time.Duration(seconds)*time.Second/2), "info")
+	go func() {
+		defer close(timer.done)
+		defer ex.removeSessionTimer(key, timer)
+		ex.runSessionTimer(ctx, instanceID, callID, node, dialog, instance.Config.RefreshMethod, seconds, role)
+	}()
+}
+
+// runSessionTimer는 session 간격의 절반마다 refresh를 보낸다 (RFC 4028 10절).
+// 실패하면 SESSION_REFRESH_FAILED를 발행하고 다음 주기에 다시 시도하며, 마지막 성공 후 간격이 지나면 BYE로 끊는다.
+func (ex *Executor) runSessionTimer(ctx context.Context, instanceID, callID string, node *GraphNode, dialog diago.DialogSession, method string, seconds int, role string) {
+	lastRefresh := time.Now()
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		statusCode, negotiated, err := refreshSession(ctx, dialog, method, seconds, role)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			lastRefresh = time.Now()
+			if negotiated != seconds {
+				seconds = negotiated
+				ticker.Reset(time.Duration(seconds) * time.Second / 2)
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Session refreshed with %s (callID: %s, %ds)", method, callID, seconds), "info",
+				WithSIPMessage("sent", method, statusCode, dialogSIPCallID(dialog), ex.instanceDN(instanceID), ""))
+			continue
+		}
+
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Session refresh failed (callID: %s): %v", callID, err), "error",
+			WithSIPMessage("sent", method, statusCode, dialogSIPCallID(dialog), ex.instanceDN(instanceID), ""))
+		ex.sessions.notifySIPEvent(eventhandler.Event{
+			Type:          eventhandler.SIPEventRefreshFailed,
+			SIPCallID:     dialogSIPCallID(dialog),
+			InstanceID:    instanceID,
+			LogicalCallID: callID,
+			StatusCode:    statusCode,
+			Detail:        err.Error(),
+		})
+		if time.Since(lastRefresh) >= time.Duration(seconds)*time.Second {
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Session expired without a successful refresh, sending BYE (callID: %s)", callID), "warn")
+			hangupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = dialog.Hangup(hangupCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+// refreshSession은 refresh 요청 한 건을 보내고 응답 코드와 적용된 session 간격을 반환한다.
+// 422 Session Interval Too Small이면 상대의 Min-SE로 한 번 다시 보낸다.
+func refreshSession(ctx context.Context, dialog diago.DialogSession, method string, seconds int, role string) (int, int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, sessionRefreshTimeout)
+	defer cancel()
+
+	if method == SessionRefreshInvite {
+		type reInviter interface {
+			ReInvite(ctx context.Context) error
+		}
+		ri, ok := dialog.(reInviter)
+		if !ok {
+			return 0, seconds, fmt.Errorf("dialog type %T does not support ReInvite", dialog)
+		}
+		if err := ri.ReInvite(timeoutCtx); err != nil {
+			return 0, seconds, fmt.Errorf("re-INVITE failed: %w", err)
+		}
+		return sip.StatusOK, seconds, nil
+	}
+
+	requester, ok := dialog.(inDialogRequester)
+	if !ok {
+		return 0, seconds, fmt.Errorf("dialog type %T does not support in-dialog requests", dialog)
+	}
+	remote := requester.RemoteContact()
+	if remote == nil {
+		return 0, seconds, fmt.Errorf("remote contact is missing")
+	}
+	for attempt := 0; ; attempt++ {
+		req := sip.NewRequest(sip.UPDATE, *remote.Address.Clone())
+		req.AppendHeader(sip.NewHeader("Supported", "timer"))
+		req.AppendHeader(sip.NewHeader("Session-Expires", fmt.Sprintf("%d;refresher=%s", seconds, role)))
+		res, err := requester.Do(timeoutCtx, req)
+		if err != nil {
+			return 0, seconds, fmt.Errorf("UPDATE failed: %w", err)
+		}
+		if res.IsSuccess() {
+			if negotiated, _, found := parseSessionExpires(res); found {
+				seconds = negotiated
+			}
+			return res.StatusCode, seconds, nil
+		}
+		if res.StatusCode == 422 && attempt == 0 {
+			if header := res.GetHeader("Min-SE"); header != nil {
+				if minSE, err := strconv.Atoi(strings.TrimSpace(strings.Split(header.Value(), ";")[0])); err == nil && minSE > seconds {
+					seconds = minSE
+					continue
+				}
+			}
+		}
+		return res.StatusCode, seconds, fmt.Errorf("UPDATE rejected with %d %s", res.StatusCode, res.Reason)
+	}
+}
+
+func (ex *Executor) removeSessionTimer(key string, timer *sessionTimer) {
+	ex.timerMu.Lock()
+	defer ex.timerMu.Unlock()
+	if ex.sessionTimers[key] == timer {
+		delete(ex.sessionTimers, key)
+	}
+}
+
+// closeSessionTimers는 run 정리 시 남은 session refresh를 모두 멈춘다
+func (ex *Executor) closeSessionTimers() {
+	ex.timerMu.Lock()
+	timers := make([]*sessionTimer, 0, len(ex.sessionTimers))
+	for _, timer := range ex.sessionTimers {
+		timers = append(timers, timer)
+	}
+	ex.timerMu.Unlock()
+
+	for _, timer := range timers {
+		timer.stop()
+	}
+}
+
+// executeSessionRefreshFailed는 callID dialog의 session refresh가 실패할 때까지 대기한다
+func (ex *Executor) executeSessionRefreshFailed(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	return ex.waitSIPEvent(ctx, instanceID, callID, eventhandler.SIPEventRefreshFailed, timeout, func(event eventhandler.Event) {
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("SESSION_REFRESH_FAILED: %s (callID: %s, sipCallID: %s)", event.Detail, callID, event.SIPCallID), "info")
+	})
+}
@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// fakeRefreshDialog answers in-dialog requests with the given status codes in order, repeating the last one
+type fakeRefreshDialog struct {
+	dialogSIP *sipgo.Dialog
+	ctx       context.Context
+	cancel    context.CancelFunc
+	codes     []int
+	minSE     string
+
+	mu       sync.Mutex
+	requests []*sip.Request
+}
+
+func newFakeRefreshDialog(callID string, codes ...int) *fakeRefreshDialog {
+	invite := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	callIDHeader := sip.CallIDHeader(callID)
+	invite.AppendHeader(&callIDHeader)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fakeRefreshDialog{
+		dialogSIP: &sipgo.Dialog{InviteRequest: invite, InviteResponse: sip.NewResponse(200, "OK")},
+		ctx:       ctx,
+		cancel:    cancel,
+		codes:     codes,
+	}
+}
+
+func (d *fakeRefreshDialog) Id() string { return "fake-refresh-dialog" }
+
+func (d *fakeRefreshDialog) Context() context.Context { return d.ctx }
+
+func (d *fakeRefreshDialog) Hangup(ctx context.Context) error {
+	d.cancel()
+	return nil
+}
+
+func (d *fakeRefreshDialog) Media() *diago.DialogMedia { return nil }
+
+func (d *fakeRefreshDialog) DialogSIP() *sipgo.Dialog { return d.dialogSIP }
+
+func (d *fakeRefreshDialog) Do(ctx context.Context, req *sip.Request) (*sip.Response, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, req)
+	code := d.codes[min(len(d.requests), len(d.codes))-1]
+	res := sip.NewResponseFromRequest(req, code, "", nil)
+	if code == 422 {
+		res.AppendHeader(sip.NewHeader("Min-SE", d.minSE))
+	}
+	return res, nil
+}
+
+func (d *fakeRefreshDialog) Close() error { return nil }
+
+func (d *fakeRefreshDialog) RemoteContact() *sip.ContactHeader {
+	return &sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: 5060}}
+}
+
+func (d *fakeRefreshDialog) sent() []*sip.Request {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*sip.Request(nil), d.requests...)
+}
+
+func TestNegotiateSessionTimer(t *testing.T) {
+	config := SipInstanceConfig{SessionExpires: 1800, MinSE: 90, SessionRefresher: SessionRefresherUAC, RefreshMethod: SessionRefreshUpdate}
+	withHeader := func(msg interface{ AppendHeader(sip.Header) }, value string) {
+		msg.AppendHeader(sip.NewHeader("Session-Expires", value))
+	}
+
+	res := sip.NewResponse(200, "OK")
+	withHeader(res, "600;refresher=uas")
+	if seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAC, &sipgo.Dialog{InviteResponse: res}); seconds != 600 || refresher != SessionRefresherUAS {
+		t.Errorf("expected 2xx to set 600s refreshed by uas, got %d %s", seconds, refresher)
+	}
+
+	req := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	req.AppendHeader(sip.NewHeader("Supported", "replaces, timer"))
+	withHeader(req, "900")
+	if seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAS, &sipgo.Dialog{InviteRequest: req}); seconds != 900 || refresher != SessionRefresherUAC {
+		t.Errorf("expected INVITE interval with configured refresher, got %d %s", seconds, refresher)
+	}
+
+	unsupported := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	withHeader(unsupported, "900")
+	if seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAS, &sipgo.Dialog{InviteRequest: unsupported}); seconds != 900 || refresher != SessionRefresherUAS {
+		t.Errorf("expected uas to refresh when the caller does not support timer, got %d %s", seconds, refresher)
+	}
+
+	if seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAC, &sipgo.Dialog{InviteResponse: sip.NewResponse(200, "OK")}); seconds != 1800 || refresher != SessionRefresherUAC {
+		t.Errorf("expected instance settings when the peer has no session timer, got %d %s", seconds, refresher)
+	}
+
+	plain := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	if seconds, refresher := negotiateSessionTimer(config, SessionRefresherUAS, &sipgo.Dialog{InviteRequest: plain}); seconds != 1800 || refresher != SessionRefresherUAS {
+		t.Errorf("expected uas to refresh when the INVITE has no Session-Expires, got %d %s", seconds, refresher)
+	}
+
+	compact := sip.NewResponse(200, "OK")
+	compact.AppendHeader(sip.NewHeader("x", "120;refresher=UAC"))
+	if seconds, refresher, found := parseSessionExpires(compact); !found || seconds != 120 || refresher != SessionRefresherUAC {
+		t.Errorf("expected compact form to parse, got %d %q %v", seconds, refresher, found)
+	}
+}
+
+func TestSessionTimerAnswerHeaders(t *testing.T) {
+	config := SipInstanceConfig{SessionExpires: 1800, MinSE: 90, SessionRefresher: SessionRefresherUAC, RefreshMethod: SessionRefreshUpdate}
+
+	invite := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	invite.AppendHeader(sip.NewHeader("Supported", "timer"))
+	invite.AppendHeader(sip.NewHeader("Session-Expires", "600"))
+	headers := sessionTimerAnswerHeaders(config, invite)
+	if len(headers) != 2 || headers[0].Value() != "600;refresher=uac" || headers[1].Name() != "Require" || headers[1].Value() != "timer" {
+		t.Errorf("expected Session-Expires 600;refresher=uac with Require: timer, got %v", headers)
+	}
+
+	plain := sip.NewRequest(sip.INVITE, sip.Uri{User: "200", Host: "127.0.0.1"})
+	headers = sessionTimerAnswerHeaders(config, plain)
+	if len(headers) != 1 || headers[0].Value() != "1800;refresher=uas" {
+		t.Errorf("expected only Session-Expires 1800;refresher=uas without caller support, got %v", headers)
+	}
+
+	if headers := sessionTimerAnswerHeaders(SipInstanceConfig{}, invite); headers != nil {
+		t.Errorf("expected no headers without session timer, got %v", headers)
+	}
+}
+
+func TestRefreshSession_RetriesWithMinSEAfter422(t *testing.T) {
+	dialog := newFakeRefreshDialog("refresh-422", 422, 200)
+	dialog.minSE = "300"
+
+	code, seconds, err := refreshSession(context.Background(), dialog, SessionRefreshUpdate, 120, SessionRefresherUAC)
+	if err != nil || code != 200 || seconds != 300 {
+		t.Fatalf("expected 200 with 300s after 422, got %d %ds (err %v)", code, seconds, err)
+	}
+	sent := dialog.sent()
+	if len(sent) != 2 || sent[0].Method != sip.UPDATE {
+		t.Fatalf("expected two UPDATE requests, got %d", len(sent))
+	}
+	if header := sent[1].GetHeader("Session-Expires"); header == nil || header.Value() != "300;refresher=uac" {
+		t.Errorf("expected retried Session-Expires 300;refresher=uac, got %v", header)
+	}
+
+	rejected := newFakeRefreshDialog("refresh-481", 481)
+	if code, _, err := refreshSession(context.Background(), rejected, SessionRefreshUpdate, 120, SessionRefresherUAC); err == nil || code != 481 {
+		t.Errorf("expected 481 failure, got %d (err %v)", code, err)
+	}
+}
+
+func TestSessionTimer_RefreshFailureRaisesEventAndExpires(t *testing.T) {
+	ex, te := newTestExecutor(t)
+	ex.im.instances["inst"] = &ManagedInstance{Config: SipInstanceConfig{
+		ID: "inst", DN: "100", SessionExpires: 1, MinSE: minSessionExpires,
+		SessionRefresher: SessionRefresherUAC, RefreshMethod: SessionRefreshUpdate,
+	}}
+	dialog := newFakeRefreshDialog("refresh-fail", 500)
+	ex.sessions.StoreDialog("inst", "call-1", dialog)
+
+	waitNode := &GraphNode{ID: "refresh-failed", Type: "event", CallID: "call-1"}
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- ex.executeSessionRefreshFailed(context.Background(), "inst", waitNode, 3*time.Second)
+	}()
+	ex.startSessionTimer("inst", "call-1", &GraphNode{ID: "call", Type: "command"}, dialog, SessionRefresherUAC)
+
+	if err := <-waitErr; err != nil {
+		t.Fatalf("SESSION_REFRESH_FAILED was not raised: %v", err)
+	}
+	select {
+	case <-dialog.Context().Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected BYE after the session expired without a refresh")
+	}
+	ex.closeSessionTimers()
+
+	if header := dialog.sent()[0].GetHeader("Session-Expires"); header == nil || header.Value() != "1;refresher=uac" {
+		t.Errorf("expected Session-Expires 1;refresher=uac, got %v", header)
+	}
+	expired := false
+	for _, log := range te.GetEventsByName(EventActionLog) {
+		if strings.Contains(log.Data["message"].(string), "Session expired") {
+			expired = true
+		}
+	}
+	if !expired {
+		t.Error("expected a session expired log")
+	}
+}
+
+func TestParseScenario_SessionTimerFields(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "inst", Type: "sipInstance", Data: map[string]interface{}{"label": "Soak", "dn": "100", "sessionExpires": float64(60)}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "MakeCall", "targetUri": "200"}},
+	}
+	edges := []FlowEdge{{ID: "e1", Source: "inst", Target: "call"}}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "below minSE") {
+		t.Errorf("expected below minSE error, got %v", err)
+	}
+	if diags := ValidateScenario(flow); !hasCode(diags, "inst", DiagInvalidSessionTimer) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidSessionTimer, diags)
+	}
+
+	nodes[0].Data["sessionExpires"] = float64(1800)
+	nodes[0].Data["sessionRefresher"] = "uas"
+	graph, err := ParseScenario(buildTestFlowData(t, nodes, edges))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	config := graph.Instances["inst"].Config
+	if config.SessionExpires != 1800 || config.MinSE != 90 || config.SessionRefresher != "uas" || config.RefreshMethod != SessionRefreshUpdate {
+		t.Errorf("unexpected session timer config %+v", config)
+	}
+	headers := sessionTimerHeaders(config)
+	if len(headers) != 3 || headers[1].Value() != "1800;refresher=uas" {
+		t.Errorf("unexpected INVITE session timer headers %v", headers)
+	}
+}
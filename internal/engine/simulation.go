@@ -0,0 +1,1225 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/diago/media/sdp"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// SimulationOptions는 dry-run 모드에서 네트워크 대신 사용하는 시뮬레이션 SIP 백엔드 설정
+type SimulationOptions struct {
+	Latency     time.Duration            // 모든 SIP 커맨드에 적용할 기본 지연
+	NodeLatency map[string]time.Duration // 노드별 지연 (Latency보다 우선)
+	AnswerDelay time.Duration            // 시나리오 밖의 대상(외부 번호)이 응답하기까지의 지연
+	FailNodes   map[string]string        // 노드 ID -> 주입할 실패 사유
+	FlakyNodes  map[string]int           // 노드 ID -> 처음 N회 실행만 실패 (재시도 검증용)
+	FailureRate float64                  // 0~1, SIP 동작이 무작위로 실패할 확률
+	Seed        int64                    // FailureRate 난수 시드 (0이면 1)
+}
+
+// simDialog는 시뮬레이션 백엔드의 한쪽 dialog. 시나리오 안의 상대방과 peer로 연결된다.
+// peer가 nil이면 시나리오 밖의 원격 단말이 응답한 통화이다.
+type simDialog struct {
+	instanceID string
+	callID     string
+	state      DialogState
+	peer       *simDialog
+	answered   chan struct{} // 발신측: 착신측 Answer 시 닫힘
+	done       chan struct{} // 종료 시 닫힘 (DISCONNECTED)
+	events     map[eventhandler.SIPEventType]chan struct{}
+	media      map[eventhandler.SIPEventType]chan string // REINVITE_RECEIVED/UPDATE_RECEIVED -> mediaDetail
+	dtmf       chan rune
+	answerOnce sync.Once
+}
+
+func newSimDialog(instanceID, callID string, state DialogState) *simDialog {
+	return &simDialog{
+		instanceID: instanceID,
+		callID:     callID,
+		state:      state,
+		answered:   make(chan struct{}),
+		done:       make(chan struct{}),
+		events: map[eventhandler.SIPEventType]chan struct{}{
+			eventhandler.SIPEventHeld:          make(chan struct{}, 8),
+			eventhandler.SIPEventRetrieved:     make(chan struct{}, 8),
+			eventhandler.SIPEventTransferred:   make(chan struct{}, 8),
+			eventhandler.SIPEventReferReceived: make(chan struct{}, 8),
+			eventhandler.SIPEventReplaced:      make(chan struct{}, 8),
+			eventhandler.SIPEventRefreshFailed: make(chan struct{}, 8),
+		},
+		media: map[eventhandler.SIPEventType]chan string{
+			eventhandler.SIPEventReInvite: make(chan string, 8),
+			eventhandler.SIPEventUpdate:   make(chan string, 8),
+		},
+		dtmf: make(chan rune, 64),
+	}
+}
+
+// notify는 dialog에 in-dialog 이벤트를 전달한다 (버퍼가 가득 차면 버린다)
+func (d *simDialog) notify(eventType eventhandler.SIPEventType) {
+	if d == nil {
+		return
+	}
+	select {
+	case d.events[eventType] <- struct{}{}:
+	default:
+	}
+}
+
+// notifyMedia는 dialog에 상대가 보낸 re-INVITE/UPDATE의 mediaDetail을 전달한다 (버퍼가 가득 차면 버린다)
+func (d *simDialog) notifyMedia(eventType eventhandler.SIPEventType, detail string) {
+	if d == nil {
+		return
+	}
+	select {
+	case d.media[eventType] <- detail:
+	default:
+	}
+}
+
+// simBackend는 dry-run 모드에서 모든 인스턴스의 SIP 상대방 역할을 프로세스 내에서 수행한다.
+// 시나리오 안의 DN으로 건 호는 해당 인스턴스의 INCOMING으로 전달되고, 그 밖의 대상은 자동 응답한다.
+type simBackend struct {
+	opts         SimulationOptions
+	mu           sync.Mutex
+	rng          *rand.Rand
+	dnToInstance map[string]string
+	instanceDN   map[string]string
+	incoming     map[string]chan *simDialog
+	dialogs      map[string]*simDialog      // sessionKey(instanceID, callID) -> dialog
+	executions   map[string]int             // 노드 ID -> 실행 횟수 (FlakyNodes 판단용)
+	bridged      map[string]string          // sessionKey -> 브리지 상대 sessionKey
+	conferences  map[string]map[string]bool // 인스턴스 ID -> 컨퍼런스 참가 callID
+	parked       map[string]*simDialog      // park slot -> 주차된 상대방 (nil이면 시나리오 밖 원격 단말)
+	nextSlot     int                        // parkSlot 없이 Park할 때 배정할 다음 slot
+	forwarding   map[string]string          // DN -> 무조건 착신전환 대상 DN
+	subscribed   map[string]bool            // subscriptionKey -> 구독 중
+
+	// 인스턴스 ID -> 시나리오 안의 다른 인스턴스가 보낸 MESSAGE
+	messages map[string]chan receivedMessage
+
+	// 인스턴스 ID -> 설정 코덱 (codecs 없는 ReInvite/Update offer에 사용)
+	codecs map[string][]string
+}
+
+func newSimBackend(graph *ExecutionGraph, opts SimulationOptions) *simBackend {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	sb := &simBackend{
+		opts:         opts,
+		rng:          rand.New(rand.NewSource(seed)),
+		dnToInstance: make(map[string]string),
+		instanceDN:   make(map[string]string),
+		incoming:     make(map[string]chan *simDialog),
+		dialogs:      make(map[string]*simDialog),
+		executions:   make(map[string]int),
+		bridged:      make(map[string]string),
+		conferences:  make(map[string]map[string]bool),
+		parked:       make(map[string]*simDialog),
+		nextSlot:     701,
+		forwarding:   make(map[string]string),
+		subscribed:   make(map[string]bool),
+		messages:     make(map[string]chan receivedMessage),
+		codecs:       make(map[string][]string),
+	}
+	for instanceID, chain := range graph.Instances {
+		sb.incoming[instanceID] = make(chan *simDialog, 16)
+		sb.messages[instanceID] = make(chan receivedMessage, 16)
+		sb.instanceDN[instanceID] = chain.Config.DN
+		sb.codecs[instanceID] = chain.Config.Codecs
+		if chain.Config.DN != "" {
+			sb.dnToInstance[chain.Config.DN] = instanceID
+		}
+	}
+	return sb
+}
+
+// handles는 노드가 시뮬레이션 대상 SIP 동작인지 반환한다. sync/CallScenario/TIMEOUT은 실제 구현을 그대로 쓴다.
+func (sb *simBackend) handles(node *GraphNode) bool {
+	if node.Type == "command" {
+		switch node.Command {
+		case SyncCommandSignal, CommandCallScenario:
+			return false
+		}
+		return true
+	}
+	switch node.Event {
+	case string(eventhandler.SIPEventTimeout), SyncEventWaitSignal, SyncEventBarrier:
+		return false
+	}
+	return true
+}
+
+func (sb *simBackend) getDialog(instanceID, callID string) (*simDialog, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	d, exists := sb.dialogs[sessionKey(instanceID, callID)]
+	return d, exists
+}
+
+func (sb *simBackend) storeDialog(d *simDialog) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.dialogs[sessionKey(d.instanceID, d.callID)] = d
+}
+
+// snapshot은 디버거 조회용으로 시뮬레이션 dialog 목록을 반환한다
+func (sb *simBackend) snapshot() []DebugDialog {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	dialogs := make([]DebugDialog, 0, len(sb.dialogs))
+	for _, d := range sb.dialogs {
+		dialogs = append(dialogs, DebugDialog{
+			InstanceID: d.instanceID,
+			CallID:     d.callID,
+			State:      dialogStateSet(d.state).String(),
+		})
+	}
+	sort.Slice(dialogs, func(i, j int) bool {
+		if dialogs[i].InstanceID != dialogs[j].InstanceID {
+			return dialogs[i].InstanceID < dialogs[j].InstanceID
+		}
+		return dialogs[i].CallID < dialogs[j].CallID
+	})
+	return dialogs
+}
+
+// activeDialog는 통화 중(confirmed/held)인 dialog를 반환한다
+func (sb *simBackend) activeDialog(instanceID, callID string) (*simDialog, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	d, exists := sb.dialogs[sessionKey(instanceID, callID)]
+	if !exists || dialogStateSet(d.state)&dialogActive == 0 {
+		return nil, false
+	}
+	return d, true
+}
+
+// terminateLocked는 dialog를 종료하고 peer에도 BYE를 전달한다. sb.mu를 잡은 상태에서 호출한다.
+func (sb *simBackend) terminateLocked(d *simDialog, propagate bool) {
+	if d == nil || d.state == DialogTerminated {
+		return
+	}
+	d.state = DialogTerminated
+	close(d.done)
+	if propagate && d.peer != nil {
+		sb.terminateLocked(d.peer, false)
+	}
+}
+
+func (sb *simBackend) terminate(d *simDialog, propagate bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.terminateLocked(d, propagate)
+}
+
+// confirm은 착신측 Answer를 발신측에 알린다. 발신측이 이미 포기했으면 false를 반환한다.
+func (sb *simBackend) confirm(callee *simDialog) bool {
+	sb.mu.Lock()
+	caller := callee.peer
+	if callee.state == DialogTerminated || (caller != nil && caller.state == DialogTerminated) {
+		sb.terminateLocked(callee, false)
+		sb.mu.Unlock()
+		return false
+	}
+	callee.state = DialogConfirmed
+	if caller != nil {
+		caller.peer = callee
+		if caller.state == DialogRinging {
+			caller.state = DialogConfirmed
+		}
+	}
+	sb.mu.Unlock()
+	if caller != nil {
+		caller.answerOnce.Do(func() { close(caller.answered) })
+	}
+	return true
+}
+
+// simTargetUser는 "sip:100@host;transport=tcp" 또는 "100" 형식에서 user 부분을 추출한다
+func simTargetUser(target string) string {
+	user := strings.TrimPrefix(strings.TrimSpace(target), "sip:")
+	if at := strings.Index(user, "@"); at >= 0 {
+		user = user[:at]
+	}
+	return user
+}
+
+func (sb *simBackend) latencyFor(node *GraphNode) time.Duration {
+	if d, ok := sb.opts.NodeLatency[node.ID]; ok {
+		return d
+	}
+	return sb.opts.Latency
+}
+
+// injectedFailure는 노드에 주입된 실패가 있으면 에러를 반환한다
+func (sb *simBackend) injectedFailure(node *GraphNode) error {
+	if reason, ok := sb.opts.FailNodes[node.ID]; ok {
+		if reason == "" {
+			reason = "injected failure"
+		}
+		return fmt.Errorf("simulated failure: %s", reason)
+	}
+	if flaky := sb.opts.FlakyNodes[node.ID]; flaky > 0 {
+		sb.mu.Lock()
+		sb.executions[node.ID]++
+		count := sb.executions[node.ID]
+		sb.mu.Unlock()
+		if count <= flaky {
+			return fmt.Errorf("simulated failure: flaky attempt %d of %d", count, flaky)
+		}
+	}
+	if sb.opts.FailureRate > 0 {
+		sb.mu.Lock()
+		hit := sb.rng.Float64() < sb.opts.FailureRate
+		sb.mu.Unlock()
+		if hit {
+			return fmt.Errorf("simulated failure: random failure (rate %.2f)", sb.opts.FailureRate)
+		}
+	}
+	return nil
+}
+
+// executeCommand는 SIP 커맨드를 시뮬레이션한다. 지연 후 실패 주입을 확인하고 커맨드별 동작을 수행한다.
+func (sb *simBackend) executeCommand(ctx context.Context, ex *Executor, instanceID string, node *GraphNode) error {
+	if err := sleepContext(ctx, sb.latencyFor(node)); err != nil {
+		return err
+	}
+	if err := sb.injectedFailure(node); err != nil {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s failed: %v", node.Command, err), "error")
+		return err
+	}
+
+	switch node.Command {
+	case string(SIPCommandMakeCall):
+		return sb.makeCall(ctx, ex, instanceID, node)
+	case string(SIPCommandAnswer):
+		return sb.answer(ex, instanceID, node)
+	case string(SIPCommandRelease):
+		return sb.release(ex, instanceID, node)
+	case string(SIPCommandPlayAudio):
+		return sb.playAudio(ex, instanceID, node)
+	case string(SIPCommandSendDTMF):
+		return sb.sendDTMF(ctx, ex, instanceID, node)
+	case string(SIPCommandHold):
+		return sb.hold(ex, instanceID, node)
+	case string(SIPCommandRetrieve):
+		return sb.retrieve(ex, instanceID, node)
+	case string(SIPCommandBlindTransfer):
+		return sb.blindTransfer(ex, instanceID, node)
+	case string(SIPCommandMuteTransfer):
+		return sb.muteTransfer(ex, instanceID, node)
+	case string(SIPCommandBridge):
+		return sb.bridge(ex, instanceID, node)
+	case string(SIPCommandUnbridge):
+		return sb.unbridge(ex, instanceID, node)
+	case string(SIPCommandConference):
+		return sb.conference(ex, instanceID, node)
+	case string(SIPCommandPark):
+		return sb.park(ex, instanceID, node)
+	case string(SIPCommandUnpark):
+		return sb.unpark(ex, instanceID, node)
+	case string(SIPCommandPickup):
+		return sb.pickup(ex, instanceID, node)
+	case string(SIPCommandSetForwarding):
+		return sb.setForwarding(ex, instanceID, node)
+	case string(SIPCommandSubscribe):
+		return sb.subscribe(ex, instanceID, node)
+	case string(SIPCommandUnsubscribe):
+		return sb.unsubscribe(ex, instanceID, node)
+	case string(SIPCommandSendMessage):
+		return sb.sendMessage(ex, instanceID, node)
+	case string(SIPCommandSendOptions):
+		return sb.sendOptions(ex, instanceID, node)
+	case string(SIPCommandReInvite), string(SIPCommandUpdate):
+		return sb.mediaUpdate(ex, instanceID, node)
+	default:
+		return fmt.Errorf("unknown command: %s", node.Command)
+	}
+}
+
+// executeEvent는 SIP 이벤트 대기를 시뮬레이션한다. ctx에는 executeEvent의 타임아웃이 적용되어 있다.
+func (sb *simBackend) executeEvent(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	if err := sb.injectedFailure(node); err != nil {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s failed: %v", node.Event, err), "error")
+		return err
+	}
+
+	switch node.Event {
+	case string(eventhandler.SIPEventIncoming):
+		return sb.waitIncoming(ctx, ex, instanceID, node, timeout)
+	case string(eventhandler.SIPEventDisconnected):
+		return sb.waitDisconnected(ctx, ex, instanceID, node, timeout)
+	case string(eventhandler.SIPEventRinging):
+		ex.emitNodeActionLog(node, instanceID, "RINGING event (simulated)", "info",
+			WithSIPMessage("received", string(eventhandler.SIPEventRinging), 180, "", "", ""))
+		return nil
+	case string(eventhandler.SIPEventDTMFReceived):
+		return sb.waitDTMF(ctx, ex, instanceID, node, timeout)
+	case string(eventhandler.SIPEventHeld), string(eventhandler.SIPEventRetrieved), string(eventhandler.SIPEventTransferred), string(eventhandler.SIPEventReplaced), string(eventhandler.SIPEventRefreshFailed):
+		return sb.waitInDialogEvent(ctx, ex, instanceID, node, eventhandler.SIPEventType(node.Event), timeout)
+	case string(eventhandler.SIPEventReferReceived):
+		action := node.ReferAction
+		if action == "" {
+			action = ReferActionAccept
+		}
+		defer ex.setReferAction(instanceID, callIDOrDefault(node), action)()
+		return sb.waitInDialogEvent(ctx, ex, instanceID, node, eventhandler.SIPEventReferReceived, timeout)
+	case ServerEventRegisterReceived:
+		// 외부 단말이 없으므로 등록을 받은 것으로 간주한다
+		number := node.RegisterNumber
+		if number == "" {
+			number = "any DN"
+		}
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("REGISTER_RECEIVED from %s (simulated)", number), "info",
+			WithSIPMessage("received", "REGISTER", 200, "", node.RegisterNumber, ""))
+		return nil
+	case SubscriptionEventNotifyReceived:
+		return sb.waitNotify(ctx, ex, instanceID, node, timeout)
+	case string(eventhandler.SIPEventReInvite), string(eventhandler.SIPEventUpdate):
+		return sb.waitMediaUpdate(ctx, ex, instanceID, node, eventhandler.SIPEventType(node.Event), timeout)
+	case MessageEventReceived:
+		return sb.waitMessage(ctx, ex, instanceID, node, timeout)
+	default:
+		return fmt.Errorf("event type %s is not supported", node.Event)
+	}
+}
+
+func (sb *simBackend) makeCall(ctx context.Context, ex *Executor, instanceID string, node *GraphNode) error {
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MakeCall to %s", node.TargetURI), "info")
+	if node.TargetURI == "" {
+		return fmt.Errorf("MakeCall requires a targetUri")
+	}
+
+	timeout := 30 * time.Second
+	if node.Timeout > 0 {
+		timeout = node.Timeout
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	callID := callIDOrDefault(node)
+	user := simTargetUser(node.TargetURI)
+	sb.mu.Lock()
+	forwardTo, forwarded := sb.forwarding[user]
+	sb.mu.Unlock()
+	if forwarded {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MakeCall: %s forwards all calls to %s (simulated)", user, forwardTo), "info",
+			WithSIPMessage("received", "302 Moved Temporarily", 302, "", sb.instanceDN[instanceID], user))
+		user = forwardTo
+	}
+	caller := newSimDialog(instanceID, callID, DialogRinging)
+
+	calleeInstance, inScenario := sb.dnToInstance[user]
+	if !inScenario {
+		// 시나리오 밖의 대상은 원격 단말이 AnswerDelay 후 자동 응답한다
+		if err := sleepContext(timeoutCtx, sb.opts.AnswerDelay); err != nil {
+			return fmt.Errorf("Invite failed: %w", err)
+		}
+		caller.state = DialogConfirmed
+		sb.storeDialog(caller)
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MakeCall succeeded (simulated remote %s)", user), "info",
+			WithSIPMessage("sent", "INVITE", 200, "", sb.instanceDN[instanceID], user))
+		return nil
+	}
+
+	callee := newSimDialog(calleeInstance, "", DialogRinging)
+	callee.peer = caller
+	caller.peer = callee
+	select {
+	case sb.incoming[calleeInstance] <- callee:
+	default:
+		return fmt.Errorf("Invite failed: %s is busy", user)
+	}
+
+	select {
+	case <-caller.answered:
+		sb.storeDialog(caller)
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MakeCall succeeded (simulated, answered by %s)", calleeInstance), "info",
+			WithSIPMessage("sent", "INVITE", 200, "", sb.instanceDN[instanceID], user))
+		return nil
+	case <-caller.done:
+		return fmt.Errorf("Invite failed: call rejected by %s", user)
+	case <-timeoutCtx.Done():
+		// 착신측이 아직 INCOMING을 받지 않았거나 응답하지 않은 채로 타임아웃 — CANCEL
+		sb.terminate(caller, true)
+		return fmt.Errorf("Invite failed: %w", timeoutCtx.Err())
+	}
+}
+
+func (sb *simBackend) answer(ex *Executor, instanceID string, node *GraphNode) error {
+	ex.emitNodeActionLog(node, instanceID, "Answer incoming call", "info")
+
+	callID := callIDOrDefault(node)
+	d, exists := sb.getDialog(instanceID, callID)
+	if !exists || d.state != DialogRinging {
+		return fmt.Errorf("no pending incoming dialog for instance %s (callID: %s)", instanceID, callID)
+	}
+
+	if !sb.confirm(d) {
+		return fmt.Errorf("Answer failed: caller cancelled the call (callID: %s)", callIDOrDefault(node))
+	}
+	ex.emitNodeActionLog(node, instanceID, "Answer succeeded (simulated)", "info",
+		WithSIPMessage("sent", "200 OK", 200, "", "", sb.instanceDN[instanceID]))
+	return nil
+}
+
+func (sb *simBackend) release(ex *Executor, instanceID string, node *GraphNode) error {
+	ex.emitNodeActionLog(node, instanceID, "Release call", "info")
+
+	d, exists := sb.getDialog(instanceID, callIDOrDefault(node))
+	if !exists || d.state == DialogTerminated {
+		ex.emitNodeActionLog(node, instanceID, "No active dialog to release (already terminated)", "warn")
+		return nil
+	}
+
+	sb.terminate(d, true)
+	ex.emitNodeActionLog(node, instanceID, "Release succeeded (simulated)", "info",
+		WithSIPMessage("sent", "BYE", 200, "", "", ""))
+	return nil
+}
+
+func (sb *simBackend) playAudio(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.FilePath == "" {
+		return fmt.Errorf("PlayAudio requires filePath")
+	}
+	if _, err := os.Stat(node.FilePath); err != nil {
+		return fmt.Errorf("audio file not found: %s", node.FilePath)
+	}
+	if _, ok := sb.activeDialog(instanceID, callIDOrDefault(node)); !ok {
+		return fmt.Errorf("no active dialog for PlayAudio")
+	}
+
+	ex.emitNodeActionLog(node, instanceID,
+		fmt.Sprintf("Playing audio file: %s (simulated)", filepath.Base(node.FilePath)), "info")
+	return nil
+}
+
+func (sb *simBackend) sendDTMF(ctx context.Context, ex *Executor, instanceID string, node *GraphNode) error {
+	if node.Digits == "" {
+		return fmt.Errorf("SendDTMF requires digits")
+	}
+	d, ok := sb.activeDialog(instanceID, callIDOrDefault(node))
+	if !ok {
+		return fmt.Errorf("no active dialog for SendDTMF")
+	}
+
+	interval := time.Duration(node.IntervalMs) * time.Millisecond
+	digits := []rune(node.Digits)
+	for i, digit := range digits {
+		if !isValidDTMF(digit) {
+			return fmt.Errorf("invalid DTMF digit: %c (allowed: 0-9, *, #, A-D)", digit)
+		}
+		if d.peer != nil {
+			select {
+			case d.peer.dtmf <- digit:
+			default:
+			}
+		}
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Sent DTMF: %c (simulated)", digit), "info")
+
+		if i < len(digits)-1 {
+			if err := sleepContext(ctx, interval); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (sb *simBackend) hold(ex *Executor, instanceID string, node *GraphNode) error {
+	sb.mu.Lock()
+	d, exists := sb.dialogs[sessionKey(instanceID, callIDOrDefault(node))]
+	if !exists || d.state != DialogConfirmed {
+		sb.mu.Unlock()
+		return fmt.Errorf("Hold: no active dialog for instance %s", instanceID)
+	}
+	d.state = DialogHeld
+	peer := d.peer
+	sb.mu.Unlock()
+
+	peer.notify(eventhandler.SIPEventHeld)
+	ex.emitNodeActionLog(node, instanceID, "Hold succeeded (simulated)", "info",
+		WithSIPMessage("sent", "INVITE", 200, "", "", ""))
+	return nil
+}
+
+func (sb *simBackend) retrieve(ex *Executor, instanceID string, node *GraphNode) error {
+	sb.mu.Lock()
+	d, exists := sb.dialogs[sessionKey(instanceID, callIDOrDefault(node))]
+	if !exists || d.state != DialogHeld {
+		sb.mu.Unlock()
+		return fmt.Errorf("Retrieve: no held dialog for instance %s", instanceID)
+	}
+	d.state = DialogConfirmed
+	peer := d.peer
+	sb.mu.Unlock()
+
+	peer.notify(eventhandler.SIPEventRetrieved)
+	ex.emitNodeActionLog(node, instanceID, "Retrieve succeeded (simulated)", "info",
+		WithSIPMessage("sent", "INVITE", 200, "", "", ""))
+	return nil
+}
+
+// blindTransfer는 상대방을 targetUser로 넘긴다. 대상이 시나리오 안의 DN이면 상대방 인스턴스에서
+// 새 INCOMING이 발생하고, Answer 시 상대방 dialog가 대상과 연결된다.
+func (sb *simBackend) blindTransfer(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.TargetUser == "" {
+		return fmt.Errorf("BlindTransfer: targetUser is required")
+	}
+	if node.TargetHost == "" {
+		return fmt.Errorf("BlindTransfer: targetHost is required")
+	}
+	d, ok := sb.activeDialog(instanceID, callIDOrDefault(node))
+	if !ok {
+		return fmt.Errorf("BlindTransfer: no active dialog for instance %s", instanceID)
+	}
+
+	rawURI := fmt.Sprintf("sip:%s@%s", node.TargetUser, node.TargetHost)
+	if err := sb.offerRefer(ex, d.peer); err != nil {
+		return fmt.Errorf("BlindTransfer: %w", err)
+	}
+	sb.mu.Lock()
+	transferee := d.peer
+	d.peer = nil
+	sb.mu.Unlock()
+
+	if transferee != nil {
+		if targetInstance, exists := sb.dnToInstance[node.TargetUser]; exists {
+			target := newSimDialog(targetInstance, "", DialogRinging)
+			sb.mu.Lock()
+			target.peer = transferee
+			transferee.peer = target
+			sb.mu.Unlock()
+			select {
+			case sb.incoming[targetInstance] <- target:
+			default:
+				return fmt.Errorf("BlindTransfer: %s is busy", node.TargetUser)
+			}
+		}
+		transferee.notify(eventhandler.SIPEventTransferred)
+	}
+
+	ex.emitNodeActionLog(node, instanceID,
+		fmt.Sprintf("BlindTransfer succeeded (simulated, Refer-To: %s)", rawURI), "info",
+		WithSIPMessage("sent", "REFER", 202, "", "", rawURI))
+
+	sb.terminate(d, false)
+	ex.emitNodeActionLog(node, instanceID, "BlindTransfer: BYE sent", "info",
+		WithSIPMessage("sent", "BYE", 200, "", "", ""))
+	return nil
+}
+
+// muteTransfer는 primary와 consult의 상대방을 서로 연결하고 자신의 두 dialog를 종료한다
+func (sb *simBackend) muteTransfer(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.ConsultCallID == "" {
+		return fmt.Errorf("MuteTransfer requires consultCallId")
+	}
+	primaryCallID := muteTransferPrimaryCallID(node)
+	primary, ok := sb.activeDialog(instanceID, primaryCallID)
+	if !ok {
+		return fmt.Errorf("MuteTransfer: no active primary dialog (callID: %s)", primaryCallID)
+	}
+	consult, ok := sb.activeDialog(instanceID, node.ConsultCallID)
+	if !ok {
+		return fmt.Errorf("MuteTransfer: no active consult dialog (callID: %s)", node.ConsultCallID)
+	}
+
+	if err := sb.offerRefer(ex, primary.peer); err != nil {
+		return fmt.Errorf("MuteTransfer: %w", err)
+	}
+
+	sb.mu.Lock()
+	transferee, target := primary.peer, consult.peer
+	primary.peer, consult.peer = nil, nil
+	if transferee != nil {
+		transferee.peer = target
+	}
+	if target != nil {
+		target.peer = transferee
+	}
+	sb.terminateLocked(primary, false)
+	sb.terminateLocked(consult, false)
+	sb.mu.Unlock()
+
+	transferee.notify(eventhandler.SIPEventTransferred)
+	// transfer target은 consult dialog가 transferee의 INVITE with Replaces로 대체된 것으로 본다
+	target.notify(eventhandler.SIPEventReplaced)
+	ex.emitNodeActionLog(node, instanceID, "MuteTransfer succeeded (simulated)", "info",
+		WithSIPMessage("sent", "REFER", 202, "", "", ""))
+	return nil
+}
+
+// offerRefer는 transferee에게 REFER를 전달한다. transferee의 REFER_RECEIVED 노드가 거절하도록 설정했으면 603으로 실패한다.
+func (sb *simBackend) offerRefer(ex *Executor, transferee *simDialog) error {
+	if transferee == nil {
+		return nil
+	}
+	sb.mu.Lock()
+	instanceID, callID := transferee.instanceID, transferee.callID
+	sb.mu.Unlock()
+
+	transferee.notify(eventhandler.SIPEventReferReceived)
+	if ex.referActionFor(instanceID, callID) == ReferActionReject {
+		return fmt.Errorf("REFER declined (603) by %s/%s", instanceID, callID)
+	}
+	return nil
+}
+
+func (sb *simBackend) waitIncoming(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	for {
+		select {
+		case d := <-sb.incoming[instanceID]:
+			sb.mu.Lock()
+			cancelled := d.state == DialogTerminated
+			d.callID = callIDOrDefault(node)
+			from := ""
+			if d.peer != nil {
+				from = sb.instanceDN[d.peer.instanceID]
+			}
+			sb.mu.Unlock()
+			if cancelled {
+				// 발신측이 이미 포기한 호 (CANCEL)
+				continue
+			}
+			sb.storeDialog(d)
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("INCOMING event received from %s (callID: %s)", from, d.callID), "info",
+				WithSIPMessage("received", "INVITE", 0, "", from, sb.instanceDN[instanceID]))
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("INCOMING event timeout after %v", timeout)
+		}
+	}
+}
+
+func (sb *simBackend) waitDisconnected(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	d, exists := sb.getDialog(instanceID, callIDOrDefault(node))
+	if !exists {
+		return fmt.Errorf("no active dialog for DISCONNECTED event")
+	}
+
+	select {
+	case <-d.done:
+		ex.emitNodeActionLog(node, instanceID, "DISCONNECTED event received", "info")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("DISCONNECTED event timeout after %v", timeout)
+	}
+}
+
+func (sb *simBackend) waitDTMF(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	d, exists := sb.getDialog(instanceID, callIDOrDefault(node))
+	if !exists {
+		return fmt.Errorf("no active dialog for DTMFReceived")
+	}
+
+	var expected rune
+	if node.ExpectedDigit != "" {
+		expected = []rune(node.ExpectedDigit)[0]
+	}
+	for {
+		select {
+		case digit := <-d.dtmf:
+			if expected != 0 && digit != expected {
+				ex.emitNodeActionLog(node, instanceID,
+					fmt.Sprintf("Received DTMF: %c (waiting for %c)", digit, expected), "info")
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Received DTMF: %c", digit), "info")
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("DTMFReceived timeout after %v", timeout)
+		}
+	}
+}
+
+func (sb *simBackend) waitInDialogEvent(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, eventType eventhandler.SIPEventType, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	d, exists := sb.getDialog(instanceID, callID)
+	if !exists {
+		return fmt.Errorf("no SIP Call-ID for instance %s (callID: %s)", instanceID, callID)
+	}
+
+	select {
+	case <-d.events[eventType]:
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s event received (callID: %s, simulated)", eventType, callID), "info")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s event timeout after %v", eventType, timeout)
+	}
+}
+
+// bridge는 두 dialog가 통화 중인지 확인하고 브리지 상태만 기록한다 (미디어는 시뮬레이션하지 않는다)
+func (sb *simBackend) bridge(ex *Executor, instanceID string, node *GraphNode) error {
+	callID := callIDOrDefault(node)
+	peerInstanceID, peerCallID := bridgePeer(instanceID, node)
+	if peerCallID == "" {
+		return fmt.Errorf("Bridge requires peerCallId")
+	}
+	a, b := sessionKey(instanceID, callID), sessionKey(peerInstanceID, peerCallID)
+	if a == b {
+		return fmt.Errorf("Bridge cannot connect call %s to itself", callID)
+	}
+	if _, ok := sb.activeDialog(instanceID, callID); !ok {
+		return fmt.Errorf("Bridge: no active dialog for %s/%s", instanceID, callID)
+	}
+	if _, ok := sb.activeDialog(peerInstanceID, peerCallID); !ok {
+		return fmt.Errorf("Bridge: no active dialog for %s/%s", peerInstanceID, peerCallID)
+	}
+
+	sb.mu.Lock()
+	for _, key := range []string{a, b} {
+		if _, bridged := sb.bridged[key]; bridged {
+			sb.mu.Unlock()
+			return fmt.Errorf("Bridge: %s is already bridged", key)
+		}
+	}
+	for _, leg := range [][2]string{{instanceID, callID}, {peerInstanceID, peerCallID}} {
+		if sb.conferences[leg[0]][leg[1]] {
+			sb.mu.Unlock()
+			return fmt.Errorf("Bridge: %s is in a conference, remove it first", sessionKey(leg[0], leg[1]))
+		}
+	}
+	sb.bridged[a] = b
+	sb.bridged[b] = a
+	sb.mu.Unlock()
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Bridged %s/%s <-> %s/%s (simulated)", instanceID, callID, peerInstanceID, peerCallID), "info")
+	return nil
+}
+
+// unbridge는 기록된 브리지 상태를 해제한다
+func (sb *simBackend) unbridge(ex *Executor, instanceID string, node *GraphNode) error {
+	callID := callIDOrDefault(node)
+	key := sessionKey(instanceID, callID)
+
+	sb.mu.Lock()
+	peer, bridged := sb.bridged[key]
+	if bridged {
+		delete(sb.bridged, key)
+		delete(sb.bridged, peer)
+	}
+	sb.mu.Unlock()
+
+	if !bridged {
+		return fmt.Errorf("Unbridge: %s/%s is not bridged", instanceID, callID)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unbridged %s/%s (simulated)", instanceID, callID), "info")
+	return nil
+}
+
+// conference는 참가 상태만 기록한다 (믹싱은 시뮬레이션하지 않는다)
+func (sb *simBackend) conference(ex *Executor, instanceID string, node *GraphNode) error {
+	for _, callID := range conferenceCallIDs(node) {
+		key := sessionKey(instanceID, callID)
+		if node.ConfAction == ConferenceActionLeave {
+			sb.mu.Lock()
+			joined := sb.conferences[instanceID][callID]
+			delete(sb.conferences[instanceID], callID)
+			remaining := len(sb.conferences[instanceID])
+			sb.mu.Unlock()
+			if !joined {
+				return fmt.Errorf("Conference: %s is not in the conference", key)
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Conference: %s left (%d parties, simulated)", callID, remaining), "info")
+			continue
+		}
+
+		if _, ok := sb.activeDialog(instanceID, callID); !ok {
+			return fmt.Errorf("Conference: no active dialog for %s", key)
+		}
+		sb.mu.Lock()
+		if _, bridged := sb.bridged[key]; bridged {
+			sb.mu.Unlock()
+			return fmt.Errorf("Conference: %s is bridged, unbridge it first", key)
+		}
+		if sb.conferences[instanceID] == nil {
+			sb.conferences[instanceID] = make(map[string]bool)
+		}
+		if sb.conferences[instanceID][callID] {
+			sb.mu.Unlock()
+			return fmt.Errorf("Conference: %s is already in the conference", key)
+		}
+		sb.conferences[instanceID][callID] = true
+		count := len(sb.conferences[instanceID])
+		sb.mu.Unlock()
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Conference: %s joined (%d parties, simulated)", callID, count), "info")
+	}
+	return nil
+}
+
+// park는 상대방을 slot에 주차하고 자신의 dialog를 종료한다. 상대방에는 TRANSFERRED가 발생한다.
+func (sb *simBackend) park(ex *Executor, instanceID string, node *GraphNode) error {
+	d, ok := sb.activeDialog(instanceID, callIDOrDefault(node))
+	if !ok {
+		return fmt.Errorf("Park: no active dialog for %s/%s", instanceID, callIDOrDefault(node))
+	}
+	if err := sb.offerRefer(ex, d.peer); err != nil {
+		return fmt.Errorf("Park: %w", err)
+	}
+
+	sb.mu.Lock()
+	slot := node.ParkSlot
+	if slot == "" {
+		for {
+			slot = strconv.Itoa(sb.nextSlot)
+			sb.nextSlot++
+			if _, taken := sb.parked[slot]; !taken {
+				break
+			}
+		}
+	}
+	if _, taken := sb.parked[slot]; taken {
+		sb.mu.Unlock()
+		return fmt.Errorf("Park: slot %s is already occupied", slot)
+	}
+	parkee := d.peer
+	d.peer = nil
+	if parkee != nil {
+		parkee.peer = nil
+	}
+	sb.parked[slot] = parkee
+	sb.terminateLocked(d, false)
+	sb.mu.Unlock()
+
+	parkee.notify(eventhandler.SIPEventTransferred)
+	ex.recordParkedSlot(slot)
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Park succeeded (simulated, slot %s)", slot), "info",
+		WithSIPMessage("sent", "REFER", 202, "", "", slot))
+	return nil
+}
+
+// unpark는 slot에 주차된 상대방과 새 dialog로 연결한다
+func (sb *simBackend) unpark(ex *Executor, instanceID string, node *GraphNode) error {
+	slot := ex.unparkSlot(node)
+	if slot == "" {
+		return fmt.Errorf("Unpark: no slot to retrieve (set parkSlot or run Park first)")
+	}
+
+	sb.mu.Lock()
+	parkee, exists := sb.parked[slot]
+	if !exists {
+		sb.mu.Unlock()
+		return fmt.Errorf("Unpark: slot %s is empty", slot)
+	}
+	delete(sb.parked, slot)
+	d := newSimDialog(instanceID, callIDOrDefault(node), DialogConfirmed)
+	if parkee != nil && parkee.state != DialogTerminated {
+		d.peer = parkee
+		parkee.peer = d
+	}
+	sb.dialogs[sessionKey(instanceID, d.callID)] = d
+	sb.mu.Unlock()
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unpark succeeded (simulated, slot %s)", slot), "info",
+		WithSIPMessage("sent", "INVITE", 200, "", sb.instanceDN[instanceID], slot))
+	return nil
+}
+
+// pickup은 pickupTarget 인스턴스에서 울리는 dialog의 발신측을 자신과 연결하고 원래 착신 dialog를 종료한다.
+// 시나리오 밖의 대상은 원격 PBX가 당겨받기에 성공한 것으로 본다.
+func (sb *simBackend) pickup(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.PickupTarget == "" {
+		return fmt.Errorf("Pickup: pickupTarget is required")
+	}
+	user := simTargetUser(node.PickupTarget)
+	callID := callIDOrDefault(node)
+	targetInstance, inScenario := sb.dnToInstance[user]
+	if !inScenario {
+		sb.storeDialog(newSimDialog(instanceID, callID, DialogConfirmed))
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup succeeded (simulated remote %s)", user), "info",
+			WithSIPMessage("sent", "INVITE", 200, "", sb.instanceDN[instanceID], user))
+		return nil
+	}
+
+	sb.mu.Lock()
+	var ringing *simDialog
+	for _, d := range sb.dialogs {
+		if d.instanceID == targetInstance && d.state == DialogRinging && d.peer != nil && d.peer.state != DialogTerminated {
+			ringing = d
+			break
+		}
+	}
+	if ringing == nil {
+		sb.mu.Unlock()
+		return fmt.Errorf("Pickup: %s has no ringing call", user)
+	}
+	caller := ringing.peer
+	picker := newSimDialog(instanceID, callID, DialogConfirmed)
+	picker.peer = caller
+	caller.peer = picker
+	if caller.state == DialogRinging {
+		caller.state = DialogConfirmed
+	}
+	ringing.peer = nil
+	sb.terminateLocked(ringing, false)
+	sb.dialogs[sessionKey(instanceID, callID)] = picker
+	sb.mu.Unlock()
+
+	caller.answerOnce.Do(func() { close(caller.answered) })
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Pickup succeeded (simulated, %s mode, picked up call ringing at %s)", node.PickupMode, user), "info",
+		WithSIPMessage("sent", "INVITE", 200, "", sb.instanceDN[instanceID], user))
+	return nil
+}
+
+// setForwarding은 인스턴스 DN의 착신전환을 설정/해제한다. 시뮬레이션에서는 always만 호 라우팅에 반영된다.
+func (sb *simBackend) setForwarding(ex *Executor, instanceID string, node *GraphNode) error {
+	dn := sb.instanceDN[instanceID]
+	state := "off"
+	if node.ForwardEnabled {
+		if node.ForwardTarget == "" {
+			return fmt.Errorf("SetForwarding: forwardTarget is required")
+		}
+		state = "on -> " + node.ForwardTarget
+	}
+
+	if node.ForwardType == ForwardTypeAlways {
+		sb.mu.Lock()
+		if node.ForwardEnabled {
+			sb.forwarding[dn] = simTargetUser(node.ForwardTarget)
+		} else {
+			delete(sb.forwarding, dn)
+		}
+		sb.mu.Unlock()
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SetForwarding succeeded (simulated, %s %s)", node.ForwardType, state), "info")
+	return nil
+}
+
+func (sb *simBackend) subscribe(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.SubTarget == "" {
+		return fmt.Errorf("Subscribe: subscribeTarget is required")
+	}
+	sb.mu.Lock()
+	sb.subscribed[subscriptionKey(instanceID, node.EventPackage, node.SubTarget)] = true
+	sb.mu.Unlock()
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Subscribe succeeded (simulated, %s of %s)", node.EventPackage, node.SubTarget), "info",
+		WithSIPMessage("sent", "SUBSCRIBE", 200, "", sb.instanceDN[instanceID], node.SubTarget))
+	return nil
+}
+
+func (sb *simBackend) unsubscribe(ex *Executor, instanceID string, node *GraphNode) error {
+	key := subscriptionKey(instanceID, node.EventPackage, node.SubTarget)
+	sb.mu.Lock()
+	subscribed := sb.subscribed[key]
+	delete(sb.subscribed, key)
+	sb.mu.Unlock()
+	if !subscribed {
+		return fmt.Errorf("Unsubscribe: no %s subscription to %s", node.EventPackage, node.SubTarget)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unsubscribe succeeded (simulated, %s of %s)", node.EventPackage, node.SubTarget), "info",
+		WithSIPMessage("sent", "SUBSCRIBE", 200, "", sb.instanceDN[instanceID], node.SubTarget))
+	return nil
+}
+
+// notifyState는 구독 대상의 현재 상태를 시뮬레이션 dialog로부터 만든다.
+// dialog는 대상 인스턴스의 dialog 상태, presence는 시나리오 안의 DN이면 open이고, 음성사서함은 없으므로 MWI는 항상 no이다.
+func (sb *simBackend) notifyState(eventPackage, target string) notifyState {
+	user := simTargetUser(target)
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	targetInstance, inScenario := sb.dnToInstance[user]
+
+	switch eventPackage {
+	case EventPackageDialog:
+		info := &dialogInfo{}
+		if inScenario {
+			for _, d := range sb.dialogs {
+				if d.instanceID != targetInstance {
+					continue
+				}
+				switch d.state {
+				case DialogRinging:
+					info.Dialogs = append(info.Dialogs, dialogInfoDialog{State: "early"})
+				case DialogConfirmed, DialogHeld:
+					info.Dialogs = append(info.Dialogs, dialogInfoDialog{State: "confirmed"})
+				}
+			}
+		}
+		return info.notifyState()
+	case EventPackageMWI:
+		return messageSummary{}.notifyState()
+	default:
+		basic := "closed"
+		if inScenario {
+			basic = "open"
+		}
+		return (&pidfDocument{Tuples: []pidfTuple{{Basic: basic}}}).notifyState()
+	}
+}
+
+// waitNotify는 구독 대상의 시뮬레이션 상태가 expectedState가 될 때까지 폴링한다
+func (sb *simBackend) waitNotify(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	sb.mu.Lock()
+	subscribed := sb.subscribed[subscriptionKey(instanceID, node.EventPackage, node.SubTarget)]
+	sb.mu.Unlock()
+	if !subscribed {
+		return fmt.Errorf("NOTIFY_RECEIVED: no %s subscription to %s (add a Subscribe node first)", node.EventPackage, node.SubTarget)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if state := sb.notifyState(node.EventPackage, node.SubTarget); state.matches(node.ExpectedState) {
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("NOTIFY_RECEIVED: %s from %s (simulated)", state.summary, node.SubTarget), "info",
+				WithSIPMessage("received", "NOTIFY", 0, "", node.SubTarget, sb.instanceDN[instanceID]))
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("NOTIFY_RECEIVED event timeout after %v waiting for %s %s of %s", timeout, node.EventPackage, node.ExpectedState, node.SubTarget)
+		}
+	}
+}
+
+// sendMessage는 시나리오 안의 DN으로 보낸 MESSAGE를 해당 인스턴스에 전달하고, 그 밖의 대상은 200으로 자동 응답한다
+func (sb *simBackend) sendMessage(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.TargetURI == "" {
+		return fmt.Errorf("SendMessage requires a targetUri")
+	}
+	contentType := node.ContentType
+	if contentType == "" {
+		contentType = defaultMessageContentType
+	}
+	from := sb.instanceDN[instanceID]
+	callID := fmt.Sprintf("sim-msg-%s-%d", node.ID, time.Now().UnixNano())
+	if targetInstance, ok := sb.dnToInstance[simTargetUser(node.TargetURI)]; ok {
+		msg := receivedMessage{
+			From:        from,
+			FromURI:     "sip:" + from + "@sim.invalid",
+			ContentType: contentType,
+			Body:        node.MessageBody,
+			CallID:      callID,
+		}
+		select {
+		case sb.messages[targetInstance] <- msg:
+		default:
+		}
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendMessage to %s succeeded (simulated, %s, %d bytes)", node.TargetURI, contentType, len(node.MessageBody)), "info",
+		WithSIPMessage("sent", "MESSAGE", 200, callID, from, node.TargetURI))
+	return nil
+}
+
+// sendOptions는 모든 대상이 200으로 응답하는 것으로 간주하고 expectedStatus와 비교한다
+func (sb *simBackend) sendOptions(ex *Executor, instanceID string, node *GraphNode) error {
+	if node.TargetURI == "" {
+		return fmt.Errorf("SendOptions requires a targetUri")
+	}
+	logOpt := WithSIPMessage("sent", "OPTIONS", 200, "", sb.instanceDN[instanceID], node.TargetURI)
+	if node.ExpectedStatus != 0 && node.ExpectedStatus != 200 {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendOptions: got 200 OK, expected %d (simulated)", node.ExpectedStatus), "error", logOpt)
+		return fmt.Errorf("SendOptions: got 200 OK, expected %d", node.ExpectedStatus)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("SendOptions to %s succeeded (simulated, 200 OK)", node.TargetURI), "info", logOpt)
+	return nil
+}
+
+// waitMessage는 시나리오 안의 다른 인스턴스가 보낸 MESSAGE 중 조건에 맞는 것을 기다린다
+func (sb *simBackend) waitMessage(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, timeout time.Duration) error {
+	for {
+		select {
+		case msg := <-sb.messages[instanceID]:
+			logOpt := WithSIPMessage("received", "MESSAGE", 200, msg.CallID, msg.From, sb.instanceDN[instanceID])
+			if !matchesMessage(node, msg) {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MESSAGE_RECEIVED: skipping MESSAGE from %s: %q (simulated)", msg.From, msg.Body), "info", logOpt)
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("MESSAGE_RECEIVED from %s (%s): %q (simulated)", msg.From, msg.ContentType, msg.Body), "info", logOpt)
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("MESSAGE_RECEIVED event timeout after %v", timeout)
+		}
+	}
+}
+
+// mediaUpdate는 상대방에게 re-INVITE/UPDATE offer를 전달한다. codecs가 없으면 인스턴스 코덱, direction이 없으면 현재 hold 상태로 offer한다.
+// re-INVITE는 실제 착신측처럼 sendonly offer에 HELD, sendrecv offer에 RETRIEVED를 함께 발행한다.
+func (sb *simBackend) mediaUpdate(ex *Executor, instanceID string, node *GraphNode) error {
+	sb.mu.Lock()
+	d, exists := sb.dialogs[sessionKey(instanceID, callIDOrDefault(node))]
+	if !exists || (d.state != DialogConfirmed && d.state != DialogHeld) {
+		sb.mu.Unlock()
+		return fmt.Errorf("%s: no active dialog for instance %s", node.Command, instanceID)
+	}
+	held := d.state == DialogHeld
+	peer := d.peer
+	sb.mu.Unlock()
+
+	method, eventType := "INVITE", eventhandler.SIPEventReInvite
+	if node.Command == string(SIPCommandUpdate) {
+		method, eventType = "UPDATE", eventhandler.SIPEventUpdate
+	}
+	detail := ""
+	if !node.WithoutSDP {
+		direction := node.MediaDirection
+		if direction == "" {
+			direction = sdp.ModeSendrecv
+			if held {
+				direction = sdp.ModeSendonly
+			}
+		}
+		codecs := node.MediaCodecs
+		if len(codecs) == 0 {
+			codecs = sb.codecs[instanceID]
+		}
+		names := make([]string, 0, len(codecs)+1)
+		for _, codec := range stringToCodecs(codecs) {
+			names = append(names, codec.Name)
+		}
+		detail = mediaDetail(direction, names)
+		if method == "INVITE" {
+			switch direction {
+			case sdp.ModeSendonly:
+				peer.notify(eventhandler.SIPEventHeld)
+			case sdp.ModeSendrecv:
+				peer.notify(eventhandler.SIPEventRetrieved)
+			}
+		}
+	}
+	peer.notifyMedia(eventType, detail)
+
+	direction, _ := parseMediaDetail(detail)
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s succeeded (simulated, %s)", node.Command, describeMediaDetail(detail)), "info",
+		WithSIPMessage("sent", method, 200, "", "", "", direction))
+	return nil
+}
+
+// waitMediaUpdate는 상대방이 보낸 re-INVITE/UPDATE 중 방향/코덱 조건에 맞는 것을 기다린다
+func (sb *simBackend) waitMediaUpdate(ctx context.Context, ex *Executor, instanceID string, node *GraphNode, eventType eventhandler.SIPEventType, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	d, exists := sb.getDialog(instanceID, callID)
+	if !exists {
+		return fmt.Errorf("no SIP Call-ID for instance %s (callID: %s)", instanceID, callID)
+	}
+
+	for {
+		select {
+		case detail := <-d.media[eventType]:
+			direction, codecs := parseMediaDetail(detail)
+			if !matchesMediaUpdate(node, direction, codecs) {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s %q does not match, skipping (simulated)", eventType, detail), "info")
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("%s event received: %s (callID: %s, simulated)", eventType, describeMediaDetail(detail), callID), "info")
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("%s event timeout after %v", eventType, timeout)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sipflow/internal/scenario"
+)
+
+// newDryRunEngine creates an engine for dry runs; no UDP networking is required
+func newDryRunEngine(t *testing.T) (*Engine, *scenario.Repository, *TestEventEmitter) {
+	t.Helper()
+
+	repo, err := scenario.NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	eng := NewEngine(repo)
+	te := &TestEventEmitter{}
+	eng.SetEventEmitter(te)
+	return eng, repo, te
+}
+
+func saveDryRunScenario(t *testing.T, repo *scenario.Repository, nodes []FlowNode, edges []FlowEdge) string {
+	t.Helper()
+
+	scn, err := repo.CreateScenario("default", "dry-run")
+	if err != nil {
+		t.Fatalf("CreateScenario failed: %v", err)
+	}
+	if err := repo.SaveScenario(scn.ID, buildTestFlowData(t, nodes, edges)); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	return scn.ID
+}
+
+func twoPartyDryRunFlow() ([]FlowNode, []FlowEdge) {
+	nodes := []FlowNode{
+		{ID: "inst-a", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "inst-b", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+		{ID: "make-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "send-dtmf", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "SendDTMF", "digits": "5"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "Release"}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-b", "command": "Answer"}},
+		{ID: "dtmf", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "DTMFReceived", "expectedDigit": "5"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "DISCONNECTED"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst-a", Target: "make-call"},
+		{ID: "e2", Source: "make-call", Target: "send-dtmf"},
+		{ID: "e3", Source: "send-dtmf", Target: "release"},
+		{ID: "e4", Source: "inst-b", Target: "incoming"},
+		{ID: "e5", Source: "incoming", Target: "answer"},
+		{ID: "e6", Source: "answer", Target: "dtmf"},
+		{ID: "e7", Source: "dtmf", Target: "disconnected"},
+	}
+	return nodes, edges
+}
+
+func TestDryRun_TwoPartyCall(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+
+	for _, id := range []string{"make-call", "send-dtmf", "release", "incoming", "answer", "dtmf", "disconnected"} {
+		if !waitForNodeState(t, te, id, NodeStateCompleted, time.Second) {
+			t.Errorf("expected node %s to complete", id)
+		}
+	}
+	if len(eng.portSlots) != 0 {
+		t.Errorf("expected no port range reserved in dry-run mode, got %v", eng.portSlots)
+	}
+}
+
+func TestDryRun_ImpossibleCallFlowIsRejected(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+	nodes := []FlowNode{
+		{ID: "inst-b", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-b", "command": "Answer"}},
+	}
+	edges := []FlowEdge{{ID: "e1", Source: "inst-b", Target: "answer"}}
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	_, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{})
+	if err == nil || !strings.Contains(err.Error(), "call flow check failed: node answer") {
+		t.Fatalf("expected call flow check error, got %v", err)
+	}
+	if runs := eng.ListRuns(); len(runs) != 0 {
+		t.Errorf("expected rejected run to be cleaned up, got %v", runs)
+	}
+}
+
+func TestDryRun_InjectedFailureTakesFailureBranch(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes := []FlowNode{
+		{ID: "inst-a", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "make-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "MakeCall", "targetUri": "sip:300@pbx.example.com"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "Release"}},
+		{ID: "on-failure", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-a", "event": "TIMEOUT", "timeout": 10}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst-a", Target: "make-call"},
+		{ID: "e2", Source: "make-call", Target: "release"},
+		{ID: "e3", Source: "make-call", Target: "on-failure", SourceHandle: "failure"},
+	}
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	opts := SimulationOptions{FailNodes: map[string]string{"make-call": "486 Busy Here"}}
+	if _, err := eng.StartScenarioDryRun(scenarioID, opts); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatal("scenario did not complete")
+	}
+
+	if !waitForNodeState(t, te, "make-call", NodeStateFailed, time.Second) {
+		t.Error("expected make-call to fail")
+	}
+	if !waitForNodeState(t, te, "on-failure", NodeStateCompleted, time.Second) {
+		t.Error("expected failure branch to run")
+	}
+	for _, e := range te.GetEventsByName(EventNodeState) {
+		if e.Data["nodeId"] == "release" {
+			t.Errorf("success branch should not run, got %v", e.Data)
+		}
+	}
+}
+
+func TestDryRun_UnansweredCallTimesOut(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes := []FlowNode{
+		{ID: "inst-a", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "inst-b", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+		{ID: "make-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "MakeCall", "targetUri": "200", "timeout": 200}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "INCOMING"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst-a", Target: "make-call"},
+		{ID: "e2", Source: "inst-b", Target: "incoming"},
+	}
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventFailed, 5*time.Second) {
+		t.Fatal("expected scenario to fail when the callee never answers")
+	}
+	if !waitForNodeState(t, te, "make-call", NodeStateFailed, time.Second) {
+		t.Error("expected make-call to fail")
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// ServerEventRegisterReceived는 SIP Server 인스턴스가 외부 단말의 REGISTER를 받을 때까지 대기하는 이벤트
+const ServerEventRegisterReceived = "REGISTER_RECEIVED"
+
+// StartSIPServer는 SIP Server 인스턴스의 registrar/proxy를 설정된 listen 주소에서 시작하고 광고 주소를 반환한다.
+// 등록된 DN으로 온 요청은 등록된 Contact로, 그 외 DN은 인스턴스 자신의 UA로 보내므로 시나리오가 INCOMING으로 받을 수 있다.
+// CreateInstances 후에 호출해야 한다.
+func (im *InstanceManager) StartSIPServer(instanceID string, logf pbxLogFunc) (string, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	inst, exists := im.instances[instanceID]
+	if !exists {
+		return "", fmt.Errorf("instance not found: %s", instanceID)
+	}
+	if !inst.Config.IsServer() {
+		return "", fmt.Errorf("instance %s is not a SIP server", instanceID)
+	}
+	if inst.server != nil {
+		return inst.server.addr(), nil
+	}
+
+	// UA는 loopback 또는 모든 인터페이스에 바인딩되므로 같은 프로세스의 proxy는 loopback으로 닿는다
+	fallback := sip.Uri{Scheme: "sip", Host: "127.0.0.1", Port: inst.Port}
+	if normalizeTransport(inst.Config.PBXTransport) == "tcp" {
+		fallback.UriParams = sip.NewParams()
+		fallback.UriParams.Add("transport", "tcp")
+	}
+	registerCh := inst.registerCh
+	opts := []pbxOption{
+		withPBXFallback(fallback),
+		withPBXRegisterHook(func(reg pbxRegistration) {
+			// 대기 중인 노드가 없으면 오래된 이벤트부터 버린다 — 등록 상태는 바인딩으로 남는다
+			pushDropOldest(registerCh, reg)
+		}),
+	}
+	if inst.Config.AuthPassword != "" {
+		opts = append(opts, withPBXAuth(inst.Config.AuthRealm, inst.Config.AuthUsername, inst.Config.AuthPassword))
+	}
+
+	server, err := startLocalPBX(inst.Config.ListenHost, inst.Config.ListenPort, logf, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to start SIP server for instance %s: %w", instanceID, err)
+	}
+	inst.server = server
+	return server.addr(), nil
+}
+
+// pushDropOldest는 ch가 가득 차 있으면 가장 오래된 값을 버리고 v를 넣는다
+func pushDropOldest[T any](ch chan T, v T) {
+	for {
+		select {
+		case ch <- v:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// ServerRegistrations는 SIP Server 인스턴스에 현재 등록된 contact 목록을 반환한다
+func (im *InstanceManager) ServerRegistrations(instanceID string) ([]PBXBinding, error) {
+	inst, err := im.GetInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if inst.server == nil {
+		return nil, fmt.Errorf("instance %s is not a running SIP server", instanceID)
+	}
+	return inst.server.Bindings(), nil
+}
+
+// ResolveTargetFor는 instanceID가 거는 호의 대상을 변환한다.
+// SIP Server 인스턴스가 자신에게 등록된 DN을 부르면 자신의 proxy를 거쳐 등록된 contact로 보낸다.
+// 미등록 DN은 proxy가 자신의 UA로 되돌려 보내므로 proxy를 거치지 않는다.
+func (im *InstanceManager) ResolveTargetFor(instanceID, target string) (string, error) {
+	dn := strings.TrimSpace(target)
+
+	im.mu.Lock()
+	inst, exists := im.instances[instanceID]
+	im.mu.Unlock()
+
+	if exists && inst.server != nil && dn != "" && !strings.HasPrefix(dn, "sip:") {
+		if _, registered := inst.server.lookup(dn); registered {
+			return fmt.Sprintf("sip:%s@%s", dn, inst.server.addr()), nil
+		}
+	}
+	return im.ResolveTarget(target)
+}
+
+// executeRegisterReceived는 SIP Server 인스턴스가 REGISTER를 받을 때까지 대기한다.
+// number가 지정되면 해당 DN만 기다리며, 이미 등록되어 있으면 바로 완료한다.
+func (ex *Executor) executeRegisterReceived(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	instance, err := ex.im.GetInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+	if instance.server == nil {
+		return fmt.Errorf("instance %s is not a running SIP server", instanceID)
+	}
+
+	if node.RegisterNumber != "" {
+		if contact, registered := instance.server.lookup(node.RegisterNumber); registered {
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("REGISTER_RECEIVED: %s already registered (contact: %s)", node.RegisterNumber, contact.String()), "info")
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case reg := <-instance.registerCh:
+			if reg.Unregister || (node.RegisterNumber != "" && reg.DN != node.RegisterNumber) {
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("REGISTER_RECEIVED from %s (contact: %s)", reg.DN, reg.Contact), "info",
+				WithSIPMessage("received", "REGISTER", 200, reg.CallID, reg.DN, instance.Config.DN))
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("REGISTER_RECEIVED event timeout after %v", timeout)
+		}
+	}
+}
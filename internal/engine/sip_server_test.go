@@ -0,0 +1,171 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+func sipServerFlow() string {
+	return `{
+		"nodes": [
+			{"id": "server", "type": "sipInstance", "data": {"label": "PBX", "role": "server", "dn": "9000",
+				"listenHost": "127.0.0.1", "listenPort": 0, "authRealm": "test", "authPassword": "secret"}},
+			{"id": "wait-reg", "type": "event", "data": {"event": "REGISTER_RECEIVED", "number": "200", "timeout": 5000}}
+		],
+		"edges": [{"id": "e1", "source": "server", "target": "wait-reg"}]
+	}`
+}
+
+func TestParseScenario_SIPServerInstance(t *testing.T) {
+	graph, err := ParseScenario(sipServerFlow())
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+
+	config := graph.Instances["server"].Config
+	if !config.IsServer() || config.Register {
+		t.Errorf("expected a non-registering server instance, got %+v", config)
+	}
+	if config.ListenHost != "127.0.0.1" || config.ListenPort != 0 || config.AuthRealm != "test" || config.AuthPassword != "secret" {
+		t.Errorf("unexpected server settings: %+v", config)
+	}
+	if got := graph.Nodes["wait-reg"].RegisterNumber; got != "200" {
+		t.Errorf("RegisterNumber = %q, want 200", got)
+	}
+
+	uaFlow := strings.Replace(sipServerFlow(), `"role": "server"`, `"role": "ua"`, 1)
+	if _, err := ParseScenario(uaFlow); err == nil || !strings.Contains(err.Error(), "requires a SIP server instance") {
+		t.Errorf("expected REGISTER_RECEIVED on a UA instance to fail, got %v", err)
+	}
+	diags := ValidateScenario(uaFlow)
+	if !hasCode(diags, "wait-reg", DiagRequiresServer) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagRequiresServer, diags)
+	}
+}
+
+func TestSIPServer_DigestRegisterReceived(t *testing.T) {
+	graph, err := ParseScenario(sipServerFlow())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex, _ := newTestExecutor(t)
+	ex.im = newRangeInstanceManager(25100, 20)
+	if err := ex.im.CreateInstances(graph); err != nil {
+		t.Fatalf("CreateInstances failed: %v", err)
+	}
+	defer ex.im.Cleanup()
+
+	var logs []string
+	addr, err := ex.im.StartSIPServer("server", func(message, level string, opts ...ActionLogOption) {
+		logs = append(logs, message)
+	})
+	if err != nil {
+		t.Fatalf("StartSIPServer failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- ex.executeRegisterReceived(ctx, "server", graph.Nodes["wait-reg"], 5*time.Second)
+	}()
+
+	phone, phonePort := newPBXTestUA(t, nil)
+	newRegister := func() *sip.Request {
+		var recipient sip.Uri
+		if err := sip.ParseUri("sip:"+addr, &recipient); err != nil {
+			t.Fatal(err)
+		}
+		req := sip.NewRequest(sip.REGISTER, recipient)
+		req.AppendHeader(&sip.ToHeader{Address: sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1"}})
+		req.AppendHeader(&sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "200", Host: "127.0.0.1", Port: phonePort}})
+		req.AppendHeader(sip.NewHeader("Expires", "60"))
+		return req
+	}
+
+	// 잘못된 password는 다시 challenge된다
+	req := newRegister()
+	res, err := phone.Do(ctx, req)
+	if err != nil || res.StatusCode != 401 {
+		t.Fatalf("expected 401 challenge, got res=%v err=%v", res, err)
+	}
+	res, err = phone.DoDigestAuth(ctx, req, res, sipgo.DigestAuth{Username: "200", Password: "wrong"})
+	if err != nil || res.StatusCode != 401 {
+		t.Fatalf("expected 401 for wrong password, got res=%v err=%v", res, err)
+	}
+
+	req = newRegister()
+	res, err = phone.Do(ctx, req)
+	if err != nil || res.StatusCode != 401 {
+		t.Fatalf("expected 401 challenge, got res=%v err=%v", res, err)
+	}
+	res, err = phone.DoDigestAuth(ctx, req, res, sipgo.DigestAuth{Username: "200", Password: "secret"})
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("expected 200 after digest auth, got res=%v err=%v", res, err)
+	}
+
+	if err := <-waitErr; err != nil {
+		t.Fatalf("REGISTER_RECEIVED failed: %v", err)
+	}
+
+	regs, err := ex.im.ServerRegistrations("server")
+	if err != nil || len(regs) != 1 || regs[0].DN != "200" {
+		t.Fatalf("unexpected registrations: %+v err=%v", regs, err)
+	}
+
+	target, err := ex.im.ResolveTargetFor("server", "200")
+	if err != nil || target != "sip:200@"+addr {
+		t.Errorf("ResolveTargetFor = %q (err=%v), want the server proxy address", target, err)
+	}
+	if !strings.Contains(strings.Join(logs, "\n"), "REGISTER 200 rejected: invalid credentials") {
+		t.Errorf("expected the bad credentials to be logged, got:\n%s", strings.Join(logs, "\n"))
+	}
+}
+
+func TestLocalPBX_FallbackRoutesUnregisteredDN(t *testing.T) {
+	invites := make(chan *sip.Request, 1)
+	_, ownPort := newPBXTestUA(t, func(req *sip.Request, tx sip.ServerTransaction) {
+		invites <- req
+		tx.Respond(sip.NewResponseFromRequest(req, 200, "OK", nil))
+	})
+	pbx, err := startLocalPBX("127.0.0.1", 0, nil, withPBXFallback(sip.Uri{Scheme: "sip", Host: "127.0.0.1", Port: ownPort}))
+	if err != nil {
+		t.Fatalf("startLocalPBX failed: %v", err)
+	}
+	t.Cleanup(pbx.close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	phone, _ := newPBXTestUA(t, nil)
+	res, err := phone.Do(ctx, sip.NewRequest(sip.INVITE, sip.Uri{Scheme: "sip", User: "555", Host: "127.0.0.1", Port: pbx.port}))
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("INVITE to unregistered DN failed: res=%v err=%v", res, err)
+	}
+	select {
+	case req := <-invites:
+		if req.Recipient.User != "555" || req.Recipient.Port != ownPort {
+			t.Errorf("expected the INVITE routed to the fallback UA, got %s", req.Recipient.String())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("fallback UA did not receive the INVITE")
+	}
+
+}
+
+func TestPushDropOldest_KeepsNewestWhenFull(t *testing.T) {
+	ch := make(chan int, 2)
+	for i := 1; i <= 4; i++ {
+		pushDropOldest(ch, i)
+	}
+	if got := []int{<-ch, <-ch}; got[0] != 3 || got[1] != 4 {
+		t.Errorf("expected the two newest values [3 4], got %v", got)
+	}
+}
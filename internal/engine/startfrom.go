@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// StartFromOptions는 선택 노드부터 실행할 때의 옵션
+type StartFromOptions struct {
+	RunSetup   bool               // 선택 노드에 이르는 경로(setup prefix)를 이벤트 없이 먼저 실행
+	Simulation *SimulationOptions // nil이 아니면 dry-run 모드로 실행
+}
+
+// runOptions는 startScenario의 실행 방식
+type runOptions struct {
+	sim       *SimulationOptions
+	startFrom []string // 비어 있으면 인스턴스의 시작 노드부터 전체 실행
+	runSetup  bool
+	variables map[string]interface{}
+}
+
+// setupStep은 setup prefix 경로의 한 노드와, 다음 노드로 가기 위해 필요한 분기
+type setupStep struct {
+	node       *GraphNode
+	viaFailure bool // 다음 노드가 실패 분기로 연결됨 — 이 노드는 실패해야 경로를 따른다
+}
+
+// chainRun은 인스턴스 하나가 실행할 setup prefix와 체인 시작 노드
+type chainRun struct {
+	setup  []setupStep
+	starts []*GraphNode
+}
+
+// planChains는 인스턴스별 실행 계획과 실행되지 않는(skipped) 노드 목록을 계산한다.
+// 전체 실행이면 모든 인스턴스의 시작 노드를 그대로 사용하고 skipped는 없다.
+func planChains(graph *ExecutionGraph, opts runOptions) (map[string]*chainRun, []string, error) {
+	runs := make(map[string]*chainRun)
+	if len(opts.startFrom) == 0 {
+		for instanceID, chain := range graph.Instances {
+			if len(chain.StartNodes) > 0 {
+				runs[instanceID] = &chainRun{starts: chain.StartNodes}
+			}
+		}
+		return runs, nil, nil
+	}
+
+	for _, nodeID := range opts.startFrom {
+		node, exists := graph.Nodes[nodeID]
+		if !exists {
+			return nil, nil, fmt.Errorf("start node %s not found", nodeID)
+		}
+		if _, dup := runs[node.InstanceID]; dup {
+			return nil, nil, fmt.Errorf("multiple start nodes for instance %s", node.InstanceID)
+		}
+
+		run := &chainRun{starts: []*GraphNode{node}}
+		if opts.runSetup {
+			setup, err := setupPath(graph.Instances[node.InstanceID], node)
+			if err != nil {
+				return nil, nil, err
+			}
+			run.setup = setup
+		}
+		runs[node.InstanceID] = run
+	}
+
+	// 선택 노드에서 도달 가능한 노드만 실행되고 나머지는 skipped
+	reachable := make(map[string]bool)
+	for _, run := range runs {
+		queue := append([]*GraphNode(nil), run.starts...)
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if node == nil || reachable[node.ID] {
+				continue
+			}
+			reachable[node.ID] = true
+			queue = append(queue, node.SuccessNext, node.FailureNext)
+		}
+	}
+
+	skipped := []string{}
+	for id := range graph.Nodes {
+		if !reachable[id] {
+			skipped = append(skipped, id)
+		}
+	}
+	sort.Strings(skipped)
+	return runs, skipped, nil
+}
+
+// setupPath는 인스턴스 시작 노드에서 target 직전까지의 경로를 찾는다.
+// 성공 분기를 먼저 탐색하므로 같은 길이라면 성공 경로가 선택된다.
+func setupPath(chain *InstanceChain, target *GraphNode) ([]setupStep, error) {
+	type visit struct {
+		prev       *GraphNode
+		viaFailure bool
+	}
+
+	for _, start := range chain.StartNodes {
+		if start == target {
+			return nil, nil
+		}
+
+		visited := map[*GraphNode]visit{start: {}}
+		queue := []*GraphNode{start}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			for _, next := range []struct {
+				node       *GraphNode
+				viaFailure bool
+			}{{node.SuccessNext, false}, {node.FailureNext, true}} {
+				if next.node == nil {
+					continue
+				}
+				if _, seen := visited[next.node]; seen {
+					continue
+				}
+				visited[next.node] = visit{prev: node, viaFailure: next.viaFailure}
+				if next.node != target {
+					queue = append(queue, next.node)
+					continue
+				}
+
+				// target에서 시작 노드까지 역추적
+				steps := []setupStep{}
+				for cur := target; cur != start; {
+					v := visited[cur]
+					steps = append([]setupStep{{node: v.prev, viaFailure: v.viaFailure}}, steps...)
+					cur = v.prev
+				}
+				return steps, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("node %s is not reachable from instance %s", target.ID, chain.Config.ID)
+}
+
+// executeSetup은 setup prefix를 노드 상태/액션 로그 없이 실행한다 (에러 로그는 남긴다).
+// 각 노드는 경로가 요구하는 분기(성공/실패)로 끝나야 하며, 그렇지 않으면 setup 실패로 처리한다.
+func (ex *Executor) executeSetup(ctx context.Context, instanceID string, steps []setupStep) error {
+	for _, step := range steps {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		err := ex.executeNode(ctx, instanceID, step.node)
+		switch {
+		case err != nil && !step.viaFailure:
+			return fmt.Errorf("setup prefix failed at node %s: %w", step.node.ID, err)
+		case err == nil && step.viaFailure:
+			return fmt.Errorf("setup prefix expected node %s to fail", step.node.ID)
+		}
+	}
+
+	if len(steps) > 0 {
+		ex.emitNodeActionLog(nil, instanceID, fmt.Sprintf("Setup prefix completed (%d nodes)", len(steps)), "info")
+	}
+	return nil
+}
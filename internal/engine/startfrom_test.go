@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanChains_StartFromWithSetup(t *testing.T) {
+	nodes, edges := twoPartyDryRunFlow()
+	graph, err := ParseScenario(buildTestFlowData(t, nodes, edges))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+
+	runs, skipped, err := planChains(graph, runOptions{startFrom: []string{"send-dtmf", "dtmf"}, runSetup: true})
+	if err != nil {
+		t.Fatalf("planChains failed: %v", err)
+	}
+
+	setupIDs := func(instanceID string) []string {
+		ids := []string{}
+		for _, step := range runs[instanceID].setup {
+			ids = append(ids, step.node.ID)
+		}
+		return ids
+	}
+	if got := setupIDs("inst-a"); !reflect.DeepEqual(got, []string{"make-call"}) {
+		t.Errorf("unexpected setup for inst-a: %v", got)
+	}
+	if got := setupIDs("inst-b"); !reflect.DeepEqual(got, []string{"incoming", "answer"}) {
+		t.Errorf("unexpected setup for inst-b: %v", got)
+	}
+	if want := []string{"answer", "incoming", "make-call"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("expected skipped %v, got %v", want, skipped)
+	}
+
+	if _, _, err := planChains(graph, runOptions{startFrom: []string{"missing"}}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, _, err := planChains(graph, runOptions{startFrom: []string{"send-dtmf", "release"}}); err == nil || !strings.Contains(err.Error(), "multiple start nodes") {
+		t.Errorf("expected multiple start nodes error, got %v", err)
+	}
+}
+
+func TestStartScenarioFrom_DryRunWithSetup(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := twoPartyDryRunFlow()
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	_, err := eng.StartScenarioFrom(scenarioID, []string{"send-dtmf", "dtmf"}, StartFromOptions{
+		RunSetup:   true,
+		Simulation: &SimulationOptions{},
+	})
+	if err != nil {
+		t.Fatalf("StartScenarioFrom failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+
+	states := map[string][]string{}
+	for _, e := range te.GetEventsByName(EventNodeState) {
+		id := e.Data["nodeId"].(string)
+		states[id] = append(states[id], e.Data["newState"].(string))
+	}
+	for _, id := range []string{"make-call", "incoming", "answer"} {
+		if !reflect.DeepEqual(states[id], []string{NodeStateSkipped}) {
+			t.Errorf("expected setup node %s to be reported only as skipped, got %v", id, states[id])
+		}
+	}
+	for _, id := range []string{"send-dtmf", "release", "dtmf", "disconnected"} {
+		if !reflect.DeepEqual(states[id], []string{NodeStateRunning, NodeStateCompleted}) {
+			t.Errorf("expected node %s to run, got %v", id, states[id])
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sipflow/internal/scenario"
+)
+
+// maxCallScenarioDepth는 CallScenario 중첩 전개의 최대 깊이
+const maxCallScenarioDepth = 8
+
+// FlowResolver는 CallScenario 노드가 참조하는 flow 데이터를 조회한다
+type FlowResolver interface {
+	ResolveScenario(scenarioID string) (string, error)
+	ResolveFragment(name string) (string, error)
+}
+
+// repositoryFlowResolver는 scenario.Repository 기반 FlowResolver 구현이다.
+// fragment 이름은 실행 중인 시나리오의 프로젝트 범위에서 조회한다.
+type repositoryFlowResolver struct {
+	repo      *scenario.Repository
+	projectID string
+}
+
+func (r *repositoryFlowResolver) ResolveScenario(scenarioID string) (string, error) {
+	scn, err := r.repo.LoadScenario(scenarioID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load scenario %s: %w", scenarioID, err)
+	}
+	return scn.FlowData, nil
+}
+
+func (r *repositoryFlowResolver) ResolveFragment(name string) (string, error) {
+	fragment, err := r.repo.LoadFragmentByName(r.projectID, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to load fragment %s: %w", name, err)
+	}
+	return fragment.FlowData, nil
+}
+
+var fragmentVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// callScenarioRef는 CallScenario 노드가 참조하는 대상을 "scenario:<id>" 또는 "fragment:<name>" 형태로 반환한다
+func callScenarioRef(data map[string]interface{}) (string, error) {
+	scenarioID := getStringField(data, "scenarioId", "")
+	fragmentName := getStringField(data, "fragmentName", "")
+	switch {
+	case scenarioID != "" && fragmentName != "":
+		return "", fmt.Errorf("CallScenario must reference either scenarioId or fragmentName, not both")
+	case scenarioID != "":
+		return "scenario:" + scenarioID, nil
+	case fragmentName != "":
+		return "fragment:" + fragmentName, nil
+	default:
+		return "", fmt.Errorf("CallScenario requires scenarioId or fragmentName")
+	}
+}
+
+func resolveCallScenarioFlow(resolver FlowResolver, ref string) (string, error) {
+	kind, key, _ := strings.Cut(ref, ":")
+	if kind == "scenario" {
+		return resolver.ResolveScenario(key)
+	}
+	return resolver.ResolveFragment(key)
+}
+
+func isFailureEdge(edge FlowEdge) bool {
+	if edge.SourceHandle == "failure" {
+		return true
+	}
+	return getStringField(edge.Data, "branchType", "") == "failure"
+}
+
+// expandCallScenarios는 CallScenario 노드가 참조하는 flow를 호출 노드 뒤에 인라인으로 전개한다.
+//
+// 전개된 노드 ID는 "<CallScenario 노드 ID>/<원본 노드 ID>" 형태이며 모두 호출 노드의 인스턴스에서 실행된다.
+// 호출 노드의 success 엣지는 fragment의 진입 노드로 연결되고, fragment의 종단 노드는 호출 노드의 원래
+// success 대상으로, failure 분기가 없는 fragment 노드는 호출 노드의 failure 대상으로 연결되어
+// fragment 결과(outcome)가 호출 측 분기로 노출된다.
+// stack은 현재 전개 경로이며 재귀 참조 감지에 사용된다.
+// values는 flow 자신의 바인딩된 변수이며, 반환하는 스코프는 여기에 전개된 fragment별 변수를 더한 것이다.
+func expandCallScenarios(flow FlowData, resolver FlowResolver, stack []string, values map[string]interface{}) (FlowData, VariableScope, error) {
+	nodeTypes := make(map[string]string, len(flow.Nodes))
+	for _, node := range flow.Nodes {
+		nodeTypes[node.ID] = node.Type
+	}
+
+	fragments := make(map[string]map[string]interface{})
+
+	expanded := FlowData{Variables: flow.Variables, MaxDurationMs: flow.MaxDurationMs, LocalPBX: flow.LocalPBX}
+	edges := append([]FlowEdge(nil), flow.Edges...)
+
+	for _, node := range flow.Nodes {
+		expanded.Nodes = append(expanded.Nodes, node)
+		if node.Type != "command" || getStringField(node.Data, "command", "") != CommandCallScenario {
+			continue
+		}
+
+		ref, err := callScenarioRef(node.Data)
+		if err != nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		if resolver == nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: CallScenario %s cannot be resolved without a scenario repository", node.ID, ref)
+		}
+		if slices.Contains(stack, ref) {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: recursive CallScenario detected: %s", node.ID, strings.Join(append(append([]string{}, stack...), ref), " -> "))
+		}
+		if len(stack) >= maxCallScenarioDepth {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: CallScenario nesting exceeds %d levels", node.ID, maxCallScenarioDepth)
+		}
+
+		callerInstanceID := getStringField(node.Data, "sipInstanceId", "")
+		if callerInstanceID == "" {
+			for _, edge := range edges {
+				if edge.Target == node.ID && nodeTypes[edge.Source] == "sipInstance" {
+					callerInstanceID = edge.Source
+					break
+				}
+			}
+		}
+		if callerInstanceID == "" {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: CallScenario requires sipInstanceId or an instance connection", node.ID)
+		}
+
+		subFlowData, err := resolveCallScenarioFlow(resolver, ref)
+		if err != nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		var sub FlowData
+		if err := json.Unmarshal([]byte(subFlowData), &sub); err != nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: failed to unmarshal %s: %w", node.ID, ref, err)
+		}
+
+		params, _ := node.Data["params"].(map[string]interface{})
+		subValues, err := bindFragmentVariables(&sub, params)
+		if err != nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: %s: %w", node.ID, ref, err)
+		}
+
+		// fragment 노드는 모두 호출 노드의 인스턴스에서 실행된다 (중첩 CallScenario 포함)
+		for i, subNode := range sub.Nodes {
+			if subNode.Type != "command" && subNode.Type != "event" {
+				continue
+			}
+			data := make(map[string]interface{}, len(subNode.Data)+1)
+			for k, v := range subNode.Data {
+				data[k] = v
+			}
+			data["sipInstanceId"] = callerInstanceID
+			delete(data, "number") // INCOMING 번호 기반 인스턴스 추론 대신 호출 노드 인스턴스를 사용
+			sub.Nodes[i].Data = data
+		}
+
+		nextStack := append(append([]string{}, stack...), ref)
+		sub, subScope, err := expandCallScenarios(sub, resolver, nextStack, subValues)
+		if err != nil {
+			return FlowData{}, VariableScope{}, err
+		}
+		fragments[node.ID] = subScope.Scenario
+		for id, vars := range subScope.Fragments {
+			fragments[node.ID+"/"+id] = vars
+		}
+
+		entryID, err := fragmentEntryNode(sub)
+		if err != nil {
+			return FlowData{}, VariableScope{}, fmt.Errorf("node %s: %s: %w", node.ID, ref, err)
+		}
+
+		// 호출 노드의 원래 분기 대상 (success 엣지는 fragment 진입 노드로 교체)
+		successTarget, failureTarget := "", ""
+		remaining := edges[:0]
+		for _, edge := range edges {
+			if edge.Source == node.ID {
+				if isFailureEdge(edge) {
+					failureTarget = edge.Target
+				} else {
+					successTarget = edge.Target
+					continue
+				}
+			}
+			remaining = append(remaining, edge)
+		}
+		edges = remaining
+
+		prefix := node.ID + "/"
+		edges = append(edges, FlowEdge{
+			ID:           prefix + "enter",
+			Source:       node.ID,
+			Target:       prefix + entryID,
+			SourceHandle: "success",
+		})
+
+		subTypes := make(map[string]string, len(sub.Nodes))
+		hasSuccess := make(map[string]bool)
+		hasFailure := make(map[string]bool)
+		for _, subNode := range sub.Nodes {
+			subTypes[subNode.ID] = subNode.Type
+		}
+		for _, edge := range sub.Edges {
+			if subTypes[edge.Source] == "sipInstance" {
+				continue
+			}
+			if isFailureEdge(edge) {
+				hasFailure[edge.Source] = true
+			} else {
+				hasSuccess[edge.Source] = true
+			}
+			edge.ID = prefix + edge.ID
+			edge.Source = prefix + edge.Source
+			edge.Target = prefix + edge.Target
+			edges = append(edges, edge)
+		}
+
+		for _, subNode := range sub.Nodes {
+			if subNode.Type != "command" && subNode.Type != "event" {
+				continue
+			}
+
+			expanded.Nodes = append(expanded.Nodes, FlowNode{
+				ID:   prefix + subNode.ID,
+				Type: subNode.Type,
+				Data: subNode.Data,
+			})
+
+			if !hasSuccess[subNode.ID] && successTarget != "" {
+				edges = append(edges, FlowEdge{
+					ID:           prefix + subNode.ID + "/return",
+					Source:       prefix + subNode.ID,
+					Target:       successTarget,
+					SourceHandle: "success",
+				})
+			}
+			if !hasFailure[subNode.ID] && failureTarget != "" {
+				edges = append(edges, FlowEdge{
+					ID:           prefix + subNode.ID + "/fail",
+					Source:       prefix + subNode.ID,
+					Target:       failureTarget,
+					SourceHandle: "failure",
+				})
+			}
+		}
+	}
+
+	scope := VariableScope{
+		Scenario:  make(map[string]interface{}, len(values)),
+		Fragments: fragments,
+	}
+	for name, value := range values {
+		scope.Scenario[name] = value
+	}
+
+	expanded.Edges = edges
+	return expanded, scope, nil
+}
+
+// fragmentEntryNode는 fragment의 단일 진입 노드를 찾는다.
+// sipInstance 노드에서 연결된 노드를 우선하고, 없으면 들어오는 엣지가 없는 노드를 진입 노드로 본다.
+func fragmentEntryNode(flow FlowData) (string, error) {
+	nodeTypes := make(map[string]string, len(flow.Nodes))
+	for _, node := range flow.Nodes {
+		nodeTypes[node.ID] = node.Type
+	}
+
+	instanceCount := 0
+	for _, node := range flow.Nodes {
+		if node.Type == "sipInstance" {
+			instanceCount++
+		}
+	}
+	if instanceCount > 1 {
+		return "", fmt.Errorf("embedded flow must target a single instance (found %d)", instanceCount)
+	}
+
+	var entries []string
+	hasIncoming := make(map[string]bool)
+	for _, edge := range flow.Edges {
+		if nodeTypes[edge.Source] == "sipInstance" {
+			entries = append(entries, edge.Target)
+			continue
+		}
+		hasIncoming[edge.Target] = true
+	}
+	if len(entries) == 0 {
+		for _, node := range flow.Nodes {
+			if (node.Type == "command" || node.Type == "event") && !hasIncoming[node.ID] {
+				entries = append(entries, node.ID)
+			}
+		}
+	}
+
+	switch len(entries) {
+	case 0:
+		return "", fmt.Errorf("embedded flow has no entry node")
+	case 1:
+		return entries[0], nil
+	default:
+		return "", fmt.Errorf("embedded flow must have exactly one entry node (found %d)", len(entries))
+	}
+}
+
+// bindFragmentVariables는 fragment 노드 데이터의 ${name} 참조를 CallScenario params로 치환하고 바인딩된 값을 반환한다.
+// fragment가 variables를 선언한 경우 선언된 기본값을 사용하고, 선언되지 않은 param은 거부한다.
+func bindFragmentVariables(flow *FlowData, params map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(flow.Variables)+len(params))
+	for name, value := range flow.Variables {
+		values[name] = value
+	}
+	for name, value := range params {
+		if flow.Variables != nil {
+			if _, declared := flow.Variables[name]; !declared {
+				return nil, fmt.Errorf("unknown parameter %q", name)
+			}
+		}
+		values[name] = value
+	}
+
+	for i, node := range flow.Nodes {
+		bound, err := bindVariableValue(node.Data, values)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		data, _ := bound.(map[string]interface{})
+		flow.Nodes[i].Data = data
+	}
+	return values, nil
+}
+
+// bindVariableValue는 value 안의 ${name} 참조를 values로 치환한다. values에 없는 참조는 에러를 반환한다.
+func bindVariableValue(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		// 값 전체가 단일 참조이면 원래 타입(숫자/불리언)을 그대로 유지한다
+		if match := fragmentVariablePattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			bound, ok := values[match[1]]
+			if !ok {
+				return nil, fmt.Errorf("unbound variable ${%s}", match[1])
+			}
+			return bound, nil
+		}
+
+		var unbound string
+		result := fragmentVariablePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			name := fragmentVariablePattern.FindStringSubmatch(ref)[1]
+			bound, ok := values[name]
+			if !ok {
+				if unbound == "" {
+					unbound = name
+				}
+				return ref
+			}
+			return formatVariableValue(bound)
+		})
+		if unbound != "" {
+			return nil, fmt.Errorf("unbound variable ${%s}", unbound)
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			bound, err := bindVariableValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = bound
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			bound, err := bindVariableValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = bound
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+func formatVariableValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// executeCallScenario는 CallScenario 커맨드를 실행한다.
+// 실제 하위 노드는 ParseScenario 단계에서 이 노드 뒤에 전개되어 있으므로 진입 로그만 남긴다.
+func (ex *Executor) executeCallScenario(ctx context.Context, instanceID string, node *GraphNode) error {
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Entering sub-scenario %s", node.SubScenarioRef), "info")
+	return nil
+}
@@ -0,0 +1,257 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sipflow/internal/scenario"
+)
+
+type fakeFlowResolver struct {
+	scenarios map[string]string
+	fragments map[string]string
+}
+
+func (r *fakeFlowResolver) ResolveScenario(scenarioID string) (string, error) {
+	flow, ok := r.scenarios[scenarioID]
+	if !ok {
+		return "", fmt.Errorf("scenario %s not found", scenarioID)
+	}
+	return flow, nil
+}
+
+func (r *fakeFlowResolver) ResolveFragment(name string) (string, error) {
+	flow, ok := r.fragments[name]
+	if !ok {
+		return "", fmt.Errorf("fragment %s not found", name)
+	}
+	return flow, nil
+}
+
+const callAndPlayFragment = `{
+  "variables": {"target": "", "callId": "call-1", "wait": 2000},
+  "nodes": [
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "targetUri": "${target}", "callId": "${callId}"}},
+    {"id": "play", "type": "command", "data": {"command": "PlayAudio", "filePath": "/tmp/${target}.wav", "callId": "${callId}", "timeout": "${wait}"}},
+    {"id": "release", "type": "command", "data": {"command": "Release", "callId": "${callId}"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "make", "target": "play", "sourceHandle": "success"},
+    {"id": "e2", "source": "play", "target": "release", "sourceHandle": "success"}
+  ]
+}`
+
+func TestParseScenarioWithResolver_ExpandsFragment(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "call-1", "type": "command", "data": {"command": "CallScenario", "fragmentName": "call-and-play", "params": {"target": "200", "callId": "primary"}}},
+    {"id": "after", "type": "event", "data": {"event": "TIMEOUT", "sipInstanceId": "inst-a", "timeout": 100}},
+    {"id": "on-fail", "type": "command", "data": {"command": "Release", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e0", "source": "inst-a", "target": "call-1", "sourceHandle": "success"},
+    {"id": "e1", "source": "call-1", "target": "after", "sourceHandle": "success"},
+    {"id": "e2", "source": "call-1", "target": "on-fail", "sourceHandle": "failure"}
+  ]
+}`
+	resolver := &fakeFlowResolver{fragments: map[string]string{"call-and-play": callAndPlayFragment}}
+
+	graph, err := ParseScenarioWithResolver(flowJSON, resolver)
+	if err != nil {
+		t.Fatalf("ParseScenarioWithResolver failed: %v", err)
+	}
+
+	callNode := graph.Nodes["call-1"]
+	if callNode.SubScenarioRef != "fragment:call-and-play" {
+		t.Errorf("expected SubScenarioRef fragment:call-and-play, got %q", callNode.SubScenarioRef)
+	}
+	if callNode.SuccessNext == nil || callNode.SuccessNext.ID != "call-1/make" {
+		t.Fatalf("expected CallScenario to enter call-1/make")
+	}
+
+	makeNode := graph.Nodes["call-1/make"]
+	if makeNode.InstanceID != "inst-a" {
+		t.Errorf("expected fragment node on caller instance inst-a, got %q", makeNode.InstanceID)
+	}
+	if makeNode.TargetURI != "200" || makeNode.CallID != "primary" {
+		t.Errorf("expected bound params, got targetUri=%q callId=%q", makeNode.TargetURI, makeNode.CallID)
+	}
+
+	play := graph.Nodes["call-1/play"]
+	if play.FilePath != "/tmp/200.wav" {
+		t.Errorf("expected interpolated filePath, got %q", play.FilePath)
+	}
+	if play.Timeout != 2*time.Second {
+		t.Errorf("expected typed default variable timeout 2s, got %v", play.Timeout)
+	}
+
+	release := graph.Nodes["call-1/release"]
+	if release.SuccessNext == nil || release.SuccessNext.ID != "after" {
+		t.Fatalf("expected fragment exit to return to caller success branch")
+	}
+	for _, id := range []string{"call-1/make", "call-1/play", "call-1/release"} {
+		if graph.Nodes[id].FailureNext == nil || graph.Nodes[id].FailureNext.ID != "on-fail" {
+			t.Errorf("%s: expected unhandled failure to route to caller failure branch", id)
+		}
+	}
+}
+
+func TestParseScenarioWithResolver_RecursionDetected(t *testing.T) {
+	resolver := &fakeFlowResolver{fragments: map[string]string{
+		"a": `{"nodes": [{"id": "n", "type": "command", "data": {"command": "CallScenario", "fragmentName": "b"}}], "edges": []}`,
+		"b": `{"nodes": [{"id": "n", "type": "command", "data": {"command": "CallScenario", "fragmentName": "a"}}], "edges": []}`,
+	}}
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "call", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "fragmentName": "a"}}
+  ],
+  "edges": []
+}`
+
+	_, err := ParseScenarioWithResolver(flowJSON, resolver)
+	if err == nil || !strings.Contains(err.Error(), "recursive CallScenario detected: fragment:a -> fragment:b -> fragment:a") {
+		t.Fatalf("expected recursion error, got %v", err)
+	}
+}
+
+func TestParseScenarioWithResolver_UnboundAndUnknownParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		params   string
+		contains string
+	}{
+		{
+			name:     "unbound variable",
+			fragment: `{"nodes": [{"id": "n", "type": "command", "data": {"command": "MakeCall", "targetUri": "${target}"}}], "edges": []}`,
+			params:   `{}`,
+			contains: "unbound variable ${target}",
+		},
+		{
+			name:     "undeclared parameter",
+			fragment: `{"variables": {"target": "200"}, "nodes": [{"id": "n", "type": "command", "data": {"command": "MakeCall", "targetUri": "${target}"}}], "edges": []}`,
+			params:   `{"targte": "300"}`,
+			contains: `unknown parameter "targte"`,
+		},
+		{
+			name:     "multiple entries",
+			fragment: `{"nodes": [{"id": "n1", "type": "command", "data": {"command": "Release"}}, {"id": "n2", "type": "command", "data": {"command": "Release"}}], "edges": []}`,
+			params:   `{}`,
+			contains: "exactly one entry node",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeFlowResolver{fragments: map[string]string{"frag": tt.fragment}}
+			flowJSON := `{"nodes": [
+  {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+  {"id": "call", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "fragmentName": "frag", "params": ` + tt.params + `}}
+], "edges": []}`
+			_, err := ParseScenarioWithResolver(flowJSON, resolver)
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Fatalf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+func TestParseScenarioWithResolver_FragmentVariableScopes(t *testing.T) {
+	resolver := &fakeFlowResolver{fragments: map[string]string{
+		"park": `{"variables": {"slot": "701"}, "nodes": [{"id": "park", "type": "command", "data": {"command": "Park", "parkSlot": "${slot}"}}], "edges": []}`,
+		"outer": `{"nodes": [
+  {"id": "inner", "type": "command", "data": {"command": "CallScenario", "fragmentName": "park", "params": {"slot": "702"}}}
+], "edges": []}`,
+	}}
+	flowJSON := `{"variables": {"trunk": "9"}, "nodes": [
+  {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+  {"id": "call", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "fragmentName": "park"}},
+  {"id": "nested", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "fragmentName": "outer"}}
+], "edges": [
+  {"id": "e1", "source": "call", "target": "nested", "sourceHandle": "success"}
+]}`
+
+	graph, err := ParseScenarioWithResolver(flowJSON, resolver)
+	if err != nil {
+		t.Fatalf("ParseScenarioWithResolver failed: %v", err)
+	}
+	if got := graph.Nodes["call/park"].ParkSlot; got != "701" {
+		t.Errorf("expected fragment default slot 701, got %q", got)
+	}
+	if got := graph.Nodes["nested/inner/park"].ParkSlot; got != "702" {
+		t.Errorf("expected nested fragment to receive bound param slot 702, got %q", got)
+	}
+	if vars := graph.Variables.Resolve("call/park"); vars["slot"] != "701" || vars["trunk"] != "9" {
+		t.Errorf("expected fragment scope over scenario variables, got %v", vars)
+	}
+	if vars := graph.Variables.Resolve("nested/inner/park"); vars["slot"] != "702" || vars["trunk"] != "9" {
+		t.Errorf("expected nested scope with bound param, got %v", vars)
+	}
+	if vars := graph.Variables.Resolve("call"); vars["slot"] != nil {
+		t.Errorf("expected fragment variables to stay out of the caller scope, got %v", vars)
+	}
+}
+
+func TestParseScenario_CallScenarioWithoutResolver(t *testing.T) {
+	flowJSON := `{"nodes": [
+  {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+  {"id": "call", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "scenarioId": "other"}}
+], "edges": []}`
+
+	_, err := ParseScenario(flowJSON)
+	if err == nil || !strings.Contains(err.Error(), "cannot be resolved") {
+		t.Fatalf("expected resolver error, got %v", err)
+	}
+}
+
+func TestRepositoryFlowResolver_ScenarioAndFragment(t *testing.T) {
+	repo, err := scenario.NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	embedded, err := repo.CreateScenario("default", "embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	embeddedFlow := `{"nodes": [
+  {"id": "inst-x", "type": "sipInstance", "data": {"dn": "900"}},
+  {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-x"}}
+], "edges": [{"id": "e", "source": "inst-x", "target": "hold"}]}`
+	if err := repo.SaveScenario(embedded.ID, embeddedFlow); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateFragment("default", "retrieve", `{"nodes": [{"id": "r", "type": "command", "data": {"command": "Retrieve"}}], "edges": []}`); err != nil {
+		t.Fatal(err)
+	}
+
+	flowJSON := `{"nodes": [
+  {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+  {"id": "call-s", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "scenarioId": "` + embedded.ID + `"}},
+  {"id": "call-f", "type": "command", "data": {"command": "CallScenario", "sipInstanceId": "inst-a", "fragmentName": "retrieve"}}
+], "edges": [
+  {"id": "e0", "source": "inst-a", "target": "call-s"},
+  {"id": "e1", "source": "call-s", "target": "call-f"}
+]}`
+
+	graph, err := ParseScenarioWithResolver(flowJSON, &repositoryFlowResolver{repo: repo, projectID: "default"})
+	if err != nil {
+		t.Fatalf("ParseScenarioWithResolver failed: %v", err)
+	}
+	if _, exists := graph.Instances["inst-x"]; exists {
+		t.Error("embedded scenario instance must not be added to the caller graph")
+	}
+	hold := graph.Nodes["call-s/hold"]
+	if hold == nil || hold.InstanceID != "inst-a" || hold.SuccessNext == nil || hold.SuccessNext.ID != "call-f" {
+		t.Fatalf("expected embedded scenario node rebound to inst-a and returning to call-f")
+	}
+	if graph.Nodes["call-f"].SuccessNext == nil || graph.Nodes["call-f"].SuccessNext.ID != "call-f/r" {
+		t.Fatal("expected fragment node after call-f")
+	}
+}
@@ -0,0 +1,556 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// SubscriptionEventNotifyReceived는 Subscribe로 만든 구독에서 본문 상태가 일치하는 NOTIFY를 받을 때까지 대기하는 이벤트
+const SubscriptionEventNotifyReceived = "NOTIFY_RECEIVED"
+
+// Subscribe/Unsubscribe/NOTIFY_RECEIVED 노드의 이벤트 패키지 (eventPackage)
+const (
+	EventPackageDialog   = "dialog"          // BLF (RFC 4235 dialog-info)
+	EventPackageMWI      = "message-summary" // MWI (RFC 3842 simple-message-summary)
+	EventPackagePresence = "presence"        // presence (RFC 3863 PIDF)
+)
+
+// defaultSubscribeExpires는 expires가 없는 Subscribe의 구독 기간(초)
+const defaultSubscribeExpires = 3600
+
+var eventPackageAccept = map[string]string{
+	EventPackageDialog:   "application/dialog-info+xml",
+	EventPackageMWI:      "application/simple-message-summary",
+	EventPackagePresence: "application/pidf+xml",
+}
+
+// eventPackageStates는 패키지별로 NOTIFY_RECEIVED의 expectedState에 쓸 수 있는 값
+var eventPackageStates = map[string][]string{
+	EventPackageDialog:   {"trying", "proceeding", "early", "confirmed", "terminated"},
+	EventPackageMWI:      {"yes", "no"},
+	EventPackagePresence: {"open", "closed"},
+}
+
+// validateEventPackage는 이벤트 패키지와 기대 상태(비어 있으면 아무 NOTIFY)를 검사한다
+func validateEventPackage(eventPackage, expectedState string) error {
+	states, ok := eventPackageStates[eventPackage]
+	if !ok {
+		return fmt.Errorf("unsupported eventPackage %q", eventPackage)
+	}
+	if expectedState == "" {
+		return nil
+	}
+	for _, state := range states {
+		if strings.EqualFold(state, expectedState) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported expectedState %q for %s (%s)", expectedState, eventPackage, strings.Join(states, "|"))
+}
+
+// notifyState는 NOTIFY 본문에서 읽은 상태
+type notifyState struct {
+	states  []string // dialog: dialog별 state (활성 dialog가 없으면 terminated), MWI: yes|no, presence: tuple별 basic
+	summary string   // 로그용 요약
+}
+
+func (s notifyState) matches(expected string) bool {
+	if expected == "" {
+		return true
+	}
+	for _, state := range s.states {
+		if strings.EqualFold(state, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNotifyBody는 이벤트 패키지에 맞게 NOTIFY 본문을 해석한다
+func parseNotifyBody(eventPackage string, body []byte) (notifyState, error) {
+	switch eventPackage {
+	case EventPackageDialog:
+		info, err := parseDialogInfo(body)
+		if err != nil {
+			return notifyState{}, err
+		}
+		return info.notifyState(), nil
+	case EventPackageMWI:
+		summary, err := parseMessageSummary(body)
+		if err != nil {
+			return notifyState{}, err
+		}
+		return summary.notifyState(), nil
+	case EventPackagePresence:
+		doc, err := parsePIDF(body)
+		if err != nil {
+			return notifyState{}, err
+		}
+		return doc.notifyState(), nil
+	}
+	return notifyState{}, fmt.Errorf("unsupported eventPackage %q", eventPackage)
+}
+
+// messageSummary는 application/simple-message-summary 본문 (RFC 3842)
+type messageSummary struct {
+	MessagesWaiting bool
+	Account         string
+	Voice           string // "new/old (urgent new/urgent old)"
+}
+
+func parseMessageSummary(body []byte) (messageSummary, error) {
+	var summary messageSummary
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "messages-waiting":
+			switch strings.ToLower(value) {
+			case "yes":
+				summary.MessagesWaiting = true
+			case "no":
+			default:
+				return messageSummary{}, fmt.Errorf("invalid Messages-Waiting value %q", value)
+			}
+			found = true
+		case "message-account":
+			summary.Account = value
+		case "voice-message":
+			summary.Voice = value
+		}
+	}
+	if !found {
+		return messageSummary{}, fmt.Errorf("message-summary body has no Messages-Waiting line")
+	}
+	return summary, nil
+}
+
+func (m messageSummary) notifyState() notifyState {
+	waiting := "no"
+	if m.MessagesWaiting {
+		waiting = "yes"
+	}
+	summary := "messages-waiting: " + waiting
+	if m.Voice != "" {
+		summary += ", voice " + m.Voice
+	}
+	return notifyState{states: []string{waiting}, summary: summary}
+}
+
+// pidfDocument는 application/pidf+xml 본문 (RFC 3863)
+type pidfDocument struct {
+	XMLName xml.Name    `xml:"presence"`
+	Entity  string      `xml:"entity,attr"`
+	Tuples  []pidfTuple `xml:"tuple"`
+}
+
+type pidfTuple struct {
+	ID    string `xml:"id,attr"`
+	Basic string `xml:"status>basic"`
+	Note  string `xml:"note"`
+}
+
+func parsePIDF(body []byte) (*pidfDocument, error) {
+	var doc pidfDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid pidf body: %w", err)
+	}
+	return &doc, nil
+}
+
+func (doc *pidfDocument) notifyState() notifyState {
+	var states []string
+	for _, tuple := range doc.Tuples {
+		if basic := strings.ToLower(strings.TrimSpace(tuple.Basic)); basic != "" {
+			states = append(states, basic)
+		}
+	}
+	if len(states) == 0 {
+		states = []string{"closed"}
+	}
+	return notifyState{states: states, summary: "presence: " + strings.Join(states, ", ")}
+}
+
+// notifyState는 BLF 상태를 반환한다. 진행 중인 dialog가 없으면 terminated(idle)로 본다.
+func (info *dialogInfo) notifyState() notifyState {
+	var states []string
+	active := false
+	for _, d := range info.Dialogs {
+		state := strings.ToLower(strings.TrimSpace(d.State))
+		if state == "" {
+			continue
+		}
+		states = append(states, state)
+		if state != "terminated" {
+			active = true
+		}
+	}
+	if !active {
+		return notifyState{states: append(states, "terminated"), summary: "dialog: idle"}
+	}
+	return notifyState{states: states, summary: "dialog: " + strings.Join(states, ", ")}
+}
+
+// eventSubscriber는 SUBSCRIBE를 보내고 NOTIFY를 받는 임시 UA.
+// 인스턴스 UA(diago)는 dialog 밖 NOTIFY를 처리하지 않으므로 구독마다 별도 UDP 리스너를 Contact로 쓴다.
+type eventSubscriber struct {
+	event    string
+	user     string
+	ua       *sipgo.UserAgent
+	client   *sipgo.Client
+	conn     net.PacketConn
+	contact  sip.Uri
+	notifies chan *sip.Request
+}
+
+func newEventSubscriber(user, bindHost, event string) (*eventSubscriber, error) {
+	advertised := advertisedHost(bindHost)
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(bindHost, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("%s subscription listen failed: %w", event, err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	uaOpts := []sipgo.UserAgentOption{sipgo.WithUserAgentHostname(advertised)}
+	if user != "" {
+		uaOpts = append(uaOpts, sipgo.WithUserAgent(user))
+	}
+	ua, err := sipgo.NewUA(uaOpts...)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create %s subscription UA: %w", event, err)
+	}
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		ua.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to create %s subscription server: %w", event, err)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname(advertised), sipgo.WithClientPort(port))
+	if err != nil {
+		ua.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to create %s subscription client: %w", event, err)
+	}
+
+	s := &eventSubscriber{
+		event:    event,
+		user:     user,
+		ua:       ua,
+		client:   client,
+		conn:     conn,
+		contact:  sip.Uri{Scheme: "sip", User: user, Host: advertised, Port: port},
+		notifies: make(chan *sip.Request, 32),
+	}
+	srv.OnNotify(func(req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil))
+		if header := req.GetHeader("Event"); header == nil || !strings.HasPrefix(strings.TrimSpace(header.Value()), event) {
+			return
+		}
+		// 대기 중인 노드가 없으면 새 NOTIFY를 버린다 — 초기 NOTIFY를 포함해 32건까지 보관
+		select {
+		case s.notifies <- req:
+		default:
+		}
+	})
+	go srv.ServeUDP(conn)
+	return s, nil
+}
+
+func (s *eventSubscriber) close() {
+	s.ua.Close()
+	s.conn.Close()
+}
+
+// subscription은 한 이벤트 패키지/대상에 대한 구독 dialog (RFC 6665)
+type subscription struct {
+	*eventSubscriber
+	eventPackage string
+	target       string
+
+	mu      sync.Mutex
+	remote  sip.Uri // in-dialog SUBSCRIBE의 Request-URI (2xx Contact, 없으면 최초 대상)
+	callID  sip.CallIDHeader
+	from    *sip.FromHeader
+	to      *sip.ToHeader // 2xx 후 to-tag 포함
+	cseq    uint32
+	refresh *time.Timer
+}
+
+func newSubscription(subscriber *eventSubscriber, eventPackage, target string, recipient sip.Uri) *subscription {
+	from := &sip.FromHeader{
+		Address: sip.Uri{Scheme: "sip", User: subscriber.user, Host: subscriber.contact.Host},
+		Params:  sip.NewParams(),
+	}
+	from.Params.Add("tag", sip.GenerateTagN(16))
+	return &subscription{
+		eventSubscriber: subscriber,
+		eventPackage:    eventPackage,
+		target:          target,
+		remote:          recipient,
+		callID:          sip.CallIDHeader(sip.GenerateTagN(24)),
+		from:            from,
+		to:              &sip.ToHeader{Address: sip.Uri{Scheme: recipient.Scheme, User: recipient.User, Host: recipient.Host}},
+		cseq:            1,
+	}
+}
+
+// send는 구독 dialog 안에서 SUBSCRIBE를 보낸다. expires가 0이면 구독 해지(또는 1회 조회).
+func (s *subscription) send(ctx context.Context, expires int) (*sip.Response, error) {
+	s.mu.Lock()
+	req := sip.NewRequest(sip.SUBSCRIBE, *s.remote.Clone())
+	req.AppendHeader(sip.HeaderClone(s.from))
+	req.AppendHeader(sip.HeaderClone(s.to))
+	callID := s.callID
+	req.AppendHeader(&callID)
+	req.AppendHeader(&sip.CSeqHeader{SeqNo: s.cseq, MethodName: sip.SUBSCRIBE})
+	s.cseq++
+	s.mu.Unlock()
+
+	req.AppendHeader(sip.NewHeader("Event", s.eventPackage))
+	req.AppendHeader(sip.NewHeader("Accept", eventPackageAccept[s.eventPackage]))
+	req.AppendHeader(sip.NewHeader("Expires", strconv.Itoa(expires)))
+	req.AppendHeader(&sip.ContactHeader{Address: s.contact})
+
+	res, err := s.client.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("SUBSCRIBE (%s) failed: %w", s.eventPackage, err)
+	}
+	if !res.IsSuccess() {
+		return res, fmt.Errorf("SUBSCRIBE (%s) rejected with %d %s", s.eventPackage, res.StatusCode, res.Reason)
+	}
+
+	s.mu.Lock()
+	if to := res.To(); to != nil {
+		if tag, ok := to.Params.Get("tag"); ok && tag != "" {
+			s.to.Params = to.Params.Clone()
+		}
+	}
+	if contact := res.Contact(); contact != nil {
+		s.remote = *contact.Address.Clone()
+	}
+	s.mu.Unlock()
+	return res, nil
+}
+
+// grantedExpires는 2xx의 Expires(없으면 요청한 값)를 반환한다
+func grantedExpires(res *sip.Response, requested int) int {
+	if header := res.GetHeader("Expires"); header != nil {
+		if granted, err := strconv.Atoi(strings.TrimSpace(header.Value())); err == nil && granted > 0 {
+			return granted
+		}
+	}
+	return requested
+}
+
+// scheduleRefresh는 구독 만료 전(80%)에 SUBSCRIBE를 다시 보낸다
+func (s *subscription) scheduleRefresh(expires int, onError func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refresh != nil {
+		s.refresh.Stop()
+	}
+	s.refresh = time.AfterFunc(time.Duration(expires)*time.Second*4/5, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, err := s.send(ctx, expires)
+		if err != nil {
+			onError(err)
+			return
+		}
+		s.scheduleRefresh(grantedExpires(res, expires), onError)
+	})
+}
+
+// terminate는 갱신을 멈추고 Expires: 0으로 구독을 해지한 뒤 리스너를 닫는다
+func (s *subscription) terminate(ctx context.Context) error {
+	s.mu.Lock()
+	if s.refresh != nil {
+		s.refresh.Stop()
+	}
+	s.mu.Unlock()
+	defer s.close()
+	_, err := s.send(ctx, 0)
+	return err
+}
+
+// parseSubscriptionFields는 Subscribe/Unsubscribe/NOTIFY_RECEIVED 노드의 구독 필드를 읽는다
+func parseSubscriptionFields(gnode *GraphNode, data map[string]interface{}) error {
+	gnode.EventPackage = getStringField(data, "eventPackage", EventPackageDialog)
+	gnode.SubTarget = getStringField(data, "subscribeTarget", "")
+	gnode.SubExpires = int(getFloatField(data, "expires", defaultSubscribeExpires))
+	if gnode.Event == SubscriptionEventNotifyReceived {
+		gnode.ExpectedState = strings.ToLower(getStringField(data, "expectedState", ""))
+	}
+	if gnode.SubTarget == "" {
+		return fmt.Errorf("%s requires subscribeTarget", gnode.Command+gnode.Event)
+	}
+	return validateEventPackage(gnode.EventPackage, gnode.ExpectedState)
+}
+
+func subscriptionKey(instanceID, eventPackage, target string) string {
+	return instanceID + "/" + eventPackage + "/" + target
+}
+
+func (ex *Executor) subscriptionFor(instanceID, eventPackage, target string) (*subscription, bool) {
+	ex.subMu.Lock()
+	defer ex.subMu.Unlock()
+	sub, exists := ex.subscriptions[subscriptionKey(instanceID, eventPackage, target)]
+	return sub, exists
+}
+
+// closeSubscriptions는 run 정리 시 남은 구독을 모두 해지한다
+func (ex *Executor) closeSubscriptions() {
+	ex.subMu.Lock()
+	subs := make([]*subscription, 0, len(ex.subscriptions))
+	for key, sub := range ex.subscriptions {
+		subs = append(subs, sub)
+		delete(ex.subscriptions, key)
+	}
+	ex.subMu.Unlock()
+
+	for _, sub := range subs {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_ = sub.terminate(ctx)
+		cancel()
+	}
+}
+
+// resolveSubscribeTarget은 구독 대상 DN/URI를 Request-URI로 변환한다
+func (ex *Executor) resolveSubscribeTarget(instanceID, target string) (sip.Uri, error) {
+	var recipient sip.Uri
+	resolved, err := ex.im.ResolveTargetFor(instanceID, target)
+	if err != nil {
+		return recipient, fmt.Errorf("failed to resolve target %q: %w", target, err)
+	}
+	if err := sip.ParseUri(resolved, &recipient); err != nil {
+		return recipient, fmt.Errorf("invalid target URI %q: %w", resolved, err)
+	}
+	return recipient, nil
+}
+
+// executeSubscribe는 subscribeTarget의 eventPackage 상태를 구독한다. 같은 대상/패키지를 다시 구독하면 갱신한다.
+func (ex *Executor) executeSubscribe(ctx context.Context, instanceID string, node *GraphNode) error {
+	if node.SubTarget == "" {
+		return fmt.Errorf("Subscribe: subscribeTarget is required")
+	}
+	expires := node.SubExpires
+	if expires <= 0 {
+		expires = defaultSubscribeExpires
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+
+	key := subscriptionKey(instanceID, node.EventPackage, node.SubTarget)
+	sub, exists := ex.subscriptionFor(instanceID, node.EventPackage, node.SubTarget)
+	if !exists {
+		instance, err := ex.im.GetInstance(instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to get instance: %w", err)
+		}
+		recipient, err := ex.resolveSubscribeTarget(instanceID, node.SubTarget)
+		if err != nil {
+			return fmt.Errorf("Subscribe: %w", err)
+		}
+		subscriber, err := newEventSubscriber(instance.Config.DN, resolveBindHost(instance.Config), node.EventPackage)
+		if err != nil {
+			return fmt.Errorf("Subscribe: %w", err)
+		}
+		sub = newSubscription(subscriber, node.EventPackage, node.SubTarget, recipient)
+	}
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Subscribe: %s of %s (expires %ds)", node.EventPackage, node.SubTarget, expires), "info")
+	res, err := sub.send(timeoutCtx, expires)
+	if err != nil {
+		if !exists {
+			sub.close()
+		}
+		return fmt.Errorf("Subscribe: %w", err)
+	}
+	granted := grantedExpires(res, expires)
+	sub.scheduleRefresh(granted, func(err error) {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Subscribe: refresh of %s/%s failed: %v", node.EventPackage, node.SubTarget, err), "warn")
+	})
+
+	ex.subMu.Lock()
+	ex.subscriptions[key] = sub
+	ex.subMu.Unlock()
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Subscribe succeeded (%s of %s, expires %ds)", node.EventPackage, node.SubTarget, granted), "info",
+		WithSIPMessage("sent", "SUBSCRIBE", res.StatusCode, string(sub.callID), ex.instanceDN(instanceID), node.SubTarget))
+	return nil
+}
+
+// executeUnsubscribe는 구독을 Expires: 0으로 해지한다
+func (ex *Executor) executeUnsubscribe(ctx context.Context, instanceID string, node *GraphNode) error {
+	key := subscriptionKey(instanceID, node.EventPackage, node.SubTarget)
+	ex.subMu.Lock()
+	sub, exists := ex.subscriptions[key]
+	delete(ex.subscriptions, key)
+	ex.subMu.Unlock()
+	if !exists {
+		return fmt.Errorf("Unsubscribe: no %s subscription to %s", node.EventPackage, node.SubTarget)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+	if err := sub.terminate(timeoutCtx); err != nil {
+		return fmt.Errorf("Unsubscribe: %w", err)
+	}
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Unsubscribe succeeded (%s of %s)", node.EventPackage, node.SubTarget), "info",
+		WithSIPMessage("sent", "SUBSCRIBE", 200, string(sub.callID), ex.instanceDN(instanceID), node.SubTarget))
+	return nil
+}
+
+// executeNotifyReceived는 구독에서 expectedState와 일치하는 NOTIFY를 받을 때까지 대기한다.
+// 일치하지 않는 NOTIFY는 로그만 남기고 계속 기다린다.
+func (ex *Executor) executeNotifyReceived(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	sub, exists := ex.subscriptionFor(instanceID, node.EventPackage, node.SubTarget)
+	if !exists {
+		return fmt.Errorf("NOTIFY_RECEIVED: no %s subscription to %s (add a Subscribe node first)", node.EventPackage, node.SubTarget)
+	}
+
+	for {
+		select {
+		case req := <-sub.notifies:
+			state, err := parseNotifyBody(node.EventPackage, req.Body())
+			if err != nil {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("NOTIFY_RECEIVED: ignoring NOTIFY: %v", err), "warn")
+				continue
+			}
+			callID := ""
+			if header := req.CallID(); header != nil {
+				callID = string(*header)
+			}
+			if !state.matches(node.ExpectedState) {
+				ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("NOTIFY_RECEIVED: %s from %s (waiting for %s)", state.summary, node.SubTarget, node.ExpectedState), "info",
+					WithSIPMessage("received", "NOTIFY", 0, callID, node.SubTarget, ex.instanceDN(instanceID)))
+				continue
+			}
+			ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("NOTIFY_RECEIVED: %s from %s", state.summary, node.SubTarget), "info",
+				WithSIPMessage("received", "NOTIFY", 0, callID, node.SubTarget, ex.instanceDN(instanceID)))
+			return nil
+		case <-ctx.Done():
+			if node.ExpectedState != "" {
+				return fmt.Errorf("NOTIFY_RECEIVED event timeout after %v waiting for %s %s of %s", timeout, node.EventPackage, node.ExpectedState, node.SubTarget)
+			}
+			return fmt.Errorf("NOTIFY_RECEIVED event timeout after %v", timeout)
+		}
+	}
+}
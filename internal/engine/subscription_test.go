@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+func TestParseNotifyBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		body     string
+		expected string
+		matches  bool
+		wantErr  bool
+	}{
+		{
+			name:     "BLF confirmed",
+			pkg:      EventPackageDialog,
+			body:     `<dialog-info xmlns="urn:ietf:params:xml:ns:dialog-info" entity="sip:2001@pbx"><dialog id="a"><state>confirmed</state></dialog></dialog-info>`,
+			expected: "confirmed",
+			matches:  true,
+		},
+		{
+			name:     "BLF idle counts as terminated",
+			pkg:      EventPackageDialog,
+			body:     `<dialog-info xmlns="urn:ietf:params:xml:ns:dialog-info" entity="sip:2001@pbx"></dialog-info>`,
+			expected: "terminated",
+			matches:  true,
+		},
+		{
+			name:     "BLF early is not confirmed",
+			pkg:      EventPackageDialog,
+			body:     `<dialog-info entity="sip:2001@pbx"><dialog id="a" direction="recipient"><state>early</state></dialog></dialog-info>`,
+			expected: "confirmed",
+			matches:  false,
+		},
+		{
+			name:     "MWI waiting",
+			pkg:      EventPackageMWI,
+			body:     "Messages-Waiting: yes\r\nMessage-Account: sip:2001@pbx\r\nVoice-Message: 2/8 (0/2)\r\n",
+			expected: "yes",
+			matches:  true,
+		},
+		{
+			name:    "MWI without Messages-Waiting",
+			pkg:     EventPackageMWI,
+			body:    "Voice-Message: 0/0\r\n",
+			wantErr: true,
+		},
+		{
+			name:     "presence open",
+			pkg:      EventPackagePresence,
+			body:     `<presence xmlns="urn:ietf:params:xml:ns:pidf" entity="sip:2001@pbx"><tuple id="t1"><status><basic>open</basic></status></tuple></presence>`,
+			expected: "open",
+			matches:  true,
+		},
+		{
+			name:     "presence without tuples is closed",
+			pkg:      EventPackagePresence,
+			body:     `<presence xmlns="urn:ietf:params:xml:ns:pidf" entity="sip:2001@pbx"></presence>`,
+			expected: "closed",
+			matches:  true,
+		},
+		{
+			name:    "unsupported package",
+			pkg:     "conference",
+			body:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := parseNotifyBody(tt.pkg, []byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got state %+v", state)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNotifyBody failed: %v", err)
+			}
+			if got := state.matches(tt.expected); got != tt.matches {
+				t.Errorf("matches(%q) = %v, want %v (states %v)", tt.expected, got, tt.matches, state.states)
+			}
+		})
+	}
+}
+
+// startTestNotifier는 SUBSCRIBE에 200으로 응답하고 Contact로 body를 NOTIFY하는 UAS를 띄운다
+func startTestNotifier(t *testing.T, event, contentType, body string) (sip.Uri, <-chan *sip.Request) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	ua, err := sipgo.NewUA(sipgo.WithUserAgent("2001"), sipgo.WithUserAgentHostname("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ua.Close()
+		conn.Close()
+	})
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname("127.0.0.1"), sipgo.WithClientPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribes := make(chan *sip.Request, 8)
+	tag := sip.GenerateTagN(8)
+	srv.OnSubscribe(func(req *sip.Request, tx sip.ServerTransaction) {
+		subscribes <- req
+		res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+		if to := res.To(); to != nil && !to.Params.Has("tag") {
+			to.Params.Add("tag", tag)
+		}
+		res.AppendHeader(&sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "2001", Host: "127.0.0.1", Port: port}})
+		_ = tx.Respond(res)
+
+		contact := req.Contact()
+		if contact == nil {
+			return
+		}
+		go func() {
+			notify := sip.NewRequest(sip.NOTIFY, *contact.Address.Clone())
+			notify.AppendHeader(sip.NewHeader("Event", event))
+			notify.AppendHeader(sip.NewHeader("Subscription-State", "active;expires=3600"))
+			notify.AppendHeader(sip.NewHeader("Content-Type", contentType))
+			notify.SetBody([]byte(body))
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			_, _ = client.Do(ctx, notify)
+		}()
+	})
+	go srv.ServeUDP(conn)
+	return sip.Uri{Scheme: "sip", User: "2001", Host: "127.0.0.1", Port: port}, subscribes
+}
+
+func TestSubscription_ReceivesNotifyAndUnsubscribesInDialog(t *testing.T) {
+	recipient, subscribes := startTestNotifier(t, EventPackageMWI, "application/simple-message-summary", "Messages-Waiting: yes\r\nVoice-Message: 1/0\r\n")
+
+	subscriber, err := newEventSubscriber("1001", "127.0.0.1", EventPackageMWI)
+	if err != nil {
+		t.Fatalf("newEventSubscriber failed: %v", err)
+	}
+	sub := newSubscription(subscriber, EventPackageMWI, "2001", recipient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	res, err := sub.send(ctx, 600)
+	if err != nil {
+		t.Fatalf("SUBSCRIBE failed: %v", err)
+	}
+	if got := grantedExpires(res, 600); got != 600 {
+		t.Errorf("expected requested expires when 2xx has none, got %d", got)
+	}
+
+	select {
+	case req := <-sub.notifies:
+		state, err := parseNotifyBody(EventPackageMWI, req.Body())
+		if err != nil || !state.matches("yes") {
+			t.Fatalf("expected messages-waiting yes, got %+v (err %v)", state, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("no NOTIFY received")
+	}
+
+	if err := sub.terminate(ctx); err != nil {
+		t.Fatalf("unsubscribe failed: %v", err)
+	}
+	first, second := <-subscribes, <-subscribes
+	if first.CallID().Value() != second.CallID().Value() {
+		t.Error("unsubscribe must reuse the subscription Call-ID")
+	}
+	if tag, _ := second.To().Params.Get("tag"); tag == "" {
+		t.Error("unsubscribe must carry the to-tag from the 2xx")
+	}
+	if second.CSeq().SeqNo != first.CSeq().SeqNo+1 {
+		t.Errorf("expected CSeq %d, got %d", first.CSeq().SeqNo+1, second.CSeq().SeqNo)
+	}
+	if header := second.GetHeader("Expires"); header == nil || header.Value() != "0" {
+		t.Errorf("unsubscribe must send Expires: 0, got %v", header)
+	}
+}
+
+func TestDryRun_BLFSubscription(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "watcher", Type: "sipInstance", Data: map[string]interface{}{"label": "Watcher", "dn": "100"}},
+		{ID: "agent", Type: "sipInstance", Data: map[string]interface{}{"label": "Agent", "dn": "2001"}},
+		{ID: "caller", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "300"}},
+
+		{ID: "subscribe", Type: "command", Data: map[string]interface{}{"sipInstanceId": "watcher", "command": "Subscribe", "subscribeTarget": "2001", "eventPackage": "dialog"}},
+		{ID: "subscribed", Type: "command", Data: map[string]interface{}{"sipInstanceId": "watcher", "command": "Signal", "signalName": "subscribed"}},
+		{ID: "busy", Type: "event", Data: map[string]interface{}{"sipInstanceId": "watcher", "event": "NOTIFY_RECEIVED", "subscribeTarget": "2001", "eventPackage": "dialog", "expectedState": "confirmed"}},
+		{ID: "idle", Type: "event", Data: map[string]interface{}{"sipInstanceId": "watcher", "event": "NOTIFY_RECEIVED", "subscribeTarget": "2001", "eventPackage": "dialog", "expectedState": "terminated"}},
+		{ID: "unsubscribe", Type: "command", Data: map[string]interface{}{"sipInstanceId": "watcher", "command": "Unsubscribe", "subscribeTarget": "2001", "eventPackage": "dialog"}},
+
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "agent", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "agent", "command": "Answer"}},
+		{ID: "disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "agent", "event": "DISCONNECTED"}},
+
+		{ID: "wait-subscribed", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "WaitSignal", "signalName": "subscribed"}},
+		{ID: "call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "MakeCall", "targetUri": "2001"}},
+		{ID: "hold-line", Type: "event", Data: map[string]interface{}{"sipInstanceId": "caller", "event": "TIMEOUT", "timeout": 100}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "caller", "command": "Release"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "watcher", Target: "subscribe"},
+		{ID: "e2", Source: "subscribe", Target: "subscribed"},
+		{ID: "e3", Source: "subscribed", Target: "busy"},
+		{ID: "e4", Source: "busy", Target: "idle"},
+		{ID: "e5", Source: "idle", Target: "unsubscribe"},
+		{ID: "e6", Source: "agent", Target: "incoming"},
+		{ID: "e7", Source: "incoming", Target: "answer"},
+		{ID: "e8", Source: "answer", Target: "disconnected"},
+		{ID: "e9", Source: "caller", Target: "wait-subscribed"},
+		{ID: "e10", Source: "wait-subscribed", Target: "call"},
+		{ID: "e11", Source: "call", Target: "hold-line"},
+		{ID: "e12", Source: "hold-line", Target: "release"},
+	}
+	te := runPBXFeatureDryRun(t, nodes, edges)
+	for _, id := range []string{"busy", "idle", "unsubscribe"} {
+		if !waitForNodeState(t, te, id, NodeStateCompleted, time.Second) {
+			t.Errorf("expected node %s to complete", id)
+		}
+	}
+}
+
+func TestParseScenario_SubscriptionFields(t *testing.T) {
+	nodes := []FlowNode{
+		{ID: "inst", Type: "sipInstance", Data: map[string]interface{}{"label": "Phone", "dn": "100"}},
+		{ID: "subscribe", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst", "command": "Subscribe", "subscribeTarget": "2001", "eventPackage": "message-summary"}},
+		{ID: "notify", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst", "event": "NOTIFY_RECEIVED", "subscribeTarget": "2001", "eventPackage": "message-summary", "expectedState": "confirmed"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst", Target: "subscribe"},
+		{ID: "e2", Source: "subscribe", Target: "notify"},
+	}
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "unsupported expectedState") {
+		t.Errorf("expected unsupported expectedState error, got %v", err)
+	}
+	if diags := ValidateScenario(flow); !hasCode(diags, "notify", DiagInvalidEventPackage) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidEventPackage, diags)
+	}
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sipflow/internal/scenario"
+)
+
+// suite 항목 상태 (RunStatus* 외 추가 상태)
+const (
+	SuiteEntrySkipped = "skipped"
+)
+
+// SuiteEntryResult는 suite 항목 하나의 실행 결과
+type SuiteEntryResult struct {
+	ScenarioID  string `json:"scenarioId"`
+	Setup       bool   `json:"setup,omitempty"` // suite 공통 setup 시나리오
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	NodeRetries int    `json:"nodeRetries"`     // 마지막 시도의 노드 재시도 횟수
+	Flaky       bool   `json:"flaky,omitempty"` // 재시도 끝에 통과 (실제 회귀와 구분)
+	RunID       string `json:"runId,omitempty"` // 마지막 시도의 run ID
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"durationMs"`
+}
+
+// SuiteReport는 suite 실행의 집계 보고서
+type SuiteReport struct {
+	SuiteID    string             `json:"suiteId"`
+	Name       string             `json:"name"`
+	Status     string             `json:"status"`
+	Passed     int                `json:"passed"`
+	Failed     int                `json:"failed"`
+	Skipped    int                `json:"skipped"`
+	Flaky      int                `json:"flaky"` // passed 중 재시도 끝에 통과한 항목 수
+	Entries    []SuiteEntryResult `json:"entries"`
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt time.Time          `json:"finishedAt"`
+}
+
+// Summary는 보고서를 한 줄로 요약한다 (스케줄 이력/알림용)
+func (r *SuiteReport) Summary() string {
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped", r.Passed, r.Failed, r.Skipped)
+	if r.Flaky > 0 {
+		summary += fmt.Sprintf(" (%d flaky)", r.Flaky)
+	}
+	return summary
+}
+
+// RunSuite는 suite의 setup 시나리오와 항목을 순서대로 실행하고 집계 보고서를 반환한다.
+// 항목마다 suite 공통 변수에 항목 override를 덮어써 바인딩하며, 실패한 항목은 Retries만큼 다시 실행한다.
+// setup이 실패하거나 StopOnFailure에서 항목이 실패하면 남은 항목은 skipped가 된다.
+// sim이 nil이 아니면 모든 항목을 dry-run으로 실행한다.
+func (e *Engine) RunSuite(ctx context.Context, suiteID string, sim *SimulationOptions) (*SuiteReport, error) {
+	suite, err := e.repo.LoadSuite(suiteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load suite: %w", err)
+	}
+
+	report := &SuiteReport{
+		SuiteID:   suite.ID,
+		Name:      suite.Name,
+		Entries:   []SuiteEntryResult{},
+		StartedAt: time.Now(),
+	}
+	e.emitActionLog("", "", fmt.Sprintf("Suite %s started (%d scenarios)", suite.Name, len(suite.Entries)), "info")
+
+	abort := false
+	if suite.SetupScenarioID != "" {
+		result := e.runSuiteEntry(ctx, suite, suite.SetupScenarioID, suite.Variables, sim)
+		result.Setup = true
+		report.add(result)
+		abort = result.Status != RunStatusCompleted
+	}
+
+	for _, entry := range suite.Entries {
+		if abort {
+			report.add(SuiteEntryResult{ScenarioID: entry.ScenarioID, Status: SuiteEntrySkipped})
+			continue
+		}
+
+		result := e.runSuiteEntry(ctx, suite, entry.ScenarioID, mergeVariables(suite.Variables, entry.Variables), sim)
+		report.add(result)
+		abort = ctx.Err() != nil || (suite.StopOnFailure && result.Status != RunStatusCompleted)
+	}
+
+	report.FinishedAt = time.Now()
+	switch {
+	case ctx.Err() != nil:
+		report.Status = RunStatusStopped
+	case report.Failed > 0 || report.Skipped > 0:
+		report.Status = RunStatusFailed
+	default:
+		report.Status = RunStatusCompleted
+	}
+
+	level := "info"
+	if report.Status != RunStatusCompleted {
+		level = "error"
+	}
+	e.emitActionLog("", "", fmt.Sprintf("Suite %s %s: %s", suite.Name, report.Status, report.Summary()), level)
+	return report, nil
+}
+
+// runSuiteEntry는 항목 하나를 재시도 정책에 따라 실행한다
+func (e *Engine) runSuiteEntry(ctx context.Context, suite *scenario.Suite, scenarioID string, variables map[string]interface{}, sim *SimulationOptions) SuiteEntryResult {
+	result := SuiteEntryResult{ScenarioID: scenarioID}
+	started := time.Now()
+
+	for attempt := 0; attempt <= suite.Retries; attempt++ {
+		if ctx.Err() != nil {
+			break
+		}
+		result.Attempts++
+
+		runCtx, cancel := ctx, context.CancelFunc(func() {})
+		if suite.TimeoutSeconds > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, time.Duration(suite.TimeoutSeconds)*time.Second)
+		}
+		run, err := e.runScenario(runCtx, scenarioID, runOptions{sim: sim, variables: variables})
+		timedOut := errors.Is(runCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		switch {
+		case err != nil:
+			result.Status = RunStatusFailed
+			result.RunID = ""
+			result.Error = err.Error()
+		case timedOut && run.Status == RunStatusStopped:
+			result.Status = RunStatusFailed
+			result.RunID = run.RunID
+			result.Error = fmt.Sprintf("timed out after %ds", suite.TimeoutSeconds)
+		default:
+			result.Status = run.Status
+			result.RunID = run.RunID
+			result.Error = run.Error
+			result.NodeRetries = run.NodeRetries
+		}
+
+		if result.Status != RunStatusFailed {
+			break
+		}
+	}
+
+	if result.Attempts == 0 {
+		result.Status = SuiteEntrySkipped
+	}
+	result.Flaky = result.Status == RunStatusCompleted && (result.Attempts > 1 || result.NodeRetries > 0)
+	result.DurationMs = time.Since(started).Milliseconds()
+	return result
+}
+
+func (r *SuiteReport) add(result SuiteEntryResult) {
+	r.Entries = append(r.Entries, result)
+	switch result.Status {
+	case RunStatusCompleted:
+		r.Passed++
+		if result.Flaky {
+			r.Flaky++
+		}
+	case SuiteEntrySkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+}
+
+// mergeVariables는 suite 공통 변수에 항목 override를 덮어쓴 새 맵을 반환한다
+func mergeVariables(shared, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(shared)+len(overrides))
+	for k, v := range shared {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"sipflow/internal/scenario"
+)
+
+func TestRunSuite_OverridesRetriesAndStopOnFailure(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+
+	// the callee only passes when ${target} routes the call to its own DN
+	nodes := []FlowNode{
+		{ID: "inst-a", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "inst-b", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200"}},
+		{ID: "make-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "MakeCall", "targetUri": "${target}"}},
+		{ID: "release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "Release"}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "INCOMING", "timeout": 300}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-b", "command": "Answer"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "inst-a", Target: "make-call"},
+		{ID: "e2", Source: "make-call", Target: "release"},
+		{ID: "e3", Source: "inst-b", Target: "incoming"},
+		{ID: "e4", Source: "incoming", Target: "answer"},
+	}
+	callScenario := saveDryRunScenario(t, repo, nodes, edges)
+
+	suite := &scenario.Suite{
+		ProjectID:       "default",
+		Name:            "regression",
+		SetupScenarioID: callScenario,
+		Variables:       map[string]interface{}{"target": "200"},
+		StopOnFailure:   true,
+		Retries:         1,
+		Entries: []scenario.SuiteEntry{
+			{ScenarioID: callScenario},
+			{ScenarioID: callScenario, Variables: map[string]interface{}{"target": "sip:999@pbx.example.com"}},
+			{ScenarioID: callScenario},
+		},
+	}
+	if err := repo.CreateSuite(suite); err != nil {
+		t.Fatalf("CreateSuite failed: %v", err)
+	}
+
+	report, err := eng.RunSuite(context.Background(), suite.ID, &SimulationOptions{})
+	if err != nil {
+		t.Fatalf("RunSuite failed: %v", err)
+	}
+
+	if report.Status != RunStatusFailed || report.Passed != 2 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected report totals: %s (%s)", report.Summary(), report.Status)
+	}
+	if !report.Entries[0].Setup || report.Entries[0].Status != RunStatusCompleted {
+		t.Errorf("expected setup to pass first, got %+v", report.Entries[0])
+	}
+	failed := report.Entries[2]
+	if failed.Status != RunStatusFailed || failed.Attempts != 2 || failed.Error == "" || failed.RunID == "" {
+		t.Errorf("expected overridden entry to fail after a retry, got %+v", failed)
+	}
+	if report.Entries[3].Status != SuiteEntrySkipped || report.Entries[3].Attempts != 0 {
+		t.Errorf("expected entry after failure to be skipped, got %+v", report.Entries[3])
+	}
+}
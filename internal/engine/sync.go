@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SyncRegistry는 인스턴스 체인 간 named signal과 barrier를 관리한다.
+// 시나리오 실행마다 새로 생성되며, SIP 트래픽과 무관하게 엔진 내부에서만 동작한다.
+type SyncRegistry struct {
+	mu       sync.Mutex
+	signals  map[string]chan struct{} // signal 이름 -> 발행 시 close되는 채널
+	barriers map[string]*barrierState // barrier 이름 -> 현재 세대 상태
+}
+
+// barrierState는 한 세대(generation)의 barrier 도착 상태
+type barrierState struct {
+	parties int
+	arrived int
+	release chan struct{}
+}
+
+// NewSyncRegistry는 새로운 SyncRegistry를 생성한다
+func NewSyncRegistry() *SyncRegistry {
+	return &SyncRegistry{
+		signals:  make(map[string]chan struct{}),
+		barriers: make(map[string]*barrierState),
+	}
+}
+
+func (sr *SyncRegistry) signalChLocked(name string) chan struct{} {
+	ch, exists := sr.signals[name]
+	if !exists {
+		ch = make(chan struct{})
+		sr.signals[name] = ch
+	}
+	return ch
+}
+
+// Raise는 named signal을 발행한다. 한 번 발행된 signal은 실행이 끝날 때까지 유지되므로
+// 발행 이후에 대기를 시작한 WaitSignal도 즉시 통과한다. 이미 발행된 경우 false를 반환한다.
+func (sr *SyncRegistry) Raise(name string) bool {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	ch := sr.signalChLocked(name)
+	select {
+	case <-ch:
+		return false
+	default:
+		close(ch)
+		return true
+	}
+}
+
+// IsRaised는 named signal이 이미 발행되었는지 확인한다
+func (sr *SyncRegistry) IsRaised(name string) bool {
+	sr.mu.Lock()
+	ch, exists := sr.signals[name]
+	sr.mu.Unlock()
+	if !exists {
+		return false
+	}
+
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// RaisedSignals는 발행된 signal 이름을 정렬하여 반환한다
+func (sr *SyncRegistry) RaisedSignals() []string {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	names := []string{}
+	for name, ch := range sr.signals {
+		select {
+		case <-ch:
+			names = append(names, name)
+		default:
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WaitSignal은 named signal이 발행될 때까지 블로킹 대기한다
+func (sr *SyncRegistry) WaitSignal(ctx context.Context, name string) error {
+	sr.mu.Lock()
+	ch := sr.signalChLocked(name)
+	sr.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Arrive는 barrier에 도착을 기록하고 parties 수만큼 체인이 도착할 때까지 대기한다.
+// 대기 중 ctx가 종료되면 도착 기록을 철회하여 이후 세대에 영향을 주지 않는다.
+func (sr *SyncRegistry) Arrive(ctx context.Context, name string, parties int) error {
+	if parties <= 0 {
+		return fmt.Errorf("barrier %q requires at least one party", name)
+	}
+
+	sr.mu.Lock()
+	state, exists := sr.barriers[name]
+	if !exists {
+		state = &barrierState{parties: parties, release: make(chan struct{})}
+		sr.barriers[name] = state
+	}
+	if state.parties != parties {
+		sr.mu.Unlock()
+		return fmt.Errorf("barrier %q expects %d parties, got %d", name, state.parties, parties)
+	}
+
+	state.arrived++
+	if state.arrived >= state.parties {
+		// 마지막 도착 — 현재 세대를 해제하고 다음 세대를 위해 상태 제거
+		close(state.release)
+		delete(sr.barriers, name)
+		sr.mu.Unlock()
+		return nil
+	}
+	release := state.release
+	sr.mu.Unlock()
+
+	select {
+	case <-release:
+		return nil
+	case <-ctx.Done():
+		sr.mu.Lock()
+		select {
+		case <-release:
+			// 취소와 해제가 경합한 경우 해제를 우선한다
+			sr.mu.Unlock()
+			return nil
+		default:
+		}
+		if current, ok := sr.barriers[name]; ok && current == state {
+			state.arrived--
+		}
+		sr.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// executeSignal은 Signal 커맨드를 실행한다 — named signal을 발행하고 즉시 다음 노드로 진행한다
+func (ex *Executor) executeSignal(ctx context.Context, instanceID string, node *GraphNode) error {
+	if node.SignalName == "" {
+		return fmt.Errorf("Signal requires signalName")
+	}
+
+	if ex.syncs.Raise(node.SignalName) {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Signal raised: %s", node.SignalName), "info")
+	} else {
+		ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Signal already raised: %s", node.SignalName), "warn")
+	}
+	return nil
+}
+
+// executeWaitSignal은 WaitSignal 이벤트를 실행한다 — 다른 체인이 named signal을 발행할 때까지 대기한다
+func (ex *Executor) executeWaitSignal(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	if node.SignalName == "" {
+		return fmt.Errorf("WaitSignal requires signalName")
+	}
+
+	if err := ex.syncs.WaitSignal(ctx, node.SignalName); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("WaitSignal %q timeout after %v", node.SignalName, timeout)
+		}
+		return err
+	}
+
+	ex.emitNodeActionLog(node, instanceID, fmt.Sprintf("Signal received: %s", node.SignalName), "info")
+	return nil
+}
+
+// executeBarrier는 Barrier 이벤트를 실행한다 — 지정된 수의 체인이 같은 barrier에 도착할 때까지 대기한다
+func (ex *Executor) executeBarrier(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	if node.BarrierName == "" {
+		return fmt.Errorf("Barrier requires barrierName")
+	}
+
+	ex.emitNodeActionLog(node, instanceID,
+		fmt.Sprintf("Barrier %s: waiting for %d chains", node.BarrierName, node.BarrierParties), "info")
+
+	if err := ex.syncs.Arrive(ctx, node.BarrierName, node.BarrierParties); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("Barrier %q timeout after %v (%d chains required)", node.BarrierName, timeout, node.BarrierParties)
+		}
+		return err
+	}
+
+	ex.emitNodeActionLog(node, instanceID,
+		fmt.Sprintf("Barrier %s released (%d chains)", node.BarrierName, node.BarrierParties), "info")
+	return nil
+}
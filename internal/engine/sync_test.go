@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncRegistry_RaiseBeforeWait(t *testing.T) {
+	sr := NewSyncRegistry()
+
+	if !sr.Raise("ua3-ready") {
+		t.Fatal("expected first Raise to report a new signal")
+	}
+	if sr.Raise("ua3-ready") {
+		t.Fatal("expected second Raise to report already raised")
+	}
+	if !sr.IsRaised("ua3-ready") {
+		t.Fatal("expected signal to stay raised")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := sr.WaitSignal(ctx, "ua3-ready"); err != nil {
+		t.Fatalf("expected latched signal to pass immediately, got %v", err)
+	}
+}
+
+func TestSyncRegistry_WaitThenRaise(t *testing.T) {
+	sr := NewSyncRegistry()
+
+	resultCh := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resultCh <- sr.WaitSignal(ctx, "go")
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	sr.Raise("go")
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released by Raise")
+	}
+}
+
+func TestSyncRegistry_BarrierReleasesAllParties(t *testing.T) {
+	sr := NewSyncRegistry()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			errs <- sr.Arrive(ctx, "all-idle", 3)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected barrier release, got %v", err)
+		}
+	}
+}
+
+func TestSyncRegistry_BarrierTimeoutWithdrawsArrival(t *testing.T) {
+	sr := NewSyncRegistry()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sr.Arrive(ctx, "pair", 2); err == nil {
+		t.Fatal("expected timeout with a single party")
+	}
+
+	// 철회된 도착은 다음 세대에 포함되지 않아야 한다
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer shortCancel()
+	if err := sr.Arrive(shortCtx, "pair", 2); err == nil {
+		t.Fatal("expected withdrawn arrival not to release the barrier")
+	}
+}
+
+func TestSyncRegistry_BarrierPartiesMismatch(t *testing.T) {
+	sr := NewSyncRegistry()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_ = sr.Arrive(ctx, "mixed", 3)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	err := sr.Arrive(context.Background(), "mixed", 2)
+	if err == nil || !strings.Contains(err.Error(), "expects 3 parties") {
+		t.Fatalf("expected parties mismatch error, got %v", err)
+	}
+}
+
+func TestExecuteEvent_WaitSignalTimeout(t *testing.T) {
+	ex, _ := newTestExecutor(t)
+	node := &GraphNode{
+		ID:         "wait-1",
+		Type:       "event",
+		Event:      SyncEventWaitSignal,
+		SignalName: "never",
+		Timeout:    50 * time.Millisecond,
+	}
+
+	err := ex.executeEvent(context.Background(), "inst-1", node)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected WaitSignal timeout, got %v", err)
+	}
+}
+
+func TestExecuteCommand_SignalReleasesWaitSignal(t *testing.T) {
+	ex, te := newTestExecutor(t)
+	waitNode := &GraphNode{
+		ID:         "wait-1",
+		Type:       "event",
+		Event:      SyncEventWaitSignal,
+		SignalName: "ua3-registered",
+		Timeout:    time.Second,
+	}
+	signalNode := &GraphNode{
+		ID:         "signal-1",
+		Type:       "command",
+		Command:    SyncCommandSignal,
+		SignalName: "ua3-registered",
+	}
+
+	resultCh := make(chan error, 1)
+	go func() {
+		resultCh <- ex.executeEvent(context.Background(), "inst-2", waitNode)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if err := ex.executeCommand(context.Background(), "inst-3", signalNode); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			t.Fatalf("expected WaitSignal to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitSignal was not released")
+	}
+
+	found := false
+	for _, event := range te.GetEventsByName(EventActionLog) {
+		if event.Data["message"] == "Signal received: ua3-registered" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected 'Signal received' action log")
+	}
+}
+
+func TestExecuteEvent_BarrierTwoChains(t *testing.T) {
+	ex, _ := newTestExecutor(t)
+	newBarrierNode := func(id string) *GraphNode {
+		return &GraphNode{
+			ID:             id,
+			Type:           "event",
+			Event:          SyncEventBarrier,
+			BarrierName:    "both-idle",
+			BarrierParties: 2,
+			Timeout:        time.Second,
+		}
+	}
+
+	resultCh := make(chan error, 2)
+	go func() { resultCh <- ex.executeEvent(context.Background(), "inst-1", newBarrierNode("barrier-1")) }()
+	go func() { resultCh <- ex.executeEvent(context.Background(), "inst-2", newBarrierNode("barrier-2")) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-resultCh:
+			if err != nil {
+				t.Fatalf("expected barrier release, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("barrier was not released")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/sipgo/sip"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// REFER_RECEIVED 노드의 응답 방식 (referAction)
+const (
+	ReferActionAccept = "accept" // 202 Accepted 후 Refer-To로 전환
+	ReferActionReject = "reject" // 603 Decline, 전환하지 않음
+)
+
+// errReferDeclined는 OnRefer 콜백이 전환을 거절할 때 반환한다 (diago가 transferor에 실패를 알린다)
+var errReferDeclined = errors.New("REFER declined")
+
+// setReferAction은 instanceID/callID dialog가 받을 REFER의 응답 방식을 등록하고 해제 함수를 반환한다
+func (ex *Executor) setReferAction(instanceID, callID, action string) func() {
+	key := sessionKey(instanceID, callID)
+	ex.referMu.Lock()
+	ex.referActions[key] = action
+	ex.referMu.Unlock()
+	return func() {
+		ex.referMu.Lock()
+		delete(ex.referActions, key)
+		ex.referMu.Unlock()
+	}
+}
+
+// referActionFor는 등록된 REFER 응답 방식을 반환한다. 대기 중인 REFER_RECEIVED 노드가 없으면 수락한다.
+func (ex *Executor) referActionFor(instanceID, callID string) string {
+	ex.referMu.Lock()
+	defer ex.referMu.Unlock()
+	if action, exists := ex.referActions[sessionKey(instanceID, callID)]; exists {
+		return action
+	}
+	return ReferActionAccept
+}
+
+func (ex *Executor) notifyReferReceived(instanceID, callID, referTo string, statusCode int) {
+	sipCallID, exists := ex.sessions.GetSIPCallID(instanceID, callID)
+	if !exists {
+		return
+	}
+	ex.sessions.notifySIPEvent(eventhandler.Event{
+		Type:          eventhandler.SIPEventReferReceived,
+		SIPCallID:     sipCallID,
+		InstanceID:    instanceID,
+		LogicalCallID: callID,
+		StatusCode:    statusCode,
+		Detail:        referTo,
+	})
+}
+
+// executeReferReceived는 callID dialog로 REFER가 올 때까지 대기하고, 노드 설정에 따라 수락(202) 또는 거절(603)한다
+func (ex *Executor) executeReferReceived(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	action := node.ReferAction
+	if action == "" {
+		action = ReferActionAccept
+	}
+	defer ex.setReferAction(instanceID, callID, action)()
+
+	return ex.waitSIPEvent(ctx, instanceID, callID, eventhandler.SIPEventReferReceived, timeout, func(event eventhandler.Event) {
+		verdict := "accepted (202)"
+		if event.StatusCode >= 300 {
+			verdict = fmt.Sprintf("declined (%d)", event.StatusCode)
+		}
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("REFER_RECEIVED: Refer-To %s %s (callID: %s)", event.Detail, verdict, callID), "info",
+			WithSIPMessage("received", "REFER", event.StatusCode, event.SIPCallID, "", event.Detail))
+	})
+}
+
+// executeReplaced는 callID dialog가 INVITE with Replaces로 대체될 때까지 대기한다
+func (ex *Executor) executeReplaced(ctx context.Context, instanceID string, node *GraphNode, timeout time.Duration) error {
+	callID := callIDOrDefault(node)
+	return ex.waitSIPEvent(ctx, instanceID, callID, eventhandler.SIPEventReplaced, timeout, func(event eventhandler.Event) {
+		ex.emitNodeActionLog(node, instanceID,
+			fmt.Sprintf("REPLACED: Call-ID %s replaced by Call-ID %s (callID: %s)", event.SIPCallID, event.Detail, callID), "info",
+			WithSIPMessage("received", "INVITE", 200, event.Detail, "", ""))
+	})
+}
+
+// inviteReplacesCallID는 수신 INVITE의 Replaces 헤더가 가리키는 SIP Call-ID를 반환한다 (없으면 빈 문자열)
+func inviteReplacesCallID(dialog diago.DialogSession) string {
+	if dialog == nil || dialog.DialogSIP() == nil || dialog.DialogSIP().InviteRequest == nil {
+		return ""
+	}
+	header := dialog.DialogSIP().InviteRequest.GetHeader("Replaces")
+	if header == nil {
+		return ""
+	}
+	callID, _, _ := strings.Cut(header.Value(), ";")
+	return strings.TrimSpace(callID)
+}
+
+// replacingDialog는 Replaces INVITE로 들어온 수신 dialog (*diago.DialogServerSession)
+type replacingDialog interface {
+	diago.DialogSession
+	AnswerOptions(opt diago.AnswerOptions) error
+	Respond(statusCode int, reason string, body []byte, headers ...sip.Header) error
+	FromUser() string
+}
+
+// handleReplacesInvite는 transfer target 쪽에서 INVITE with Replaces를 처리한다 (RFC 3891).
+// 대체 대상 dialog를 찾으면 새 dialog에 응답해 같은 callID로 교체하고 기존 dialog에 BYE를 보낸 뒤 REPLACED를 발행한다.
+// 대상이 없으면 481로 거절한다.
+func (ex *Executor) handleReplacesInvite(instanceID string, inDialog replacingDialog) {
+	replacedSIPCallID := inviteReplacesCallID(inDialog)
+	callID, found := ex.sessions.FindCallID(instanceID, replacedSIPCallID)
+	var replaced diago.DialogSession
+	if found {
+		replaced, found = ex.sessions.GetDialog(instanceID, callID)
+	}
+	if !found || replaced.Context().Err() != nil {
+		_ = inDialog.Respond(sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil)
+		ex.emitNodeActionLog(nil, instanceID,
+			fmt.Sprintf("INVITE with Replaces rejected (481): no active dialog with Call-ID %s", replacedSIPCallID), "warn")
+		return
+	}
+
+	if err := inDialog.AnswerOptions(ex.answerOptions(instanceID, callID, nil)); err != nil {
+		ex.emitNodeActionLog(nil, instanceID,
+			fmt.Sprintf("INVITE with Replaces: Answer failed: %v", err), "error", WithCallID(callID))
+		return
+	}
+	ex.sessions.StoreDialog(instanceID, callID, inDialog)
+	newSIPCallID := dialogSIPCallID(inDialog)
+
+	hangupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := replaced.Hangup(hangupCtx); err != nil {
+		ex.emitNodeActionLog(nil, instanceID,
+			fmt.Sprintf("Replaced dialog BYE warning: %v", err), "warn", WithCallID(callID))
+	}
+	cancel()
+
+	ex.emitNodeActionLog(nil, instanceID,
+		fmt.Sprintf("Dialog replaced by INVITE with Replaces from %s (Call-ID %s -> %s)", inDialog.FromUser(), replacedSIPCallID, newSIPCallID), "info",
+		WithCallID(callID), WithSIPMessage("received", "INVITE", 200, newSIPCallID, inDialog.FromUser(), ""))
+	ex.sessions.notifySIPEvent(eventhandler.Event{
+		Type:          eventhandler.SIPEventReplaced,
+		SIPCallID:     replacedSIPCallID,
+		InstanceID:    instanceID,
+		LogicalCallID: callID,
+		StatusCode:    200,
+		Detail:        newSIPCallID,
+	})
+}
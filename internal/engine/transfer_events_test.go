@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiago/diago"
+	"github.com/emiago/sipgo/sip"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// fakeReplacingDialog는 INVITE with Replaces로 들어온 수신 dialog를 흉내낸다
+type fakeReplacingDialog struct {
+	*fakeTransferDialog
+	answered  int
+	responded []int
+}
+
+func (d *fakeReplacingDialog) AnswerOptions(opt diago.AnswerOptions) error {
+	d.answered++
+	return nil
+}
+
+func (d *fakeReplacingDialog) Respond(statusCode int, reason string, body []byte, headers ...sip.Header) error {
+	d.responded = append(d.responded, statusCode)
+	return nil
+}
+
+func (d *fakeReplacingDialog) FromUser() string { return "200" }
+
+func newFakeReplacingDialog(sipCallID, replaces string) *fakeReplacingDialog {
+	d := &fakeReplacingDialog{fakeTransferDialog: newFakeTransferDialogWithCallID(sipCallID)}
+	d.dialogSIP.InviteRequest.AppendHeader(sip.NewHeader("Replaces", replaces))
+	return d
+}
+
+// waitForListener는 SIP Call-ID dispatcher에 대기 노드가 구독할 때까지 기다린다
+func waitForListener(t *testing.T, ss *SessionStore, sipCallID string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		ss.mu.RLock()
+		dispatcher, exists := ss.dispatchers[sipCallID]
+		listening := exists && dispatcher.ListenerCount() > 0
+		ss.mu.RUnlock()
+		if listening {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no listener subscribed to %s", sipCallID)
+}
+
+func TestReferReceived_RejectDeclinesTransfer(t *testing.T) {
+	ex, emitter := newTestExecutor(t)
+	original := newFakeTransferDialogWithCallID("sip-primary")
+	ex.sessions.StoreDialog("inst-1", "primary", original)
+
+	node := &GraphNode{ID: "refer-node", Type: "event", Event: string(eventhandler.SIPEventReferReceived), CallID: "primary", ReferAction: ReferActionReject}
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- ex.executeReferReceived(t.Context(), "inst-1", node, 2*time.Second)
+	}()
+	waitForListener(t, ex.sessions, "sip-primary")
+
+	referDialog := newFakeTransferDialogWithCallID("sip-referred")
+	err := ex.handleAnswerRefer("inst-1", "primary", nil, referDialog)
+	if !errors.Is(err, errReferDeclined) {
+		t.Fatalf("expected REFER to be declined, got %v", err)
+	}
+	if referDialog.inviteCalled != 0 {
+		t.Errorf("declined REFER must not INVITE the Refer-To target")
+	}
+	if stored, _ := ex.sessions.GetDialog("inst-1", "primary"); stored != original {
+		t.Error("declined REFER must keep the original dialog")
+	}
+	if err := <-waitErr; err != nil {
+		t.Fatalf("REFER_RECEIVED failed: %v", err)
+	}
+
+	found := false
+	for _, log := range emitter.GetEventsByName(EventActionLog) {
+		if log.Data["nodeId"] == "refer-node" && strings.Contains(log.Data["message"].(string), "declined (603)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected REFER_RECEIVED to log the 603 decision")
+	}
+	if got := ex.referActionFor("inst-1", "primary"); got != ReferActionAccept {
+		t.Errorf("reject policy should be cleared after the node completes, got %s", got)
+	}
+}
+
+func TestHandleReplacesInvite_ReplacesDialogAndEmitsReplaced(t *testing.T) {
+	ex, _ := newTestExecutor(t)
+	consult := newFakeTransferDialogWithCallID("sip-consult")
+	ex.sessions.StoreDialog("target", "consult", consult)
+
+	node := &GraphNode{ID: "replaced-node", Type: "event", Event: string(eventhandler.SIPEventReplaced), CallID: "consult"}
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- ex.executeReplaced(t.Context(), "target", node, 2*time.Second)
+	}()
+	waitForListener(t, ex.sessions, "sip-consult")
+
+	inDialog := newFakeReplacingDialog("sip-transferee", "sip-consult;to-tag=to-tag;from-tag=from-tag")
+	ex.handleReplacesInvite("target", inDialog)
+
+	if err := <-waitErr; err != nil {
+		t.Fatalf("REPLACED failed: %v", err)
+	}
+	if inDialog.answered != 1 || consult.hangupCalled != 1 {
+		t.Errorf("expected the new dialog answered and the replaced one hung up, got answered=%d hangup=%d", inDialog.answered, consult.hangupCalled)
+	}
+	if stored, _ := ex.sessions.GetDialog("target", "consult"); stored != inDialog {
+		t.Error("expected the Replaces dialog to take over callID consult")
+	}
+	if sipCallID, _ := ex.sessions.GetSIPCallID("target", "consult"); sipCallID != "sip-transferee" {
+		t.Errorf("expected consult to map to the new SIP Call-ID, got %s", sipCallID)
+	}
+
+	unknown := newFakeReplacingDialog("sip-other", "sip-missing;to-tag=a;from-tag=b")
+	ex.handleReplacesInvite("target", unknown)
+	if unknown.answered != 0 || len(unknown.responded) != 1 || unknown.responded[0] != 481 {
+		t.Errorf("expected 481 for an unknown Replaces Call-ID, got answered=%d responses=%v", unknown.answered, unknown.responded)
+	}
+}
+
+func attendedTransferDryRunFlow(referAction string) ([]FlowNode, []FlowEdge) {
+	nodes := []FlowNode{
+		{ID: "transferor", Type: "sipInstance", Data: map[string]interface{}{"label": "Transferor", "dn": "100"}},
+		{ID: "transferee", Type: "sipInstance", Data: map[string]interface{}{"label": "Transferee", "dn": "200"}},
+		{ID: "target", Type: "sipInstance", Data: map[string]interface{}{"label": "Target", "dn": "300"}},
+
+		{ID: "t-incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "transferor", "event": "INCOMING"}},
+		{ID: "t-answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "transferor", "command": "Answer"}},
+		{ID: "t-consult", Type: "command", Data: map[string]interface{}{"sipInstanceId": "transferor", "command": "MakeCall", "targetUri": "300", "callId": "consult"}},
+		{ID: "t-transfer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "transferor", "command": "MuteTransfer", "consultCallId": "consult"}},
+
+		{ID: "e-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "transferee", "command": "MakeCall", "targetUri": "100"}},
+		{ID: "e-refer", Type: "event", Data: map[string]interface{}{"sipInstanceId": "transferee", "event": "REFER_RECEIVED", "referAction": referAction}},
+		{ID: "e-transferred", Type: "event", Data: map[string]interface{}{"sipInstanceId": "transferee", "event": "TRANSFERRED"}},
+		{ID: "e-release", Type: "command", Data: map[string]interface{}{"sipInstanceId": "transferee", "command": "Release"}},
+
+		{ID: "g-incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "target", "event": "INCOMING", "callId": "consult"}},
+		{ID: "g-answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "target", "command": "Answer", "callId": "consult"}},
+		{ID: "g-replaced", Type: "event", Data: map[string]interface{}{"sipInstanceId": "target", "event": "REPLACED", "callId": "consult"}},
+		{ID: "g-disconnected", Type: "event", Data: map[string]interface{}{"sipInstanceId": "target", "event": "DISCONNECTED", "callId": "consult"}},
+	}
+	edges := []FlowEdge{
+		{ID: "e1", Source: "transferor", Target: "t-incoming"},
+		{ID: "e2", Source: "t-incoming", Target: "t-answer"},
+		{ID: "e3", Source: "t-answer", Target: "t-consult"},
+		{ID: "e4", Source: "t-consult", Target: "t-transfer"},
+		{ID: "e5", Source: "transferee", Target: "e-call"},
+		{ID: "e6", Source: "e-call", Target: "e-refer"},
+		{ID: "e7", Source: "e-refer", Target: "e-transferred"},
+		{ID: "e8", Source: "e-transferred", Target: "e-release"},
+		{ID: "e9", Source: "target", Target: "g-incoming"},
+		{ID: "e10", Source: "g-incoming", Target: "g-answer"},
+		{ID: "e11", Source: "g-answer", Target: "g-replaced"},
+		{ID: "e12", Source: "g-replaced", Target: "g-disconnected"},
+	}
+	return nodes, edges
+}
+
+func TestDryRun_AttendedTransferReferAndReplaced(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := attendedTransferDryRunFlow(ReferActionAccept)
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForEvent(t, te, EventCompleted, 5*time.Second) {
+		t.Fatalf("scenario did not complete, failed events: %v", te.GetEventsByName(EventFailed))
+	}
+	for _, id := range []string{"e-refer", "g-replaced"} {
+		if !waitForNodeState(t, te, id, NodeStateCompleted, time.Second) {
+			t.Errorf("expected node %s to complete", id)
+		}
+	}
+}
+
+func TestDryRun_RejectedReferFailsTransfer(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+	nodes, edges := attendedTransferDryRunFlow(ReferActionReject)
+	scenarioID := saveDryRunScenario(t, repo, nodes, edges)
+
+	if _, err := eng.StartScenarioDryRun(scenarioID, SimulationOptions{Latency: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("StartScenarioDryRun failed: %v", err)
+	}
+	if !waitForNodeState(t, te, "t-transfer", NodeStateFailed, 5*time.Second) {
+		t.Fatal("expected MuteTransfer to fail when the transferee rejects the REFER")
+	}
+	if !waitForNodeState(t, te, "e-refer", NodeStateCompleted, time.Second) {
+		t.Error("expected REFER_RECEIVED to complete after declining")
+	}
+}
+
+func TestParseScenario_ReferAction(t *testing.T) {
+	nodes, edges := attendedTransferDryRunFlow("forward")
+	flow := buildTestFlowData(t, nodes, edges)
+	if _, err := ParseScenario(flow); err == nil || !strings.Contains(err.Error(), "unsupported referAction") {
+		t.Errorf("expected unsupported referAction error, got %v", err)
+	}
+	if diags := ValidateScenario(flow); !hasCode(diags, "e-refer", DiagInvalidReferAction) {
+		t.Errorf("expected %s diagnostic, got %+v", DiagInvalidReferAction, diags)
+	}
+}
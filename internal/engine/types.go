@@ -14,8 +14,31 @@ const (
 	SIPCommandRetrieve      SIPCommandType = "Retrieve"
 	SIPCommandBlindTransfer SIPCommandType = "BlindTransfer"
 	SIPCommandMuteTransfer  SIPCommandType = "MuteTransfer"
+	SIPCommandBridge        SIPCommandType = "Bridge"
+	SIPCommandUnbridge      SIPCommandType = "Unbridge"
+	SIPCommandConference    SIPCommandType = "Conference"
+	SIPCommandPark          SIPCommandType = "Park"
+	SIPCommandUnpark        SIPCommandType = "Unpark"
+	SIPCommandPickup        SIPCommandType = "Pickup"
+	SIPCommandSetForwarding SIPCommandType = "SetForwarding"
+	SIPCommandSubscribe     SIPCommandType = "Subscribe"
+	SIPCommandUnsubscribe   SIPCommandType = "Unsubscribe"
+	SIPCommandSendMessage   SIPCommandType = "SendMessage"
+	SIPCommandSendOptions   SIPCommandType = "SendOptions"
+	SIPCommandReInvite      SIPCommandType = "ReInvite"
+	SIPCommandUpdate        SIPCommandType = "Update"
 )
 
+// 인스턴스 체인 간 동기화 노드 (SIP 트래픽 없이 엔진 내부에서 처리)
+const (
+	SyncCommandSignal   = "Signal"
+	SyncEventWaitSignal = "WaitSignal"
+	SyncEventBarrier    = "Barrier"
+)
+
+// CommandCallScenario는 저장된 시나리오/fragment를 호출 위치에 전개하는 커맨드
+const CommandCallScenario = "CallScenario"
+
 var supportedCommands = []string{
 	string(SIPCommandMakeCall),
 	string(SIPCommandAnswer),
@@ -26,6 +49,21 @@ var supportedCommands = []string{
 	string(SIPCommandRetrieve),
 	string(SIPCommandBlindTransfer),
 	string(SIPCommandMuteTransfer),
+	string(SIPCommandBridge),
+	string(SIPCommandUnbridge),
+	string(SIPCommandConference),
+	string(SIPCommandPark),
+	string(SIPCommandUnpark),
+	string(SIPCommandPickup),
+	string(SIPCommandSetForwarding),
+	string(SIPCommandSubscribe),
+	string(SIPCommandUnsubscribe),
+	string(SIPCommandSendMessage),
+	string(SIPCommandSendOptions),
+	string(SIPCommandReInvite),
+	string(SIPCommandUpdate),
+	SyncCommandSignal,
+	CommandCallScenario,
 }
 
 var supportedEvents = []string{
@@ -37,6 +75,16 @@ var supportedEvents = []string{
 	string(eventhandler.SIPEventHeld),
 	string(eventhandler.SIPEventRetrieved),
 	string(eventhandler.SIPEventTransferred),
+	string(eventhandler.SIPEventReferReceived),
+	string(eventhandler.SIPEventReplaced),
+	string(eventhandler.SIPEventRefreshFailed),
+	string(eventhandler.SIPEventReInvite),
+	string(eventhandler.SIPEventUpdate),
+	SyncEventWaitSignal,
+	SyncEventBarrier,
+	ServerEventRegisterReceived,
+	SubscriptionEventNotifyReceived,
+	MessageEventReceived,
 }
 
 func SupportedCommands() []string {
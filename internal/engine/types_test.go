@@ -19,6 +19,21 @@ func TestSupportedCommands(t *testing.T) {
 		string(SIPCommandRetrieve),
 		string(SIPCommandBlindTransfer),
 		string(SIPCommandMuteTransfer),
+		string(SIPCommandBridge),
+		string(SIPCommandUnbridge),
+		string(SIPCommandConference),
+		string(SIPCommandPark),
+		string(SIPCommandUnpark),
+		string(SIPCommandPickup),
+		string(SIPCommandSetForwarding),
+		string(SIPCommandSubscribe),
+		string(SIPCommandUnsubscribe),
+		string(SIPCommandSendMessage),
+		string(SIPCommandSendOptions),
+		string(SIPCommandReInvite),
+		string(SIPCommandUpdate),
+		SyncCommandSignal,
+		CommandCallScenario,
 	}
 
 	if len(commands) != len(expected) {
@@ -43,6 +58,16 @@ func TestSupportedEvents(t *testing.T) {
 		string(eventhandler.SIPEventHeld),
 		string(eventhandler.SIPEventRetrieved),
 		string(eventhandler.SIPEventTransferred),
+		string(eventhandler.SIPEventReferReceived),
+		string(eventhandler.SIPEventReplaced),
+		string(eventhandler.SIPEventRefreshFailed),
+		string(eventhandler.SIPEventReInvite),
+		string(eventhandler.SIPEventUpdate),
+		SyncEventWaitSignal,
+		SyncEventBarrier,
+		ServerEventRegisterReceived,
+		SubscriptionEventNotifyReceived,
+		MessageEventReceived,
 	}
 
 	if len(events) != len(expected) {
@@ -0,0 +1,503 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"sipflow/internal/pkg/eventhandler"
+)
+
+// DiagnosticSeverity는 검증 결과의 심각도
+type DiagnosticSeverity string
+
+const (
+	SeverityError   DiagnosticSeverity = "error"   // 실행 시 실패가 확실한 문제
+	SeverityWarning DiagnosticSeverity = "warning" // 실행은 가능하지만 의도와 다를 수 있는 문제
+)
+
+// 검증 진단 코드 — 프론트엔드에서 코드별로 메시지/하이라이트를 분기할 수 있도록 고정 문자열을 사용한다
+const (
+	DiagInvalidFlow           = "invalid_flow"
+	DiagNoInstances           = "no_instances"
+	DiagDuplicateDN           = "duplicate_dn"
+	DiagMissingInstance       = "missing_instance"
+	DiagUnknownInstance       = "unknown_instance"
+	DiagUnknownNumber         = "unknown_number"
+	DiagUnsupportedCommand    = "unsupported_command"
+	DiagUnsupportedEvent      = "unsupported_event"
+	DiagMissingField          = "missing_field"
+	DiagDanglingEdge          = "dangling_edge"
+	DiagUnreachableNode       = "unreachable_node"
+	DiagCycle                 = "cycle"
+	DiagAnswerWithoutIncoming = "answer_without_incoming"
+	DiagUnknownCallID         = "unknown_call_id"
+	DiagInvalidCallState      = "invalid_call_state"
+	DiagMissingWAV            = "missing_wav"
+	DiagInvalidDTMF           = "invalid_dtmf"
+	DiagInvalidRole           = "invalid_role"
+	DiagRequiresServer        = "requires_server"
+	DiagInvalidConference     = "invalid_conference_action"
+	DiagInvalidReferAction    = "invalid_refer_action"
+	DiagInvalidFeatureOption  = "invalid_feature_option"
+	DiagInvalidEventPackage   = "invalid_event_package"
+	DiagInvalidStatusCode     = "invalid_status_code"
+	DiagInvalidPattern        = "invalid_pattern"
+	DiagInvalidSessionTimer   = "invalid_session_timer"
+	DiagInvalidMediaUpdate    = "invalid_media_update"
+	DiagNoMaxDuration         = "no_max_duration"
+	DiagInvalidCallScenario   = "invalid_call_scenario"
+)
+
+// Diagnostic은 시나리오 검증에서 발견된 단일 문제
+type Diagnostic struct {
+	NodeID   string             `json:"nodeId,omitempty"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Code     string             `json:"code"`
+	Message  string             `json:"message"`
+}
+
+// flowNode는 정적 분석용으로 인스턴스가 해석된 command/event 노드
+type flowNode struct {
+	id         string
+	instanceID string
+	callID     string
+	name       string // command 또는 event 이름
+	data       map[string]interface{}
+	success    []string // 성공 분기 대상 노드 ID
+	failure    []string // 실패 분기 대상 노드 ID
+}
+
+// next는 성공/실패 분기 대상 노드 ID를 모두 반환한다
+func (n *flowNode) next() []string {
+	return append(append([]string(nil), n.success...), n.failure...)
+}
+
+// ValidateScenario는 FlowData JSON에 대해 모든 검증을 수행하고 발견된 문제를 전부 반환한다.
+// ParseScenario와 달리 첫 에러에서 멈추지 않으며, 문제가 없으면 빈 슬라이스를 반환한다.
+// CallScenario 노드는 전개하지 않고 참조 필드만 확인하며, fragment가 만든 dialog를 알 수 없으므로
+// CallScenario 뒤의 callID 흐름 진단은 warning으로 낮춘다.
+func ValidateScenario(flowData string) []Diagnostic {
+	return ValidateScenarioWithResolver(flowData, nil)
+}
+
+// ValidateScenarioWithResolver는 CallScenario 노드를 resolver로 전개한 flow에 대해 ValidateScenario의 검증을 수행한다.
+// fragment 내부 노드의 진단은 호출한 CallScenario 노드에 귀속된다.
+// resolver가 nil이면 ValidateScenario와 같고, 전개에 실패하면 그 에러를 보고한 뒤 전개하지 않고 검증한다.
+func ValidateScenarioWithResolver(flowData string, resolver FlowResolver) []Diagnostic {
+	diags := []Diagnostic{}
+	report := func(nodeID string, severity DiagnosticSeverity, code, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			NodeID:   nodeID,
+			Severity: severity,
+			Code:     code,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	var flow FlowData
+	if err := json.Unmarshal([]byte(flowData), &flow); err != nil {
+		report("", SeverityError, DiagInvalidFlow, "failed to unmarshal flowData: %v", err)
+		return diags
+	}
+
+	// 0. CallScenario 전개 — 참조 필드가 잘못된 노드는 아래 필드 검증에서 보고되므로 전개하지 않는다
+	topLevel := make(map[string]bool, len(flow.Nodes))
+	callScenarios := []string{}
+	for _, node := range flow.Nodes {
+		topLevel[node.ID] = true
+		if node.Type == "command" && getStringField(node.Data, "command", "") == CommandCallScenario {
+			callScenarios = append(callScenarios, node.ID)
+			if _, err := callScenarioRef(node.Data); err != nil {
+				resolver = nil
+			}
+		}
+	}
+	expanded := false
+	if resolver != nil && len(callScenarios) > 0 {
+		values := make(map[string]interface{}, len(flow.Variables))
+		for name, value := range flow.Variables {
+			values[name] = value
+		}
+		if expandedFlow, _, err := expandCallScenarios(flow, resolver, nil, values); err != nil {
+			report("", SeverityError, DiagInvalidCallScenario, "%v", err)
+		} else {
+			flow = expandedFlow
+			expanded = true
+		}
+	}
+
+	// 1. 인스턴스와 DN 수집
+	instances := make(map[string]bool)
+	servers := make(map[string]bool)
+	registers := false
+	dnOwners := make(map[string]string)
+	for _, node := range flow.Nodes {
+		if node.Type != "sipInstance" {
+			continue
+		}
+		instances[node.ID] = true
+		switch role := getStringField(node.Data, "role", InstanceRoleUA); role {
+		case InstanceRoleUA:
+			timer := SipInstanceConfig{
+				SessionExpires:   int(getFloatField(node.Data, "sessionExpires", 0)),
+				MinSE:            int(getFloatField(node.Data, "minSE", minSessionExpires)),
+				SessionRefresher: getStringField(node.Data, "sessionRefresher", SessionRefresherUAC),
+				RefreshMethod:    getStringField(node.Data, "sessionRefreshMethod", SessionRefreshUpdate),
+			}
+			if err := validateSessionTimer(timer); err != nil {
+				report(node.ID, SeverityError, DiagInvalidSessionTimer, "%v", err)
+			}
+			registers = registers || getBoolField(node.Data, "register", true)
+		case InstanceRoleServer:
+			servers[node.ID] = true
+		default:
+			report(node.ID, SeverityError, DiagInvalidRole, "unsupported instance role %q", role)
+		}
+		dn := getStringField(node.Data, "dn", "")
+		if dn == "" {
+			continue
+		}
+		if owner, exists := dnOwners[dn]; exists {
+			report(node.ID, SeverityError, DiagDuplicateDN, "DN %s is already used by instance %s", dn, owner)
+			continue
+		}
+		dnOwners[dn] = node.ID
+	}
+	if len(instances) == 0 {
+		report("", SeverityError, DiagNoInstances, "no sipInstance nodes found")
+	}
+
+	// 2. command/event 노드 수집 및 노드별 필드 검증
+	nodes := make(map[string]*flowNode)
+	order := []string{}
+	for _, node := range flow.Nodes {
+		if node.Type != "command" && node.Type != "event" {
+			continue
+		}
+		vn := &flowNode{
+			id:         node.ID,
+			instanceID: getStringField(node.Data, "sipInstanceId", ""),
+			callID:     getStringField(node.Data, "callId", defaultCallID),
+			data:       node.Data,
+		}
+		if node.Type == "command" {
+			vn.name = getStringField(node.Data, "command", "")
+			if vn.name != "" && !slices.Contains(supportedCommands, vn.name) {
+				report(node.ID, SeverityError, DiagUnsupportedCommand, "unsupported command %s", vn.name)
+			}
+		} else {
+			vn.name = getStringField(node.Data, "event", "")
+			if vn.name != "" && !slices.Contains(supportedEvents, vn.name) {
+				report(node.ID, SeverityError, DiagUnsupportedEvent, "unsupported event %s", vn.name)
+			}
+			if vn.name == string(eventhandler.SIPEventIncoming) && vn.instanceID == "" {
+				if number := getStringField(node.Data, "number", ""); number != "" {
+					owner, exists := dnOwners[number]
+					if !exists {
+						report(node.ID, SeverityError, DiagUnknownNumber, "INCOMING references unknown number %s", number)
+					}
+					vn.instanceID = owner
+				}
+			}
+		}
+		if vn.instanceID != "" && !instances[vn.instanceID] {
+			report(node.ID, SeverityError, DiagUnknownInstance, "node references unknown instance %s", vn.instanceID)
+		}
+
+		validateNodeFields(vn, report)
+		nodes[node.ID] = vn
+		order = append(order, node.ID)
+	}
+
+	// 3. 엣지 — 시작 노드, 분기, 인스턴스 상속
+	startNodes := []string{}
+	instanceStarts := make(map[string][]string)
+	for _, edge := range flow.Edges {
+		target, targetExists := nodes[edge.Target]
+		if instances[edge.Source] {
+			if !targetExists {
+				continue
+			}
+			startNodes = append(startNodes, target.id)
+			instanceStarts[edge.Source] = append(instanceStarts[edge.Source], target.id)
+			if target.instanceID == "" {
+				target.instanceID = edge.Source
+			}
+			continue
+		}
+		source, sourceExists := nodes[edge.Source]
+		if !sourceExists || !targetExists {
+			report("", SeverityWarning, DiagDanglingEdge, "edge %s connects unknown nodes %s -> %s", edge.ID, edge.Source, edge.Target)
+			continue
+		}
+		if isFailureEdge(edge) {
+			source.failure = append(source.failure, target.id)
+		} else {
+			source.success = append(source.success, target.id)
+		}
+	}
+
+	for _, id := range order {
+		if nodes[id].instanceID == "" {
+			report(id, SeverityError, DiagMissingInstance, "node is missing sipInstanceId")
+		} else if nodes[id].name == ServerEventRegisterReceived && instances[nodes[id].instanceID] && !servers[nodes[id].instanceID] {
+			report(id, SeverityError, DiagRequiresServer, "%s requires a SIP server instance", ServerEventRegisterReceived)
+		}
+	}
+
+	// 4. 도달 가능성 — 인스턴스 노드에서 연결되지 않은 노드는 실행되지 않는다
+	reachable := make(map[string]bool)
+	queue := append([]string(nil), startNodes...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		queue = append(queue, nodes[id].next()...)
+	}
+	for _, id := range order {
+		if !reachable[id] {
+			report(id, SeverityWarning, DiagUnreachableNode, "node is not reachable from any sipInstance and will never run")
+		}
+	}
+
+	// 등록만 하는 run은 maxDurationMs가 없으면 기본 유지 시간 후 종료된다
+	if len(startNodes) == 0 && registers && flow.MaxDurationMs <= 0 {
+		report("", SeverityWarning, DiagNoMaxDuration, "registration-only scenario has no maxDurationMs; registrations are held for %s", defaultRegistrationHold)
+	}
+
+	// 5. 순환 — 실행기는 분기를 따라가기만 하므로 순환은 무한 루프가 된다
+	for _, id := range findCycleNodes(nodes, order) {
+		report(id, SeverityError, DiagCycle, "node is part of a cycle")
+	}
+
+	// 6. callID 흐름 — 경로별 dialog 상태로 불가능한 호 제어 동작 검출
+	// 한 인스턴스의 시작 체인은 엣지 순서대로 이어서 실행된다 (planChains와 동일)
+	instanceIDs := make([]string, 0, len(instanceStarts))
+	for id := range instanceStarts {
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Strings(instanceIDs)
+	startChains := make([][]string, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		startChains = append(startChains, instanceStarts[id])
+	}
+	callFlowDiags := analyzeCallFlow(nodes, order, startChains)
+	if !expanded && len(callScenarios) > 0 {
+		// 전개하지 않은 CallScenario 뒤에서는 fragment가 dialog를 만들거나 응답했을 수 있다
+		downstream := make(map[string]bool)
+		queue := append([]string(nil), callScenarios...)
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			node, exists := nodes[id]
+			if !exists {
+				continue
+			}
+			for _, next := range node.next() {
+				if !downstream[next] {
+					downstream[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		for i, d := range callFlowDiags {
+			if downstream[d.NodeID] && d.Severity == SeverityError {
+				callFlowDiags[i].Severity = SeverityWarning
+				callFlowDiags[i].Message += " (unless a preceding CallScenario sets it up)"
+			}
+		}
+	}
+	diags = append(diags, callFlowDiags...)
+
+	// fragment 내부 노드("<CallScenario 노드 ID>/<원본 노드 ID>")의 진단은 편집기에 보이는 호출 노드로 옮긴다
+	if expanded {
+		for i, d := range diags {
+			if d.NodeID == "" || topLevel[d.NodeID] {
+				continue
+			}
+			if callerID, inner, found := strings.Cut(d.NodeID, "/"); found {
+				diags[i].NodeID = callerID
+				diags[i].Message = fmt.Sprintf("fragment node %s: %s", inner, d.Message)
+			}
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Severity == SeverityError && diags[j].Severity != SeverityError
+	})
+	return diags
+}
+
+type diagnosticReporter func(nodeID string, severity DiagnosticSeverity, code, format string, args ...interface{})
+
+// validateNodeFields는 노드 단위로 확인 가능한 필수 필드와 값 형식을 검증한다
+func validateNodeFields(vn *flowNode, report diagnosticReporter) {
+	switch vn.name {
+	case string(SIPCommandPlayAudio):
+		filePath := getStringField(vn.data, "filePath", "")
+		if filePath == "" {
+			report(vn.id, SeverityError, DiagMissingField, "PlayAudio requires filePath")
+		} else if info, err := os.Stat(filePath); err != nil || info.IsDir() {
+			report(vn.id, SeverityError, DiagMissingWAV, "WAV file not found: %s", filePath)
+		}
+	case string(SIPCommandSendDTMF):
+		digits := getStringField(vn.data, "digits", "")
+		if digits == "" {
+			report(vn.id, SeverityError, DiagMissingField, "SendDTMF requires digits")
+		}
+		if invalid := invalidDTMFDigits(digits); invalid != "" {
+			report(vn.id, SeverityError, DiagInvalidDTMF, "invalid DTMF digits: %s", invalid)
+		}
+	case string(eventhandler.SIPEventDTMFReceived):
+		expected := getStringField(vn.data, "expectedDigit", "")
+		if len([]rune(expected)) > 1 {
+			report(vn.id, SeverityError, DiagInvalidDTMF, "expectedDigit must be a single digit, got %q", expected)
+		} else if invalid := invalidDTMFDigits(expected); invalid != "" {
+			report(vn.id, SeverityError, DiagInvalidDTMF, "invalid expected DTMF digit: %s", invalid)
+		}
+	case string(SIPCommandMuteTransfer):
+		if getStringField(vn.data, "consultCallId", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "MuteTransfer requires consultCallId")
+		}
+	case string(SIPCommandBridge):
+		if getStringField(vn.data, "peerCallId", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "Bridge requires peerCallId")
+		}
+	case string(eventhandler.SIPEventReferReceived):
+		if action := getStringField(vn.data, "referAction", ReferActionAccept); action != ReferActionAccept && action != ReferActionReject {
+			report(vn.id, SeverityError, DiagInvalidReferAction, "unsupported referAction %q (accept|reject)", action)
+		}
+	case string(SIPCommandConference):
+		if action := getStringField(vn.data, "conferenceAction", ConferenceActionJoin); action != ConferenceActionJoin && action != ConferenceActionLeave {
+			report(vn.id, SeverityError, DiagInvalidConference, "unsupported conferenceAction %q (join|leave)", action)
+		}
+	case string(SIPCommandPickup):
+		if getStringField(vn.data, "pickupTarget", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "Pickup requires pickupTarget")
+		}
+		if mode := getStringField(vn.data, "pickupMode", PickupModeFeatureCode); mode != PickupModeFeatureCode && mode != PickupModeReplaces {
+			report(vn.id, SeverityError, DiagInvalidFeatureOption, "unsupported pickupMode %q (featureCode|replaces)", mode)
+		}
+	case string(SIPCommandSetForwarding):
+		forwardType := getStringField(vn.data, "forwardType", ForwardTypeAlways)
+		if _, err := defaultFeatureCodes.forwardCode(forwardType, true); err != nil {
+			report(vn.id, SeverityError, DiagInvalidFeatureOption, "%v (always|busy|noAnswer)", err)
+		}
+		if getBoolField(vn.data, "forwardEnabled", true) && getStringField(vn.data, "forwardTarget", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "SetForwarding requires forwardTarget when enabling forwarding")
+		}
+	case string(SIPCommandSubscribe), string(SIPCommandUnsubscribe), SubscriptionEventNotifyReceived:
+		if getStringField(vn.data, "subscribeTarget", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "%s requires subscribeTarget", vn.name)
+		}
+		expectedState := ""
+		if vn.name == SubscriptionEventNotifyReceived {
+			expectedState = getStringField(vn.data, "expectedState", "")
+		}
+		if err := validateEventPackage(getStringField(vn.data, "eventPackage", EventPackageDialog), expectedState); err != nil {
+			report(vn.id, SeverityError, DiagInvalidEventPackage, "%v", err)
+		}
+	case string(SIPCommandSendMessage), string(SIPCommandSendOptions):
+		if getStringField(vn.data, "targetUri", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "%s requires targetUri", vn.name)
+		}
+		if vn.name == string(SIPCommandSendOptions) {
+			if status := int(getFloatField(vn.data, "expectedStatus", 200)); status != 0 && (status < 200 || status > 699) {
+				report(vn.id, SeverityError, DiagInvalidStatusCode, "expectedStatus must be a final response code (200-699) or 0, got %d", status)
+			}
+		}
+	case string(SIPCommandReInvite), string(SIPCommandUpdate):
+		err := validateMediaUpdate(strings.ToLower(getStringField(vn.data, "direction", "")), getStringArrayField(vn.data, "codecs", nil),
+			getStringField(vn.data, "mediaAddress", ""), getBoolField(vn.data, "withoutSdp", false))
+		if err != nil {
+			report(vn.id, SeverityError, DiagInvalidMediaUpdate, "%v", err)
+		}
+	case string(eventhandler.SIPEventReInvite), string(eventhandler.SIPEventUpdate):
+		if err := validateMediaUpdate(strings.ToLower(getStringField(vn.data, "direction", "")), nil, "", false); err != nil {
+			report(vn.id, SeverityError, DiagInvalidMediaUpdate, "%v", err)
+		}
+	case MessageEventReceived:
+		if _, err := regexp.Compile(getStringField(vn.data, "messageMatch", "")); err != nil {
+			report(vn.id, SeverityError, DiagInvalidPattern, "invalid messageMatch: %v", err)
+		}
+	case SyncCommandSignal, SyncEventWaitSignal:
+		if getStringField(vn.data, "signalName", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "%s requires signalName", vn.name)
+		}
+	case SyncEventBarrier:
+		if getStringField(vn.data, "barrierName", "") == "" {
+			report(vn.id, SeverityError, DiagMissingField, "Barrier requires barrierName")
+		}
+	case CommandCallScenario:
+		if _, err := callScenarioRef(vn.data); err != nil {
+			report(vn.id, SeverityError, DiagMissingField, "%v", err)
+		}
+	}
+}
+
+// invalidDTMFDigits는 허용되지 않는 DTMF 문자만 모아 반환한다
+func invalidDTMFDigits(digits string) string {
+	var invalid strings.Builder
+	for _, r := range digits {
+		if !isValidDTMF(r) {
+			invalid.WriteRune(r)
+		}
+	}
+	return invalid.String()
+}
+
+// findCycleNodes는 분기 그래프에서 순환에 포함된 노드를 flow 순서대로 반환한다
+func findCycleNodes(nodes map[string]*flowNode, order []string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(nodes))
+	onCycle := make(map[string]bool)
+	stack := []string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = inProgress
+		stack = append(stack, id)
+		for _, next := range nodes[id].next() {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inProgress:
+				// 스택에서 next 위치부터 현재 노드까지가 순환
+				for i := len(stack) - 1; i >= 0; i-- {
+					onCycle[stack[i]] = true
+					if stack[i] == next {
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	for _, id := range order {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	result := []string{}
+	for _, id := range order {
+		if onCycle[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// diagnosticCodes는 nodeID -> 진단 코드 목록으로 변환한다
+func diagnosticCodes(diags []Diagnostic) map[string][]string {
+	out := make(map[string][]string)
+	for _, d := range diags {
+		out[d.NodeID] = append(out[d.NodeID], d.Code)
+	}
+	return out
+}
+
+func hasCode(diags []Diagnostic, nodeID, code string) bool {
+	for _, d := range diags {
+		if d.NodeID == nodeID && d.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateScenario_Valid(t *testing.T) {
+	wavPath := filepath.Join(t.TempDir(), "prompt.wav")
+	if err := os.WriteFile(wavPath, []byte("RIFF"), 0644); err != nil {
+		t.Fatalf("failed to write wav: %v", err)
+	}
+
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "inst-b", "type": "sipInstance", "data": {"dn": "200"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-a", "targetUri": "sip:200@pbx"}},
+    {"id": "dtmf", "type": "command", "data": {"command": "SendDTMF", "sipInstanceId": "inst-a", "digits": "12#*A"}},
+    {"id": "incoming", "type": "event", "data": {"event": "INCOMING", "number": "200"}},
+    {"id": "answer", "type": "command", "data": {"command": "Answer", "sipInstanceId": "inst-b"}},
+    {"id": "play", "type": "command", "data": {"command": "PlayAudio", "sipInstanceId": "inst-b", "filePath": "` + filepath.ToSlash(wavPath) + `"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "make"},
+    {"id": "e2", "source": "make", "target": "dtmf"},
+    {"id": "e3", "source": "inst-b", "target": "incoming"},
+    {"id": "e4", "source": "incoming", "target": "answer"},
+    {"id": "e5", "source": "answer", "target": "play"}
+  ]
+}`
+
+	diags := ValidateScenario(flowJSON)
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diags)
+	}
+}
+
+func TestValidateScenario_ReportsAllProblems(t *testing.T) {
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "inst-b", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "ghost", "type": "command", "data": {"command": "MakeCall", "sipInstanceId": "inst-x"}},
+    {"id": "answer", "type": "command", "data": {"command": "Answer"}},
+    {"id": "dtmf", "type": "command", "data": {"command": "SendDTMF", "digits": "12x"}},
+    {"id": "play", "type": "command", "data": {"command": "PlayAudio", "filePath": "/nonexistent/missing.wav"}},
+    {"id": "xfer", "type": "command", "data": {"command": "MuteTransfer", "primaryCallId": "call-1", "consultCallId": "call-9"}},
+    {"id": "loop-a", "type": "command", "data": {"command": "Hold"}},
+    {"id": "loop-b", "type": "command", "data": {"command": "Retrieve"}},
+    {"id": "orphan", "type": "event", "data": {"event": "RINGING", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "answer"},
+    {"id": "e2", "source": "answer", "target": "dtmf"},
+    {"id": "e3", "source": "dtmf", "target": "play"},
+    {"id": "e4", "source": "play", "target": "xfer"},
+    {"id": "e5", "source": "xfer", "target": "loop-a"},
+    {"id": "e6", "source": "loop-a", "target": "loop-b"},
+    {"id": "e7", "source": "loop-b", "target": "loop-a"}
+  ]
+}`
+
+	diags := ValidateScenario(flowJSON)
+
+	tests := []struct {
+		nodeID string
+		code   string
+	}{
+		{"inst-b", DiagDuplicateDN},
+		{"ghost", DiagUnknownInstance},
+		{"ghost", DiagUnreachableNode},
+		{"answer", DiagAnswerWithoutIncoming},
+		{"dtmf", DiagInvalidDTMF},
+		{"play", DiagMissingWAV},
+		{"xfer", DiagUnknownCallID},
+		{"loop-a", DiagCycle},
+		{"loop-b", DiagCycle},
+		{"orphan", DiagUnreachableNode},
+	}
+	for _, tt := range tests {
+		if !hasCode(diags, tt.nodeID, tt.code) {
+			t.Errorf("expected %s on %s, got %v", tt.code, tt.nodeID, diagnosticCodes(diags))
+		}
+	}
+
+	// primary call-1 is never created either (no MakeCall/INCOMING on inst-a)
+	count := 0
+	for _, d := range diags {
+		if d.NodeID == "xfer" && d.Code == DiagUnknownCallID {
+			count++
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected primary and consult callId diagnostics on xfer, got %d", count)
+	}
+
+	// errors come before warnings
+	seenWarning := false
+	for _, d := range diags {
+		if d.Severity == SeverityWarning {
+			seenWarning = true
+		} else if seenWarning {
+			t.Fatalf("error diagnostic %+v listed after a warning", d)
+		}
+	}
+}
+
+func TestValidateScenario_AnswerOnlyOnOneBranch(t *testing.T) {
+	// INCOMING이 한쪽 분기에만 있으면 합류 지점의 Answer는 보장되지 않는다
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "wait", "type": "event", "data": {"event": "WaitSignal", "signalName": "go", "sipInstanceId": "inst-a"}},
+    {"id": "incoming", "type": "event", "data": {"event": "INCOMING", "sipInstanceId": "inst-a"}},
+    {"id": "answer", "type": "command", "data": {"command": "Answer", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "wait"},
+    {"id": "e2", "source": "wait", "target": "incoming", "sourceHandle": "success"},
+    {"id": "e3", "source": "wait", "target": "answer", "sourceHandle": "failure"},
+    {"id": "e4", "source": "incoming", "target": "answer"}
+  ]
+}`
+
+	diags := ValidateScenario(flowJSON)
+	if !hasCode(diags, "answer", DiagAnswerWithoutIncoming) {
+		t.Errorf("expected answer_without_incoming, got %v", diagnosticCodes(diags))
+	}
+}
+
+func TestValidateScenario_RegistrationOnlyWithoutMaxDuration(t *testing.T) {
+	flowJSON := `{"nodes": [{"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}}], "edges": []}`
+	if diags := ValidateScenario(flowJSON); !hasCode(diags, "", DiagNoMaxDuration) {
+		t.Errorf("expected no_max_duration, got %v", diagnosticCodes(diags))
+	}
+
+	capped := `{"maxDurationMs": 60000, "nodes": [{"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}}], "edges": []}`
+	if diags := ValidateScenario(capped); len(diags) != 0 {
+		t.Errorf("expected no diagnostics with maxDurationMs, got %+v", diags)
+	}
+}
+
+func TestValidateScenario_InvalidJSON(t *testing.T) {
+	diags := ValidateScenario("{not json")
+	if len(diags) != 1 || diags[0].Code != DiagInvalidFlow || diags[0].Severity != SeverityError {
+		t.Errorf("expected single invalid_flow error, got %+v", diags)
+	}
+}
+
+func TestValidateScenario_CallScenarioDialogs(t *testing.T) {
+	// fragment가 call-1을 confirmed로 남기므로 호출 측의 이후 Hold는 유효하다
+	flowJSON := `{
+  "nodes": [
+    {"id": "inst-a", "type": "sipInstance", "data": {"dn": "100"}},
+    {"id": "dial", "type": "command", "data": {"command": "CallScenario", "fragmentName": "dial"}},
+    {"id": "hold", "type": "command", "data": {"command": "Hold", "sipInstanceId": "inst-a"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "inst-a", "target": "dial"},
+    {"id": "e2", "source": "dial", "target": "hold"}
+  ]
+}`
+	resolver := &fakeFlowResolver{fragments: map[string]string{
+		"dial": `{"nodes": [{"id": "make", "type": "command", "data": {"command": "MakeCall", "targetUri": "sip:200@pbx"}}], "edges": []}`,
+		"hold-first": `{
+  "nodes": [
+    {"id": "hold", "type": "command", "data": {"command": "Hold"}},
+    {"id": "make", "type": "command", "data": {"command": "MakeCall", "targetUri": "sip:200@pbx"}}
+  ],
+  "edges": [{"id": "e1", "source": "hold", "target": "make"}]
+}`,
+	}}
+
+	// resolver가 없으면 fragment 내용을 알 수 없으므로 Hold는 warning에 그친다
+	diags := ValidateScenario(flowJSON)
+	d, ok := findDiagnostic(diags, "hold")
+	if !ok || d.Severity != SeverityWarning {
+		t.Errorf("expected a warning on hold without a resolver, got %+v", diags)
+	}
+
+	if diags := ValidateScenarioWithResolver(flowJSON, resolver); len(diags) != 0 {
+		t.Errorf("expected no diagnostics with the fragment expanded, got %+v", diags)
+	}
+
+	// fragment 내부의 문제는 CallScenario 노드에 보고된다
+	broken := strings.Replace(flowJSON, `"fragmentName": "dial"`, `"fragmentName": "hold-first"`, 1)
+	diags = ValidateScenarioWithResolver(broken, resolver)
+	if !hasCode(diags, "dial", DiagInvalidCallState) {
+		t.Errorf("expected invalid_call_state on dial, got %+v", diags)
+	}
+
+	missing := strings.Replace(flowJSON, `"fragmentName": "dial"`, `"fragmentName": "nope"`, 1)
+	if diags := ValidateScenarioWithResolver(missing, resolver); !hasCode(diags, "", DiagInvalidCallScenario) {
+		t.Errorf("expected invalid_call_scenario for a missing fragment, got %+v", diags)
+	}
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchdog은 시나리오 최대 실행 시간과 인스턴스 체인별 deadline을 감시한다.
+// 만료되면 실행 중인 노드와 열린 dialog를 진단 메시지로 남기고 run을 실패로 종료한다.
+// 디버거 정지 시간도 실행 시간에 포함된다.
+type watchdog struct {
+	engine *Engine
+	run    *scenarioRun
+	ex     *Executor
+	errCh  chan<- error
+	cancel context.CancelFunc
+}
+
+// arm은 d 후에 reason으로 run을 실패시키는 타이머를 건다. d가 0 이하면 아무것도 하지 않는다.
+// 반환된 함수로 타이머를 해제한다.
+func (w *watchdog) arm(d time.Duration, reason string) func() {
+	if d <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(d, func() { w.fire(reason) })
+	return func() { timer.Stop() }
+}
+
+// fire는 진단을 수집한 뒤 run을 실패 처리하고 취소한다.
+// 진단은 취소로 노드와 dialog가 정리되기 전에 수집해야 한다.
+func (w *watchdog) fire(reason string) {
+	w.run.mu.Lock()
+	running := w.run.terminal == terminalStateRunning
+	w.run.mu.Unlock()
+	if !running {
+		return
+	}
+
+	diagnostic := w.ex.watchdogDiagnostic(reason)
+	if !w.run.markTerminalState(terminalStateFailed) {
+		return
+	}
+
+	w.engine.emitActionLog("", "", diagnostic, "error", WithRunID(w.run.id))
+	select {
+	case w.errCh <- errors.New(diagnostic):
+	default:
+	}
+	w.cancel()
+}
+
+// watchdogDiagnostic은 "<reason>; running nodes: ...; open dialogs: ..." 형식의 진단 메시지를 만든다
+func (ex *Executor) watchdogDiagnostic(reason string) string {
+	ex.activeMu.Lock()
+	nodes := make([]string, 0, len(ex.active))
+	for nodeID, instanceID := range ex.active {
+		nodes = append(nodes, fmt.Sprintf("%s (%s)", nodeID, instanceID))
+	}
+	ex.activeMu.Unlock()
+	sort.Strings(nodes)
+
+	dialogs := ex.sessions.Snapshot()
+	if ex.sim != nil {
+		dialogs = append(dialogs, ex.sim.snapshot()...)
+	}
+	open := make([]string, 0, len(dialogs))
+	for _, d := range dialogs {
+		if d.State == "terminated" {
+			continue
+		}
+		open = append(open, fmt.Sprintf("%s/%s (%s)", d.InstanceID, d.CallID, d.State))
+	}
+
+	return fmt.Sprintf("watchdog: %s; running nodes: %s; open dialogs: %s", reason, joinOrNone(nodes), joinOrNone(open))
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
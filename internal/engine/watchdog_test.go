@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchdog_MaxDurationEndsRegistrationOnlyRun(t *testing.T) {
+	eng, repo, te := newDryRunEngine(t)
+
+	var flow FlowData
+	flowData := buildTestFlowData(t, []FlowNode{
+		{ID: "inst-r", Type: "sipInstance", Data: map[string]interface{}{"label": "Registrar", "dn": "100", "register": true}},
+	}, nil)
+	if err := json.Unmarshal([]byte(flowData), &flow); err != nil {
+		t.Fatal(err)
+	}
+	flow.MaxDurationMs = 100
+	b, err := json.Marshal(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scn, err := repo.CreateScenario("default", "registration-only")
+	if err != nil {
+		t.Fatalf("CreateScenario failed: %v", err)
+	}
+	if err := repo.SaveScenario(scn.ID, string(b)); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+
+	result, err := eng.RunScenario(context.Background(), scn.ID, &SimulationOptions{})
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusFailed || !strings.Contains(result.Error, "scenario exceeded max duration 100ms") {
+		t.Fatalf("expected watchdog failure, got %+v", result)
+	}
+	if len(te.GetEventsByName(EventFailed)) != 1 {
+		t.Errorf("expected one scenario:failed event, got %v", te.GetEventsByName(EventFailed))
+	}
+}
+
+func TestWatchdog_RegistrationOnlyRunEndsAfterDefaultHold(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+	eng.regHold = 100 * time.Millisecond
+	scenarioID := saveDryRunScenario(t, repo, []FlowNode{
+		{ID: "inst-r", Type: "sipInstance", Data: map[string]interface{}{"label": "Registrar", "dn": "100", "register": true}},
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := eng.RunScenario(ctx, scenarioID, &SimulationOptions{})
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusCompleted {
+		t.Fatalf("expected registration-only run to complete after the default hold, got %+v", result)
+	}
+}
+
+func TestWatchdog_ChainDeadlineReportsRunningNodesAndDialogs(t *testing.T) {
+	eng, repo, _ := newDryRunEngine(t)
+	scenarioID := saveDryRunScenario(t, repo, []FlowNode{
+		{ID: "inst-a", Type: "sipInstance", Data: map[string]interface{}{"label": "Caller", "dn": "100"}},
+		{ID: "inst-b", Type: "sipInstance", Data: map[string]interface{}{"label": "Callee", "dn": "200", "chainDeadlineMs": 200}},
+		{ID: "make-call", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-a", "command": "MakeCall", "targetUri": "200"}},
+		{ID: "wait-bye", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-a", "event": "DISCONNECTED", "timeout": 30000}},
+		{ID: "incoming", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "INCOMING"}},
+		{ID: "answer", Type: "command", Data: map[string]interface{}{"sipInstanceId": "inst-b", "command": "Answer"}},
+		{ID: "wait-dtmf", Type: "event", Data: map[string]interface{}{"sipInstanceId": "inst-b", "event": "DTMFReceived", "timeout": 30000}},
+	}, []FlowEdge{
+		{ID: "e1", Source: "inst-a", Target: "make-call"},
+		{ID: "e2", Source: "make-call", Target: "wait-bye"},
+		{ID: "e3", Source: "inst-b", Target: "incoming"},
+		{ID: "e4", Source: "incoming", Target: "answer"},
+		{ID: "e5", Source: "answer", Target: "wait-dtmf"},
+	})
+
+	result, err := eng.RunScenario(context.Background(), scenarioID, &SimulationOptions{})
+	if err != nil {
+		t.Fatalf("RunScenario failed: %v", err)
+	}
+	if result.Status != RunStatusFailed {
+		t.Fatalf("expected failed run, got %+v", result)
+	}
+	for _, want := range []string{
+		"instance inst-b chain exceeded deadline 200ms",
+		"running nodes: wait-bye (inst-a), wait-dtmf (inst-b)",
+		"inst-a/call-1 (confirmed)",
+		"inst-b/call-1 (confirmed)",
+	} {
+		if !strings.Contains(result.Error, want) {
+			t.Errorf("diagnostic %q does not contain %q", result.Error, want)
+		}
+	}
+}
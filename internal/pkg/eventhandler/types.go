@@ -5,15 +5,20 @@ import "time"
 type SIPEventType string
 
 const (
-	SIPEventIncoming     SIPEventType = "INCOMING"
-	SIPEventDisconnected SIPEventType = "DISCONNECTED"
-	SIPEventRinging      SIPEventType = "RINGING"
-	SIPEventTimeout      SIPEventType = "TIMEOUT"
-	SIPEventDTMFReceived SIPEventType = "DTMFReceived"
-	SIPEventHeld         SIPEventType = "HELD"
-	SIPEventRetrieved    SIPEventType = "RETRIEVED"
-	SIPEventTransferred  SIPEventType = "TRANSFERRED"
-	SIPEventNotify       SIPEventType = "NOTIFY"
+	SIPEventIncoming      SIPEventType = "INCOMING"
+	SIPEventDisconnected  SIPEventType = "DISCONNECTED"
+	SIPEventRinging       SIPEventType = "RINGING"
+	SIPEventTimeout       SIPEventType = "TIMEOUT"
+	SIPEventDTMFReceived  SIPEventType = "DTMFReceived"
+	SIPEventHeld          SIPEventType = "HELD"
+	SIPEventRetrieved     SIPEventType = "RETRIEVED"
+	SIPEventTransferred   SIPEventType = "TRANSFERRED"
+	SIPEventNotify        SIPEventType = "NOTIFY"
+	SIPEventReferReceived SIPEventType = "REFER_RECEIVED"
+	SIPEventReplaced      SIPEventType = "REPLACED"
+	SIPEventRefreshFailed SIPEventType = "SESSION_REFRESH_FAILED"
+	SIPEventReInvite      SIPEventType = "REINVITE_RECEIVED"
+	SIPEventUpdate        SIPEventType = "UPDATE_RECEIVED"
 )
 
 type Event struct {
@@ -22,6 +27,7 @@ type Event struct {
 	InstanceID    string
 	LogicalCallID string
 	StatusCode    int
+	Detail        string // REFER_RECEIVED: Refer-To URI, REPLACED: new SIP Call-ID, SESSION_REFRESH_FAILED: 실패 사유, REINVITE_RECEIVED/UPDATE_RECEIVED: 상대 offer의 "방향 코덱,..."
 }
 
 type Subject interface {
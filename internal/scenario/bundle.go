@@ -0,0 +1,637 @@
+package scenario
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BundleFormatVersion is the current version of the portable scenario bundle format
+const BundleFormatVersion = 1
+
+// Bundle is a portable, versioned snapshot of scenarios, fragments and optional media
+// that can be written to a file, committed to git and imported into another database.
+type Bundle struct {
+	FormatVersion int              `json:"format_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Project       *BundleProject   `json:"project,omitempty"`
+	Scenarios     []BundleScenario `json:"scenarios"`
+	Fragments     []BundleFragment `json:"fragments,omitempty"`
+	Media         []BundleMedia    `json:"media,omitempty"`
+}
+
+// BundleProject describes the project a bundle was exported from
+type BundleProject struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BundleScenario is a scenario entry in a bundle. FlowData is embedded as raw JSON
+// so exported files stay readable and diff cleanly.
+type BundleScenario struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	FlowData json.RawMessage `json:"flow_data"`
+}
+
+// BundleFragment is a flow fragment entry in a bundle
+type BundleFragment struct {
+	Name     string          `json:"name"`
+	FlowData json.RawMessage `json:"flow_data"`
+}
+
+// BundleMedia is a WAV file referenced by a PlayAudio node, keyed by its original path
+type BundleMedia struct {
+	Path string `json:"path"`
+	Data []byte `json:"data"`
+}
+
+// ExportOptions controls what is included in an exported bundle
+type ExportOptions struct {
+	IncludeMedia bool
+}
+
+// ConflictPolicy decides what happens when an imported scenario ID or fragment name already exists
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // keep the existing entry
+	ConflictOverwrite ConflictPolicy = "overwrite" // replace the existing entry's name and flow data (scenarios of other projects are copied)
+	ConflictCopy      ConflictPolicy = "copy"      // import as a new entry with a fresh ID / unique name
+)
+
+// ImportOptions controls how a bundle is imported
+type ImportOptions struct {
+	OnConflict ConflictPolicy
+	MediaDir   string // directory for extracted media files; media is ignored when empty
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	Imported   []string          `json:"imported"`    // IDs of created or overwritten scenarios
+	Skipped    []string          `json:"skipped"`     // bundle IDs of scenarios left untouched
+	Renamed    map[string]string `json:"renamed"`     // bundle ID -> new ID for copied scenarios
+	Fragments  int               `json:"fragments"`   // number of fragments created or overwritten
+	MediaDir   string            `json:"media_dir"`   // where media files were written
+	MediaFiles int               `json:"media_files"` // number of media files written
+}
+
+// MarshalBundle encodes a bundle as indented JSON
+func MarshalBundle(b *Bundle) ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// UnmarshalBundle decodes and validates a bundle
+func UnmarshalBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if b.FormatVersion == 0 {
+		return nil, fmt.Errorf("not a scenario bundle: missing format_version")
+	}
+	if b.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d (max %d)", b.FormatVersion, BundleFormatVersion)
+	}
+	for i, s := range b.Scenarios {
+		if s.ID == "" || s.Name == "" {
+			return nil, fmt.Errorf("scenario #%d: id and name are required", i+1)
+		}
+		if !json.Valid(s.FlowData) {
+			return nil, fmt.Errorf("scenario %s: invalid flow_data", s.ID)
+		}
+	}
+	for i, f := range b.Fragments {
+		if f.Name == "" {
+			return nil, fmt.Errorf("fragment #%d: name is required", i+1)
+		}
+		if !json.Valid(f.FlowData) {
+			return nil, fmt.Errorf("fragment %s: invalid flow_data", f.Name)
+		}
+	}
+	return &b, nil
+}
+
+// ExportScenarios builds a bundle containing the given scenarios and the fragments of their projects
+func (r *Repository) ExportScenarios(ids []string, opts ExportOptions) (*Bundle, error) {
+	bundle := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		ExportedAt:    time.Now(),
+		Scenarios:     []BundleScenario{},
+	}
+
+	projectIDs := []string{}
+	for _, id := range ids {
+		s, err := r.LoadScenario(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scenario %s: %w", id, err)
+		}
+		entry, err := newBundleScenario(s)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Scenarios = append(bundle.Scenarios, entry)
+		if !slices.Contains(projectIDs, s.ProjectID) {
+			projectIDs = append(projectIDs, s.ProjectID)
+		}
+	}
+
+	for _, projectID := range projectIDs {
+		if err := r.appendBundleFragments(bundle, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.IncludeMedia {
+		if err := collectBundleMedia(bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	return bundle, nil
+}
+
+// ExportProject builds a bundle containing every scenario and fragment of a project
+func (r *Repository) ExportProject(projectID string, opts ExportOptions) (*Bundle, error) {
+	var project BundleProject
+	err := r.db.QueryRow(`SELECT id, name FROM projects WHERE id = ?`, projectID).Scan(&project.ID, &project.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project %s: %w", projectID, err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, project_id, name, flow_data, created_at, updated_at
+		FROM scenarios
+		WHERE project_id = ?
+		ORDER BY name ASC
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export scenarios: %w", err)
+	}
+	defer rows.Close()
+
+	bundle := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		ExportedAt:    time.Now(),
+		Project:       &project,
+		Scenarios:     []BundleScenario{},
+	}
+	for rows.Next() {
+		var s Scenario
+		if err := rows.Scan(&s.ID, &s.ProjectID, &s.Name, &s.FlowData, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scenario: %w", err)
+		}
+		entry, err := newBundleScenario(&s)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Scenarios = append(bundle.Scenarios, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scenarios: %w", err)
+	}
+
+	if err := r.appendBundleFragments(bundle, projectID); err != nil {
+		return nil, err
+	}
+
+	if opts.IncludeMedia {
+		if err := collectBundleMedia(bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportBundle imports a bundle into the given project inside a single transaction.
+// Scenario ID conflicts and fragment name conflicts are resolved by opts.OnConflict.
+// Media referenced by the imported scenarios and fragments is extracted only after the import commits.
+func (r *Repository) ImportBundle(projectID string, bundle *Bundle, opts ImportOptions) (*ImportResult, error) {
+	policy := opts.OnConflict
+	if policy == "" {
+		policy = ConflictSkip
+	}
+	if policy != ConflictSkip && policy != ConflictOverwrite && policy != ConflictCopy {
+		return nil, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+
+	result := &ImportResult{
+		Imported: []string{},
+		Skipped:  []string{},
+		Renamed:  map[string]string{},
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Resolve scenario IDs up front so CallScenario references inside the bundle follow renamed copies.
+	// Overwrite only replaces scenarios of the target project; a conflicting ID owned by another
+	// project is imported as a copy instead of moving that scenario out of its project.
+	type plannedScenario struct {
+		entry  BundleScenario
+		id     string
+		exists bool
+	}
+	planned := make([]plannedScenario, 0, len(bundle.Scenarios))
+	idMap := map[string]string{}
+	for _, entry := range bundle.Scenarios {
+		var ownerID string
+		err := tx.QueryRow(`SELECT project_id FROM scenarios WHERE id = ?`, entry.ID).Scan(&ownerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check scenario %s: %w", entry.ID, err)
+		}
+		exists := err == nil
+
+		id := entry.ID
+		if exists && (policy == ConflictCopy || (policy == ConflictOverwrite && ownerID != projectID)) {
+			id = uuid.New().String()
+			result.Renamed[entry.ID] = id
+			exists = false
+		}
+		idMap[entry.ID] = id
+		planned = append(planned, plannedScenario{entry: entry, id: id, exists: exists})
+	}
+
+	// Resolve fragment names before rewriting flows so CallScenario references follow renamed copies
+	type plannedFragment struct {
+		entry      BundleFragment
+		name       string
+		existingID string
+	}
+	plannedFragments := make([]plannedFragment, 0, len(bundle.Fragments))
+	fragmentNames := map[string]string{}
+	reserved := map[string]bool{}
+	for _, entry := range bundle.Fragments {
+		var existingID string
+		err := tx.QueryRow(`SELECT id FROM fragments WHERE project_id = ? AND name = ?`, projectID, entry.Name).Scan(&existingID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check fragment %s: %w", entry.Name, err)
+		}
+
+		name := entry.Name
+		if existingID != "" && policy == ConflictCopy {
+			name, err = uniqueFragmentName(tx, projectID, entry.Name, reserved)
+			if err != nil {
+				return nil, err
+			}
+			existingID = ""
+		}
+		reserved[name] = true
+		fragmentNames[entry.Name] = name
+		plannedFragments = append(plannedFragments, plannedFragment{entry: entry, name: name, existingID: existingID})
+	}
+
+	// Choose where the media of imported flows will be extracted so their filePath can be rewritten;
+	// the files themselves are written after the commit so skipped or failed imports leave none behind
+	mediaPaths := map[string]string{}
+	if opts.MediaDir != "" && len(bundle.Media) > 0 {
+		flows := make([]json.RawMessage, 0, len(planned)+len(plannedFragments))
+		for _, p := range planned {
+			if !(p.exists && policy == ConflictSkip) {
+				flows = append(flows, p.entry.FlowData)
+			}
+		}
+		for _, p := range plannedFragments {
+			if !(p.existingID != "" && policy == ConflictSkip) {
+				flows = append(flows, p.entry.FlowData)
+			}
+		}
+		referenced, err := bundleMediaRefs(flows)
+		if err != nil {
+			return nil, err
+		}
+		mediaPaths, err = planBundleMedia(bundle.Media, referenced, opts.MediaDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	refs := bundleRefs{scenarioIDs: idMap, fragmentNames: fragmentNames, mediaPaths: mediaPaths}
+	now := time.Now()
+	for _, p := range planned {
+		if p.exists && policy == ConflictSkip {
+			result.Skipped = append(result.Skipped, p.entry.ID)
+			continue
+		}
+
+		flowData, err := rewriteBundleFlow(p.entry.FlowData, refs)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %w", p.entry.ID, err)
+		}
+
+		if p.exists && policy == ConflictOverwrite {
+			if err := recordBaselineRevision(tx, p.id, now); err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec(`
+				UPDATE scenarios
+				SET name = ?, flow_data = ?, updated_at = ?
+				WHERE id = ?
+			`, p.entry.Name, flowData, now, p.id)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO scenarios (id, project_id, name, flow_data, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, p.id, projectID, p.entry.Name, flowData, now, now)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to import scenario %s: %w", p.entry.ID, err)
+		}
+		if err := recordRevision(tx, p.id, flowData, "Imported from bundle", now); err != nil {
+			return nil, err
+		}
+		result.Imported = append(result.Imported, p.id)
+	}
+
+	for _, p := range plannedFragments {
+		if p.existingID != "" && policy == ConflictSkip {
+			continue
+		}
+
+		flowData, err := rewriteBundleFlow(p.entry.FlowData, refs)
+		if err != nil {
+			return nil, fmt.Errorf("fragment %s: %w", p.entry.Name, err)
+		}
+
+		if p.existingID != "" {
+			_, err = tx.Exec(`UPDATE fragments SET flow_data = ?, updated_at = ? WHERE id = ?`, flowData, now, p.existingID)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO fragments (id, project_id, name, flow_data, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, uuid.New().String(), projectID, p.name, flowData, now, now)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to import fragment %s: %w", p.entry.Name, err)
+		}
+		result.Fragments++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	if len(mediaPaths) > 0 {
+		written, err := writeBundleMedia(bundle.Media, mediaPaths, opts.MediaDir)
+		result.MediaDir = opts.MediaDir
+		result.MediaFiles = len(written)
+		if err != nil {
+			return result, fmt.Errorf("imported, but media extraction failed: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Repository) appendBundleFragments(bundle *Bundle, projectID string) error {
+	fragments, err := r.ListFragments(projectID)
+	if err != nil {
+		return err
+	}
+	for _, f := range fragments {
+		raw, err := rawFlowData(f.FlowData)
+		if err != nil {
+			return fmt.Errorf("fragment %s: %w", f.Name, err)
+		}
+		bundle.Fragments = append(bundle.Fragments, BundleFragment{Name: f.Name, FlowData: raw})
+	}
+	return nil
+}
+
+func newBundleScenario(s *Scenario) (BundleScenario, error) {
+	raw, err := rawFlowData(s.FlowData)
+	if err != nil {
+		return BundleScenario{}, fmt.Errorf("scenario %s: %w", s.ID, err)
+	}
+	return BundleScenario{ID: s.ID, Name: s.Name, FlowData: raw}, nil
+}
+
+func rawFlowData(flowData string) (json.RawMessage, error) {
+	if flowData == "" {
+		return json.RawMessage("{}"), nil
+	}
+	if !json.Valid([]byte(flowData)) {
+		return nil, fmt.Errorf("stored flow_data is not valid JSON")
+	}
+	return json.RawMessage(flowData), nil
+}
+
+// flowNodeData returns the data map of every node in a flow document
+func flowNodeData(flow map[string]interface{}) []map[string]interface{} {
+	nodes, _ := flow["nodes"].([]interface{})
+	out := make([]map[string]interface{}, 0, len(nodes))
+	for _, n := range nodes {
+		node, ok := n.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if data, ok := node["data"].(map[string]interface{}); ok {
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+// collectBundleMedia embeds every WAV file referenced by a filePath node field
+func collectBundleMedia(bundle *Bundle) error {
+	seen := map[string]bool{}
+	flows := make([]json.RawMessage, 0, len(bundle.Scenarios)+len(bundle.Fragments))
+	for _, s := range bundle.Scenarios {
+		flows = append(flows, s.FlowData)
+	}
+	for _, f := range bundle.Fragments {
+		flows = append(flows, f.FlowData)
+	}
+
+	for _, raw := range flows {
+		var flow map[string]interface{}
+		if err := json.Unmarshal(raw, &flow); err != nil {
+			return fmt.Errorf("failed to parse flow data: %w", err)
+		}
+		for _, data := range flowNodeData(flow) {
+			path, _ := data["filePath"].(string)
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read media %s: %w", path, err)
+			}
+			bundle.Media = append(bundle.Media, BundleMedia{Path: path, Data: content})
+		}
+	}
+	return nil
+}
+
+// bundleMediaRefs returns the filePath values referenced by the given flows
+func bundleMediaRefs(flows []json.RawMessage) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	for _, raw := range flows {
+		var flow map[string]interface{}
+		if err := json.Unmarshal(raw, &flow); err != nil {
+			return nil, fmt.Errorf("failed to parse flow data: %w", err)
+		}
+		for _, data := range flowNodeData(flow) {
+			if path, _ := data["filePath"].(string); path != "" {
+				referenced[path] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// planBundleMedia picks an extraction path in dir for every referenced media file and returns
+// original path -> new path. Existing files are never overwritten; a numbered name is used instead.
+func planBundleMedia(media []BundleMedia, referenced map[string]bool, dir string) (map[string]string, error) {
+	targets := make(map[string]string, len(media))
+	taken := map[string]bool{}
+	for _, m := range media {
+		if !referenced[m.Path] {
+			continue
+		}
+		base := path.Base(strings.ReplaceAll(m.Path, "\\", "/"))
+		if base == "." || base == "/" {
+			base = "media.wav"
+		}
+		ext := filepath.Ext(base)
+
+		name := base
+		for i := 2; ; i++ {
+			target := filepath.Join(dir, name)
+			if !taken[target] {
+				_, err := os.Stat(target)
+				if errors.Is(err, fs.ErrNotExist) {
+					break
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to check media %s: %w", name, err)
+				}
+			}
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(base, ext), i, ext)
+		}
+
+		target := filepath.Join(dir, name)
+		taken[target] = true
+		targets[m.Path] = target
+	}
+	return targets, nil
+}
+
+// writeBundleMedia writes media files to the paths chosen by planBundleMedia and returns the
+// files written. A target created by someone else in the meantime is left alone and reported.
+func writeBundleMedia(media []BundleMedia, targets map[string]string, dir string) ([]string, error) {
+	written := []string{}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return written, fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	for _, m := range media {
+		target, ok := targets[m.Path]
+		if !ok {
+			continue
+		}
+		file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return written, fmt.Errorf("failed to create media %s: %w", target, err)
+		}
+		_, err = file.Write(m.Data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to write media %s: %w", target, err)
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+// bundleRefs maps bundle references to their imported values
+type bundleRefs struct {
+	scenarioIDs   map[string]string // bundle scenario ID -> imported ID
+	fragmentNames map[string]string // bundle fragment name -> imported name
+	mediaPaths    map[string]string // original media path -> extracted path
+}
+
+// rewriteBundleFlow remaps CallScenario scenario IDs, fragment names and media paths inside a flow document
+func rewriteBundleFlow(raw json.RawMessage, refs bundleRefs) (string, error) {
+	var flow map[string]interface{}
+	if err := json.Unmarshal(raw, &flow); err != nil {
+		return "", fmt.Errorf("failed to parse flow data: %w", err)
+	}
+
+	changed := false
+	for _, data := range flowNodeData(flow) {
+		if id, ok := data["scenarioId"].(string); ok {
+			if mapped, ok := refs.scenarioIDs[id]; ok && mapped != id {
+				data["scenarioId"] = mapped
+				changed = true
+			}
+		}
+		if name, ok := data["fragmentName"].(string); ok {
+			if mapped, ok := refs.fragmentNames[name]; ok && mapped != name {
+				data["fragmentName"] = mapped
+				changed = true
+			}
+		}
+		if path, ok := data["filePath"].(string); ok {
+			if mapped, ok := refs.mediaPaths[path]; ok {
+				data["filePath"] = mapped
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		// MarshalBundle indents embedded flow data; store it compact like the editor does
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return "", fmt.Errorf("failed to compact flow data: %w", err)
+		}
+		return buf.String(), nil
+	}
+
+	out, err := json.Marshal(flow)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode flow data: %w", err)
+	}
+	return string(out), nil
+}
+
+func uniqueFragmentName(tx *sql.Tx, projectID, name string, reserved map[string]bool) (string, error) {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if reserved[candidate] {
+			continue
+		}
+		var one int
+		err := tx.QueryRow(`SELECT 1 FROM fragments WHERE project_id = ? AND name = ?`, projectID, candidate).Scan(&one)
+		if errors.Is(err, sql.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check fragment name: %w", err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package scenario
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newBundleTestRepo(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestExportImportBundle_RoundTrip(t *testing.T) {
+	src := newBundleTestRepo(t)
+
+	sc, err := src.CreateScenario("default", "Basic Call")
+	if err != nil {
+		t.Fatalf("failed to create scenario: %v", err)
+	}
+	flow := `{"nodes":[{"id":"n1","type":"command","data":{"command":"MakeCall"}}],"edges":[]}`
+	if err := src.SaveScenario(sc.ID, flow); err != nil {
+		t.Fatalf("failed to save scenario: %v", err)
+	}
+	if _, err := src.CreateFragment("default", "login", `{"nodes":[],"edges":[]}`); err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+
+	bundle, err := src.ExportProject("default", ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportProject failed: %v", err)
+	}
+	if bundle.Project == nil || bundle.Project.ID != "default" {
+		t.Errorf("expected project 'default' in bundle, got %+v", bundle.Project)
+	}
+
+	data, err := MarshalBundle(bundle)
+	if err != nil {
+		t.Fatalf("MarshalBundle failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"command": "MakeCall"`) {
+		t.Errorf("expected flow data to be embedded as readable JSON, got:\n%s", data)
+	}
+
+	decoded, err := UnmarshalBundle(data)
+	if err != nil {
+		t.Fatalf("UnmarshalBundle failed: %v", err)
+	}
+
+	dst := newBundleTestRepo(t)
+	result, err := dst.ImportBundle("default", decoded, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+	if len(result.Imported) != 1 || result.Imported[0] != sc.ID {
+		t.Errorf("expected scenario %s imported with the same ID, got %v", sc.ID, result.Imported)
+	}
+	if result.Fragments != 1 {
+		t.Errorf("expected 1 fragment imported, got %d", result.Fragments)
+	}
+
+	loaded, err := dst.LoadScenario(sc.ID)
+	if err != nil {
+		t.Fatalf("failed to load imported scenario: %v", err)
+	}
+	if loaded.Name != "Basic Call" || loaded.FlowData != flow {
+		t.Errorf("imported scenario mismatch: name=%q flow=%q", loaded.Name, loaded.FlowData)
+	}
+	if _, err := dst.LoadFragmentByName("default", "login"); err != nil {
+		t.Errorf("expected fragment 'login' to be imported: %v", err)
+	}
+}
+
+func TestImportBundle_ConflictPolicies(t *testing.T) {
+	repo := newBundleTestRepo(t)
+
+	callee, err := repo.CreateScenario("default", "Callee")
+	if err != nil {
+		t.Fatalf("failed to create scenario: %v", err)
+	}
+	caller, err := repo.CreateScenario("default", "Caller")
+	if err != nil {
+		t.Fatalf("failed to create scenario: %v", err)
+	}
+	callerFlow := `{"nodes":[{"id":"c1","type":"command","data":{"command":"CallScenario","scenarioId":"` + callee.ID + `"}}],"edges":[]}`
+	if err := repo.SaveScenario(caller.ID, callerFlow); err != nil {
+		t.Fatalf("failed to save scenario: %v", err)
+	}
+
+	bundle, err := repo.ExportScenarios([]string{callee.ID, caller.ID}, ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportScenarios failed: %v", err)
+	}
+	bundle.Scenarios[0].Name = "Callee v2"
+
+	// skip: existing rows are untouched
+	result, err := repo.ImportBundle("default", bundle, ImportOptions{OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatalf("ImportBundle(skip) failed: %v", err)
+	}
+	if len(result.Skipped) != 2 || len(result.Imported) != 0 {
+		t.Errorf("expected 2 skipped, got imported=%v skipped=%v", result.Imported, result.Skipped)
+	}
+
+	// overwrite: existing rows are replaced in place
+	if _, err := repo.ImportBundle("default", bundle, ImportOptions{OnConflict: ConflictOverwrite}); err != nil {
+		t.Fatalf("ImportBundle(overwrite) failed: %v", err)
+	}
+	loaded, _ := repo.LoadScenario(callee.ID)
+	if loaded.Name != "Callee v2" {
+		t.Errorf("expected overwritten name 'Callee v2', got %q", loaded.Name)
+	}
+
+	// copy: new IDs are assigned and CallScenario references follow the copies
+	result, err = repo.ImportBundle("default", bundle, ImportOptions{OnConflict: ConflictCopy})
+	if err != nil {
+		t.Fatalf("ImportBundle(copy) failed: %v", err)
+	}
+	newCallee, ok := result.Renamed[callee.ID]
+	if !ok || newCallee == callee.ID {
+		t.Fatalf("expected callee to be copied with a new ID, got %v", result.Renamed)
+	}
+	newCaller := result.Renamed[caller.ID]
+	copied, err := repo.LoadScenario(newCaller)
+	if err != nil {
+		t.Fatalf("failed to load copied caller: %v", err)
+	}
+	if !strings.Contains(copied.FlowData, newCallee) {
+		t.Errorf("expected copied caller to reference %s, got %s", newCallee, copied.FlowData)
+	}
+
+	list, _ := repo.ListScenarios("default")
+	if len(list) != 4 {
+		t.Errorf("expected 4 scenarios after copy import, got %d", len(list))
+	}
+
+	if _, err := repo.ImportBundle("default", bundle, ImportOptions{OnConflict: "merge"}); err == nil {
+		t.Error("expected error for unknown conflict policy")
+	}
+
+	// overwrite into another project: the existing scenario stays in its project and a copy is imported
+	other, err := repo.CreateProject("Other")
+	if err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	result, err = repo.ImportBundle(other.ID, bundle, ImportOptions{OnConflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("ImportBundle(overwrite, other project) failed: %v", err)
+	}
+	if _, ok := result.Renamed[callee.ID]; !ok {
+		t.Errorf("expected scenario of another project to be copied, got renamed=%v", result.Renamed)
+	}
+	loaded, _ = repo.LoadScenario(callee.ID)
+	if loaded.ProjectID != "default" {
+		t.Errorf("expected existing scenario to stay in project default, got %q", loaded.ProjectID)
+	}
+}
+
+func TestImportBundle_CopyRemapsFragmentNames(t *testing.T) {
+	repo := newBundleTestRepo(t)
+
+	if _, err := repo.CreateFragment("default", "login", `{"nodes":[],"edges":[]}`); err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+	bundle := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		Scenarios: []BundleScenario{{
+			ID:       "caller",
+			Name:     "Caller",
+			FlowData: json.RawMessage(`{"nodes":[{"id":"c1","type":"command","data":{"command":"CallScenario","fragmentName":"login"}}],"edges":[]}`),
+		}},
+		Fragments: []BundleFragment{{Name: "login", FlowData: json.RawMessage(`{"nodes":[{"id":"r","type":"command","data":{"command":"Release"}}],"edges":[]}`)}},
+	}
+
+	if _, err := repo.ImportBundle("default", bundle, ImportOptions{OnConflict: ConflictCopy}); err != nil {
+		t.Fatalf("ImportBundle(copy) failed: %v", err)
+	}
+	if _, err := repo.LoadFragmentByName("default", "login (2)"); err != nil {
+		t.Fatalf("expected fragment copied as 'login (2)': %v", err)
+	}
+	loaded, err := repo.LoadScenario("caller")
+	if err != nil {
+		t.Fatalf("failed to load imported scenario: %v", err)
+	}
+	if !strings.Contains(loaded.FlowData, `"fragmentName":"login (2)"`) {
+		t.Errorf("expected CallScenario to reference the imported copy, got %s", loaded.FlowData)
+	}
+}
+
+func TestExportImportBundle_Media(t *testing.T) {
+	repo := newBundleTestRepo(t)
+
+	wavPath := filepath.Join(t.TempDir(), "greeting.wav")
+	if err := os.WriteFile(wavPath, []byte("RIFF-test"), 0644); err != nil {
+		t.Fatalf("failed to write media: %v", err)
+	}
+
+	sc, _ := repo.CreateScenario("default", "Play")
+	flow, _ := json.Marshal(map[string]interface{}{
+		"nodes": []interface{}{
+			map[string]interface{}{"id": "p1", "type": "command", "data": map[string]interface{}{"command": "PlayAudio", "filePath": wavPath}},
+		},
+		"edges": []interface{}{},
+	})
+	if err := repo.SaveScenario(sc.ID, string(flow)); err != nil {
+		t.Fatalf("failed to save scenario: %v", err)
+	}
+
+	bundle, err := repo.ExportScenarios([]string{sc.ID}, ExportOptions{IncludeMedia: true})
+	if err != nil {
+		t.Fatalf("ExportScenarios failed: %v", err)
+	}
+	if len(bundle.Media) != 1 || bundle.Media[0].Path != wavPath {
+		t.Fatalf("expected media for %s, got %+v", wavPath, bundle.Media)
+	}
+
+	mediaDir := filepath.Join(t.TempDir(), "media")
+	dst := newBundleTestRepo(t)
+	result, err := dst.ImportBundle("default", bundle, ImportOptions{MediaDir: mediaDir})
+	if err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+	if result.MediaFiles != 1 {
+		t.Errorf("expected 1 media file written, got %d", result.MediaFiles)
+	}
+
+	extracted := filepath.Join(mediaDir, "greeting.wav")
+	if content, err := os.ReadFile(extracted); err != nil || string(content) != "RIFF-test" {
+		t.Errorf("expected media extracted to %s: %v", extracted, err)
+	}
+
+	loaded, _ := dst.LoadScenario(sc.ID)
+	if !strings.Contains(loaded.FlowData, "greeting.wav") || strings.Contains(loaded.FlowData, wavPath) {
+		t.Errorf("expected filePath rewritten to extracted media, got %s", loaded.FlowData)
+	}
+
+	// a skipped scenario extracts no media
+	skipped, err := dst.ImportBundle("default", bundle, ImportOptions{MediaDir: mediaDir, OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatalf("skipping ImportBundle failed: %v", err)
+	}
+	if len(skipped.Skipped) != 1 || skipped.MediaFiles != 0 {
+		t.Errorf("expected the scenario skipped without media, got %+v", skipped)
+	}
+	if entries, _ := os.ReadDir(mediaDir); len(entries) != 1 {
+		t.Errorf("expected skipped import to leave 1 media file, got %d", len(entries))
+	}
+
+	// a second import keeps the existing file and writes the new one next to it
+	bundle.Media[0].Data = []byte("RIFF-other")
+	if _, err := newBundleTestRepo(t).ImportBundle("default", bundle, ImportOptions{MediaDir: mediaDir}); err != nil {
+		t.Fatalf("second ImportBundle failed: %v", err)
+	}
+	if content, _ := os.ReadFile(extracted); string(content) != "RIFF-test" {
+		t.Errorf("expected existing media to be kept, got %q", content)
+	}
+	if content, err := os.ReadFile(filepath.Join(mediaDir, "greeting-2.wav")); err != nil || string(content) != "RIFF-other" {
+		t.Errorf("expected new media written as greeting-2.wav: %v", err)
+	}
+
+	// a failed import writes no media
+	bundle.Scenarios[0].FlowData = json.RawMessage(`{"nodes":`)
+	if _, err := newBundleTestRepo(t).ImportBundle("default", bundle, ImportOptions{MediaDir: mediaDir}); err == nil {
+		t.Fatal("expected import with invalid flow data to fail")
+	}
+	entries, _ := os.ReadDir(mediaDir)
+	if len(entries) != 2 {
+		t.Errorf("expected failed import to leave 2 media files, got %d", len(entries))
+	}
+}
+
+func TestUnmarshalBundle_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `not json`},
+		{"missing version", `{"scenarios":[]}`},
+		{"future version", `{"format_version":99,"scenarios":[]}`},
+		{"missing id", `{"format_version":1,"scenarios":[{"name":"x","flow_data":{}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UnmarshalBundle([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
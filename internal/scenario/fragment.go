@@ -0,0 +1,152 @@
+package scenario
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateFragment creates a new named flow fragment in the given project
+func (r *Repository) CreateFragment(projectID, name, flowData string) (*Fragment, error) {
+	id := uuid.New().String()
+	now := time.Now()
+	if flowData == "" {
+		flowData = "{}"
+	}
+
+	query := `
+		INSERT INTO fragments (id, project_id, name, flow_data, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query, id, projectID, name, flowData, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fragment: %w", err)
+	}
+
+	return &Fragment{
+		ID:        id,
+		ProjectID: projectID,
+		Name:      name,
+		FlowData:  flowData,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// SaveFragment updates the flow data for an existing fragment
+func (r *Repository) SaveFragment(id, flowData string) error {
+	query := `
+		UPDATE fragments
+		SET flow_data = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, flowData, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to save fragment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// LoadFragment retrieves a fragment by ID
+func (r *Repository) LoadFragment(id string) (*Fragment, error) {
+	query := `
+		SELECT id, project_id, name, flow_data, created_at, updated_at
+		FROM fragments
+		WHERE id = ?
+	`
+
+	var f Fragment
+	err := r.db.QueryRow(query, id).Scan(
+		&f.ID, &f.ProjectID, &f.Name, &f.FlowData, &f.CreatedAt, &f.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// LoadFragmentByName retrieves a fragment by its name within a project
+func (r *Repository) LoadFragmentByName(projectID, name string) (*Fragment, error) {
+	query := `
+		SELECT id, project_id, name, flow_data, created_at, updated_at
+		FROM fragments
+		WHERE project_id = ? AND name = ?
+	`
+
+	var f Fragment
+	err := r.db.QueryRow(query, projectID, name).Scan(
+		&f.ID, &f.ProjectID, &f.Name, &f.FlowData, &f.CreatedAt, &f.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// ListFragments retrieves all fragments for a project, ordered by name
+func (r *Repository) ListFragments(projectID string) ([]Fragment, error) {
+	query := `
+		SELECT id, project_id, name, flow_data, created_at, updated_at
+		FROM fragments
+		WHERE project_id = ?
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fragments: %w", err)
+	}
+	defer rows.Close()
+
+	fragments := []Fragment{}
+	for rows.Next() {
+		var f Fragment
+		if err := rows.Scan(&f.ID, &f.ProjectID, &f.Name, &f.FlowData, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fragment: %w", err)
+		}
+		fragments = append(fragments, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fragments: %w", err)
+	}
+
+	return fragments, nil
+}
+
+// DeleteFragment removes a fragment by ID
+func (r *Repository) DeleteFragment(id string) error {
+	query := `DELETE FROM fragments WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete fragment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
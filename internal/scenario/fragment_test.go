@@ -0,0 +1,103 @@
+package scenario
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateAndLoadFragment(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	repo, err := NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	fragment, err := repo.CreateFragment("default", "call-and-play", `{"nodes":[],"edges":[]}`)
+	if err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+
+	loaded, err := repo.LoadFragmentByName("default", "call-and-play")
+	if err != nil {
+		t.Fatalf("failed to load fragment by name: %v", err)
+	}
+	if loaded.ID != fragment.ID {
+		t.Errorf("expected fragment ID %s, got %s", fragment.ID, loaded.ID)
+	}
+	if loaded.FlowData != `{"nodes":[],"edges":[]}` {
+		t.Errorf("unexpected flow_data: %s", loaded.FlowData)
+	}
+
+	if err := repo.SaveFragment(fragment.ID, `{"nodes":[{"id":"n1"}],"edges":[]}`); err != nil {
+		t.Fatalf("failed to save fragment: %v", err)
+	}
+	loaded, err = repo.LoadFragment(fragment.ID)
+	if err != nil {
+		t.Fatalf("failed to load fragment: %v", err)
+	}
+	if loaded.FlowData != `{"nodes":[{"id":"n1"}],"edges":[]}` {
+		t.Errorf("expected updated flow_data, got %s", loaded.FlowData)
+	}
+}
+
+func TestCreateFragmentDuplicateName(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	repo, err := NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	if _, err := repo.CreateFragment("default", "dup", ""); err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+	if _, err := repo.CreateFragment("default", "dup", ""); err == nil {
+		t.Error("expected duplicate fragment name to fail")
+	}
+}
+
+func TestListAndDeleteFragments(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	repo, err := NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	second, err := repo.CreateFragment("default", "b-release", "")
+	if err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+	if _, err := repo.CreateFragment("default", "a-dial", ""); err != nil {
+		t.Fatalf("failed to create fragment: %v", err)
+	}
+
+	fragments, err := repo.ListFragments("default")
+	if err != nil {
+		t.Fatalf("failed to list fragments: %v", err)
+	}
+	if len(fragments) != 2 {
+		t.Fatalf("expected 2 fragments, got %d", len(fragments))
+	}
+	if fragments[0].Name != "a-dial" || fragments[1].Name != "b-release" {
+		t.Errorf("expected fragments ordered by name, got %s, %s", fragments[0].Name, fragments[1].Name)
+	}
+
+	if err := repo.DeleteFragment(second.ID); err != nil {
+		t.Fatalf("failed to delete fragment: %v", err)
+	}
+	if _, err := repo.LoadFragment(second.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows after delete, got %v", err)
+	}
+	if err := repo.DeleteFragment(second.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+}
@@ -10,6 +10,33 @@ type Project struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ProjectSettings holds project-scoped defaults applied to SIP instances that leave them unset
+type ProjectSettings struct {
+	ProjectID    string       `json:"project_id"`
+	PBXHost      string       `json:"pbx_host"`
+	PBXPort      string       `json:"pbx_port"`
+	PBXTransport string       `json:"pbx_transport"`
+	Codecs       []string     `json:"codecs"`
+	FeatureCodes FeatureCodes `json:"feature_codes"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// FeatureCodes holds the PBX feature-code templates dialed by the Park, Unpark, Pickup and
+// SetForwarding nodes. {slot}, {target} and {number} placeholders are filled in when the node
+// runs; an empty template falls back to the engine default.
+type FeatureCodes struct {
+	Park               string `json:"park"`
+	ParkSlotHeader     string `json:"park_slot_header"` // response header carrying the assigned park slot
+	Unpark             string `json:"unpark"`
+	Pickup             string `json:"pickup"`
+	ForwardAlwaysOn    string `json:"forward_always_on"`
+	ForwardAlwaysOff   string `json:"forward_always_off"`
+	ForwardBusyOn      string `json:"forward_busy_on"`
+	ForwardBusyOff     string `json:"forward_busy_off"`
+	ForwardNoAnswerOn  string `json:"forward_no_answer_on"`
+	ForwardNoAnswerOff string `json:"forward_no_answer_off"`
+}
+
 // Scenario represents a single test scenario with flow data
 type Scenario struct {
 	ID        string    `json:"id"`
@@ -28,3 +55,121 @@ type ScenarioListItem struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// Fragment represents a named, reusable flow fragment embedded by CallScenario nodes
+type Fragment struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Name      string    `json:"name"`
+	FlowData  string    `json:"flow_data"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Revision is a saved snapshot of a scenario's flow data
+type Revision struct {
+	ID         int64     `json:"id"`
+	ScenarioID string    `json:"scenario_id"`
+	Revision   int       `json:"revision"`
+	Message    string    `json:"message"`
+	FlowData   string    `json:"flow_data"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RevisionListItem represents a revision without flow data, for list queries
+type RevisionListItem struct {
+	ID         int64     `json:"id"`
+	ScenarioID string    `json:"scenario_id"`
+	Revision   int       `json:"revision"`
+	Message    string    `json:"message"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Suite is an ordered collection of scenarios run sequentially with one aggregated report
+type Suite struct {
+	ID              string                 `json:"id"`
+	ProjectID       string                 `json:"project_id"`
+	Name            string                 `json:"name"`
+	SetupScenarioID string                 `json:"setup_scenario_id"` // run once before the entries; empty for none
+	Variables       map[string]interface{} `json:"variables"`         // shared by the setup scenario and every entry
+	StopOnFailure   bool                   `json:"stop_on_failure"`
+	Retries         int                    `json:"retries"`         // extra attempts for a failing entry
+	TimeoutSeconds  int                    `json:"timeout_seconds"` // per entry attempt; 0 for no limit
+	Entries         []SuiteEntry           `json:"entries"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// SuiteEntry is one scenario of a suite with variables overriding the suite's shared ones
+type SuiteEntry struct {
+	ScenarioID string                 `json:"scenario_id"`
+	Variables  map[string]interface{} `json:"variables"`
+}
+
+// SuiteListItem represents a suite without its entries, for list queries
+type SuiteListItem struct {
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"project_id"`
+	Name       string    `json:"name"`
+	EntryCount int       `json:"entry_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Schedule target kinds
+const (
+	ScheduleTargetScenario = "scenario"
+	ScheduleTargetSuite    = "suite"
+)
+
+// Schedule runs a scenario or suite in the background on a cron-like schedule
+type Schedule struct {
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"project_id"`
+	Name       string    `json:"name"`
+	Cron       string    `json:"cron"`
+	TargetKind string    `json:"target_kind"`
+	TargetID   string    `json:"target_id"`
+	Enabled    bool      `json:"enabled"`
+	NotifyURL  string    `json:"notify_url"` // webhook POSTed when a run fails; empty disables it
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ScheduleRun is the recorded result of one scheduled run
+type ScheduleRun struct {
+	ID         int64     `json:"id"`
+	ScheduleID string    `json:"schedule_id"`
+	RunID      string    `json:"run_id"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Change kinds reported by a FlowDiff
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "changed"
+)
+
+// FlowDiff is a structural diff between two flow data documents
+type FlowDiff struct {
+	Nodes []ElementDiff `json:"nodes"`
+	Edges []ElementDiff `json:"edges"`
+}
+
+// ElementDiff describes how a single node or edge changed
+type ElementDiff struct {
+	ID     string        `json:"id"`
+	Change string        `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a field-level change; Before or After is nil when the field was added or removed
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
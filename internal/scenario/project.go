@@ -0,0 +1,282 @@
+package scenario
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultProjectID is the seeded project that always exists and cannot be deleted
+const DefaultProjectID = "default"
+
+// ErrDefaultProject is returned when attempting to delete the default project
+var ErrDefaultProject = errors.New("the default project cannot be deleted")
+
+// CreateProject creates a new project with the given name
+func (r *Repository) CreateProject(name string) (*Project, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	query := `
+		INSERT INTO projects (id, name, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query, id, name, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &Project{
+		ID:        id,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// LoadProject retrieves a project by ID
+func (r *Repository) LoadProject(id string) (*Project, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM projects
+		WHERE id = ?
+	`
+
+	var p Project
+	err := r.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// ListProjects retrieves all projects, with the default project first and the rest ordered by name
+func (r *Repository) ListProjects() ([]Project, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM projects
+		ORDER BY id <> 'default', name ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var p Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// RenameProject updates the name of a project
+func (r *Repository) RenameProject(id, newName string) error {
+	query := `
+		UPDATE projects
+		SET name = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, newName, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to rename project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteProject removes a project together with its scenarios, fragments and settings
+func (r *Repository) DeleteProject(id string) error {
+	if id == DefaultProjectID {
+		return ErrDefaultProject
+	}
+
+	query := `DELETE FROM projects WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MoveScenario moves a scenario to another project
+func (r *Repository) MoveScenario(id, projectID string) error {
+	query := `
+		UPDATE scenarios
+		SET project_id = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, projectID, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to move scenario: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CopyScenario copies a scenario into a project under a new ID and records the copy's initial revision.
+// An empty name keeps the source scenario's name.
+func (r *Repository) CopyScenario(id, projectID, name string) (*Scenario, error) {
+	source, err := r.LoadScenario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = source.Name
+	}
+
+	copied := &Scenario{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		Name:      name,
+		FlowData:  source.FlowData,
+		CreatedAt: time.Now(),
+	}
+	copied.UpdatedAt = copied.CreatedAt
+
+	query := `
+		INSERT INTO scenarios (id, project_id, name, flow_data, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin copy: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query, copied.ID, copied.ProjectID, copied.Name, copied.FlowData, copied.CreatedAt, copied.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy scenario: %w", err)
+	}
+
+	if err := recordRevision(tx, copied.ID, copied.FlowData, fmt.Sprintf("Copied from %s", source.Name), copied.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit copy: %w", err)
+	}
+
+	return copied, nil
+}
+
+// LoadProjectSettings retrieves the settings for a project.
+// A project without saved settings returns empty settings.
+func (r *Repository) LoadProjectSettings(projectID string) (*ProjectSettings, error) {
+	if _, err := r.LoadProject(projectID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT pbx_host, pbx_port, pbx_transport, codecs, feature_codes, updated_at
+		FROM project_settings
+		WHERE project_id = ?
+	`
+
+	settings := &ProjectSettings{ProjectID: projectID, Codecs: []string{}}
+	var codecs, featureCodes string
+	err := r.db.QueryRow(query, projectID).Scan(
+		&settings.PBXHost, &settings.PBXPort, &settings.PBXTransport, &codecs, &featureCodes, &settings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project settings: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(codecs), &settings.Codecs); err != nil {
+		return nil, fmt.Errorf("failed to decode project codecs: %w", err)
+	}
+	if err := json.Unmarshal([]byte(featureCodes), &settings.FeatureCodes); err != nil {
+		return nil, fmt.Errorf("failed to decode project feature codes: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveProjectSettings creates or replaces the settings for a project
+func (r *Repository) SaveProjectSettings(settings *ProjectSettings) error {
+	codecs := settings.Codecs
+	if codecs == nil {
+		codecs = []string{}
+	}
+	encoded, err := json.Marshal(codecs)
+	if err != nil {
+		return fmt.Errorf("failed to encode project codecs: %w", err)
+	}
+	featureCodes, err := json.Marshal(settings.FeatureCodes)
+	if err != nil {
+		return fmt.Errorf("failed to encode project feature codes: %w", err)
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO project_settings (project_id, pbx_host, pbx_port, pbx_transport, codecs, feature_codes, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(project_id) DO UPDATE SET
+			pbx_host = excluded.pbx_host,
+			pbx_port = excluded.pbx_port,
+			pbx_transport = excluded.pbx_transport,
+			codecs = excluded.codecs,
+			feature_codes = excluded.feature_codes,
+			updated_at = excluded.updated_at
+	`
+
+	_, err = r.db.Exec(query, settings.ProjectID, settings.PBXHost, settings.PBXPort, settings.PBXTransport, string(encoded), string(featureCodes), now)
+	if err != nil {
+		return fmt.Errorf("failed to save project settings: %w", err)
+	}
+
+	settings.UpdatedAt = now
+	return nil
+}
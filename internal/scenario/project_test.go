@@ -0,0 +1,142 @@
+package scenario
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectCRUD(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	project, err := repo.CreateProject("Customer A")
+	if err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	if err := repo.RenameProject(project.ID, "Customer A (lab)"); err != nil {
+		t.Fatalf("failed to rename project: %v", err)
+	}
+
+	projects, err := repo.ListProjects()
+	if err != nil {
+		t.Fatalf("failed to list projects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	if projects[0].ID != DefaultProjectID {
+		t.Errorf("expected default project first, got %s", projects[0].ID)
+	}
+	if projects[1].Name != "Customer A (lab)" {
+		t.Errorf("expected renamed project, got %q", projects[1].Name)
+	}
+
+	if _, err := repo.CreateScenario(project.ID, "Call"); err != nil {
+		t.Fatalf("failed to create scenario: %v", err)
+	}
+
+	if err := repo.DeleteProject(project.ID); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+	scenarios, _ := repo.ListScenarios(project.ID)
+	if len(scenarios) != 0 {
+		t.Errorf("expected scenarios to be deleted with the project, got %d", len(scenarios))
+	}
+
+	if err := repo.DeleteProject(DefaultProjectID); !errors.Is(err, ErrDefaultProject) {
+		t.Errorf("expected ErrDefaultProject, got %v", err)
+	}
+	if err := repo.RenameProject("missing", "x"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestMoveAndCopyScenario(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	target, _ := repo.CreateProject("Target")
+	sc, _ := repo.CreateScenario(DefaultProjectID, "Original")
+	if err := repo.SaveScenario(sc.ID, `{"nodes":[],"edges":[]}`); err != nil {
+		t.Fatalf("failed to save scenario: %v", err)
+	}
+
+	copied, err := repo.CopyScenario(sc.ID, target.ID, "")
+	if err != nil {
+		t.Fatalf("failed to copy scenario: %v", err)
+	}
+	if copied.ID == sc.ID || copied.ProjectID != target.ID || copied.Name != "Original" {
+		t.Errorf("unexpected copy: %+v", copied)
+	}
+	if copied.FlowData != `{"nodes":[],"edges":[]}` {
+		t.Errorf("expected flow data to be copied, got %q", copied.FlowData)
+	}
+
+	if err := repo.MoveScenario(sc.ID, target.ID); err != nil {
+		t.Fatalf("failed to move scenario: %v", err)
+	}
+	moved, _ := repo.LoadScenario(sc.ID)
+	if moved.ProjectID != target.ID {
+		t.Errorf("expected scenario in project %s, got %s", target.ID, moved.ProjectID)
+	}
+
+	if err := repo.MoveScenario(sc.ID, "missing-project"); err == nil {
+		t.Error("expected foreign key error when moving to unknown project")
+	}
+}
+
+func TestProjectSettings(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	settings, err := repo.LoadProjectSettings(DefaultProjectID)
+	if err != nil {
+		t.Fatalf("failed to load empty settings: %v", err)
+	}
+	if settings.PBXHost != "" || len(settings.Codecs) != 0 {
+		t.Errorf("expected empty settings, got %+v", settings)
+	}
+
+	settings.PBXHost = "10.0.0.5"
+	settings.PBXTransport = "TCP"
+	settings.Codecs = []string{"PCMA", "telephone-event"}
+	settings.FeatureCodes = FeatureCodes{Park: "*85{slot}", Pickup: "**{target}"}
+	if err := repo.SaveProjectSettings(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	settings.PBXHost = "10.0.0.6"
+	if err := repo.SaveProjectSettings(settings); err != nil {
+		t.Fatalf("failed to update settings: %v", err)
+	}
+
+	loaded, err := repo.LoadProjectSettings(DefaultProjectID)
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if loaded.PBXHost != "10.0.0.6" || loaded.PBXTransport != "TCP" {
+		t.Errorf("unexpected settings: %+v", loaded)
+	}
+	if len(loaded.Codecs) != 2 || loaded.Codecs[0] != "PCMA" {
+		t.Errorf("expected codecs [PCMA telephone-event], got %v", loaded.Codecs)
+	}
+	if loaded.FeatureCodes.Park != "*85{slot}" || loaded.FeatureCodes.Pickup != "**{target}" || loaded.FeatureCodes.Unpark != "" {
+		t.Errorf("unexpected feature codes: %+v", loaded.FeatureCodes)
+	}
+
+	if _, err := repo.LoadProjectSettings("missing"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for unknown project, got %v", err)
+	}
+}
@@ -16,7 +16,7 @@ type Repository struct {
 
 // NewRepository creates a new scenario repository with the given database path
 func NewRepository(dbPath string) (*Repository, error) {
-	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)", dbPath)
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", dbPath)
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -50,6 +50,80 @@ func (r *Repository) initTables() error {
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS fragments (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		flow_data TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (project_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS scenario_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scenario_id TEXT NOT NULL REFERENCES scenarios(id) ON DELETE CASCADE,
+		revision INTEGER NOT NULL,
+		message TEXT NOT NULL DEFAULT '',
+		flow_data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (scenario_id, revision)
+	);
+
+	CREATE TABLE IF NOT EXISTS project_settings (
+		project_id TEXT PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+		pbx_host TEXT NOT NULL DEFAULT '',
+		pbx_port TEXT NOT NULL DEFAULT '',
+		pbx_transport TEXT NOT NULL DEFAULT '',
+		codecs TEXT NOT NULL DEFAULT '[]',
+		feature_codes TEXT NOT NULL DEFAULT '{}',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS suites (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		setup_scenario_id TEXT NOT NULL DEFAULT '',
+		variables TEXT NOT NULL DEFAULT '{}',
+		stop_on_failure INTEGER NOT NULL DEFAULT 0,
+		retries INTEGER NOT NULL DEFAULT 0,
+		timeout_seconds INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS suite_entries (
+		suite_id TEXT NOT NULL REFERENCES suites(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		scenario_id TEXT NOT NULL REFERENCES scenarios(id) ON DELETE CASCADE,
+		variables TEXT NOT NULL DEFAULT '{}',
+		PRIMARY KEY (suite_id, position)
+	);
+
+	CREATE TABLE IF NOT EXISTS schedules (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		cron TEXT NOT NULL,
+		target_kind TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		notify_url TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS schedule_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		schedule_id TEXT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+		run_id TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL
+	);
+
 	INSERT OR IGNORE INTO projects (id, name) VALUES ('default', 'Default Project');
 	`
 
@@ -61,21 +135,35 @@ func (r *Repository) initTables() error {
 	return nil
 }
 
-// CreateScenario creates a new scenario with the given name
+// CreateScenario creates a new scenario with the given name and records its initial revision
 func (r *Repository) CreateScenario(projectID, name string) (*Scenario, error) {
 	id := uuid.New().String()
 	now := time.Now()
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin create: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO scenarios (id, project_id, name, flow_data, created_at, updated_at)
 		VALUES (?, ?, ?, '{}', ?, ?)
 	`
 
-	_, err := r.db.Exec(query, id, projectID, name, now, now)
+	_, err = tx.Exec(query, id, projectID, name, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scenario: %w", err)
 	}
 
+	if err := recordRevision(tx, id, "{}", "Created", now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit create: %w", err)
+	}
+
 	return &Scenario{
 		ID:        id,
 		ProjectID: projectID,
@@ -86,15 +174,32 @@ func (r *Repository) CreateScenario(projectID, name string) (*Scenario, error) {
 	}, nil
 }
 
-// SaveScenario updates the flow data for an existing scenario
+// SaveScenario updates the flow data for an existing scenario and records a revision
 func (r *Repository) SaveScenario(id, flowData string) error {
+	return r.SaveScenarioWithMessage(id, flowData, "")
+}
+
+// SaveScenarioWithMessage updates the flow data for an existing scenario and records
+// a revision carrying the given message
+func (r *Repository) SaveScenarioWithMessage(id, flowData, message string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin save: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if err := recordBaselineRevision(tx, id, now); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE scenarios
 		SET flow_data = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query, flowData, time.Now(), id)
+	result, err := tx.Exec(query, flowData, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to save scenario: %w", err)
 	}
@@ -108,6 +213,14 @@ func (r *Repository) SaveScenario(id, flowData string) error {
 		return sql.ErrNoRows
 	}
 
+	if err := recordRevision(tx, id, flowData, message, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit save: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,246 @@
+package scenario
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// recordRevision appends a revision for a scenario inside the caller's transaction.
+// A save without a message whose flow data matches the latest revision is not recorded again,
+// so autosaves of an unchanged flow don't flood the history.
+func recordRevision(tx *sql.Tx, scenarioID, flowData, message string, now time.Time) error {
+	var latest int
+	var latestFlow sql.NullString
+	err := tx.QueryRow(`
+		SELECT revision, flow_data
+		FROM scenario_revisions
+		WHERE scenario_id = ?
+		ORDER BY revision DESC
+		LIMIT 1
+	`, scenarioID).Scan(&latest, &latestFlow)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to load latest revision: %w", err)
+	}
+
+	if message == "" && latestFlow.Valid && latestFlow.String == flowData {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO scenario_revisions (scenario_id, revision, message, flow_data, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, scenarioID, latest+1, message, flowData, now)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
+// recordBaselineRevision records the scenario's current flow data as a "Baseline" revision
+// when it has no history yet, so scenarios created before revisions existed keep their
+// pre-upgrade flow restorable once they are overwritten. Call it before changing flow_data.
+func recordBaselineRevision(tx *sql.Tx, scenarioID string, now time.Time) error {
+	var flowData string
+	err := tx.QueryRow(`
+		SELECT s.flow_data
+		FROM scenarios s
+		WHERE s.id = ?
+		AND NOT EXISTS (SELECT 1 FROM scenario_revisions r WHERE r.scenario_id = s.id)
+	`, scenarioID).Scan(&flowData)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load baseline flow: %w", err)
+	}
+
+	return recordRevision(tx, scenarioID, flowData, "Baseline", now)
+}
+
+// ListRevisions retrieves the revision history of a scenario, newest first
+func (r *Repository) ListRevisions(scenarioID string) ([]RevisionListItem, error) {
+	query := `
+		SELECT id, scenario_id, revision, message, created_at
+		FROM scenario_revisions
+		WHERE scenario_id = ?
+		ORDER BY revision DESC
+	`
+
+	rows, err := r.db.Query(query, scenarioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []RevisionListItem{}
+	for rows.Next() {
+		var rev RevisionListItem
+		if err := rows.Scan(&rev.ID, &rev.ScenarioID, &rev.Revision, &rev.Message, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// LoadRevision retrieves a single revision including its flow data
+func (r *Repository) LoadRevision(id int64) (*Revision, error) {
+	query := `
+		SELECT id, scenario_id, revision, message, flow_data, created_at
+		FROM scenario_revisions
+		WHERE id = ?
+	`
+
+	var rev Revision
+	err := r.db.QueryRow(query, id).Scan(
+		&rev.ID, &rev.ScenarioID, &rev.Revision, &rev.Message, &rev.FlowData, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// RestoreRevision makes a past revision the scenario's current flow data.
+// The restore is itself recorded as a new revision so nothing in the history is lost.
+func (r *Repository) RestoreRevision(id int64) error {
+	rev, err := r.LoadRevision(id)
+	if err != nil {
+		return err
+	}
+
+	return r.SaveScenarioWithMessage(rev.ScenarioID, rev.FlowData, fmt.Sprintf("Restored revision %d", rev.Revision))
+}
+
+// DiffRevisions computes a structural diff from one revision to another of the same scenario
+func (r *Repository) DiffRevisions(fromID, toID int64) (*FlowDiff, error) {
+	from, err := r.LoadRevision(fromID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revision %d: %w", fromID, err)
+	}
+	to, err := r.LoadRevision(toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revision %d: %w", toID, err)
+	}
+	if from.ScenarioID != to.ScenarioID {
+		return nil, fmt.Errorf("revisions %d and %d belong to different scenarios", fromID, toID)
+	}
+
+	return DiffFlowData(from.FlowData, to.FlowData)
+}
+
+// DiffFlowData computes a structural diff between two flow data documents.
+// Nodes and edges are matched by ID; changed elements list field-level changes
+// using dotted paths such as "data.command" or "position.x".
+func DiffFlowData(before, after string) (*FlowDiff, error) {
+	beforeNodes, beforeEdges, err := flowElements(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base flow data: %w", err)
+	}
+	afterNodes, afterEdges, err := flowElements(after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target flow data: %w", err)
+	}
+
+	return &FlowDiff{
+		Nodes: diffElements(beforeNodes, afterNodes),
+		Edges: diffElements(beforeEdges, afterEdges),
+	}, nil
+}
+
+// flowElements indexes the nodes and edges of a flow document by ID
+func flowElements(flowData string) (map[string]map[string]interface{}, map[string]map[string]interface{}, error) {
+	var flow struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Edges []map[string]interface{} `json:"edges"`
+	}
+	if flowData != "" {
+		if err := json.Unmarshal([]byte(flowData), &flow); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	index := func(elements []map[string]interface{}) map[string]map[string]interface{} {
+		out := make(map[string]map[string]interface{}, len(elements))
+		for _, el := range elements {
+			if id, ok := el["id"].(string); ok {
+				out[id] = el
+			}
+		}
+		return out
+	}
+
+	return index(flow.Nodes), index(flow.Edges), nil
+}
+
+func diffElements(before, after map[string]map[string]interface{}) []ElementDiff {
+	diffs := []ElementDiff{}
+
+	for id, b := range before {
+		a, exists := after[id]
+		if !exists {
+			diffs = append(diffs, ElementDiff{ID: id, Change: ChangeRemoved})
+			continue
+		}
+		fields := []FieldChange{}
+		diffFields("", b, a, &fields)
+		if len(fields) > 0 {
+			sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+			diffs = append(diffs, ElementDiff{ID: id, Change: ChangeModified, Fields: fields})
+		}
+	}
+
+	for id := range after {
+		if _, exists := before[id]; !exists {
+			diffs = append(diffs, ElementDiff{ID: id, Change: ChangeAdded})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].ID < diffs[j].ID })
+	return diffs
+}
+
+// diffFields walks nested objects and records leaf values that differ.
+// Arrays and scalars are compared as whole values.
+func diffFields(prefix string, before, after map[string]interface{}, out *[]FieldChange) {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		b, bok := before[k]
+		a, aok := after[k]
+		bMap, bIsMap := b.(map[string]interface{})
+		aMap, aIsMap := a.(map[string]interface{})
+		if bIsMap && aIsMap {
+			diffFields(path, bMap, aMap, out)
+			continue
+		}
+
+		if bok && aok && reflect.DeepEqual(b, a) {
+			continue
+		}
+		*out = append(*out, FieldChange{Path: path, Before: b, After: a})
+	}
+}
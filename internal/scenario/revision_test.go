@@ -0,0 +1,184 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveScenario_RecordsRevisions(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	sc, _ := repo.CreateScenario("default", "History")
+	v1 := `{"nodes":[{"id":"n1","type":"command","data":{"command":"MakeCall"}}],"edges":[]}`
+	v2 := `{"nodes":[{"id":"n1","type":"command","data":{"command":"Release"}}],"edges":[]}`
+
+	if err := repo.SaveScenario(sc.ID, v1); err != nil {
+		t.Fatalf("save v1 failed: %v", err)
+	}
+	// identical autosave is not recorded again
+	if err := repo.SaveScenario(sc.ID, v1); err != nil {
+		t.Fatalf("save v1 again failed: %v", err)
+	}
+	if err := repo.SaveScenarioWithMessage(sc.ID, v2, "switch to release"); err != nil {
+		t.Fatalf("save v2 failed: %v", err)
+	}
+
+	revisions, err := repo.ListRevisions(sc.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions failed: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Revision != 3 || revisions[0].Message != "switch to release" {
+		t.Errorf("expected newest revision 3 with message, got %+v", revisions[0])
+	}
+	if revisions[2].Revision != 1 || revisions[2].Message != "Created" {
+		t.Errorf("expected creation recorded as revision 1, got %+v", revisions[2])
+	}
+
+	if err := repo.RestoreRevision(revisions[1].ID); err != nil {
+		t.Fatalf("RestoreRevision failed: %v", err)
+	}
+
+	loaded, _ := repo.LoadScenario(sc.ID)
+	if loaded.FlowData != v1 {
+		t.Errorf("expected restored flow data v1, got %s", loaded.FlowData)
+	}
+
+	revisions, _ = repo.ListRevisions(sc.ID)
+	if len(revisions) != 4 || revisions[0].Message != "Restored revision 2" {
+		t.Errorf("expected restore recorded as revision 4, got %+v", revisions)
+	}
+
+	copied, err := repo.CopyScenario(sc.ID, "default", "History copy")
+	if err != nil {
+		t.Fatalf("CopyScenario failed: %v", err)
+	}
+	copyRevisions, _ := repo.ListRevisions(copied.ID)
+	if len(copyRevisions) != 1 || copyRevisions[0].Message != "Copied from History" {
+		t.Fatalf("expected copy to record an initial revision, got %+v", copyRevisions)
+	}
+	if _, err := repo.DiffRevisions(revisions[0].ID, copyRevisions[0].ID); err == nil {
+		t.Error("expected diff across scenarios to be rejected")
+	}
+
+	if err := repo.DeleteScenario(sc.ID); err != nil {
+		t.Fatalf("DeleteScenario failed: %v", err)
+	}
+	revisions, _ = repo.ListRevisions(sc.ID)
+	if len(revisions) != 0 {
+		t.Errorf("expected revisions removed with scenario, got %d", len(revisions))
+	}
+}
+
+func TestSaveScenario_RecordsBaselineForScenarioWithoutHistory(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	// a scenario saved before revisions existed has no history rows
+	original := `{"nodes":[{"id":"n1","type":"command","data":{"command":"MakeCall"}}],"edges":[]}`
+	now := time.Now()
+	if _, err := repo.db.Exec(`
+		INSERT INTO scenarios (id, project_id, name, flow_data, created_at, updated_at)
+		VALUES ('legacy', 'default', 'Legacy', ?, ?, ?)
+	`, original, now, now); err != nil {
+		t.Fatalf("failed to insert legacy scenario: %v", err)
+	}
+
+	updated := `{"nodes":[{"id":"n1","type":"command","data":{"command":"Release"}}],"edges":[]}`
+	if err := repo.SaveScenario("legacy", updated); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+
+	revisions, err := repo.ListRevisions("legacy")
+	if err != nil {
+		t.Fatalf("ListRevisions failed: %v", err)
+	}
+	if len(revisions) != 2 || revisions[1].Revision != 1 || revisions[1].Message != "Baseline" {
+		t.Fatalf("expected a baseline revision before the save, got %+v", revisions)
+	}
+	baseline, err := repo.LoadRevision(revisions[1].ID)
+	if err != nil {
+		t.Fatalf("LoadRevision failed: %v", err)
+	}
+	if baseline.FlowData != original {
+		t.Errorf("expected baseline to keep the pre-upgrade flow, got %s", baseline.FlowData)
+	}
+
+	if err := repo.SaveScenario("legacy", original); err != nil {
+		t.Fatalf("second SaveScenario failed: %v", err)
+	}
+	if revisions, _ := repo.ListRevisions("legacy"); len(revisions) != 3 {
+		t.Errorf("expected baseline recorded only once, got %d revisions", len(revisions))
+	}
+}
+
+func TestDiffFlowData(t *testing.T) {
+	before := `{
+		"nodes": [
+			{"id": "n1", "type": "command", "position": {"x": 0, "y": 0}, "data": {"command": "MakeCall", "targetUri": "sip:100@pbx"}},
+			{"id": "n2", "type": "event", "data": {"event": "RINGING"}}
+		],
+		"edges": [
+			{"id": "e1", "source": "n1", "target": "n2"}
+		]
+	}`
+	after := `{
+		"nodes": [
+			{"id": "n1", "type": "command", "position": {"x": 0, "y": 40}, "data": {"command": "MakeCall", "targetUri": "sip:200@pbx", "timeout": 5000}},
+			{"id": "n3", "type": "command", "data": {"command": "Release"}}
+		],
+		"edges": [
+			{"id": "e2", "source": "n1", "target": "n3"}
+		]
+	}`
+
+	diff, err := DiffFlowData(before, after)
+	if err != nil {
+		t.Fatalf("DiffFlowData failed: %v", err)
+	}
+
+	if len(diff.Nodes) != 3 {
+		t.Fatalf("expected 3 node diffs, got %+v", diff.Nodes)
+	}
+
+	n1 := diff.Nodes[0]
+	if n1.ID != "n1" || n1.Change != ChangeModified {
+		t.Fatalf("expected n1 changed, got %+v", n1)
+	}
+	expectedPaths := []string{"data.targetUri", "data.timeout", "position.y"}
+	if len(n1.Fields) != len(expectedPaths) {
+		t.Fatalf("expected %d field changes, got %+v", len(expectedPaths), n1.Fields)
+	}
+	for i, path := range expectedPaths {
+		if n1.Fields[i].Path != path {
+			t.Errorf("field[%d]: expected %s, got %s", i, path, n1.Fields[i].Path)
+		}
+	}
+	if n1.Fields[0].Before != "sip:100@pbx" || n1.Fields[0].After != "sip:200@pbx" {
+		t.Errorf("unexpected targetUri change: %+v", n1.Fields[0])
+	}
+	if n1.Fields[1].Before != nil {
+		t.Errorf("expected added field to have nil before, got %v", n1.Fields[1].Before)
+	}
+
+	if diff.Nodes[1].ID != "n2" || diff.Nodes[1].Change != ChangeRemoved {
+		t.Errorf("expected n2 removed, got %+v", diff.Nodes[1])
+	}
+	if diff.Nodes[2].ID != "n3" || diff.Nodes[2].Change != ChangeAdded {
+		t.Errorf("expected n3 added, got %+v", diff.Nodes[2])
+	}
+
+	if len(diff.Edges) != 2 || diff.Edges[0].Change != ChangeRemoved || diff.Edges[1].Change != ChangeAdded {
+		t.Errorf("expected e1 removed and e2 added, got %+v", diff.Edges)
+	}
+}
@@ -0,0 +1,186 @@
+package scenario
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateSchedule stores a new schedule and fills in its ID and timestamps
+func (r *Repository) CreateSchedule(s *Schedule) error {
+	s.ID = uuid.New().String()
+	now := time.Now()
+
+	query := `
+		INSERT INTO schedules (id, project_id, name, cron, target_kind, target_id, enabled, notify_url, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query, s.ID, s.ProjectID, s.Name, s.Cron, s.TargetKind, s.TargetID, s.Enabled, s.NotifyURL, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	return nil
+}
+
+// UpdateSchedule replaces the editable fields of an existing schedule
+func (r *Repository) UpdateSchedule(s *Schedule) error {
+	now := time.Now()
+	query := `
+		UPDATE schedules
+		SET name = ?, cron = ?, target_kind = ?, target_id = ?, enabled = ?, notify_url = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, s.Name, s.Cron, s.TargetKind, s.TargetID, s.Enabled, s.NotifyURL, now, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.UpdatedAt = now
+	return nil
+}
+
+// LoadSchedule retrieves a schedule by ID
+func (r *Repository) LoadSchedule(id string) (*Schedule, error) {
+	query := `
+		SELECT id, project_id, name, cron, target_kind, target_id, enabled, notify_url, created_at, updated_at
+		FROM schedules
+		WHERE id = ?
+	`
+
+	var s Schedule
+	err := r.db.QueryRow(query, id).Scan(
+		&s.ID, &s.ProjectID, &s.Name, &s.Cron, &s.TargetKind, &s.TargetID, &s.Enabled, &s.NotifyURL, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// ListSchedules retrieves schedules ordered by name. An empty projectID lists schedules of every project.
+func (r *Repository) ListSchedules(projectID string) ([]Schedule, error) {
+	query := `
+		SELECT id, project_id, name, cron, target_kind, target_id, enabled, notify_url, created_at, updated_at
+		FROM schedules
+		WHERE ? = '' OR project_id = ?
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, projectID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []Schedule{}
+	for rows.Next() {
+		var s Schedule
+		if err := rows.Scan(
+			&s.ID, &s.ProjectID, &s.Name, &s.Cron, &s.TargetKind, &s.TargetID, &s.Enabled, &s.NotifyURL, &s.CreatedAt, &s.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// DeleteSchedule removes a schedule and its run history
+func (r *Repository) DeleteSchedule(id string) error {
+	query := `DELETE FROM schedules WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordScheduleRun appends a run result to a schedule's history and fills in its ID
+func (r *Repository) RecordScheduleRun(run *ScheduleRun) error {
+	query := `
+		INSERT INTO schedule_runs (schedule_id, run_id, status, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, run.ScheduleID, run.RunID, run.Status, run.Error, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record schedule run: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get schedule run id: %w", err)
+	}
+
+	run.ID = id
+	return nil
+}
+
+// ListScheduleRuns retrieves a schedule's history, newest first. A limit of 0 or less returns every run.
+func (r *Repository) ListScheduleRuns(scheduleID string, limit int) ([]ScheduleRun, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `
+		SELECT id, schedule_id, run_id, status, error, started_at, finished_at
+		FROM schedule_runs
+		WHERE schedule_id = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []ScheduleRun{}
+	for rows.Next() {
+		var run ScheduleRun
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.RunID, &run.Status, &run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,72 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedule_CRUDAndHistory(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	sc, _ := repo.CreateScenario("default", "Smoke")
+	sched := &Schedule{
+		ProjectID:  "default",
+		Name:       "Nightly smoke",
+		Cron:       "0 2 * * *",
+		TargetKind: ScheduleTargetScenario,
+		TargetID:   sc.ID,
+		Enabled:    true,
+	}
+	if err := repo.CreateSchedule(sched); err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+
+	sched.Enabled = false
+	sched.NotifyURL = "http://hooks.example.com/sipflow"
+	if err := repo.UpdateSchedule(sched); err != nil {
+		t.Fatalf("UpdateSchedule failed: %v", err)
+	}
+
+	loaded, err := repo.LoadSchedule(sched.ID)
+	if err != nil {
+		t.Fatalf("LoadSchedule failed: %v", err)
+	}
+	if loaded.Enabled || loaded.NotifyURL != sched.NotifyURL || loaded.Cron != "0 2 * * *" {
+		t.Errorf("unexpected loaded schedule: %+v", loaded)
+	}
+
+	started := time.Now().Add(-time.Minute)
+	for _, status := range []string{"completed", "failed"} {
+		run := &ScheduleRun{ScheduleID: sched.ID, RunID: "run-" + status, Status: status, StartedAt: started, FinishedAt: started.Add(time.Second)}
+		if err := repo.RecordScheduleRun(run); err != nil {
+			t.Fatalf("RecordScheduleRun failed: %v", err)
+		}
+		started = started.Add(10 * time.Second)
+	}
+
+	runs, err := repo.ListScheduleRuns(sched.ID, 1)
+	if err != nil {
+		t.Fatalf("ListScheduleRuns failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != "failed" {
+		t.Errorf("expected newest run only, got %+v", runs)
+	}
+
+	all, _ := repo.ListSchedules("")
+	if len(all) != 1 {
+		t.Errorf("expected 1 schedule across projects, got %d", len(all))
+	}
+
+	if err := repo.DeleteSchedule(sched.ID); err != nil {
+		t.Fatalf("DeleteSchedule failed: %v", err)
+	}
+	runs, _ = repo.ListScheduleRuns(sched.ID, 0)
+	if len(runs) != 0 {
+		t.Errorf("expected history removed with schedule, got %d", len(runs))
+	}
+}
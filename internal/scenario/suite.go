@@ -0,0 +1,241 @@
+package scenario
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateSuite stores a new suite with its entries and fills in its ID and timestamps
+func (r *Repository) CreateSuite(s *Suite) error {
+	variables, err := encodeVariables(s.Variables)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin suite create: %w", err)
+	}
+	defer tx.Rollback()
+
+	id := uuid.New().String()
+	now := time.Now()
+	query := `
+		INSERT INTO suites (id, project_id, name, setup_scenario_id, variables, stop_on_failure, retries, timeout_seconds, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = tx.Exec(query, id, s.ProjectID, s.Name, s.SetupScenarioID, variables, s.StopOnFailure, s.Retries, s.TimeoutSeconds, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create suite: %w", err)
+	}
+
+	if err := replaceSuiteEntries(tx, id, s.Entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit suite create: %w", err)
+	}
+
+	s.ID = id
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	return nil
+}
+
+// UpdateSuite replaces the settings and entries of an existing suite
+func (r *Repository) UpdateSuite(s *Suite) error {
+	variables, err := encodeVariables(s.Variables)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin suite update: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		UPDATE suites
+		SET name = ?, setup_scenario_id = ?, variables = ?, stop_on_failure = ?, retries = ?, timeout_seconds = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := tx.Exec(query, s.Name, s.SetupScenarioID, variables, s.StopOnFailure, s.Retries, s.TimeoutSeconds, now, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update suite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := replaceSuiteEntries(tx, s.ID, s.Entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit suite update: %w", err)
+	}
+
+	s.UpdatedAt = now
+	return nil
+}
+
+func replaceSuiteEntries(tx *sql.Tx, suiteID string, entries []SuiteEntry) error {
+	if _, err := tx.Exec(`DELETE FROM suite_entries WHERE suite_id = ?`, suiteID); err != nil {
+		return fmt.Errorf("failed to clear suite entries: %w", err)
+	}
+
+	for i, entry := range entries {
+		variables, err := encodeVariables(entry.Variables)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO suite_entries (suite_id, position, scenario_id, variables)
+			VALUES (?, ?, ?, ?)
+		`
+		if _, err := tx.Exec(query, suiteID, i, entry.ScenarioID, variables); err != nil {
+			return fmt.Errorf("failed to save suite entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// LoadSuite retrieves a suite and its entries in order
+func (r *Repository) LoadSuite(id string) (*Suite, error) {
+	query := `
+		SELECT id, project_id, name, setup_scenario_id, variables, stop_on_failure, retries, timeout_seconds, created_at, updated_at
+		FROM suites
+		WHERE id = ?
+	`
+
+	var s Suite
+	var variables string
+	err := r.db.QueryRow(query, id).Scan(
+		&s.ID, &s.ProjectID, &s.Name, &s.SetupScenarioID, &variables, &s.StopOnFailure, &s.Retries, &s.TimeoutSeconds, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Variables, err = decodeVariables(variables); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT scenario_id, variables
+		FROM suite_entries
+		WHERE suite_id = ?
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load suite entries: %w", err)
+	}
+	defer rows.Close()
+
+	s.Entries = []SuiteEntry{}
+	for rows.Next() {
+		var entry SuiteEntry
+		if err := rows.Scan(&entry.ScenarioID, &variables); err != nil {
+			return nil, fmt.Errorf("failed to scan suite entry: %w", err)
+		}
+		if entry.Variables, err = decodeVariables(variables); err != nil {
+			return nil, err
+		}
+		s.Entries = append(s.Entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suite entries: %w", err)
+	}
+
+	return &s, nil
+}
+
+// ListSuites retrieves all suites for a project, ordered by name
+func (r *Repository) ListSuites(projectID string) ([]SuiteListItem, error) {
+	query := `
+		SELECT s.id, s.project_id, s.name, COUNT(e.position), s.created_at, s.updated_at
+		FROM suites s
+		LEFT JOIN suite_entries e ON e.suite_id = s.id
+		WHERE s.project_id = ?
+		GROUP BY s.id
+		ORDER BY s.name
+	`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suites: %w", err)
+	}
+	defer rows.Close()
+
+	suites := []SuiteListItem{}
+	for rows.Next() {
+		var s SuiteListItem
+		if err := rows.Scan(&s.ID, &s.ProjectID, &s.Name, &s.EntryCount, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan suite: %w", err)
+		}
+		suites = append(suites, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suites: %w", err)
+	}
+
+	return suites, nil
+}
+
+// DeleteSuite removes a suite and its entries
+func (r *Repository) DeleteSuite(id string) error {
+	query := `DELETE FROM suites WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete suite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func encodeVariables(variables map[string]interface{}) (string, error) {
+	if variables == nil {
+		variables = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(variables)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode variables: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func decodeVariables(encoded string) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(encoded), &variables); err != nil {
+		return nil, fmt.Errorf("failed to decode variables: %w", err)
+	}
+	return variables, nil
+}
@@ -0,0 +1,70 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSuite_CRUDKeepsEntryOrder(t *testing.T) {
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	setup, _ := repo.CreateScenario("default", "Register all")
+	first, _ := repo.CreateScenario("default", "Basic call")
+	second, _ := repo.CreateScenario("default", "Blind transfer")
+
+	suite := &Suite{
+		ProjectID:       "default",
+		Name:            "Core regression",
+		SetupScenarioID: setup.ID,
+		Variables:       map[string]interface{}{"target": "200"},
+		StopOnFailure:   true,
+		Retries:         1,
+		TimeoutSeconds:  60,
+		Entries: []SuiteEntry{
+			{ScenarioID: second.ID, Variables: map[string]interface{}{"target": "300"}},
+			{ScenarioID: first.ID},
+		},
+	}
+	if err := repo.CreateSuite(suite); err != nil {
+		t.Fatalf("CreateSuite failed: %v", err)
+	}
+
+	loaded, err := repo.LoadSuite(suite.ID)
+	if err != nil {
+		t.Fatalf("LoadSuite failed: %v", err)
+	}
+	if len(loaded.Entries) != 2 || loaded.Entries[0].ScenarioID != second.ID || loaded.Entries[0].Variables["target"] != "300" {
+		t.Fatalf("unexpected entries: %+v", loaded.Entries)
+	}
+	if loaded.Variables["target"] != "200" || !loaded.StopOnFailure || loaded.Retries != 1 || loaded.TimeoutSeconds != 60 {
+		t.Errorf("unexpected suite settings: %+v", loaded)
+	}
+
+	loaded.Entries = loaded.Entries[1:]
+	if err := repo.UpdateSuite(loaded); err != nil {
+		t.Fatalf("UpdateSuite failed: %v", err)
+	}
+
+	// deleting a scenario drops it from every suite
+	if err := repo.DeleteScenario(first.ID); err != nil {
+		t.Fatalf("DeleteScenario failed: %v", err)
+	}
+	suites, err := repo.ListSuites("default")
+	if err != nil {
+		t.Fatalf("ListSuites failed: %v", err)
+	}
+	if len(suites) != 1 || suites[0].EntryCount != 0 {
+		t.Errorf("expected suite with no entries left, got %+v", suites)
+	}
+
+	if err := repo.DeleteSuite(suite.ID); err != nil {
+		t.Fatalf("DeleteSuite failed: %v", err)
+	}
+	if _, err := repo.LoadSuite(suite.ID); err == nil {
+		t.Error("expected deleted suite to be gone")
+	}
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sipflow/internal/scenario"
+)
+
+// Failure describes a failed scheduled run passed to notification hooks
+type Failure struct {
+	Schedule scenario.Schedule    `json:"schedule"`
+	Run      scenario.ScheduleRun `json:"run"`
+}
+
+// Notifier is a hook fired when a scheduled run fails
+type Notifier interface {
+	NotifyFailure(ctx context.Context, failure Failure) error
+}
+
+// WebhookNotifier POSTs the failure as JSON to the schedule's notify URL
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a bounded request timeout
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NotifyFailure sends the failure to the schedule's webhook; schedules without one are skipped
+func (w *WebhookNotifier) NotifyFailure(ctx context.Context, failure Failure) error {
+	if failure.Schedule.NotifyURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(failure)
+	if err != nil {
+		return fmt.Errorf("failed to encode failure notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, failure.Schedule.NotifyURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(ctx context.Context, failure Failure) error
+
+// NotifyFailure calls f
+func (f NotifierFunc) NotifyFailure(ctx context.Context, failure Failure) error {
+	return f(ctx, failure)
+}
@@ -0,0 +1,280 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sipflow/internal/engine"
+	"sipflow/internal/scenario"
+)
+
+// pollInterval is how often enabled schedules are checked for due runs
+const pollInterval = 15 * time.Second
+
+// ErrScheduleRunning is returned when a schedule is triggered while its previous run is still active
+var ErrScheduleRunning = errors.New("schedule is already running")
+
+// Runner executes a scenario or suite to completion. *engine.Engine satisfies it.
+type Runner interface {
+	RunScenario(ctx context.Context, scenarioID string, sim *engine.SimulationOptions) (*engine.RunResult, error)
+	RunSuite(ctx context.Context, suiteID string, sim *engine.SimulationOptions) (*engine.SuiteReport, error)
+}
+
+// Scheduler runs stored schedules in the background while the process is alive.
+// Runs missed while the process was not running are not caught up.
+type Scheduler struct {
+	repo      *scenario.Repository
+	runner    Runner
+	notifiers []Notifier
+	onError   func(err error)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	next    map[string]dueTime // schedule ID -> next due time
+	running map[string]bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// dueTime remembers which cron expression a due time was computed from,
+// so an edited schedule is rescheduled on the next poll
+type dueTime struct {
+	cron string
+	at   time.Time
+}
+
+// New creates a scheduler that notifies failures through each schedule's webhook
+func New(repo *scenario.Repository, runner Runner) *Scheduler {
+	return &Scheduler{
+		repo:      repo,
+		runner:    runner,
+		notifiers: []Notifier{NewWebhookNotifier()},
+		now:       time.Now,
+		next:      make(map[string]dueTime),
+		running:   make(map[string]bool),
+	}
+}
+
+// AddNotifier registers an additional failure hook. Call before Start.
+func (s *Scheduler) AddNotifier(n Notifier) {
+	s.notifiers = append(s.notifiers, n)
+}
+
+// SetErrorHandler registers a callback for errors that happen in the background,
+// such as failing to list schedules, record a run or deliver a failure notification. Call before Start.
+func (s *Scheduler) SetErrorHandler(fn func(err error)) {
+	s.onError = fn
+}
+
+func (s *Scheduler) reportError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// Start begins polling schedules until Stop is called or ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	runCtx := s.ctx
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.tick(s.now())
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels in-flight runs and waits for them to be recorded
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// NextRun returns when an enabled schedule is next due, or the zero time if it is not scheduled
+func (s *Scheduler) NextRun(sched *scenario.Schedule) time.Time {
+	if !sched.Enabled {
+		return time.Time{}
+	}
+
+	s.mu.Lock()
+	due, ok := s.next[sched.ID]
+	s.mu.Unlock()
+	if ok && due.cron == sched.Cron {
+		return due.at
+	}
+
+	spec, err := ParseCron(sched.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	return spec.Next(s.now())
+}
+
+// RunNow triggers a schedule immediately in the background, regardless of its cron expression
+func (s *Scheduler) RunNow(scheduleID string) error {
+	sched, err := s.repo.LoadSchedule(scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedule: %w", err)
+	}
+	return s.trigger(*sched)
+}
+
+// tick fires every enabled schedule whose due time has passed
+func (s *Scheduler) tick(now time.Time) {
+	schedules, err := s.repo.ListSchedules("")
+	if err != nil {
+		s.reportError(fmt.Errorf("failed to list schedules: %w", err))
+		return
+	}
+
+	seen := make(map[string]bool, len(schedules))
+	for _, sched := range schedules {
+		if !sched.Enabled {
+			continue
+		}
+		seen[sched.ID] = true
+
+		spec, err := ParseCron(sched.Cron)
+		if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		due, ok := s.next[sched.ID]
+		if !ok || due.cron != sched.Cron {
+			due = dueTime{cron: sched.Cron, at: spec.Next(now)}
+			s.next[sched.ID] = due
+		}
+		fire := !due.at.IsZero() && !now.Before(due.at)
+		if fire {
+			s.next[sched.ID] = dueTime{cron: sched.Cron, at: spec.Next(now)}
+		}
+		s.mu.Unlock()
+
+		if fire {
+			// an overlapping run is skipped; the schedule fires again at its next due time
+			s.trigger(sched)
+		}
+	}
+
+	s.mu.Lock()
+	for id := range s.next {
+		if !seen[id] {
+			delete(s.next, id)
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) trigger(sched scenario.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx == nil || s.ctx.Err() != nil {
+		return errors.New("scheduler is not running")
+	}
+	if s.running[sched.ID] {
+		return ErrScheduleRunning
+	}
+	s.running[sched.ID] = true
+
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(ctx, sched)
+
+		s.mu.Lock()
+		delete(s.running, sched.ID)
+		s.mu.Unlock()
+	}()
+	return nil
+}
+
+// execute runs the schedule target, records the result and fires the failure hooks
+func (s *Scheduler) execute(ctx context.Context, sched scenario.Schedule) {
+	record := &scenario.ScheduleRun{
+		ScheduleID: sched.ID,
+		StartedAt:  s.now(),
+	}
+
+	result, err := s.runTarget(ctx, sched)
+	switch {
+	case err != nil:
+		record.Status = engine.RunStatusFailed
+		record.Error = err.Error()
+	default:
+		record.RunID = result.RunID
+		record.Status = result.Status
+		record.Error = result.Error
+		record.StartedAt = result.StartedAt
+	}
+	record.FinishedAt = s.now()
+
+	// history is kept even if the app is shutting down
+	if err := s.repo.RecordScheduleRun(record); err != nil {
+		s.reportError(fmt.Errorf("schedule %s: failed to record run: %w", sched.Name, err))
+	}
+
+	if record.Status != engine.RunStatusFailed {
+		return
+	}
+	failure := Failure{Schedule: sched, Run: *record}
+	for _, n := range s.notifiers {
+		notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := n.NotifyFailure(notifyCtx, failure); err != nil {
+			s.reportError(fmt.Errorf("schedule %s: failed to notify failure: %w", sched.Name, err))
+		}
+		cancel()
+	}
+}
+
+func (s *Scheduler) runTarget(ctx context.Context, sched scenario.Schedule) (*engine.RunResult, error) {
+	switch sched.TargetKind {
+	case scenario.ScheduleTargetScenario:
+		return s.runner.RunScenario(ctx, sched.TargetID, nil)
+	case scenario.ScheduleTargetSuite:
+		report, err := s.runner.RunSuite(ctx, sched.TargetID, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := &engine.RunResult{
+			Status:     report.Status,
+			StartedAt:  report.StartedAt,
+			FinishedAt: report.FinishedAt,
+		}
+		if report.Status != engine.RunStatusCompleted {
+			result.Error = report.Summary()
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown schedule target kind %q", sched.TargetKind)
+	}
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sipflow/internal/engine"
+	"sipflow/internal/scenario"
+)
+
+// fakeRunner returns a canned status for each scenario
+type fakeRunner struct {
+	mu     sync.Mutex
+	status map[string]string
+	calls  []string
+}
+
+func (f *fakeRunner) RunScenario(ctx context.Context, scenarioID string, sim *engine.SimulationOptions) (*engine.RunResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, scenarioID)
+
+	result := &engine.RunResult{RunID: "run-" + scenarioID, ScenarioID: scenarioID, Status: f.status[scenarioID], StartedAt: time.Now()}
+	if result.Status == engine.RunStatusFailed {
+		result.Error = "instance inst-a: node make-call failed"
+	}
+	return result, nil
+}
+
+func (f *fakeRunner) RunSuite(ctx context.Context, suiteID string, sim *engine.SimulationOptions) (*engine.SuiteReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, suiteID)
+
+	report := &engine.SuiteReport{SuiteID: suiteID, Status: f.status[suiteID], Passed: 1, StartedAt: time.Now()}
+	if report.Status == engine.RunStatusFailed {
+		report.Failed = 1
+	}
+	return report, nil
+}
+
+func newTestScheduler(t *testing.T, runner Runner) (*Scheduler, *scenario.Repository) {
+	t.Helper()
+
+	repo, err := scenario.NewRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return New(repo, runner), repo
+}
+
+func TestScheduler_FiresDueScheduleAndRecordsHistory(t *testing.T) {
+	runner := &fakeRunner{status: map[string]string{"scn-ok": engine.RunStatusCompleted, "suite-bad": engine.RunStatusFailed}}
+	s, repo := newTestScheduler(t, runner)
+
+	var hookMu sync.Mutex
+	var hooked []Failure
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f Failure
+		json.NewDecoder(r.Body).Decode(&f)
+		hookMu.Lock()
+		hooked = append(hooked, f)
+		hookMu.Unlock()
+	}))
+	defer webhook.Close()
+
+	ok := &scenario.Schedule{ProjectID: "default", Name: "ok", Cron: "0 2 * * *", TargetKind: scenario.ScheduleTargetScenario, TargetID: "scn-ok", Enabled: true}
+	bad := &scenario.Schedule{ProjectID: "default", Name: "bad", Cron: "0 2 * * *", TargetKind: scenario.ScheduleTargetSuite, TargetID: "suite-bad", Enabled: true, NotifyURL: webhook.URL}
+	off := &scenario.Schedule{ProjectID: "default", Name: "off", Cron: "* * * * *", TargetKind: scenario.ScheduleTargetScenario, TargetID: "scn-ok", Enabled: false}
+	for _, sched := range []*scenario.Schedule{ok, bad, off} {
+		if err := repo.CreateSchedule(sched); err != nil {
+			t.Fatalf("CreateSchedule failed: %v", err)
+		}
+	}
+
+	start := time.Date(2026, 3, 14, 1, 59, 0, 0, time.Local)
+	s.now = func() time.Time { return start }
+	s.Start(context.Background())
+	defer s.Stop()
+
+	if next := s.NextRun(ok); !next.Equal(time.Date(2026, 3, 14, 2, 0, 0, 0, time.Local)) {
+		t.Errorf("unexpected next run %v", next)
+	}
+
+	s.tick(start.Add(30 * time.Second))
+	s.tick(start.Add(90 * time.Second))
+	s.Stop()
+
+	if len(runner.calls) != 2 {
+		t.Fatalf("expected both enabled schedules to run once, got %v", runner.calls)
+	}
+
+	runs, _ := repo.ListScheduleRuns(bad.ID, 0)
+	if len(runs) != 1 || runs[0].Status != engine.RunStatusFailed || runs[0].Error != "1 passed, 1 failed, 0 skipped" {
+		t.Errorf("unexpected history for failing schedule: %+v", runs)
+	}
+	runs, _ = repo.ListScheduleRuns(ok.ID, 0)
+	if len(runs) != 1 || runs[0].Status != engine.RunStatusCompleted {
+		t.Errorf("unexpected history for passing schedule: %+v", runs)
+	}
+
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	if len(hooked) != 1 || hooked[0].Schedule.ID != bad.ID || hooked[0].Run.Error == "" {
+		t.Errorf("expected one webhook for the failing schedule, got %+v", hooked)
+	}
+}
+
+func TestScheduler_RunNowRecordsUnknownTarget(t *testing.T) {
+	s, repo := newTestScheduler(t, &fakeRunner{})
+
+	var failures []Failure
+	s.AddNotifier(NotifierFunc(func(ctx context.Context, f Failure) error {
+		failures = append(failures, f)
+		return errors.New("hook unavailable")
+	}))
+	var reported []error
+	s.SetErrorHandler(func(err error) { reported = append(reported, err) })
+
+	sched := &scenario.Schedule{ProjectID: "default", Name: "broken", Cron: "@daily", TargetKind: "playlist", TargetID: "x", Enabled: true}
+	if err := repo.CreateSchedule(sched); err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+
+	if err := s.RunNow(sched.ID); err == nil {
+		t.Fatal("expected RunNow to fail before Start")
+	}
+
+	s.Start(context.Background())
+	if err := s.RunNow(sched.ID); err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	s.Stop()
+
+	runs, _ := repo.ListScheduleRuns(sched.ID, 0)
+	if len(runs) != 1 || runs[0].Status != engine.RunStatusFailed || runs[0].Error == "" {
+		t.Errorf("expected recorded failure for unknown target, got %+v", runs)
+	}
+	if len(failures) != 1 {
+		t.Errorf("expected failure hook to fire once, got %d", len(failures))
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "failed to notify failure: hook unavailable") {
+		t.Errorf("expected the hook error to be reported, got %v", reported)
+	}
+}
@@ -31,6 +31,7 @@ func main() {
 			app.engineBinding,
 			app.scenarioBinding,
 			app.mediaBinding,
+			app.scheduleBinding,
 		},
 	})
 